@@ -0,0 +1,41 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// requireToken wraps a handler so that it only serves requests carrying
+// "Authorization: Bearer <token>". An empty token disables the check.
+func requireToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	if token == "" {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		const prefix = "Bearer "
+		if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) ||
+			subtle.ConstantTimeCompare([]byte(header[len(prefix):]), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="golang-ai-agent"`)
+			writeJSONError(w, http.StatusUnauthorized, "Missing or invalid bearer token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requireTokenFor is requireToken for the requests of the given methods only,
+// e.g. to protect the writes of an endpoint whose reads are public
+func requireTokenFor(token string, next http.HandlerFunc, methods ...string) http.HandlerFunc {
+	protected := requireToken(token, next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		for _, method := range methods {
+			if r.Method == method {
+				protected(w, r)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
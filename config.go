@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 )
 
 type Config struct {
@@ -12,36 +13,90 @@ type Config struct {
 		Host         string `json:"host"`
 		ReadTimeout  int    `json:"read_timeout"`
 		WriteTimeout int    `json:"write_timeout"`
+		// ShutdownTimeout is how long in-flight requests may take to finish on shutdown
+		ShutdownTimeout int `json:"shutdown_timeout"`
+		// APIToken, when set, is the bearer token required by endpoints that
+		// generate, test or analyze applications
+		APIToken string `json:"api_token"`
 	} `json:"server"`
 	
 	GitHub struct {
 		Token         string `json:"token"`
 		WebhookSecret string `json:"webhook_secret"`
 		BaseURL       string `json:"base_url"`
+		// GitHub App authentication, used instead of Token when AppID is set
+		AppID          int64  `json:"app_id"`
+		InstallationID int64  `json:"installation_id"`
+		PrivateKeyPath string `json:"private_key_path"`
 	} `json:"github"`
 	
 	Storage struct {
-		Type string `json:"type"`
+		Type string `json:"type"` // file or s3
+		// Path is the data directory holding the fine-tuning database and,
+		// for file storage, the projects
 		Path string `json:"path"`
+		// S3 settings; credentials come from AWS_ACCESS_KEY_ID,
+		// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+		Bucket   string `json:"bucket"`
+		Region   string `json:"region"`
+		Prefix   string `json:"prefix"`
+		Endpoint string `json:"endpoint"`
+		// OutputDir is where generated applications are written
+		OutputDir string `json:"output_dir"`
 	} `json:"storage"`
 	
+	// Database tunes the SQLite fine-tuning database
+	Database struct {
+		JournalMode  string `json:"journal_mode"`
+		BusyTimeout  int    `json:"busy_timeout"` // milliseconds
+		MaxOpenConns int    `json:"max_open_conns"`
+	} `json:"database"`
+
 	Testing struct {
 		Timeout       int  `json:"timeout"`
 		Parallel      bool `json:"parallel"`
 		Coverage      bool `json:"coverage"`
 		SecurityScan  bool `json:"security_scan"`
+		CoverageThreshold float64 `json:"coverage_threshold"`
+		SmokeTest     bool `json:"smoke_test"`
+		APIPort       int  `json:"api_port"`
+		SecurityFailSeverity string `json:"security_fail_severity"` // none, low, medium, high
+		CommandTimeouts map[string]int `json:"command_timeouts"` // seconds per language
+		// ArtifactsDir holds the binaries and databases of API tests; empty uses the app directory
+		ArtifactsDir  string `json:"artifacts_dir"`
+		KeepArtifacts bool   `json:"keep_artifacts"`
 	} `json:"testing"`
 	
+	Generation struct {
+		MaxEntities  int   `json:"max_entities"`
+		MaxEndpoints int   `json:"max_endpoints"`
+		MaxFiles     int   `json:"max_files"`
+		MaxBytes     int64 `json:"max_bytes"`
+		TemplatesDir string `json:"templates_dir"`
+		CompileCheck bool   `json:"compile_check"`
+		// CollisionPolicy handles regenerating an existing app: error, overwrite or suffix
+		CollisionPolicy string `json:"collision_policy"`
+		// LicenseHeader, when set, is inserted at the top of every generated source file
+		LicenseHeader string `json:"license_header"`
+	} `json:"generation"`
+	
 	Debugging struct {
 		LogLevel    string `json:"log_level"`
 		ProfileMode bool   `json:"profile_mode"`
 		MaxSessions int    `json:"max_sessions"`
 	} `json:"debugging"`
 	
+	Finetuning struct {
+		Interval int `json:"interval"` // seconds between scheduled runs
+	} `json:"finetuning"`
+
 	Workflow struct {
-		MaxConcurrent int `json:"max_concurrent"`
-		RetryAttempts int `json:"retry_attempts"`
-		CleanupAfter  int `json:"cleanup_after"`
+		MaxConcurrent int    `json:"max_concurrent"`
+		RetryAttempts int    `json:"retry_attempts"`
+		CleanupAfter  int    `json:"cleanup_after"`
+		Dir           string `json:"dir"` // YAML and JSON workflow definitions loaded at startup
+		// QualityThreshold is the lowest quality score the ci_cd analyze step accepts
+		QualityThreshold float64 `json:"quality_threshold"`
 	} `json:"workflow"`
 }
 
@@ -52,25 +107,47 @@ func LoadConfig(configPath string) (*Config, error) {
 	config.Server.Port = "8080"
 	config.Server.Host = "0.0.0.0"
 	config.Server.ReadTimeout = 30
-	config.Server.WriteTimeout = 30
+	// /generate-and-test replies once the tests are done, which may take up
+	// to testing.timeout
+	config.Server.WriteTimeout = 600
+	config.Server.ShutdownTimeout = 30
 	
 	config.GitHub.BaseURL = "https://api.github.com"
 	
 	config.Storage.Type = "file"
 	config.Storage.Path = "./data"
+	config.Storage.OutputDir = "./generated_apps"
 	
+	config.Database.JournalMode = "WAL"
+	config.Database.BusyTimeout = 5000
+	config.Database.MaxOpenConns = 1
+
 	config.Testing.Timeout = 300
 	config.Testing.Parallel = true
 	config.Testing.Coverage = true
 	config.Testing.SecurityScan = true
+	config.Testing.CoverageThreshold = 0
+	config.Testing.SmokeTest = false
+	config.Testing.APIPort = 0
+	config.Testing.SecurityFailSeverity = "high"
+	
+	config.Generation.MaxEntities = 20
+	config.Generation.MaxEndpoints = 100
+	config.Generation.MaxFiles = 500
+	config.Generation.MaxBytes = 10 * 1024 * 1024
+	config.Generation.CompileCheck = true
+	config.Generation.CollisionPolicy = "overwrite"
 	
 	config.Debugging.LogLevel = "info"
 	config.Debugging.ProfileMode = false
 	config.Debugging.MaxSessions = 5
 	
+	config.Finetuning.Interval = 300
+
 	config.Workflow.MaxConcurrent = 3
 	config.Workflow.RetryAttempts = 3
 	config.Workflow.CleanupAfter = 24
+	config.Workflow.QualityThreshold = 60
 	
 	// Load from file if exists
 	if configPath != "" {
@@ -95,9 +172,41 @@ func LoadConfig(configPath string) (*Config, error) {
 		config.GitHub.WebhookSecret = secret
 	}
 	
+	if dir := os.Getenv("TEMPLATES_DIR"); dir != "" {
+		config.Generation.TemplatesDir = dir
+	}
+
 	if port := os.Getenv("PORT"); port != "" {
 		config.Server.Port = port
 	}
+
+	if region := os.Getenv("AWS_REGION"); region != "" && config.Storage.Region == "" {
+		config.Storage.Region = region
+	}
+
+	if token := os.Getenv("API_TOKEN"); token != "" {
+		config.Server.APIToken = token
+	}
+
+	if dir := os.Getenv("DATA_DIR"); dir != "" {
+		config.Storage.Path = dir
+	}
+
+	if dir := os.Getenv("OUTPUT_DIR"); dir != "" {
+		config.Storage.OutputDir = dir
+	}
+
+	// FINETUNE_INTERVAL is a duration such as "10m"
+	if value := os.Getenv("FINETUNE_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FINETUNE_INTERVAL: %w", err)
+		}
+		if interval < time.Second {
+			return nil, fmt.Errorf("invalid FINETUNE_INTERVAL: %s is shorter than a second", value)
+		}
+		config.Finetuning.Interval = int(interval / time.Second)
+	}
 	
 	return config, nil
 }
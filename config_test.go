@@ -0,0 +1,47 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigEnvironment(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "missing.json")
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Storage.Path != "./data" || cfg.Storage.OutputDir != "./generated_apps" || cfg.Finetuning.Interval != 300 {
+		t.Errorf("Expected default directories and interval, got %q, %q and %d",
+			cfg.Storage.Path, cfg.Storage.OutputDir, cfg.Finetuning.Interval)
+	}
+
+	t.Setenv("DATA_DIR", "/var/lib/agent")
+	t.Setenv("OUTPUT_DIR", "/srv/apps")
+	t.Setenv("FINETUNE_INTERVAL", "10m")
+	t.Setenv("TEMPLATES_DIR", "/etc/agent/templates")
+	cfg, err = LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Storage.Path != "/var/lib/agent" {
+		t.Errorf("Expected DATA_DIR to set the data directory, got %q", cfg.Storage.Path)
+	}
+	if cfg.Storage.OutputDir != "/srv/apps" {
+		t.Errorf("Expected OUTPUT_DIR to set the output directory, got %q", cfg.Storage.OutputDir)
+	}
+	if cfg.Finetuning.Interval != 600 {
+		t.Errorf("Expected a 600 second interval, got %d", cfg.Finetuning.Interval)
+	}
+	if cfg.Generation.TemplatesDir != "/etc/agent/templates" {
+		t.Errorf("Expected TEMPLATES_DIR to set the templates directory, got %q", cfg.Generation.TemplatesDir)
+	}
+
+	for _, interval := range []string{"300", "soon", "500ms", "-1m"} {
+		t.Setenv("FINETUNE_INTERVAL", interval)
+		if _, err := LoadConfig(configPath); err == nil {
+			t.Errorf("Expected FINETUNE_INTERVAL=%s to be rejected", interval)
+		}
+	}
+}
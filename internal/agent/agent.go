@@ -4,9 +4,11 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/kevinpranata97/golang-ai-agent/internal/github"
+	"github.com/kevinpranata97/golang-ai-agent/internal/logging"
 	"github.com/kevinpranata97/golang-ai-agent/internal/storage"
 	testingpkg "github.com/kevinpranata97/golang-ai-agent/internal/testing"
 	"github.com/kevinpranata97/golang-ai-agent/internal/workflow"
@@ -18,10 +20,16 @@ type Agent struct {
 	Status    string
 	CreatedAt time.Time
 	Jobs      map[string]*Job
-	Storage *storage.FileStorage
+	Storage storage.Storage
 	GithubClient *github.Client
 	TestRunner *testingpkg.TestRunner
 	WorkflowEngine *workflow.Engine
+
+	logger        logging.Logger
+	webhookSecret string
+	webhooks      sync.WaitGroup // webhook deliveries still being processed
+	mutex         sync.Mutex
+	processed     map[string]time.Time // repo@sha of recently run commits
 }
 
 // Job represents a processing job
@@ -37,7 +45,7 @@ type Job struct {
 }
 
 // NewAgent creates a new AI agent instance
-func NewAgent(storage *storage.FileStorage, githubClient *github.Client, testRunner *testingpkg.TestRunner, workflowEngine *workflow.Engine) *Agent {
+func NewAgent(storage storage.Storage, githubClient *github.Client, testRunner *testingpkg.TestRunner, workflowEngine *workflow.Engine) *Agent {
 	return &Agent{
 		ID:        generateID(),
 		Status:    "idle",
@@ -47,9 +55,16 @@ func NewAgent(storage *storage.FileStorage, githubClient *github.Client, testRun
 		GithubClient: githubClient,
 		TestRunner: testRunner,
 		WorkflowEngine: workflowEngine,
+		processed: make(map[string]time.Time),
+		logger:    logging.Default(),
 	}
 }
 
+// SetLogger sets the logger for webhook processing
+func (a *Agent) SetLogger(logger logging.Logger) {
+	a.logger = logger
+}
+
 // generateID generates a random ID
 func generateID() string {
 	bytes := make([]byte, 16)
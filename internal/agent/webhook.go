@@ -0,0 +1,336 @@
+package agent
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/github"
+	"github.com/kevinpranata97/golang-ai-agent/internal/workflow"
+)
+
+// commentOutputLines caps how much of a failed step's output is quoted in a PR comment
+const commentOutputLines = 30
+
+// WebhookPayload is the subset of GitHub push and pull_request events the agent acts on
+type WebhookPayload struct {
+	// push
+	Ref     string `json:"ref"`
+	After   string `json:"after"`
+	Deleted bool   `json:"deleted"`
+
+	// pull_request
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Head struct {
+			Ref  string `json:"ref"`
+			SHA  string `json:"sha"`
+			Repo struct {
+				FullName string `json:"full_name"`
+				CloneURL string `json:"clone_url"`
+			} `json:"repo"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+
+	Repository struct {
+		FullName string `json:"full_name"`
+		CloneURL string `json:"clone_url"`
+	} `json:"repository"`
+	Commits []struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+		Author  struct {
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"commits"`
+}
+
+// maxWebhookBytes is the largest payload GitHub delivers
+const maxWebhookBytes = 25 << 20
+
+// processedCommitTTL is how long a commit is remembered to avoid running the
+// workflow twice when both a push and a pull_request event report it
+const processedCommitTTL = time.Hour
+
+// HandleWebhook accepts GitHub push and pull_request events, routed by the
+// X-GitHub-Event header, and runs the CI/CD workflow for them in the background
+func (a *Agent) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	event := r.Header.Get("X-GitHub-Event")
+	if event == "" {
+		http.Error(w, "Missing X-GitHub-Event header", http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBytes))
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	if !a.verifySignature(body, r.Header.Get("X-Hub-Signature-256"), r.Header.Get("X-Hub-Signature")) {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	var process func(WebhookPayload)
+	switch event {
+	case "push":
+		process = a.processWebhook
+	case "pull_request":
+		process = a.processPullRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if process == nil {
+		json.NewEncoder(w).Encode(map[string]string{"status": "ignored", "event": event})
+		return
+	}
+
+	a.webhooks.Add(1)
+	go func() {
+		defer a.webhooks.Done()
+		process(payload)
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "accepted", "event": event})
+}
+
+// SetWebhookSecret sets the secret webhook signatures are verified against.
+// With no secret, deliveries are accepted unsigned.
+func (a *Agent) SetWebhookSecret(secret string) {
+	a.webhookSecret = secret
+}
+
+// verifySignature checks the HMAC of body against the X-Hub-Signature-256
+// header, falling back to the legacy SHA-1 X-Hub-Signature header. When a
+// secret is configured, a delivery without either header is rejected.
+func (a *Agent) verifySignature(body []byte, signature256, signature1 string) bool {
+	if a.webhookSecret == "" {
+		return true
+	}
+	switch {
+	case signature256 != "":
+		return validHMAC(sha256.New, "sha256=", a.webhookSecret, body, signature256)
+	case signature1 != "":
+		return validHMAC(sha1.New, "sha1=", a.webhookSecret, body, signature1)
+	default:
+		return false
+	}
+}
+
+// validHMAC compares a "<algorithm>=<hex digest>" signature with the HMAC of
+// body in constant time
+func validHMAC(newHash func() hash.Hash, prefix, secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+	received, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(received, mac.Sum(nil))
+}
+
+// processWebhook runs the ci_cd workflow for a branch push, reports it as a
+// commit status and comments the result on any open pull request for the branch
+func (a *Agent) processWebhook(payload WebhookPayload) {
+	if payload.Deleted || !strings.HasPrefix(payload.Ref, "refs/heads/") {
+		return
+	}
+	repo := payload.Repository.FullName
+	branch := strings.TrimPrefix(payload.Ref, "refs/heads/")
+	a.logger.Info("Processing push", "repository", repo, "branch", branch)
+
+	ctx := workflow.Context{
+		Repository: repo,
+		CloneURL:   payload.Repository.CloneURL,
+		Ref:        payload.Ref,
+	}
+	for _, commit := range payload.Commits {
+		ctx.Commits = append(ctx.Commits, workflow.Commit{ID: commit.ID, Message: commit.Message, Author: commit.Author.Name})
+	}
+
+	result, ok := a.runWorkflow(ctx, payload.After)
+	if !ok || a.GithubClient == nil {
+		return
+	}
+	pulls, err := a.GithubClient.ListOpenPullRequests(repo)
+	if err != nil {
+		a.logGitHubError("list pull requests", err)
+		return
+	}
+	comment := formatWorkflowComment(result)
+	for _, pr := range pulls {
+		if pr.Head.Ref != branch || (pr.Head.Repo.FullName != "" && pr.Head.Repo.FullName != repo) {
+			continue
+		}
+		if err := a.GithubClient.CreateIssueComment(repo, pr.Number, comment); err != nil {
+			a.logGitHubError(fmt.Sprintf("comment on pull request #%d", pr.Number), err)
+			return
+		}
+	}
+}
+
+// processPullRequest runs the ci_cd workflow against the head of an opened or
+// updated pull request, reports it on the head commit and comments the result
+func (a *Agent) processPullRequest(payload WebhookPayload) {
+	switch payload.Action {
+	case "opened", "synchronize", "reopened":
+	default:
+		return
+	}
+	repo := payload.Repository.FullName
+	head := payload.PullRequest.Head
+	a.logger.Info("Processing pull request", "repository", repo, "number", payload.Number, "head", head.Ref, "base", payload.PullRequest.Base.Ref)
+
+	// The head may live in a fork, so clone it from there
+	cloneURL := head.Repo.CloneURL
+	if cloneURL == "" {
+		cloneURL = payload.Repository.CloneURL
+	}
+	ctx := workflow.Context{
+		Repository: repo,
+		CloneURL:   cloneURL,
+		Ref:        "refs/heads/" + head.Ref,
+	}
+
+	result, ok := a.runWorkflow(ctx, head.SHA)
+	if !ok || a.GithubClient == nil {
+		return
+	}
+	if err := a.GithubClient.CreateIssueComment(repo, payload.Number, formatWorkflowComment(result)); err != nil {
+		a.logGitHubError(fmt.Sprintf("comment on pull request #%d", payload.Number), err)
+	}
+}
+
+// runWorkflow runs the ci_cd workflow for a commit and reports its state as a
+// commit status. It returns false without running when the commit was already
+// processed recently.
+func (a *Agent) runWorkflow(ctx workflow.Context, sha string) (workflow.Result, bool) {
+	if sha != "" && !a.markProcessed(ctx.Repository+"@"+sha) {
+		a.logger.Info("Skipping commit: already processed", "repository", ctx.Repository, "sha", sha)
+		return workflow.Result{}, false
+	}
+
+	a.setCommitStatus(ctx.Repository, sha, "pending", "Workflow running")
+	result := a.WorkflowEngine.ExecuteWorkflow("ci_cd", ctx)
+	if result.Success {
+		a.setCommitStatus(ctx.Repository, sha, "success", "Workflow passed")
+	} else {
+		a.setCommitStatus(ctx.Repository, sha, "failure", "Workflow failed: "+result.Error)
+	}
+	return result, true
+}
+
+// markProcessed records a commit key, returning false if it was already recorded
+func (a *Agent) markProcessed(key string) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := time.Now()
+	for k, at := range a.processed {
+		if now.Sub(at) > processedCommitTTL {
+			delete(a.processed, k)
+		}
+	}
+	if _, seen := a.processed[key]; seen {
+		return false
+	}
+	a.processed[key] = now
+	return true
+}
+
+// setCommitStatus reports a workflow state on a commit, logging failures
+func (a *Agent) setCommitStatus(repo, sha, state, description string) {
+	if a.GithubClient == nil || sha == "" {
+		return
+	}
+	// GitHub rejects descriptions longer than 140 characters
+	if len(description) > 140 {
+		description = description[:137] + "..."
+	}
+	if err := a.GithubClient.SetCommitStatus(repo, sha, state, description); err != nil {
+		a.logGitHubError("set commit status", err)
+	}
+}
+
+// logGitHubError logs a failed GitHub call, noting when to retry after a rate limit
+func (a *Agent) logGitHubError(action string, err error) {
+	var rateLimit *github.RateLimitError
+	if errors.As(err, &rateLimit) {
+		a.logger.Warn("Skipping GitHub call: rate limited", "action", action, "reset", rateLimit.Reset.Format(time.RFC3339))
+		return
+	}
+	a.logger.Error("GitHub call failed", "action", action, "error", err)
+}
+
+// formatWorkflowComment renders a workflow result as a markdown PR comment: a
+// table of steps plus the tail of the failing step's output
+func formatWorkflowComment(result workflow.Result) string {
+	var b strings.Builder
+	if result.Success {
+		b.WriteString("### ✅ golang-ai-agent: workflow passed\n\n")
+	} else {
+		b.WriteString("### ❌ golang-ai-agent: workflow failed\n\n")
+	}
+
+	b.WriteString("| Step | Status | Duration |\n|------|--------|----------|\n")
+	var failed *workflow.StepResult
+	for i, step := range result.Steps {
+		status := "✅ passed"
+		switch {
+		case step.Skipped:
+			status = "⏭️ skipped"
+		case !step.Success:
+			status = "❌ failed"
+			if failed == nil {
+				failed = &result.Steps[i]
+			}
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s |\n", step.Name, status, step.Duration.Round(time.Millisecond))
+	}
+	fmt.Fprintf(&b, "\nTotal duration: %s\n", result.Duration.Round(time.Millisecond))
+
+	if failed != nil {
+		fmt.Fprintf(&b, "\n<details><summary>Output of <code>%s</code></summary>\n\n```\n", failed.Name)
+		lines := strings.Split(strings.TrimRight(failed.Output, "\n"), "\n")
+		if len(lines) > commentOutputLines {
+			lines = lines[len(lines)-commentOutputLines:]
+		}
+		b.WriteString(strings.Join(lines, "\n"))
+		if failed.Error != "" {
+			b.WriteString("\n" + failed.Error)
+		}
+		b.WriteString("\n```\n</details>\n")
+	} else if !result.Success && result.Error != "" {
+		fmt.Fprintf(&b, "\nError: %s\n", result.Error)
+	}
+
+	return b.String()
+}
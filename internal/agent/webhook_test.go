@@ -0,0 +1,260 @@
+package agent
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/github"
+	"github.com/kevinpranata97/golang-ai-agent/internal/workflow"
+)
+
+// fakeGitHub records the statuses and comments the agent reports
+type fakeGitHub struct {
+	mutex    sync.Mutex
+	statuses []string
+	comments map[string]string // keyed by request path
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/repos/owner/repo/statuses/"):
+		var status github.CommitStatus
+		json.NewDecoder(r.Body).Decode(&status)
+		sha := strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/statuses/")
+		f.statuses = append(f.statuses, sha+":"+status.State)
+		w.WriteHeader(http.StatusCreated)
+	case r.URL.Path == "/repos/owner/repo/pulls":
+		w.Write([]byte(`[
+			{"number": 3, "head": {"ref": "feature", "repo": {"full_name": "owner/repo"}}},
+			{"number": 4, "head": {"ref": "other", "repo": {"full_name": "owner/repo"}}},
+			{"number": 5, "head": {"ref": "feature", "repo": {"full_name": "fork/repo"}}}
+		]`))
+	case strings.HasSuffix(r.URL.Path, "/comments"):
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		f.comments[r.URL.Path] = body["body"]
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newTestAgent returns an agent whose ci_cd workflow runs the given steps
+// locally and reports to fake
+func newTestAgent(fake *fakeGitHub, steps []workflow.Step) (*Agent, func()) {
+	server := httptest.NewServer(fake)
+	client := github.NewClient("token")
+	client.SetBaseURL(server.URL)
+
+	engine := workflow.NewEngine(nil)
+	engine.RegisterWorkflow(workflow.Workflow{Name: "ci_cd", Steps: steps})
+	return NewAgent(nil, client, nil, engine), server.Close
+}
+
+func TestProcessWebhookCommentsOnPullRequest(t *testing.T) {
+	fake := &fakeGitHub{comments: map[string]string{}}
+	dir := os.TempDir()
+	agent, cleanup := newTestAgent(fake, []workflow.Step{
+		{Name: "lint", Command: "true", WorkDir: dir, Timeout: time.Minute},
+		{Name: "unit", Command: "sh", Args: []string{"-c", "echo assertion failed; exit 1"}, WorkDir: dir, Timeout: time.Minute},
+		{Name: "deploy", Command: "true", WorkDir: dir, Timeout: time.Minute},
+	})
+	defer cleanup()
+
+	var payload WebhookPayload
+	payload.Ref = "refs/heads/feature"
+	payload.After = "abc123"
+	payload.Repository.FullName = "owner/repo"
+	agent.processWebhook(payload)
+
+	if strings.Join(fake.statuses, ",") != "abc123:pending,abc123:failure" {
+		t.Errorf("unexpected commit statuses %v", fake.statuses)
+	}
+	if len(fake.comments) != 1 {
+		t.Fatalf("expected one comment on the branch's pull request, got %v", fake.comments)
+	}
+	comment := fake.comments["/repos/owner/repo/issues/3/comments"]
+	for _, expected := range []string{"workflow failed", "| `lint` | ✅ passed", "| `unit` | ❌ failed", "| `deploy` | ⏭️ skipped", "assertion failed"} {
+		if !strings.Contains(comment, expected) {
+			t.Errorf("comment missing %q:\n%s", expected, comment)
+		}
+	}
+}
+
+func TestProcessWebhookIgnoresTagsAndDeletions(t *testing.T) {
+	fake := &fakeGitHub{comments: map[string]string{}}
+	agent, cleanup := newTestAgent(fake, nil)
+	defer cleanup()
+
+	var tag WebhookPayload
+	tag.Ref = "refs/tags/v1.0.0"
+	tag.Repository.FullName = "owner/repo"
+	agent.processWebhook(tag)
+
+	var deleted WebhookPayload
+	deleted.Ref = "refs/heads/feature"
+	deleted.Deleted = true
+	deleted.Repository.FullName = "owner/repo"
+	agent.processWebhook(deleted)
+
+	if len(fake.statuses) != 0 || len(fake.comments) != 0 {
+		t.Errorf("expected no reports, got statuses %v and comments %v", fake.statuses, fake.comments)
+	}
+}
+
+const pushPayload = `{
+	"ref": "refs/heads/feature",
+	"after": "1111111",
+	"repository": {"full_name": "owner/repo", "clone_url": "https://github.com/owner/repo.git"},
+	"commits": [{"id": "1111111", "message": "Add feature", "author": {"name": "dev"}}]
+}`
+
+const pullRequestPayload = `{
+	"action": "%s",
+	"number": 9,
+	"pull_request": {
+		"head": {"ref": "fix", "sha": "%s", "repo": {"full_name": "fork/repo", "clone_url": "https://github.com/fork/repo.git"}},
+		"base": {"ref": "main"}
+	},
+	"repository": {"full_name": "owner/repo", "clone_url": "https://github.com/owner/repo.git"}
+}`
+
+// deliver sends a webhook through the handler and waits for its processing
+func deliver(t *testing.T, agent *Agent, event, body string) int {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewBufferString(body))
+	if event != "" {
+		req.Header.Set("X-GitHub-Event", event)
+	}
+	rec := httptest.NewRecorder()
+	agent.HandleWebhook(rec, req)
+	agent.webhooks.Wait()
+	return rec.Code
+}
+
+func TestHandleWebhookRoutesEvents(t *testing.T) {
+	fake := &fakeGitHub{comments: map[string]string{}}
+	agent, cleanup := newTestAgent(fake, []workflow.Step{
+		{Name: "check", Command: "true", WorkDir: os.TempDir(), Timeout: time.Minute},
+	})
+	defer cleanup()
+
+	if code := deliver(t, agent, "push", pushPayload); code != http.StatusAccepted {
+		t.Errorf("push: expected 202, got %d", code)
+	}
+	if code := deliver(t, agent, "pull_request", fmt.Sprintf(pullRequestPayload, "opened", "2222222")); code != http.StatusAccepted {
+		t.Errorf("pull_request: expected 202, got %d", code)
+	}
+	// Closing a pull request and unrelated events run nothing
+	if code := deliver(t, agent, "pull_request", fmt.Sprintf(pullRequestPayload, "closed", "3333333")); code != http.StatusAccepted {
+		t.Errorf("closed pull_request: expected 202, got %d", code)
+	}
+	if code := deliver(t, agent, "issues", `{"action": "opened"}`); code != http.StatusOK {
+		t.Errorf("issues: expected 200, got %d", code)
+	}
+	// The same head reported again is not run twice
+	deliver(t, agent, "pull_request", fmt.Sprintf(pullRequestPayload, "synchronize", "2222222"))
+
+	expected := "1111111:pending,1111111:success,2222222:pending,2222222:success"
+	if got := strings.Join(fake.statuses, ","); got != expected {
+		t.Errorf("expected statuses %s, got %s", expected, got)
+	}
+	if comment := fake.comments["/repos/owner/repo/issues/9/comments"]; !strings.Contains(comment, "workflow passed") {
+		t.Errorf("expected a passing comment on pull request #9, got %v", fake.comments)
+	}
+	if _, ok := fake.comments["/repos/owner/repo/issues/3/comments"]; !ok {
+		t.Errorf("expected the push to comment on the branch's pull request #3, got %v", fake.comments)
+	}
+}
+
+func TestHandleWebhookRejectsBadRequests(t *testing.T) {
+	agent, cleanup := newTestAgent(&fakeGitHub{comments: map[string]string{}}, nil)
+	defer cleanup()
+
+	if code := deliver(t, agent, "", pushPayload); code != http.StatusBadRequest {
+		t.Errorf("missing event header: expected 400, got %d", code)
+	}
+	if code := deliver(t, agent, "push", "{"); code != http.StatusBadRequest {
+		t.Errorf("invalid JSON: expected 400, got %d", code)
+	}
+}
+
+func sign(newHash func() hash.Hash, prefix, secret string, body []byte) string {
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return prefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"ref": "refs/heads/main"}`)
+	tampered := []byte(`{"ref": "refs/heads/evil"}`)
+	valid256 := sign(sha256.New, "sha256=", "secret", body)
+	valid1 := sign(sha1.New, "sha1=", "secret", body)
+
+	tests := []struct {
+		name         string
+		secret       string
+		body         []byte
+		signature256 string
+		signature1   string
+		expected     bool
+	}{
+		{"valid sha256", "secret", body, valid256, "", true},
+		{"valid sha1", "secret", body, "", valid1, true},
+		{"sha256 preferred over sha1", "secret", body, valid256, "sha1=00", true},
+		{"tampered body sha256", "secret", tampered, valid256, "", false},
+		{"tampered body sha1", "secret", tampered, "", valid1, false},
+		{"wrong secret", "other", body, valid256, valid1, false},
+		{"wrong algorithm prefix", "secret", body, strings.Replace(valid256, "sha256=", "sha1=", 1), "", false},
+		{"malformed hex", "secret", body, "sha256=zz", "", false},
+		{"missing headers", "secret", body, "", "", false},
+		{"no secret configured", "", body, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := NewAgent(nil, nil, nil, nil)
+			agent.SetWebhookSecret(tt.secret)
+			if got := agent.verifySignature(tt.body, tt.signature256, tt.signature1); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookRequiresSignature(t *testing.T) {
+	agent, cleanup := newTestAgent(&fakeGitHub{comments: map[string]string{}}, nil)
+	defer cleanup()
+	agent.SetWebhookSecret("secret")
+
+	body := `{"action": "closed"}`
+	if code := deliver(t, agent, "pull_request", body); code != http.StatusUnauthorized {
+		t.Errorf("unsigned delivery: expected 401, got %d", code)
+	}
+
+	req := httptest.NewRequest("POST", "/webhook", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	req.Header.Set("X-Hub-Signature-256", sign(sha256.New, "sha256=", "secret", []byte(body)))
+	rec := httptest.NewRecorder()
+	agent.HandleWebhook(rec, req)
+	agent.webhooks.Wait()
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("signed delivery: expected 202, got %d", rec.Code)
+	}
+}
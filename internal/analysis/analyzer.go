@@ -9,12 +9,21 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/kevinpranata97/golang-ai-agent/internal/apptesting"
 	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
 	"github.com/kevinpranata97/golang-ai-agent/internal/storage"
+	testingpkg "github.com/kevinpranata97/golang-ai-agent/internal/testing"
+)
+
+const (
+	// complexHotspotCount is how many of the most complex functions an analysis reports
+	complexHotspotCount = 5
+	// complexityThreshold is the complexity above which a function gets a suggestion
+	complexityThreshold = 10
 )
 
 // CodeAnalyzer handles code analysis and improvement suggestions
@@ -59,6 +68,9 @@ func (ca *CodeAnalyzer) AnalyzeProject(projectID, appPath string, appReq *requir
 
 	// Generate improvement suggestions
 	suggestions := ca.generateImprovementSuggestions(analysis, appReq, testResults)
+	if err := ca.applySuggestionStatuses(projectID, suggestions); err != nil {
+		return nil, fmt.Errorf("failed to load suggestion statuses: %v", err)
+	}
 	analysis.Suggestions = suggestions
 
 	// Save analysis to storage
@@ -80,23 +92,27 @@ func (ca *CodeAnalyzer) analyzeCodeQuality(appPath string) (*storage.CodeQuality
 	}
 	metrics.LinesOfCode = loc
 
-	// Calculate cyclomatic complexity
-	complexity, err := ca.calculateCyclomaticComplexity(appPath)
+	// Calculate cyclomatic complexity per function
+	complexities, err := ca.calculateCyclomaticComplexity(appPath)
 	if err != nil {
 		return nil, err
 	}
-	metrics.CyclomaticComplexity = complexity
+	for _, complexity := range complexities {
+		metrics.CyclomaticComplexity += complexity
+	}
+	metrics.ComplexHotspots = complexHotspots(complexities, complexHotspotCount)
 
 	// Extract test coverage from test results (if available)
 	// This would typically be extracted from go test -cover output
 	metrics.TestCoverage = 0.0 // Placeholder
 
 	// Calculate code duplication
-	duplication, err := ca.calculateDuplication(appPath)
+	duplication, clones, err := ca.calculateDuplication(appPath)
 	if err != nil {
 		return nil, err
 	}
 	metrics.DuplicationRatio = duplication
+	metrics.Clones = clones
 
 	// Assess technical debt and maintainability
 	metrics.TechnicalDebt = ca.assessTechnicalDebt(metrics)
@@ -125,18 +141,33 @@ func (ca *CodeAnalyzer) analyzePerformance(appPath string, testResults *apptesti
 		}
 	}
 
-	// Estimate startup time (placeholder - would need actual measurement)
+	// Use the startup latency measured by the API tests, falling back to an estimate
 	metrics.StartupTime = 0.5 // seconds
+	if testResults != nil {
+		for _, result := range testResults.Results {
+			details, ok := result.Details.(map[string]interface{})
+			if result.Type != "api" || !ok {
+				continue
+			}
+			if startup, ok := details[apptesting.StartupTimeDetail].(float64); ok {
+				metrics.StartupTime = startup
+				break
+			}
+		}
+	}
 
 	// Estimate memory usage (placeholder - would need actual profiling)
 	metrics.MemoryUsage = 10 * 1024 * 1024 // 10MB
 
-	// Extract response time from API tests
+	// Use the average endpoint response time measured by the API tests
 	if testResults != nil {
 		for _, result := range testResults.Results {
-			if result.Type == "api" && result.Details != nil {
-				// Extract average response time from API test details
-				metrics.ResponseTime = 0.1 // seconds (placeholder)
+			details, ok := result.Details.(map[string]interface{})
+			if result.Type != "api" || !ok {
+				continue
+			}
+			if avg, ok := details[apptesting.ResponseTimeAvgDetail].(float64); ok {
+				metrics.ResponseTime = avg / 1000 // seconds
 				break
 			}
 		}
@@ -215,11 +246,16 @@ func (ca *CodeAnalyzer) generateImprovementSuggestions(analysis *storage.Analysi
 	var suggestions []storage.ImprovementSuggestion
 
 	// Code quality suggestions
-	if analysis.CodeQuality.CyclomaticComplexity > 10 {
+	for _, hotspot := range analysis.CodeQuality.ComplexHotspots {
+		if hotspot.Complexity <= complexityThreshold {
+			continue
+		}
+		// The complexity is left out of the description so the suggestion
+		// keeps its ID, and its status, while the function is reworked
 		suggestions = append(suggestions, storage.ImprovementSuggestion{
 			Type:        "quality",
 			Priority:    "medium",
-			Description: "High cyclomatic complexity detected. Consider breaking down complex functions into smaller, more manageable pieces.",
+			Description: fmt.Sprintf("High cyclomatic complexity in %s. Consider breaking it down into smaller, more manageable functions.", hotspot.Function),
 			Impact:      "Improved code readability and maintainability",
 			Effort:      "medium",
 		})
@@ -333,6 +369,25 @@ func (ca *CodeAnalyzer) generateImprovementSuggestions(analysis *storage.Analysi
 	return suggestions
 }
 
+// applySuggestionStatuses assigns stable IDs and carries forward statuses from earlier analyses
+func (ca *CodeAnalyzer) applySuggestionStatuses(projectID string, suggestions []storage.ImprovementSuggestion) error {
+	statuses, err := ca.storage.GetSuggestionStatuses(projectID)
+	if err != nil {
+		return err
+	}
+
+	for i := range suggestions {
+		suggestions[i].ID = storage.SuggestionID(suggestions[i])
+		if status, ok := statuses[suggestions[i].ID]; ok {
+			suggestions[i].Status = status
+		} else {
+			suggestions[i].Status = "open"
+		}
+	}
+
+	return nil
+}
+
 // Helper methods for analysis
 
 // countLinesOfCode counts non-empty, non-comment lines of code
@@ -366,9 +421,10 @@ func (ca *CodeAnalyzer) countLinesOfCode(appPath string) (int, error) {
 	return totalLines, err
 }
 
-// calculateCyclomaticComplexity calculates cyclomatic complexity
-func (ca *CodeAnalyzer) calculateCyclomaticComplexity(appPath string) (int, error) {
-	totalComplexity := 0
+// calculateCyclomaticComplexity returns the cyclomatic complexity of every
+// function in the app, keyed by file:function
+func (ca *CodeAnalyzer) calculateCyclomaticComplexity(appPath string) (map[string]int, error) {
+	complexities := make(map[string]int)
 
 	err := filepath.Walk(appPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -382,49 +438,13 @@ func (ca *CodeAnalyzer) calculateCyclomaticComplexity(appPath string) (int, erro
 				return err
 			}
 
-			ast.Inspect(node, func(n ast.Node) bool {
-				switch n.(type) {
-				case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt:
-					totalComplexity++
-				case *ast.CaseClause:
-					totalComplexity++
-				}
-				return true
-			})
-		}
-
-		return nil
-	})
-
-	return totalComplexity, err
-}
-
-// calculateDuplication calculates code duplication ratio
-func (ca *CodeAnalyzer) calculateDuplication(appPath string) (float64, error) {
-	// This is a simplified implementation
-	// In a real system, you'd use more sophisticated algorithms
-	
-	var allLines []string
-	lineCount := make(map[string]int)
-
-	err := filepath.Walk(appPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if strings.HasSuffix(info.Name(), ".go") {
-			file, err := os.Open(path)
+			file, err := filepath.Rel(appPath, path)
 			if err != nil {
 				return err
 			}
-			defer file.Close()
-
-			scanner := bufio.NewScanner(file)
-			for scanner.Scan() {
-				line := strings.TrimSpace(scanner.Text())
-				if line != "" && !strings.HasPrefix(line, "//") && len(line) > 10 {
-					allLines = append(allLines, line)
-					lineCount[line]++
+			for _, decl := range node.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok {
+					complexities[filepath.ToSlash(file)+":"+functionName(fn)] = testingpkg.CyclomaticComplexity(fn)
 				}
 			}
 		}
@@ -432,22 +452,50 @@ func (ca *CodeAnalyzer) calculateDuplication(appPath string) (float64, error) {
 		return nil
 	})
 
-	if err != nil {
-		return 0, err
+	return complexities, err
+}
+
+// functionName returns the name of a function, prefixed with its receiver
+// type for methods
+func functionName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
 	}
 
-	duplicatedLines := 0
-	for _, count := range lineCount {
-		if count > 1 {
-			duplicatedLines += count - 1
-		}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
 	}
+	// Drop the type parameters of generic receivers
+	switch x := recv.(type) {
+	case *ast.IndexExpr:
+		recv = x.X
+	case *ast.IndexListExpr:
+		recv = x.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
 
-	if len(allLines) == 0 {
-		return 0, nil
+// complexHotspots returns the n most complex functions, most complex first
+func complexHotspots(complexities map[string]int, n int) []storage.FunctionComplexity {
+	hotspots := make([]storage.FunctionComplexity, 0, len(complexities))
+	for function, complexity := range complexities {
+		hotspots = append(hotspots, storage.FunctionComplexity{Function: function, Complexity: complexity})
 	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		if hotspots[i].Complexity != hotspots[j].Complexity {
+			return hotspots[i].Complexity > hotspots[j].Complexity
+		}
+		return hotspots[i].Function < hotspots[j].Function
+	})
 
-	return float64(duplicatedLines) / float64(len(allLines)), nil
+	if len(hotspots) > n {
+		hotspots = hotspots[:n]
+	}
+	return hotspots
 }
 
 // assessTechnicalDebt assesses technical debt level
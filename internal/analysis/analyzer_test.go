@@ -0,0 +1,292 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/apptesting"
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+	"github.com/kevinpranata97/golang-ai-agent/internal/storage"
+)
+
+func TestDismissedSuggestionStaysDismissed(t *testing.T) {
+	store := storage.NewFileStorage(t.TempDir())
+	ca := NewCodeAnalyzer(store)
+
+	appPath := t.TempDir()
+	source := "package main\n\nfunc main() {}\n"
+	if err := os.WriteFile(filepath.Join(appPath, "main.go"), []byte(source), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+	appReq := &requirements.ApplicationRequirement{Language: "go", Framework: "gin", Database: "sqlite"}
+
+	first, err := ca.AnalyzeProject("project-1", appPath, appReq, nil)
+	if err != nil {
+		t.Fatalf("first analysis failed: %v", err)
+	}
+	if len(first.Suggestions) == 0 {
+		t.Fatal("expected suggestions from first analysis")
+	}
+	for _, suggestion := range first.Suggestions {
+		if suggestion.ID == "" || suggestion.Status != "open" {
+			t.Fatalf("expected new suggestion to have an ID and open status, got %+v", suggestion)
+		}
+	}
+
+	dismissed := first.Suggestions[0].ID
+	if err := store.UpdateSuggestionStatus("project-1", dismissed, "dismissed"); err != nil {
+		t.Fatalf("failed to dismiss suggestion: %v", err)
+	}
+
+	second, err := ca.AnalyzeProject("project-1", appPath, appReq, nil)
+	if err != nil {
+		t.Fatalf("second analysis failed: %v", err)
+	}
+	if len(second.Suggestions) != len(first.Suggestions) {
+		t.Fatalf("expected %d suggestions, got %d", len(first.Suggestions), len(second.Suggestions))
+	}
+	for _, suggestion := range second.Suggestions {
+		expected := "open"
+		if suggestion.ID == dismissed {
+			expected = "dismissed"
+		}
+		if suggestion.Status != expected {
+			t.Errorf("suggestion %s (%s): expected status %s, got %s", suggestion.ID, suggestion.Description, expected, suggestion.Status)
+		}
+	}
+}
+
+func TestUpdateSuggestionStatusValidation(t *testing.T) {
+	store := storage.NewFileStorage(t.TempDir())
+
+	if err := store.UpdateSuggestionStatus("project-1", "missing", "bogus"); err == nil {
+		t.Error("expected error for invalid status")
+	}
+	if err := store.UpdateSuggestionStatus("project-1", "missing", "done"); err == nil {
+		t.Error("expected error for unknown suggestion")
+	}
+}
+
+func TestAnalyzePerformanceUsesMeasuredStartup(t *testing.T) {
+	ca := NewCodeAnalyzer(storage.NewFileStorage(t.TempDir()))
+
+	metrics, err := ca.analyzePerformance(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("analyzePerformance failed: %v", err)
+	}
+	if metrics.StartupTime != 0.5 {
+		t.Errorf("expected fallback startup time 0.5, got %v", metrics.StartupTime)
+	}
+
+	suite := &apptesting.TestSuite{
+		Results: []apptesting.TestResult{
+			{Type: "build", Status: "pass"},
+			{Type: "api", Status: "pass", Details: map[string]interface{}{apptesting.StartupTimeDetail: 1.25}},
+		},
+	}
+	metrics, err = ca.analyzePerformance(t.TempDir(), suite)
+	if err != nil {
+		t.Fatalf("analyzePerformance failed: %v", err)
+	}
+	if metrics.StartupTime != 1.25 {
+		t.Errorf("expected measured startup time 1.25, got %v", metrics.StartupTime)
+	}
+}
+
+func TestAnalyzePerformanceUsesMeasuredResponseTime(t *testing.T) {
+	ca := NewCodeAnalyzer(storage.NewFileStorage(t.TempDir()))
+
+	suite := &apptesting.TestSuite{
+		Results: []apptesting.TestResult{
+			{Type: "api", Status: "pass", Details: map[string]interface{}{apptesting.ResponseTimeAvgDetail: 250.0}},
+		},
+	}
+	metrics, err := ca.analyzePerformance(t.TempDir(), suite)
+	if err != nil {
+		t.Fatalf("analyzePerformance failed: %v", err)
+	}
+	if metrics.ResponseTime != 0.25 {
+		t.Errorf("expected measured response time 0.25s, got %v", metrics.ResponseTime)
+	}
+}
+
+// complexitySource has functions of known cyclomatic complexity
+const complexitySource = `package main
+
+type Server struct{}
+
+func straight() int {
+	return 1
+}
+
+func branches(n int) string {
+	if n > 0 {
+		return "positive"
+	} else if n < 0 {
+		return "negative"
+	}
+	return "zero"
+}
+
+func (s *Server) route(path string, ids []int) int {
+	for _, id := range ids {
+		if id == 0 {
+			continue
+		}
+	}
+	switch path {
+	case "/":
+		return 0
+	case "/users", "/posts":
+		return 1
+	default:
+		return 2
+	}
+}
+`
+
+func TestCalculateCyclomaticComplexity(t *testing.T) {
+	ca := NewCodeAnalyzer(storage.NewFileStorage(t.TempDir()))
+
+	appPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(appPath, "handlers"), 0755); err != nil {
+		t.Fatalf("failed to create handlers dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(appPath, "handlers", "server.go"), []byte(complexitySource), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	complexities, err := ca.calculateCyclomaticComplexity(appPath)
+	if err != nil {
+		t.Fatalf("calculateCyclomaticComplexity failed: %v", err)
+	}
+
+	expected := map[string]int{
+		"handlers/server.go:straight":     1,
+		"handlers/server.go:branches":     3, // two ifs
+		"handlers/server.go:Server.route": 7, // range, if, switch and three cases
+	}
+	if len(complexities) != len(expected) {
+		t.Errorf("expected %d functions, got %v", len(expected), complexities)
+	}
+	for function, complexity := range expected {
+		if complexities[function] != complexity {
+			t.Errorf("%s: expected complexity %d, got %d", function, complexity, complexities[function])
+		}
+	}
+
+	hotspots := complexHotspots(complexities, 2)
+	if len(hotspots) != 2 || hotspots[0].Function != "handlers/server.go:Server.route" || hotspots[1].Function != "handlers/server.go:branches" {
+		t.Errorf("expected route and branches as the top hotspots, got %+v", hotspots)
+	}
+}
+
+func TestComplexitySuggestionsNameFunctions(t *testing.T) {
+	ca := NewCodeAnalyzer(storage.NewFileStorage(t.TempDir()))
+	analysis := &storage.AnalysisData{
+		CodeQuality: storage.CodeQualityMetrics{
+			TestCoverage: 100,
+			ComplexHotspots: []storage.FunctionComplexity{
+				{Function: "handlers/user.go:UserHandler.Create", Complexity: 14},
+				{Function: "main.go:main", Complexity: 10},
+			},
+		},
+	}
+
+	var complexity []string
+	for _, suggestion := range ca.generateImprovementSuggestions(analysis, &requirements.ApplicationRequirement{}, nil) {
+		if strings.Contains(suggestion.Description, "cyclomatic complexity") {
+			complexity = append(complexity, suggestion.Description)
+		}
+	}
+	if len(complexity) != 1 || !strings.Contains(complexity[0], "handlers/user.go:UserHandler.Create") {
+		t.Errorf("expected one suggestion naming UserHandler.Create, got %v", complexity)
+	}
+}
+
+// clonedSource repeats createUser, reindented and with its comment dropped,
+// as updateUser
+const clonedSource = `package main
+
+import "errors"
+
+type User struct {
+	Name  string
+	Email string
+	Age   int
+}
+
+func createUser(name, email string, age int) (*User, error) {
+	// Validate before saving
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if email == "" {
+		return nil, errors.New("email is required")
+	}
+	if age < 0 || age > 150 {
+		return nil, errors.New("age is out of range")
+	}
+	return &User{Name: name, Email: email, Age: age}, nil
+}
+
+func updateUser(name, email string, age int) (*User, error) {
+		if name == "" {
+			return nil, errors.New("name is required")
+		}
+		if email == "" {
+			return nil, errors.New("email is required")
+		}
+		if age < 0 || age > 150 {
+			return nil, errors.New("age is out of range")
+		}
+		return &User{Name: name, Email: email, Age: age}, nil
+}
+`
+
+func TestCalculateDuplication(t *testing.T) {
+	ca := NewCodeAnalyzer(storage.NewFileStorage(t.TempDir()))
+
+	appPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(appPath, "users.go"), []byte(clonedSource), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	ratio, clones, err := ca.calculateDuplication(appPath)
+	if err != nil {
+		t.Fatalf("calculateDuplication failed: %v", err)
+	}
+
+	// updateUser copies createUser from its parameter list to the end, so the
+	// windows lying wholly inside that part are the duplicated ones
+	all, _ := tokenizeGo([]byte(clonedSource))
+	copied, _ := tokenizeGo([]byte(clonedSource[strings.LastIndex(clonedSource, "(name, email"):]))
+	expected := float64(len(copied)-duplicationWindow+1) / float64(len(all)-duplicationWindow+1)
+	if ratio != expected {
+		t.Errorf("expected duplication ratio %v, got %v", expected, ratio)
+	}
+
+	want := []storage.CodeClone{{File: "users.go", Line: 25, OriginalFile: "users.go", OriginalLine: 11}}
+	if len(clones) != len(want) || clones[0] != want[0] {
+		t.Errorf("expected clones %+v, got %+v", want, clones)
+	}
+}
+
+func TestCalculateDuplicationIgnoresCommonLines(t *testing.T) {
+	ca := NewCodeAnalyzer(storage.NewFileStorage(t.TempDir()))
+
+	appPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(appPath, "server.go"), []byte(complexitySource), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	ratio, clones, err := ca.calculateDuplication(appPath)
+	if err != nil {
+		t.Fatalf("calculateDuplication failed: %v", err)
+	}
+	if ratio != 0 || len(clones) != 0 {
+		t.Errorf("expected no duplication, got ratio %v and clones %+v", ratio, clones)
+	}
+}
@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"go/scanner"
+	"go/token"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/storage"
+)
+
+// duplicationWindow is how many consecutive tokens must repeat to count as a clone
+const duplicationWindow = 40
+
+// duplicationBase is the multiplier of the rolling window hash
+const duplicationBase = 1099511628211
+
+// tokenLocation is where a token window starts
+type tokenLocation struct {
+	file string
+	line int
+}
+
+// calculateDuplication returns the fraction of token windows in the app's Go
+// files that repeat an earlier window, and the clones they form. Comments and
+// formatting are ignored, so reindented copies are still found. Windows are
+// hashed with a rolling hash, keeping the detection linear in the size of the
+// code.
+func (ca *CodeAnalyzer) calculateDuplication(appPath string) (float64, []storage.CodeClone, error) {
+	seen := make(map[uint64]tokenLocation)
+	var clones []storage.CodeClone
+	windows, duplicated := 0, 0
+
+	// pow is the weight of the token leaving the window
+	pow := uint64(1)
+	for i := 0; i < duplicationWindow; i++ {
+		pow *= duplicationBase
+	}
+
+	err := filepath.Walk(appPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".go") {
+			return nil
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		file, err := filepath.Rel(appPath, path)
+		if err != nil {
+			return err
+		}
+		file = filepath.ToSlash(file)
+
+		hashes, lines := tokenizeGo(src)
+
+		var hash uint64
+		inClone := false
+		for i := range hashes {
+			hash = hash*duplicationBase + hashes[i]
+			if i >= duplicationWindow {
+				hash -= hashes[i-duplicationWindow] * pow
+			}
+			if i < duplicationWindow-1 {
+				continue
+			}
+
+			windows++
+			start := tokenLocation{file: file, line: lines[i-duplicationWindow+1]}
+			original, exists := seen[hash]
+			if !exists {
+				seen[hash] = start
+				inClone = false
+				continue
+			}
+
+			duplicated++
+			// Overlapping duplicate windows form one clone, reported where it starts
+			if !inClone {
+				clones = append(clones, storage.CodeClone{
+					File:         start.file,
+					Line:         start.line,
+					OriginalFile: original.file,
+					OriginalLine: original.line,
+				})
+			}
+			inClone = true
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if windows == 0 {
+		return 0, nil, nil
+	}
+	return float64(duplicated) / float64(windows), clones, nil
+}
+
+// tokenizeGo returns the hash and line of every token in src, skipping
+// comments and the semicolons inserted at line ends
+func tokenizeGo(src []byte) ([]uint64, []int) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(file, src, nil, 0) // Unparsable code is tokenized as far as possible
+
+	var hashes []uint64
+	var lines []int
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+
+		h := fnv.New64a()
+		h.Write([]byte(tok.String()))
+		h.Write([]byte{0})
+		h.Write([]byte(lit))
+		hashes = append(hashes, h.Sum64())
+		lines = append(lines, fset.Position(pos).Line)
+	}
+	return hashes, lines
+}
@@ -0,0 +1,158 @@
+package apptesting
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+// BinaryName is the file name of the binary API tests build for Go applications
+const BinaryName = "app"
+
+// testDatabaseName is the SQLite database applications use during API tests
+const testDatabaseName = "api_test.db"
+
+// sqliteSuffixes are the files SQLite keeps next to a database
+var sqliteSuffixes = []string{"", "-journal", "-wal", "-shm"}
+
+// SetArtifactsDir sets the directory API tests build binaries and create
+// SQLite databases in, with a subdirectory per application. Empty, the
+// default, keeps them in the application directory.
+func (at *ApplicationTester) SetArtifactsDir(dir string) {
+	at.artifactsDir = dir
+}
+
+// SetKeepArtifacts keeps the binaries and databases a test run creates, e.g.
+// to inspect a failed run, instead of removing them when the run finishes
+func (at *ApplicationTester) SetKeepArtifacts(keep bool) {
+	at.keepArtifacts = keep
+}
+
+// artifactPath returns the absolute path of the artifact name of the
+// application at appPath and records it, and the directory it is created in,
+// for Cleanup
+func (at *ApplicationTester) artifactPath(appPath, name string) (string, error) {
+	dir := appPath
+	if at.artifactsDir != "" {
+		dir = filepath.Join(at.artifactsDir, filepath.Base(appPath))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create artifacts directory: %v", err)
+		}
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve artifacts directory: %v", err)
+	}
+	path := filepath.Join(dir, name)
+
+	at.artifactsMutex.Lock()
+	defer at.artifactsMutex.Unlock()
+	if at.artifacts == nil {
+		at.artifacts = make(map[string][]string)
+	}
+	if at.artifactsDir != "" && !containsPath(at.artifacts[appPath], dir) {
+		at.artifacts[appPath] = append(at.artifacts[appPath], dir)
+	}
+	if !containsPath(at.artifacts[appPath], path) {
+		at.artifacts[appPath] = append(at.artifacts[appPath], path)
+	}
+	return path, nil
+}
+
+// builtBinary returns the path of the binary the current run built for the
+// application at appPath, or "" if it built none
+func (at *ApplicationTester) builtBinary(appPath string) string {
+	at.artifactsMutex.Lock()
+	defer at.artifactsMutex.Unlock()
+	for _, path := range at.artifacts[appPath] {
+		if filepath.Base(path) != BinaryName {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// testDatabaseEnv returns the DATABASE_URL pointing the application at appPath
+// to a test SQLite database, or "" if it does not use SQLite or its language
+// does not read DATABASE_URL for it
+func (at *ApplicationTester) testDatabaseEnv(appPath string, appReq *requirements.ApplicationRequirement, language string) (string, error) {
+	switch strings.ToLower(appReq.Database) {
+	case "", "sqlite", "sqlite3":
+	default:
+		return "", nil
+	}
+
+	var prefix string
+	switch language {
+	case "go", "golang":
+	case "python":
+		prefix = "sqlite:///"
+	default:
+		return "", nil
+	}
+
+	path, err := at.artifactPath(appPath, testDatabaseName)
+	if err != nil {
+		return "", err
+	}
+	return "DATABASE_URL=" + prefix + path, nil
+}
+
+// Cleanup removes the binaries, SQLite databases and artifact directories the
+// tests of the application at appPath created. TestApplication calls it when
+// it finishes unless artifacts are kept.
+func (at *ApplicationTester) Cleanup(appPath string) error {
+	at.artifactsMutex.Lock()
+	paths := at.artifacts[appPath]
+	delete(at.artifacts, appPath)
+	at.artifactsMutex.Unlock()
+
+	var errs []string
+	for _, path := range paths {
+		removals := []string{path}
+		if filepath.Base(path) == testDatabaseName {
+			removals = removals[:0]
+			for _, suffix := range sqliteSuffixes {
+				removals = append(removals, path+suffix)
+			}
+		}
+		for _, removal := range removals {
+			if err := os.RemoveAll(removal); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New("failed to remove test artifacts: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// finishArtifacts removes the artifacts of a finished run unless they are kept
+func (at *ApplicationTester) finishArtifacts(appPath string) error {
+	if at.keepArtifacts {
+		// Forget them so the next run builds afresh rather than reusing them
+		at.artifactsMutex.Lock()
+		delete(at.artifacts, appPath)
+		at.artifactsMutex.Unlock()
+		return nil
+	}
+	return at.Cleanup(appPath)
+}
+
+// containsPath reports whether paths contains path
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package apptesting
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultCommandTimeout bounds commands of languages without their own default
+const defaultCommandTimeout = 5 * time.Minute
+
+// defaultCommandTimeouts bound each build, test and analysis command by
+// language; installing dependencies makes some much slower than others
+var defaultCommandTimeouts = map[string]time.Duration{
+	"go":         5 * time.Minute,
+	"javascript": 10 * time.Minute, // npm install
+	"python":     10 * time.Minute, // pip install
+	"java":       10 * time.Minute, // maven downloads
+	"php":        5 * time.Minute,
+	"ruby":       10 * time.Minute, // bundle install
+	"rust":       15 * time.Minute, // cargo compiles every dependency
+	"csharp":     10 * time.Minute,
+}
+
+// SetCommandTimeouts overrides the timeout of the commands run for each
+// language, keyed by language name. Languages left out keep their defaults.
+func (at *ApplicationTester) SetCommandTimeouts(timeouts map[string]time.Duration) {
+	if at.commandTimeouts == nil {
+		at.commandTimeouts = make(map[string]time.Duration)
+	}
+	for language, timeout := range timeouts {
+		at.commandTimeouts[languageKey(language)] = timeout
+	}
+}
+
+// commandTimeout returns how long a single command may run for language
+func (at *ApplicationTester) commandTimeout(language string) time.Duration {
+	key := languageKey(language)
+	if timeout, ok := at.commandTimeouts[key]; ok && timeout > 0 {
+		return timeout
+	}
+	if timeout, ok := defaultCommandTimeouts[key]; ok {
+		return timeout
+	}
+	return defaultCommandTimeout
+}
+
+// commandContext returns the context commands run for language are created
+// with, derived from the context of the phase running them
+func (at *ApplicationTester) commandContext(ctx context.Context, language string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, at.commandTimeout(language))
+}
+
+// languageKey maps language aliases to the names timeouts are keyed by
+func languageKey(language string) string {
+	switch language = strings.ToLower(language); language {
+	case "node", "nodejs":
+		return "javascript"
+	case "golang":
+		return "go"
+	case "c#", "dotnet":
+		return "csharp"
+	default:
+		return language
+	}
+}
+
+// runCommand runs cmd and, when ctx expires first, kills its whole process group
+// so children holding the output pipes (npm spawning node) cannot keep it alive
+func runCommand(ctx context.Context, cmd *exec.Cmd) error {
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		killProcessGroup(cmd)
+		return <-done
+	}
+}
+
+// startServer starts cmd, an application server under test, in its own
+// process group and returns a function stopping it. stop kills the whole group,
+// so children such as the node process npm spawns die with it, and reaps the
+// command so no zombie is left behind; it is meant to be deferred right after
+// a successful start.
+func startServer(cmd *exec.Cmd) (stop func(), err error) {
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return func() {
+		killProcessGroup(cmd)
+		cmd.Wait()
+	}, nil
+}
+
+// combinedOutput runs cmd like runCommand and returns its stdout and stderr
+func combinedOutput(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := runCommand(ctx, cmd)
+	return output.Bytes(), err
+}
+
+// timeoutError describes a command stopped by its timeout, or returns "" when
+// ctx did not expire
+func timeoutError(ctx context.Context, cmd *exec.Cmd, timeout time.Duration) string {
+	if ctx.Err() != context.DeadlineExceeded {
+		return ""
+	}
+	return fmt.Sprintf("%s timed out after %v", strings.Join(cmd.Args, " "), timeout)
+}
+
+// commandError describes why a command run for language failed, reporting a
+// timeout rather than the kill signal it caused
+func (at *ApplicationTester) commandError(ctx context.Context, cmd *exec.Cmd, language string, err error) string {
+	if message := timeoutError(ctx, cmd, at.commandTimeout(language)); message != "" {
+		return message
+	}
+	return err.Error()
+}
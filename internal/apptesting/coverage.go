@@ -0,0 +1,93 @@
+package apptesting
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CoverageProfileFile is the coverage profile the unit test phase writes for a
+// Go application, next to the binary API tests build
+const CoverageProfileFile = "coverage.out"
+
+// Unit test details: the path of the coverage profile, and whether the phase
+// failed for coverage below the tester's threshold
+const (
+	CoverageProfileDetail = "coverage_profile"
+	BelowThresholdDetail  = "below_coverage_threshold"
+)
+
+// parseCoverProfile returns the percentage of statements covered according to
+// a go test -coverprofile file. Blocks reported by several packages are
+// counted once, covered when any run covered them.
+func parseCoverProfile(path string) (float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := make(map[string]block)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "mode:") {
+			continue
+		}
+
+		// file.go:startLine.startCol,endLine.endCol statements count
+		fields := strings.Fields(text)
+		if len(fields) != 3 {
+			return 0, fmt.Errorf("%s:%d: malformed coverage block", path, line)
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, fmt.Errorf("%s:%d: invalid statement count: %w", path, line, err)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return 0, fmt.Errorf("%s:%d: invalid hit count: %w", path, line, err)
+		}
+
+		b := blocks[fields[0]]
+		b.statements = statements
+		b.covered = b.covered || count > 0
+		blocks[fields[0]] = b
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	total, covered := 0, 0
+	for _, b := range blocks {
+		total += b.statements
+		if b.covered {
+			covered += b.statements
+		}
+	}
+	if total == 0 {
+		return 0, nil
+	}
+	return float64(covered) / float64(total) * 100, nil
+}
+
+// belowThreshold reports whether a unit test result fell short of the
+// coverage threshold: passing tests with too little coverage, or a phase
+// failed for it
+func belowThreshold(result TestResult, threshold float64) bool {
+	if result.Type != "unit" || threshold <= 0 || result.Coverage >= threshold {
+		return false
+	}
+	if result.Status == "pass" {
+		return true
+	}
+	details, _ := result.Details.(map[string]interface{})
+	below, _ := details[BelowThresholdDetail].(bool)
+	return below
+}
@@ -0,0 +1,108 @@
+package apptesting
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Format selects how SaveResults writes a test suite
+type Format string
+
+const (
+	FormatJSON  Format = "json"
+	FormatJUnit Format = "junit"
+)
+
+// JUnitResultsFile is the name of the JUnit report saved next to TestResultsFile
+const JUnitResultsFile = "test_results.xml"
+
+// SaveResults writes suite to outputPath in the given format
+func (at *ApplicationTester) SaveResults(suite *TestSuite, outputPath string, format Format) error {
+	switch format {
+	case FormatJSON, "":
+		return at.SaveTestResults(suite, outputPath)
+	case FormatJUnit:
+		return at.SaveTestResultsJUnit(suite, outputPath)
+	default:
+		return fmt.Errorf("unsupported results format %q", format)
+	}
+}
+
+// junitTestSuite is the <testsuite> root of a JUnit XML report
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitMessage is a <failure> or <skipped> element
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// SaveTestResultsJUnit writes suite as a JUnit XML report: one <testcase> per
+// result, with failures and skips as child elements and durations in seconds
+func (at *ApplicationTester) SaveTestResultsJUnit(suite *TestSuite, outputPath string) error {
+	report := junitTestSuite{
+		Name:     suite.Name,
+		Tests:    suite.TotalTests,
+		Failures: suite.FailedTests,
+		Skipped:  suite.SkippedTests,
+		Time:     junitSeconds(suite.Duration),
+	}
+	if !suite.StartTime.IsZero() {
+		report.Timestamp = suite.StartTime.Format("2006-01-02T15:04:05")
+	}
+
+	for _, result := range suite.Results {
+		testCase := junitTestCase{
+			Name:      result.Name,
+			Classname: suite.Name + "." + result.Type,
+			Time:      junitSeconds(result.Duration),
+			SystemOut: result.Output,
+		}
+		switch result.Status {
+		case "fail":
+			message := result.Error
+			if message == "" {
+				message = "test failed"
+			}
+			testCase.Failure = &junitMessage{Message: message, Type: result.Type, Text: result.Output}
+			testCase.SystemOut = ""
+		case "skip":
+			testCase.Skipped = &junitMessage{Message: result.Error}
+		}
+		report.TestCases = append(report.TestCases, testCase)
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %v", err)
+	}
+	return os.WriteFile(outputPath, append([]byte(xml.Header), data...), 0644)
+}
+
+// junitSeconds formats a duration as fractional seconds, as JUnit expects
+func junitSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,157 @@
+package apptesting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SecurityFinding is a single issue reported by a security scanner
+type SecurityFinding struct {
+	Tool     string `json:"tool"`
+	RuleID   string `json:"rule_id"`
+	Severity string `json:"severity"` // LOW, MEDIUM, HIGH
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// severityRanks orders finding severities; unknown severities rank as zero
+var severityRanks = map[string]int{
+	"LOW":    1,
+	"MEDIUM": 2,
+	"HIGH":   3,
+}
+
+// securityTool is a scanner command and, when its output is structured, a parser for it
+type securityTool struct {
+	args  []string
+	parse func(output []byte) ([]SecurityFinding, error)
+}
+
+// SetSecurityFailSeverity makes security tests fail when a finding at or above
+// severity (low, medium or high) is reported. Empty or "none", the default,
+// only records findings.
+func (at *ApplicationTester) SetSecurityFailSeverity(severity string) {
+	at.securityFailSeverity = strings.ToUpper(severity)
+}
+
+// blockingFindings returns the findings at or above the configured fail severity
+func (at *ApplicationTester) blockingFindings(findings []SecurityFinding) []SecurityFinding {
+	threshold, ok := severityRanks[at.securityFailSeverity]
+	if !ok {
+		return nil
+	}
+
+	var blocking []SecurityFinding
+	for _, finding := range findings {
+		if severityRanks[finding.Severity] >= threshold {
+			blocking = append(blocking, finding)
+		}
+	}
+	return blocking
+}
+
+// parseGosecOutput parses the report of gosec -fmt=json
+func parseGosecOutput(output []byte) ([]SecurityFinding, error) {
+	var report struct {
+		Issues []struct {
+			Severity string `json:"severity"`
+			RuleID   string `json:"rule_id"`
+			Details  string `json:"details"`
+			File     string `json:"file"`
+			Line     string `json:"line"` // "12" or a range such as "12-14"
+		} `json:"Issues"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse gosec output: %v", err)
+	}
+
+	var findings []SecurityFinding
+	for _, issue := range report.Issues {
+		line, _ := strconv.Atoi(strings.SplitN(issue.Line, "-", 2)[0])
+		findings = append(findings, SecurityFinding{
+			Tool:     "gosec",
+			RuleID:   issue.RuleID,
+			Severity: strings.ToUpper(issue.Severity),
+			File:     issue.File,
+			Line:     line,
+			Message:  issue.Details,
+		})
+	}
+	return findings, nil
+}
+
+// parseGovulncheckOutput parses the message stream of govulncheck -json.
+// Vulnerabilities whose vulnerable symbols are called are reported as HIGH,
+// ones that are only imported or required as LOW; each ID is reported once.
+func parseGovulncheckOutput(output []byte) ([]SecurityFinding, error) {
+	type position struct {
+		Filename string `json:"filename"`
+		Line     int    `json:"line"`
+	}
+	type message struct {
+		OSV *struct {
+			ID      string `json:"id"`
+			Summary string `json:"summary"`
+		} `json:"osv"`
+		Finding *struct {
+			OSV   string `json:"osv"`
+			Trace []struct {
+				Function string    `json:"function"`
+				Position *position `json:"position"`
+			} `json:"trace"`
+		} `json:"finding"`
+	}
+
+	summaries := map[string]string{}
+	byID := map[string]*SecurityFinding{}
+	var order []string
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var msg message
+		if err := decoder.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse govulncheck output: %v", err)
+		}
+
+		if msg.OSV != nil {
+			summaries[msg.OSV.ID] = msg.OSV.Summary
+		}
+		if msg.Finding == nil {
+			continue
+		}
+
+		finding := SecurityFinding{Tool: "govulncheck", RuleID: msg.Finding.OSV, Severity: "LOW"}
+		if len(msg.Finding.Trace) > 0 && msg.Finding.Trace[0].Function != "" {
+			finding.Severity = "HIGH"
+		}
+		// The last frame with a position is the call site in the scanned module
+		for _, frame := range msg.Finding.Trace {
+			if frame.Position != nil {
+				finding.File, finding.Line = frame.Position.Filename, frame.Position.Line
+			}
+		}
+
+		existing, ok := byID[finding.RuleID]
+		if !ok {
+			order = append(order, finding.RuleID)
+		}
+		if !ok || severityRanks[finding.Severity] > severityRanks[existing.Severity] {
+			byID[finding.RuleID] = &finding
+		}
+	}
+
+	var findings []SecurityFinding
+	for _, id := range order {
+		finding := *byID[id]
+		finding.Message = summaries[id]
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
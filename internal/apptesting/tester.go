@@ -1,19 +1,26 @@
 package apptesting
 
 import (
-	"bufio"
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/kevinpranata97/golang-ai-agent/internal/buildsys"
 	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
 )
 
@@ -44,24 +51,128 @@ type TestSuite struct {
 	Results      []TestResult `json:"results"`
 	Summary      string       `json:"summary"`
 	OverallStatus string       `json:"overall_status"` // Added field
+	CoverageThreshold float64 `json:"coverage_threshold,omitempty"`
+	BelowThreshold    bool    `json:"below_threshold,omitempty"`
 }
 
 // ApplicationTester handles testing of generated applications
 type ApplicationTester struct {
-	workingDir string
-	timeout    time.Duration
+	workingDir        string
+	timeout           time.Duration
+	commandTimeouts   map[string]time.Duration
+	parallel          bool
+	coverageThreshold float64
+	smokeTest         bool
+	apiTestPort       int
+	securityFailSeverity string
+	// artifactsDir and keepArtifacts place and keep the binaries and
+	// databases tests create; artifacts lists them by application path
+	artifactsDir   string
+	keepArtifacts  bool
+	artifactsMutex sync.Mutex
+	artifacts      map[string][]string
+	// phaseObserver sees the result of every phase of every run, e.g. to
+	// record metrics
+	phaseObserver PhaseFunc
 }
 
+// serverStartTimeout bounds how long API tests wait for a started application to respond
+const serverStartTimeout = 30 * time.Second
+
+// StartupTimeDetail is the API test detail holding the measured server
+// startup latency in seconds
+const StartupTimeDetail = "startup_time_seconds"
+
+// API test details holding the fastest, average and slowest endpoint
+// response times in milliseconds
+const (
+	ResponseTimeMinDetail = "response_time_min_ms"
+	ResponseTimeAvgDetail = "response_time_avg_ms"
+	ResponseTimeMaxDetail = "response_time_max_ms"
+)
+
 // NewApplicationTester creates a new application tester
 func NewApplicationTester(workingDir string) *ApplicationTester {
 	return &ApplicationTester{
 		workingDir: workingDir,
 		timeout:    5 * time.Minute,
+		parallel:   true,
 	}
 }
 
+// SetCoverageThreshold sets the minimum unit test coverage (in percent); the
+// unit test phase, and with it the suite, fails below it. Zero disables the
+// check.
+func (at *ApplicationTester) SetCoverageThreshold(threshold float64) {
+	at.coverageThreshold = threshold
+}
+
+// SetSmokeTest enables running the generated scripts/smoke_test.sh as the
+// final gate of TestApplication
+func (at *ApplicationTester) SetSmokeTest(enabled bool) {
+	at.smokeTest = enabled
+}
+
+// SetAPITestPort sets the port applications listen on during API tests. Zero,
+// the default, picks a free ephemeral port for every run.
+func (at *ApplicationTester) SetAPITestPort(port int) {
+	at.apiTestPort = port
+}
+
+// apiPort returns the configured API test port or a currently free one
+func (at *ApplicationTester) apiPort() (int, error) {
+	if at.apiTestPort > 0 {
+		return at.apiTestPort, nil
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// waitForServer polls /health and / under baseURL every 100ms until the
+// server returns any HTTP response, timeout elapses or ctx is done
+func waitForServer(ctx context.Context, baseURL string, timeout time.Duration) error {
+	client := &http.Client{Timeout: time.Second}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		var err error
+		for _, path := range []string{"/health", "/"} {
+			var resp *http.Response
+			if resp, err = client.Get(baseURL + path); err == nil {
+				resp.Body.Close()
+				return nil
+			}
+		}
+		if time.Now().Add(100 * time.Millisecond).After(deadline) {
+			return fmt.Errorf("server at %s did not respond within %v: %v", baseURL, timeout, err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for server at %s: %v", baseURL, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// PhaseFunc is called with the kind of a test phase ("build", "static",
+// "unit", "api", "security", "performance" or "smoke") and its result as soon
+// as the phase finishes
+type PhaseFunc func(kind string, result TestResult)
+
 // TestApplication runs comprehensive tests on a generated application
 func (at *ApplicationTester) TestApplication(appPath string, appReq *requirements.ApplicationRequirement) (*TestSuite, error) {
+	return at.TestApplicationProgress(appPath, appReq, nil)
+}
+
+// TestApplicationProgress runs the tests of TestApplication, reporting every
+// phase to onPhase when it finishes. Calls are made one at a time, and none
+// after TestApplicationProgress returns; a nil onPhase reports nothing.
+func (at *ApplicationTester) TestApplicationProgress(appPath string, appReq *requirements.ApplicationRequirement, onPhase PhaseFunc) (*TestSuite, error) {
 	suite := &TestSuite{
 		Name:      appReq.Name,
 		AppPath:   appPath,
@@ -72,36 +183,195 @@ func (at *ApplicationTester) TestApplication(appPath string, appReq *requirement
 	// Detect the language of the application
 	language := at.detectApplicationLanguage(appPath, appReq)
 
-	// Test 1: Build Test (language-specific)
-	buildResult := at.testBuildByLanguage(appPath, appReq, language)
-	suite.Results = append(suite.Results, buildResult)
-
-	// Test 2: Static Analysis (language-specific)
-	staticResult := at.testStaticAnalysisByLanguage(appPath, appReq, language)
-	suite.Results = append(suite.Results, staticResult)
-
-	// Test 3: Unit Tests (if any exist)
-	unitResult := at.testUnitByLanguage(appPath, appReq, language)
-	suite.Results = append(suite.Results, unitResult)
+	// Build, unit, API, performance and smoke tests use the built application
+	// and run in order; static analysis and security checks only read the
+	// sources and run alongside them
+	phases := []testPhase{
+		// Test 1: Build Test (language-specific)
+		{"Build Test", "build", chainGroup, func(ctx context.Context) TestResult { return at.testBuildByLanguage(ctx, appPath, appReq, language) }},
+		// Test 2: Static Analysis (language-specific)
+		{"Static Analysis", "static", 1, func(ctx context.Context) TestResult { return at.testStaticAnalysisByLanguage(ctx, appPath, appReq, language) }},
+		// Test 3: Unit Tests (if any exist)
+		{"Unit Tests", "unit", chainGroup, func(ctx context.Context) TestResult { return at.testUnitByLanguage(ctx, appPath, appReq, language) }},
+	}
 
 	// Test 4: API Tests (if it's an API application)
 	if appReq.Type == "api" || appReq.Type == "web" {
-		apiResult := at.testAPIByLanguage(appPath, appReq, language)
-		suite.Results = append(suite.Results, apiResult)
+		phases = append(phases, testPhase{"API Tests", "api", chainGroup, func(ctx context.Context) TestResult { return at.testAPIByLanguage(ctx, appPath, appReq, language) }})
 	}
 
-	// Test 5: Security Tests (language-specific)
-	securityResult := at.testSecurityByLanguage(appPath, appReq, language)
-	suite.Results = append(suite.Results, securityResult)
+	phases = append(phases,
+		// Test 5: Security Tests (language-specific)
+		testPhase{"Security Tests", "security", 2, func(ctx context.Context) TestResult { return at.testSecurityByLanguage(ctx, appPath, appReq, language) }},
+		// Test 6: Performance Tests (basic), measuring the binary API tests built
+		testPhase{"Performance Tests", "performance", chainGroup, func(context.Context) TestResult { return at.testPerformanceByLanguage(appPath, appReq, language) }},
+	)
 
-	// Test 6: Performance Tests (basic)
-	perfResult := at.testPerformanceByLanguage(appPath, appReq, language)
-	suite.Results = append(suite.Results, perfResult)
+	// Test 7: End-to-end smoke test against the real server (opt-in)
+	if at.smokeTest {
+		phases = append(phases, testPhase{"Smoke Test", "smoke", chainGroup, func(ctx context.Context) TestResult { return at.testSmoke(ctx, appPath) }})
+	}
+
+	suite.Results = at.runPhases(phases, at.observePhases(onPhase))
 
 	// Calculate summary
 	suite.EndTime = time.Now()
 	suite.Duration = suite.EndTime.Sub(suite.StartTime)
+	at.summarizeSuite(suite)
+
+	// Remove the binaries and databases the phases created
+	if err := at.finishArtifacts(appPath); err != nil {
+		suite.Summary += "\n" + err.Error()
+	}
+
+	return suite, nil
+}
+
+// SetPhaseObserver sets a function called with the result of each phase of
+// every test run, in addition to the onPhase of TestApplicationProgress
+func (at *ApplicationTester) SetPhaseObserver(observer PhaseFunc) {
+	at.phaseObserver = observer
+}
+
+// observePhases returns a PhaseFunc passing each phase to the phase observer
+// and then to onPhase, either of which may be nil
+func (at *ApplicationTester) observePhases(onPhase PhaseFunc) PhaseFunc {
+	if at.phaseObserver == nil {
+		return onPhase
+	}
+	return func(kind string, result TestResult) {
+		at.phaseObserver(kind, result)
+		if onPhase != nil {
+			onPhase(kind, result)
+		}
+	}
+}
+
+// chainGroup is the group of phases that depend on the built application
+const chainGroup = 0
+
+// testPhase is one stage of TestApplication. Phases in the same group run in
+// order; groups run concurrently when parallel phases are enabled.
+type testPhase struct {
+	name  string
+	kind  string
+	group int
+	run   func(ctx context.Context) TestResult
+}
+
+// runPhases runs phases and returns their results in declaration order. When
+// the tester timeout expires the context of the phases is cancelled, stopping
+// their commands, and runPhases waits for them to return before reporting the
+// unfinished ones as failed, so nothing still uses the application afterwards.
+// Each result is passed to onPhase, if set, while holding the lock that guards
+// the results.
+func (at *ApplicationTester) runPhases(phases []testPhase, onPhase PhaseFunc) []TestResult {
+	groups := map[int][]int{}
+	var groupOrder []int
+	for i, phase := range phases {
+		group := phase.group
+		if !at.parallel {
+			group = chainGroup
+		}
+		if _, exists := groups[group]; !exists {
+			groupOrder = append(groupOrder, group)
+		}
+		groups[group] = append(groups[group], i)
+	}
+
+	var mutex sync.Mutex
+	results := make([]*TestResult, len(phases))
+	expired := false
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, group := range groupOrder {
+		wg.Add(1)
+		go func(indexes []int) {
+			defer wg.Done()
+			for _, i := range indexes {
+				result := phases[i].run(ctx)
+				mutex.Lock()
+				stop := expired
+				if !stop {
+					results[i] = &result
+					if onPhase != nil {
+						onPhase(phases[i].kind, result)
+					}
+				}
+				mutex.Unlock()
+				if stop {
+					return
+				}
+			}
+		}(groups[group])
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	var timeout <-chan time.Time
+	if at.timeout > 0 {
+		timer := time.NewTimer(at.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-done:
+	case <-timeout:
+		// Discard the results of the cancelled phases, which fail early
+		mutex.Lock()
+		expired = true
+		mutex.Unlock()
+		cancel()
+		<-done
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	expired = true
+
+	ordered := make([]TestResult, len(phases))
+	for i, phase := range phases {
+		if results[i] != nil {
+			ordered[i] = *results[i]
+			continue
+		}
+		ordered[i] = TestResult{
+			Name:     phase.name,
+			Type:     phase.kind,
+			Status:   "fail",
+			Error:    fmt.Sprintf("did not finish within %v", at.timeout),
+			Duration: at.timeout,
+		}
+		if onPhase != nil {
+			onPhase(phase.kind, ordered[i])
+		}
+	}
+	return ordered
+}
+
+// SetTimeout bounds how long TestApplication waits for its phases. Zero waits
+// until every phase finishes.
+func (at *ApplicationTester) SetTimeout(timeout time.Duration) {
+	at.timeout = timeout
+}
+
+// SetParallel runs the phases that do not need the built application
+// concurrently with the ones that do
+func (at *ApplicationTester) SetParallel(parallel bool) {
+	at.parallel = parallel
+}
+
+// summarizeSuite computes totals, coverage and the overall status of a suite
+func (at *ApplicationTester) summarizeSuite(suite *TestSuite) {
 	suite.TotalTests = len(suite.Results)
+	suite.PassedTests, suite.FailedTests, suite.SkippedTests = 0, 0, 0
 
 	for _, result := range suite.Results {
 		switch result.Status {
@@ -135,184 +405,76 @@ func (at *ApplicationTester) TestApplication(appPath string, appReq *requirement
 		suite.Coverage = totalCoverage / float64(coverageCount)
 	}
 
+	// Enforce the coverage threshold against the unit test stage
+	suite.CoverageThreshold = at.coverageThreshold
+	suite.BelowThreshold = false
+	for _, result := range suite.Results {
+		if belowThreshold(result, at.coverageThreshold) {
+			suite.BelowThreshold = true
+			suite.OverallStatus = "failure"
+		}
+	}
+
 	// Generate summary
 	suite.Summary = at.generateSummary(suite)
-
-	return suite, nil
 }
 
-// testBuild tests if the application builds successfully
-func (at *ApplicationTester) testBuild(appPath string, appReq *requirements.ApplicationRequirement) TestResult {
-	result := TestResult{
-		Name: "Build Test",
-		Type: "build",
-	}
-
-	startTime := time.Now()
-
-	// Change to app directory and run go build
-	cmd := exec.Command("go", "build", "-v", ".")
-	cmd.Dir = appPath
-	cmd.Env = append(os.Environ(), "PATH="+os.Getenv("PATH")+":/usr/local/go/bin")
-
-	output, err := cmd.CombinedOutput()
-	result.Duration = time.Since(startTime)
-	result.Output = string(output)
-
-	if err != nil {
-		result.Status = "fail"
-		result.Error = err.Error()
-	} else {
-		result.Status = "pass"
-	}
+// Credentials the API tests start applications with
+const (
+	apiTestKey       = "api-test-key"
+	apiTestJWTSecret = "api-test-secret"
+)
 
-	return result
+// apiAuth is how the API tests authenticate with an application
+type apiAuth struct {
+	env    []string    // variables the application is started with
+	header http.Header // sent with every probe of the requirements' endpoints
+	// unauthenticated is set when no credentials can be obtained, as for an
+	// OAuth2 provider, so protected endpoints answering 401 pass
+	unauthenticated bool
 }
 
-// testStaticAnalysis runs static analysis tools
-func (at *ApplicationTester) testStaticAnalysis(appPath string, appReq *requirements.ApplicationRequirement) TestResult {
-	result := TestResult{
-		Name: "Static Analysis",
-		Type: "static",
-	}
-
-	startTime := time.Now()
-
-	var outputs []string
-	var errors []string
-
-	// Run go vet
-	cmd := exec.Command("go", "vet", "./...")
-	cmd.Dir = appPath
-	cmd.Env = append(os.Environ(), "PATH="+os.Getenv("PATH")+":/usr/local/go/bin")
-
-	output, err := cmd.CombinedOutput()
-	outputs = append(outputs, "=== go vet ===\n"+string(output))
-	if err != nil {
-		errors = append(errors, "go vet: "+err.Error())
-	}
-
-	// Run go fmt check
-	cmd = exec.Command("go", "fmt", "./...")
-	cmd.Dir = appPath
-	cmd.Env = append(os.Environ(), "PATH="+os.Getenv("PATH")+":/usr/local/go/bin")
-
-	output, err = cmd.CombinedOutput()
-	outputs = append(outputs, "=== go fmt ===\n"+string(output))
-	if err != nil {
-		errors = append(errors, "go fmt: "+err.Error())
-	}
-
-	result.Duration = time.Since(startTime)
-	result.Output = strings.Join(outputs, "\n\n")
-
-	if len(errors) > 0 {
-		result.Status = "fail"
-		result.Error = strings.Join(errors, "; ")
-	} else {
-		result.Status = "pass"
-	}
-
-	return result
+// newAPIAuth returns the credentials for appReq's auth strategy: a test API
+// key, or a token signed with a test JWT secret, handed to the application
+// through its environment like the generated smoke test does
+func newAPIAuth(appReq *requirements.ApplicationRequirement) apiAuth {
+	auth := apiAuth{header: http.Header{}}
+	switch appReq.EffectiveAuthStrategy() {
+	case "apikey":
+		auth.env = []string{"API_KEY=" + apiTestKey}
+		auth.header.Set("X-API-Key", apiTestKey)
+	case "jwt":
+		auth.env = []string{"JWT_SECRET=" + apiTestJWTSecret}
+		auth.header.Set("Authorization", "Bearer "+signJWT("api-test", []byte(apiTestJWTSecret)))
+	case "oauth2":
+		auth.unauthenticated = true
+	}
+	return auth
 }
 
-// testUnit runs unit tests
-func (at *ApplicationTester) testUnit(appPath string, appReq *requirements.ApplicationRequirement) TestResult {
-	result := TestResult{
-		Name: "Unit Tests",
-		Type: "unit",
-	}
-
-	startTime := time.Now()
-
-	// Check if there are any test files
-	hasTests, err := at.hasTestFiles(appPath)
-	if err != nil {
-		result.Status = "fail"
-		result.Error = err.Error()
-		result.Duration = time.Since(startTime)
-		return result
-	}
-
-	if !hasTests {
-		result.Status = "skip"
-		result.Output = "No test files found"
-		result.Duration = time.Since(startTime)
-		return result
-	}
-
-	// Run go test with coverage
-	cmd := exec.Command("go", "test", "-v", "-cover", "./...")
-	cmd.Dir = appPath
-	cmd.Env = append(os.Environ(), "PATH="+os.Getenv("PATH")+":/usr/local/go/bin")
-
-	output, err := cmd.CombinedOutput()
-	result.Duration = time.Since(startTime)
-	result.Output = string(output)
-
-	if err != nil {
-		result.Status = "fail"
-		result.Error = err.Error()
-	} else {
-		result.Status = "pass"
-		// Extract coverage from output
-		result.Coverage = at.extractCoverage(string(output))
-	}
-
-	return result
+// signJWT returns an HS256 JWT for subject, valid for an hour
+func signJWT(subject string, secret []byte) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	claims := fmt.Sprintf(`{"sub":%q,"exp":%d}`, subject, time.Now().Add(time.Hour).Unix())
+	payload := base64.RawURLEncoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(header + "." + payload))
+	return header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
 }
 
-// testAPI tests API endpoints
-func (at *ApplicationTester) testAPI(appPath string, appReq *requirements.ApplicationRequirement) TestResult {
-	result := TestResult{
-		Name: "API Tests",
-		Type: "api",
-	}
-
-	startTime := time.Now()
-
-	// Start the application
-	cmd := exec.Command("./"+filepath.Base(appPath))
-	cmd.Dir = appPath
-	cmd.Env = append(os.Environ(), "PORT=8081") // Use different port for testing
-
-	// Start the server
-	err := cmd.Start()
-	if err != nil {
-		result.Status = "fail"
-		result.Error = "Failed to start application: " + err.Error()
-		result.Duration = time.Since(startTime)
-		return result
-	}
-
-	// Ensure we kill the process when done
-	defer func() {
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
-	}()
-
-	// Wait a moment for server to start
-	time.Sleep(2 * time.Second)
-
-	var testResults []map[string]interface{}
+// probeEndpoints sends a request to each endpoint of appReq with auth's
+// credentials, {id} replaced by a test value and a body generated from the
+// first entity for POST and PUT. It returns the result of every request and an
+// error for each that failed.
+func (at *ApplicationTester) probeEndpoints(baseURL string, appReq *requirements.ApplicationRequirement, auth apiAuth) ([]map[string]interface{}, []string) {
+	var results []map[string]interface{}
 	var errors []string
-
-	// Test health endpoint
-	healthResult := at.testEndpoint("GET", "http://localhost:8081/health", nil)
-	testResults = append(testResults, map[string]interface{}{
-		"endpoint": "/health",
-		"method":   "GET",
-		"result":   healthResult,
-	})
-
-	// Test each API endpoint
 	for _, endpoint := range appReq.Endpoints {
-		url := "http://localhost:8081" + endpoint.Path
-		
+		url := baseURL + endpoint.Path
+
 		// Replace path parameters with test values
 		url = strings.ReplaceAll(url, "{id}", "1")
-		
+
 		var body []byte
 		if endpoint.Method == "POST" || endpoint.Method == "PUT" {
 			// Create test data based on the first entity
@@ -322,124 +484,50 @@ func (at *ApplicationTester) testAPI(appPath string, appReq *requirements.Applic
 			}
 		}
 
-		endpointResult := at.testEndpoint(endpoint.Method, url, body)
-		testResults = append(testResults, map[string]interface{}{
+		endpointResult := at.testEndpoint(endpoint.Method, url, body, auth.header)
+		if auth.unauthenticated && endpointResult["status_code"] == http.StatusUnauthorized {
+			// The endpoint is protected and the tests hold no credentials for it
+			endpointResult["success"] = true
+		}
+		results = append(results, map[string]interface{}{
 			"endpoint": endpoint.Path,
 			"method":   endpoint.Method,
 			"result":   endpointResult,
 		})
 
 		if !endpointResult["success"].(bool) {
-			errors = append(errors, fmt.Sprintf("%s %s: %s", endpoint.Method, endpoint.Path, endpointResult["error"]))
-		}
-	}
-
-	result.Duration = time.Since(startTime)
-	result.Details = testResults
-
-	if len(errors) > 0 {
-		result.Status = "fail"
-		result.Error = strings.Join(errors, "; ")
-	} else {
-		result.Status = "pass"
-	}
-
-	result.Output = fmt.Sprintf("Tested %d endpoints", len(testResults))
-
-	return result
-}
-
-// testSecurity runs basic security tests
-func (at *ApplicationTester) testSecurity(appPath string, appReq *requirements.ApplicationRequirement) TestResult {
-	result := TestResult{
-		Name: "Security Tests",
-		Type: "security",
-	}
-
-	startTime := time.Now()
-
-	var issues []string
-	var outputs []string
-
-	// Check for common security issues in code
-	securityIssues := at.scanForSecurityIssues(appPath)
-	if len(securityIssues) > 0 {
-		issues = append(issues, securityIssues...)
-	}
-
-	// Check for hardcoded secrets
-	secrets := at.scanForHardcodedSecrets(appPath)
-	if len(secrets) > 0 {
-		issues = append(issues, secrets...)
-	}
-
-	outputs = append(outputs, fmt.Sprintf("Security scan completed. Found %d potential issues.", len(issues)))
-
-	result.Duration = time.Since(startTime)
-	result.Output = strings.Join(outputs, "\n")
-
-	if len(issues) > 0 {
-		result.Status = "fail"
-		result.Error = strings.Join(issues, "; ")
-		result.Details = map[string]interface{}{
-			"issues": issues,
+			reason := endpointResult["error"]
+			if reason == nil {
+				reason = fmt.Sprintf("status %v", endpointResult["status_code"])
+			}
+			errors = append(errors, fmt.Sprintf("%s %s: %s", endpoint.Method, endpoint.Path, reason))
 		}
-	} else {
-		result.Status = "pass"
-	}
-
-	return result
-}
-
-// testPerformance runs basic performance tests
-func (at *ApplicationTester) testPerformance(appPath string, appReq *requirements.ApplicationRequirement) TestResult {
-	result := TestResult{
-		Name: "Performance Tests",
-		Type: "performance",
-	}
-
-	startTime := time.Now()
-
-	// For now, just check binary size and basic metrics
-	var metrics []string
-
-	// Check binary size
-	binaryPath := filepath.Join(appPath, filepath.Base(appPath))
-	if info, err := os.Stat(binaryPath); err == nil {
-		size := info.Size()
-		metrics = append(metrics, fmt.Sprintf("Binary size: %d bytes (%.2f MB)", size, float64(size)/1024/1024))
-	}
-
-	// Count lines of code
-	loc, err := at.countLinesOfCode(appPath)
-	if err == nil {
-		metrics = append(metrics, fmt.Sprintf("Lines of code: %d", loc))
 	}
-
-	result.Duration = time.Since(startTime)
-	result.Output = strings.Join(metrics, "\n")
-	result.Status = "pass"
-
-	result.Details = map[string]interface{}{
-		"metrics": metrics,
-	}
-
-	return result
+	return results, errors
 }
 
 // Helper methods
 
+// errStopWalk ends a filepath.Walk early once the answer is known
+var errStopWalk = errors.New("stop walk")
+
 // hasTestFiles checks if there are any test files in the project
 func (at *ApplicationTester) hasTestFiles(appPath string) (bool, error) {
-	return filepath.Walk(appPath, func(path string, info os.FileInfo, err error) error {
+	found := false
+	err := filepath.Walk(appPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if strings.HasSuffix(info.Name(), "_test.go") {
-			return fmt.Errorf("found test file") // Use error to break out of walk
+		if !info.IsDir() && strings.HasSuffix(info.Name(), "_test.go") {
+			found = true
+			return errStopWalk
 		}
 		return nil
-	}) != nil, nil
+	})
+	if err != nil && err != errStopWalk {
+		return false, fmt.Errorf("failed to scan for test files: %v", err)
+	}
+	return found, nil
 }
 
 // extractCoverage extracts coverage percentage from go test output
@@ -454,8 +542,8 @@ func (at *ApplicationTester) extractCoverage(output string) float64 {
 	return 0
 }
 
-// testEndpoint tests a single API endpoint
-func (at *ApplicationTester) testEndpoint(method, url string, body []byte) map[string]interface{} {
+// testEndpoint tests a single API endpoint, sending header with the request
+func (at *ApplicationTester) testEndpoint(method, url string, body []byte, header http.Header) map[string]interface{} {
 	client := &http.Client{Timeout: 10 * time.Second}
 	
 	var req *http.Request
@@ -474,8 +562,13 @@ func (at *ApplicationTester) testEndpoint(method, url string, body []byte) map[s
 			"error":   err.Error(),
 		}
 	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
 
+	sent := time.Now()
 	resp, err := client.Do(req)
+	responseTime := time.Since(sent)
 	if err != nil {
 		return map[string]interface{}{
 			"success": false,
@@ -487,19 +580,48 @@ func (at *ApplicationTester) testEndpoint(method, url string, body []byte) map[s
 	responseBody, _ := io.ReadAll(resp.Body)
 
 	return map[string]interface{}{
-		"success":     resp.StatusCode < 400,
-		"status_code": resp.StatusCode,
-		"response":    string(responseBody),
+		"success":          resp.StatusCode < 400,
+		"status_code":      resp.StatusCode,
+		"response":         string(responseBody),
+		"response_time_ms": float64(responseTime) / float64(time.Millisecond),
 	}
 }
 
+// addResponseTimes aggregates the response times of the endpoint results
+// into details; endpoints that did not respond are left out
+func addResponseTimes(details map[string]interface{}, endpoints []map[string]interface{}) {
+	var min, max, total float64
+	count := 0
+	for _, endpoint := range endpoints {
+		result, _ := endpoint["result"].(map[string]interface{})
+		ms, ok := result["response_time_ms"].(float64)
+		if !ok {
+			continue
+		}
+		if count == 0 || ms < min {
+			min = ms
+		}
+		if ms > max {
+			max = ms
+		}
+		total += ms
+		count++
+	}
+	if count == 0 {
+		return
+	}
+	details[ResponseTimeMinDetail] = min
+	details[ResponseTimeAvgDetail] = total / float64(count)
+	details[ResponseTimeMaxDetail] = max
+}
+
 // generateTestData generates test data for an entity
 func (at *ApplicationTester) generateTestData(entity requirements.Entity) map[string]interface{} {
 	data := make(map[string]interface{})
 	
 	for _, field := range entity.Fields {
-		if field.Name == "id" || field.Name == "created_at" {
-			continue // Skip auto-generated fields
+		if field.Name == "id" || field.IsAutoManaged() {
+			continue // Skip fields the application sets itself
 		}
 		
 		switch field.Type {
@@ -521,123 +643,162 @@ func (at *ApplicationTester) generateTestData(entity requirements.Entity) map[st
 	return data
 }
 
-// scanForSecurityIssues scans code for common security issues
-func (at *ApplicationTester) scanForSecurityIssues(appPath string) []string {
-	var issues []string
-	
+// sourceScanTool is the Tool of the findings the built-in source scans report
+const sourceScanTool = "source scan"
+
+// scanSource runs the built-in source scans over the Go files of appPath,
+// reporting each location once even when several scans flag it
+func (at *ApplicationTester) scanSource(appPath string) ([]SecurityFinding, error) {
+	findings, err := at.scanForHardcodedSecrets(appPath)
+	if err != nil {
+		return findings, err
+	}
+	reported := make(map[string]bool)
+	for _, finding := range findings {
+		reported[fmt.Sprintf("%s:%d", finding.File, finding.Line)] = true
+	}
+
+	issues, err := at.scanForSecurityIssues(appPath)
+	for _, issue := range issues {
+		if issue.Line > 0 && reported[fmt.Sprintf("%s:%d", issue.File, issue.Line)] {
+			continue
+		}
+		findings = append(findings, issue)
+	}
+	return findings, err
+}
+
+// scannedFile reports whether the source scans read the file: Go source,
+// leaving out tests, whose fixtures are not shipped with the application
+func scannedFile(info os.FileInfo) bool {
+	name := info.Name()
+	return !info.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+}
+
+// samplePlaceholderPattern matches the "Sample <Entity> <field> N" literals
+// generated seed data uses, which are placeholders rather than secrets
+var samplePlaceholderPattern = regexp.MustCompile("(?i)[\"'`]sample \\w+ \\w+ \\d+[\"'`]")
+
+// withoutPlaceholders blanks the sample placeholders of a line before it is
+// matched against the secret patterns
+func withoutPlaceholders(line string) string {
+	return samplePlaceholderPattern.ReplaceAllString(line, `""`)
+}
+
+// hardcodedPasswordPattern matches a password assigned or keyed to a string
+// literal, e.g. password = "..." or "password": "...", in lower-cased source
+var hardcodedPasswordPattern = regexp.MustCompile(`password"?\s*[:=]\s*"[^"]+"`)
+
+// scanForSecurityIssues scans non-test Go files for common security issues: SQL built
+// by concatenation in files that call db.Exec, and hardcoded passwords
+func (at *ApplicationTester) scanForSecurityIssues(appPath string) ([]SecurityFinding, error) {
+	var findings []SecurityFinding
+
 	// This is a basic implementation - in a real system, you'd use tools like gosec
 	err := filepath.Walk(appPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		
-		if strings.HasSuffix(info.Name(), ".go") {
-			content, err := os.ReadFile(path)
-			if err != nil {
-				return err
-			}
-			
-			contentStr := string(content)
-			
-			// Check for SQL injection vulnerabilities
-			if strings.Contains(contentStr, "db.Exec(") && strings.Contains(contentStr, "+") {
-				issues = append(issues, fmt.Sprintf("Potential SQL injection in %s", path))
-			}
-			
-			// Check for hardcoded passwords
-			if regexp.MustCompile(`password\s*[:=]\s*[""][^"\]+[""]`).MatchString(strings.ToLower(contentStr)) {
-				issues = append(issues, fmt.Sprintf("Potential hardcoded password in %s", path))
-			}
+		if !scannedFile(info) {
+			return nil
 		}
-		
-		return nil
-	})
-	
-	if err != nil {
-		issues = append(issues, "Error scanning for security issues: "+err.Error())
-	}
-	
-	return issues
-}
 
-// scanForHardcodedSecrets scans for hardcoded secrets
-func (at *ApplicationTester) scanForHardcodedSecrets(appPath string) []string {
-	var secrets []string
-	
-	patterns := []*regexp.Regexp{
-		regexp.MustCompile(`(?i)api[_-]?key\s*[:=]\s*[""][^"\]{10,}[""]`),
-		regexp.MustCompile(`(?i)secret[_-]?key\s*[:=]\s*[""][^"\]{10,}[""]`),
-		regexp.MustCompile(`(?i)token\s*[:=]\s*[""][^"\]{10,}[""]`),
-		regexp.MustCompile(`(?i)password\s*[:=]\s*[""][^"\]{8,}[""]`),
-	}
-	
-	err := filepath.Walk(appPath, func(path string, info os.FileInfo, err error) error {
+		content, err := os.ReadFile(path)
 		if err != nil {
 			return err
 		}
-		
-		if strings.HasSuffix(info.Name(), ".go") {
-			content, err := os.ReadFile(path)
-			if err != nil {
-				return err
-			}
-			
-			contentStr := string(content)
-			
-			for _, pattern := range patterns {
-				if pattern.MatchString(contentStr) {
-					secrets = append(secrets, fmt.Sprintf("Potential hardcoded secret in %s", path))
-				}
+		contentStr := string(content)
+
+		// Check for SQL injection vulnerabilities
+		if strings.Contains(contentStr, "db.Exec(") && strings.Contains(contentStr, "+") {
+			findings = append(findings, SecurityFinding{
+				Tool:     sourceScanTool,
+				RuleID:   "sql-injection",
+				Severity: "MEDIUM",
+				File:     path,
+				Message:  "Potential SQL injection",
+			})
+		}
+
+		// Check for hardcoded passwords
+		for i, line := range strings.Split(strings.ToLower(contentStr), "\n") {
+			if hardcodedPasswordPattern.MatchString(withoutPlaceholders(line)) {
+				findings = append(findings, SecurityFinding{
+					Tool:     sourceScanTool,
+					RuleID:   "hardcoded-password",
+					Severity: "HIGH",
+					File:     path,
+					Line:     i + 1,
+					Message:  "Potential hardcoded password",
+				})
 			}
 		}
-		
 		return nil
 	})
-	
 	if err != nil {
-		secrets = append(secrets, "Error scanning for secrets: "+err.Error())
+		return findings, fmt.Errorf("failed to scan for security issues: %w", err)
 	}
-	
-	return secrets
+	return findings, nil
 }
 
-// countLinesOfCode counts lines of code in the project
-func (at *ApplicationTester) countLinesOfCode(appPath string) (int, error) {
-	totalLines := 0
-	
+// quotedLiteral matches a double-, single- or backtick-quoted literal of at least
+// min characters on one line
+func quotedLiteral(min int) string {
+	return fmt.Sprintf(`(?:"[^"\n]{%[1]d,}"|'[^'\n]{%[1]d,}'|`+"`[^`\\n]{%[1]d,}`)", min)
+}
+
+// secretPatterns match assignments of string literals to secret-looking names,
+// e.g. apiKey := "..." or "password": '...'
+var secretPatterns = []struct {
+	kind    string
+	rule    string
+	pattern *regexp.Regexp
+}{
+	{"API key", "hardcoded-api-key", regexp.MustCompile(`(?i)api[_-]?key\w*["']?\s*(?::=|[:=])\s*` + quotedLiteral(10))},
+	{"secret key", "hardcoded-secret-key", regexp.MustCompile(`(?i)secret[_-]?key\w*["']?\s*(?::=|[:=])\s*` + quotedLiteral(10))},
+	{"token", "hardcoded-token", regexp.MustCompile(`(?i)token\w*["']?\s*(?::=|[:=])\s*` + quotedLiteral(10))},
+	{"password", "hardcoded-password", regexp.MustCompile(`(?i)password\w*["']?\s*(?::=|[:=])\s*` + quotedLiteral(8))},
+}
+
+// scanForHardcodedSecrets scans non-test Go files for hardcoded secrets, reporting each
+// offending line once even when several patterns match it
+func (at *ApplicationTester) scanForHardcodedSecrets(appPath string) ([]SecurityFinding, error) {
+	var findings []SecurityFinding
+
 	err := filepath.Walk(appPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		
-		if strings.HasSuffix(info.Name(), ".go") {
-			file, err := os.Open(path)
-			if err != nil {
-				return err
-			}
-			defer file.Close()
-			
-			scanner := bufio.NewScanner(file)
-			for scanner.Scan() {
-				line := strings.TrimSpace(scanner.Text())
-				if len(line) > 0 && !strings.HasPrefix(line, "//") {
-					totalLines++
+		if !scannedFile(info) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		for i, line := range strings.Split(string(content), "\n") {
+			for _, secret := range secretPatterns {
+				if secret.pattern.MatchString(withoutPlaceholders(line)) {
+					findings = append(findings, SecurityFinding{
+						Tool:     sourceScanTool,
+						RuleID:   secret.rule,
+						Severity: "HIGH",
+						File:     path,
+						Line:     i + 1,
+						Message:  "Potential hardcoded " + secret.kind,
+					})
+					break
 				}
 			}
-			
-			if err := scanner.Err(); err != nil {
-				return err
-			}
 		}
-		
 		return nil
 	})
-	
 	if err != nil {
-		return 0, err
+		return findings, fmt.Errorf("failed to scan for secrets: %w", err)
 	}
-	
-	return totalLines, nil
+	return findings, nil
 }
 
 // generateSummary generates a summary of the test suite
@@ -650,6 +811,9 @@ func (at *ApplicationTester) generateSummary(suite *TestSuite) string {
 	if suite.Coverage > 0 {
 		summary.WriteString(fmt.Sprintf("Coverage: %.2f%%\n", suite.Coverage))
 	}
+	if suite.BelowThreshold {
+		summary.WriteString(fmt.Sprintf("Coverage below threshold: %.2f%% < %.2f%%\n", suite.Coverage, suite.CoverageThreshold))
+	}
 
 	for _, result := range suite.Results {
 		summary.WriteString(fmt.Sprintf("- %s (%s): %s\n", result.Name, result.Type, strings.ToUpper(result.Status)))
@@ -673,47 +837,89 @@ func (at *ApplicationTester) SaveTestResults(suite *TestSuite, outputPath string
 	return os.WriteFile(outputPath, data, 0644)
 }
 
+// TestResultsFile is the name of the saved test suite in an application directory
+const TestResultsFile = "test_results.json"
+
+// LoadTestResults reads a test suite written by SaveTestResults
+func LoadTestResults(path string) (*TestSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test results: %w", err)
+	}
+
+	var suite TestSuite
+	if err := json.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse test results: %v", err)
+	}
+	return &suite, nil
+}
+
 
 
 
 // detectApplicationLanguage detects the programming language of the generated application
 func (at *ApplicationTester) detectApplicationLanguage(appPath string, appReq *requirements.ApplicationRequirement) string {
-	// First check the requirement language if available
-	if appReq.Language != "" {
-		return strings.ToLower(appReq.Language)
-	}
+	detected := at.detectLanguageFromFiles(appPath)
 
-	// Check for language-specific files
-	if _, err := os.Stat(filepath.Join(appPath, "package.json")); err == nil {
-		return "javascript"
+	requested := ""
+	if appReq != nil {
+		requested = strings.ToLower(appReq.Language)
 	}
-	if _, err := os.Stat(filepath.Join(appPath, "go.mod")); err == nil {
+
+	// "go" is what requirements default to, so it only wins when the files
+	// on disk don't point at another language
+	if requested == "" || (requested == "go" && detected != "") {
+		if detected != "" {
+			return detected
+		}
 		return "go"
 	}
-	if _, err := os.Stat(filepath.Join(appPath, "requirements.txt")); err == nil {
-		return "python"
-	}
-	if _, err := os.Stat(filepath.Join(appPath, "pom.xml")); err == nil {
-		return "java"
-	}
-	if _, err := os.Stat(filepath.Join(appPath, "composer.json")); err == nil {
-		return "php"
-	}
-	if _, err := os.Stat(filepath.Join(appPath, "Gemfile")); err == nil {
-		return "ruby"
+
+	return requested
+}
+
+// detectLanguageFromFiles infers the language from well-known project files,
+// returning an empty string when none are present
+func (at *ApplicationTester) detectLanguageFromFiles(appPath string) string {
+	indicators := []struct {
+		file     string
+		language string
+	}{
+		{"go.mod", "go"},
+		{"package.json", "javascript"},
+		{"requirements.txt", "python"},
+		{"pom.xml", "java"},
+		{"composer.json", "php"},
+		{"Gemfile", "ruby"},
+		{"Cargo.toml", "rust"},
+		{"*.sln", "csharp"},
+		{"*.csproj", "csharp"},
+	}
+
+	for _, indicator := range indicators {
+		if matches, _ := filepath.Glob(filepath.Join(appPath, indicator.file)); len(matches) > 0 {
+			return indicator.language
+		}
 	}
 
-	// Default to go if no specific indicators found
-	return "go"
+	return ""
+}
+
+// CheckBuild builds the application at appPath as the build test phase does,
+// without running the rest of the suite
+func (at *ApplicationTester) CheckBuild(appPath string, appReq *requirements.ApplicationRequirement) TestResult {
+	return at.testBuildByLanguage(context.Background(), appPath, appReq, at.detectApplicationLanguage(appPath, appReq))
 }
 
 // testBuildByLanguage runs build tests specific to the detected language
-func (at *ApplicationTester) testBuildByLanguage(appPath string, appReq *requirements.ApplicationRequirement, language string) TestResult {
+func (at *ApplicationTester) testBuildByLanguage(ctx context.Context, appPath string, appReq *requirements.ApplicationRequirement, language string) TestResult {
 	result := TestResult{
 		Name: "Build Test",
 		Type: "build",
 	}
 	start := time.Now()
+	ctx, cancel := at.commandContext(ctx, language)
+	defer cancel()
 
 	var cmd *exec.Cmd
 	switch language {
@@ -725,13 +931,16 @@ func (at *ApplicationTester) testBuildByLanguage(appPath string, appReq *require
 			result.Duration = time.Since(start)
 			return result
 		}
-		cmd = exec.Command("npm", "install")
+		cmd = exec.CommandContext(ctx, "npm", "install")
 	case "go", "golang":
-		cmd = exec.Command("go", "build", "-v", ".")
+		// -mod=mod fills in the go.sum that generated apps ship empty; the
+		// binaries are discarded, as go build would otherwise write one named
+		// after the module into the app directory when ./... is a single package
+		cmd = exec.CommandContext(ctx, "go", "build", "-mod=mod", "-o", os.DevNull, "./...")
 	case "python":
 		// Check if requirements.txt exists
 		if _, err := os.Stat(filepath.Join(appPath, "requirements.txt")); err == nil {
-			cmd = exec.Command("pip", "install", "-r", "requirements.txt")
+			cmd = exec.CommandContext(ctx, "pip", "install", "-r", "requirements.txt")
 		} else {
 			result.Status = "skip"
 			result.Output = "No requirements.txt found, skipping build test"
@@ -740,13 +949,13 @@ func (at *ApplicationTester) testBuildByLanguage(appPath string, appReq *require
 		}
 	case "java":
 		if _, err := os.Stat(filepath.Join(appPath, "pom.xml")); err == nil {
-			cmd = exec.Command("mvn", "compile")
+			cmd = exec.CommandContext(ctx, "mvn", "compile")
 		} else {
-			cmd = exec.Command("javac", "*.java")
+			cmd = exec.CommandContext(ctx, "javac", "*.java")
 		}
 	case "php":
 		if _, err := os.Stat(filepath.Join(appPath, "composer.json")); err == nil {
-			cmd = exec.Command("composer", "install")
+			cmd = exec.CommandContext(ctx, "composer", "install")
 		} else {
 			result.Status = "skip"
 			result.Output = "No composer.json found, skipping build test"
@@ -755,7 +964,7 @@ func (at *ApplicationTester) testBuildByLanguage(appPath string, appReq *require
 		}
 	case "ruby":
 		if _, err := os.Stat(filepath.Join(appPath, "Gemfile")); err == nil {
-			cmd = exec.Command("bundle", "install")
+			cmd = exec.CommandContext(ctx, "bundle", "install")
 		} else {
 			result.Status = "skip"
 			result.Output = "No Gemfile found, skipping build test"
@@ -763,20 +972,25 @@ func (at *ApplicationTester) testBuildByLanguage(appPath string, appReq *require
 			return result
 		}
 	default:
-		result.Status = "skip"
-		result.Output = fmt.Sprintf("Build test not implemented for language: %s", language)
-		result.Duration = time.Since(start)
-		return result
+		// Other languages are built with whatever build system the project uses
+		name, args := buildsys.DetectBuildCommand(appPath)
+		if name == "" {
+			result.Status = "skip"
+			result.Output = fmt.Sprintf("Build test not implemented for language: %s", language)
+			result.Duration = time.Since(start)
+			return result
+		}
+		cmd = exec.CommandContext(ctx, name, args...)
 	}
 
 	cmd.Dir = appPath
-	output, err := cmd.CombinedOutput()
+	output, err := combinedOutput(ctx, cmd)
 	result.Duration = time.Since(start)
 	result.Output = string(output)
 
 	if err != nil {
 		result.Status = "fail"
-		result.Error = err.Error()
+		result.Error = at.commandError(ctx, cmd, language, err)
 	} else {
 		result.Status = "pass"
 	}
@@ -785,7 +999,7 @@ func (at *ApplicationTester) testBuildByLanguage(appPath string, appReq *require
 }
 
 // testStaticAnalysisByLanguage runs static analysis specific to the detected language
-func (at *ApplicationTester) testStaticAnalysisByLanguage(appPath string, appReq *requirements.ApplicationRequirement, language string) TestResult {
+func (at *ApplicationTester) testStaticAnalysisByLanguage(ctx context.Context, appPath string, appReq *requirements.ApplicationRequirement, language string) TestResult {
 	result := TestResult{
 		Name: "Static Analysis",
 		Type: "static",
@@ -841,15 +1055,17 @@ func (at *ApplicationTester) testStaticAnalysisByLanguage(appPath string, appReq
 	allPassed := true
 
 	for _, cmdArgs := range commands {
-		cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+		ctx, cancel := at.commandContext(ctx, language)
+		cmd := exec.CommandContext(ctx, cmdArgs[0], cmdArgs[1:]...)
 		cmd.Dir = appPath
-		output, err := cmd.CombinedOutput()
+		output, err := combinedOutput(ctx, cmd)
 		outputs = append(outputs, fmt.Sprintf("%s: %s", strings.Join(cmdArgs, " "), string(output)))
-		
+
 		if err != nil {
 			allPassed = false
-			errors = append(errors, fmt.Sprintf("%s: %s", strings.Join(cmdArgs, " "), err.Error()))
+			errors = append(errors, fmt.Sprintf("%s: %s", strings.Join(cmdArgs, " "), at.commandError(ctx, cmd, language, err)))
 		}
+		cancel()
 	}
 
 	result.Duration = time.Since(start)
@@ -866,14 +1082,19 @@ func (at *ApplicationTester) testStaticAnalysisByLanguage(appPath string, appReq
 }
 
 // testUnitByLanguage runs unit tests specific to the detected language
-func (at *ApplicationTester) testUnitByLanguage(appPath string, appReq *requirements.ApplicationRequirement, language string) TestResult {
+func (at *ApplicationTester) testUnitByLanguage(ctx context.Context, appPath string, appReq *requirements.ApplicationRequirement, language string) TestResult {
 	result := TestResult{
 		Name: "Unit Tests",
 		Type: "unit",
 	}
 	start := time.Now()
+	ctx, cancel := at.commandContext(ctx, language)
+	defer cancel()
 
 	var cmd *exec.Cmd
+	// profile is where Go unit tests write their coverage profile, an artifact
+	// removed with the binaries when the run finishes
+	var profile string
 	switch language {
 	case "javascript", "node", "nodejs":
 		// Check if test script exists in package.json
@@ -883,32 +1104,55 @@ func (at *ApplicationTester) testUnitByLanguage(appPath string, appReq *requirem
 			if json.Unmarshal(data, &packageJson) == nil {
 				if scripts, ok := packageJson["scripts"].(map[string]interface{}); ok {
 					if _, hasTest := scripts["test"]; hasTest {
-						cmd = exec.Command("npm", "test")
+						cmd = exec.CommandContext(ctx, "npm", "test")
 					}
 				}
 			}
 		}
 	case "go", "golang":
-		cmd = exec.Command("go", "test", "-v", "./...")
+		hasTests, err := at.hasTestFiles(appPath)
+		if err != nil {
+			result.Status = "fail"
+			result.Error = err.Error()
+			result.Duration = time.Since(start)
+			return result
+		}
+		if !hasTests {
+			result.Status = "skip"
+			result.Output = "No test files found"
+			result.Duration = time.Since(start)
+			return result
+		}
+		if profile, err = at.artifactPath(appPath, CoverageProfileFile); err != nil {
+			result.Status = "fail"
+			result.Error = err.Error()
+			result.Duration = time.Since(start)
+			return result
+		}
+		cmd = exec.CommandContext(ctx, "go", "test", "-v", "-coverprofile="+profile, "./...")
 	case "python":
 		if _, err := exec.LookPath("pytest"); err == nil {
-			cmd = exec.Command("pytest", "-v")
+			cmd = exec.CommandContext(ctx, "pytest", "-v")
 		} else if _, err := exec.LookPath("python"); err == nil {
-			cmd = exec.Command("python", "-m", "unittest", "discover", "-v")
+			cmd = exec.CommandContext(ctx, "python", "-m", "unittest", "discover", "-v")
 		}
 	case "java":
 		if _, err := os.Stat(filepath.Join(appPath, "pom.xml")); err == nil {
-			cmd = exec.Command("mvn", "test")
+			cmd = exec.CommandContext(ctx, "mvn", "test")
 		}
 	case "php":
 		if _, err := exec.LookPath("phpunit"); err == nil {
-			cmd = exec.Command("phpunit")
+			cmd = exec.CommandContext(ctx, "phpunit")
 		}
 	case "ruby":
 		if _, err := os.Stat(filepath.Join(appPath, "Rakefile")); err == nil {
-			cmd = exec.Command("rake", "test")
+			cmd = exec.CommandContext(ctx, "rake", "test")
 		} else if _, err := exec.LookPath("rspec"); err == nil {
-			cmd = exec.Command("rspec")
+			cmd = exec.CommandContext(ctx, "rspec")
+		}
+	default:
+		if name, args := buildsys.DetectTestCommand(appPath); name != "" {
+			cmd = exec.CommandContext(ctx, name, args...)
 		}
 	}
 
@@ -920,22 +1164,43 @@ func (at *ApplicationTester) testUnitByLanguage(appPath string, appReq *requirem
 	}
 
 	cmd.Dir = appPath
-	output, err := cmd.CombinedOutput()
+	output, err := combinedOutput(ctx, cmd)
 	result.Duration = time.Since(start)
 	result.Output = string(output)
 
 	if err != nil {
 		result.Status = "fail"
-		result.Error = err.Error()
-	} else {
-		result.Status = "pass"
+		result.Error = at.commandError(ctx, cmd, language, err)
+		return result
+	}
+
+	result.Status = "pass"
+	result.Coverage = at.extractCoverage(string(output))
+	details := map[string]interface{}{}
+	if profile != "" {
+		// The profile totals every package, where the output reports each one
+		if coverage, err := parseCoverProfile(profile); err == nil {
+			result.Coverage = coverage
+			details[CoverageProfileDetail] = profile
+		}
+	}
+
+	if at.coverageThreshold > 0 && result.Coverage < at.coverageThreshold {
+		result.Status = "fail"
+		result.Error = fmt.Sprintf("coverage %.1f%% is below the minimum of %.1f%%", result.Coverage, at.coverageThreshold)
+		details[BelowThresholdDetail] = true
+	}
+	if len(details) > 0 {
+		result.Details = details
 	}
 
 	return result
 }
 
-// testAPIByLanguage runs API tests specific to the detected language
-func (at *ApplicationTester) testAPIByLanguage(appPath string, appReq *requirements.ApplicationRequirement, language string) TestResult {
+// testAPIByLanguage runs API tests specific to the detected language. It
+// probes a few common health routes and then every endpoint of appReq, failing
+// when no health route answers or any endpoint fails.
+func (at *ApplicationTester) testAPIByLanguage(ctx context.Context, appPath string, appReq *requirements.ApplicationRequirement, language string) TestResult {
 	result := TestResult{
 		Name: "API Tests",
 		Type: "api",
@@ -944,33 +1209,43 @@ func (at *ApplicationTester) testAPIByLanguage(appPath string, appReq *requireme
 
 	// Start the application based on language
 	var cmd *exec.Cmd
-	var port string = "3000" // default port
 
 	switch language {
 	case "javascript", "node", "nodejs":
 		// Try to start with npm start first
 		if _, err := os.Stat(filepath.Join(appPath, "package.json")); err == nil {
-			cmd = exec.Command("npm", "start")
+			cmd = exec.CommandContext(ctx, "npm", "start")
 		} else if _, err := os.Stat(filepath.Join(appPath, "app.js")); err == nil {
-			cmd = exec.Command("node", "app.js")
+			cmd = exec.CommandContext(ctx, "node", "app.js")
 		} else if _, err := os.Stat(filepath.Join(appPath, "index.js")); err == nil {
-			cmd = exec.Command("node", "index.js")
+			cmd = exec.CommandContext(ctx, "node", "index.js")
 		}
 	case "go", "golang":
 		// Build first, then run
-		buildCmd := exec.Command("go", "build", "-o", "app", ".")
+		binaryPath, err := at.artifactPath(appPath, BinaryName)
+		if err != nil {
+			result.Status = "fail"
+			result.Error = err.Error()
+			result.Duration = time.Since(start)
+			return result
+		}
+		buildCtx, cancel := at.commandContext(ctx, language)
+		buildCmd := exec.CommandContext(buildCtx, "go", "build", "-o", binaryPath, ".")
 		buildCmd.Dir = appPath
-		if err := buildCmd.Run(); err == nil {
-			cmd = exec.Command("./app")
-			port = "8080" // Go apps typically use 8080
+		err = runCommand(buildCtx, buildCmd)
+		cancel()
+		if err == nil {
+			cmd = exec.CommandContext(ctx, binaryPath)
+		} else {
+			// The performance phase must not measure a binary left by an earlier run
+			os.Remove(binaryPath)
 		}
 	case "python":
 		if _, err := os.Stat(filepath.Join(appPath, "app.py")); err == nil {
-			cmd = exec.Command("python", "app.py")
+			cmd = exec.CommandContext(ctx, "python", "app.py")
 		} else if _, err := os.Stat(filepath.Join(appPath, "main.py")); err == nil {
-			cmd = exec.Command("python", "main.py")
+			cmd = exec.CommandContext(ctx, "python", "main.py")
 		}
-		port = "5000" // Flask default
 	}
 
 	if cmd == nil {
@@ -980,21 +1255,55 @@ func (at *ApplicationTester) testAPIByLanguage(appPath string, appReq *requireme
 		return result
 	}
 
+	port, err := at.apiPort()
+	if err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+		result.Duration = time.Since(start)
+		return result
+	}
+	baseURL := fmt.Sprintf("http://localhost:%d", port)
+
+	databaseEnv, err := at.testDatabaseEnv(appPath, appReq, language)
+	if err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	auth := newAPIAuth(appReq)
 	cmd.Dir = appPath
-	
+	cmd.Env = append(os.Environ(), fmt.Sprintf("PORT=%d", port))
+	cmd.Env = append(cmd.Env, auth.env...)
+	if databaseEnv != "" {
+		cmd.Env = append(cmd.Env, databaseEnv)
+	}
+
 	// Start the application
-	if err := cmd.Start(); err != nil {
+	launched := time.Now()
+	stop, err := startServer(cmd)
+	if err != nil {
 		result.Status = "fail"
 		result.Error = fmt.Sprintf("Failed to start application: %v", err)
 		result.Duration = time.Since(start)
 		return result
 	}
+	defer stop()
 
-	// Wait a moment for the server to start
-	time.Sleep(2 * time.Second)
+	// Wait for the server to start
+	if err := waitForServer(ctx, baseURL, serverStartTimeout); err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+		result.Duration = time.Since(start)
+		return result
+	}
+	details := map[string]interface{}{
+		StartupTimeDetail: time.Since(launched).Seconds(),
+	}
+	result.Details = details
 
 	// Test basic endpoints
-	baseURL := fmt.Sprintf("http://localhost:%s", port)
 	endpoints := []string{"/", "/health", "/api", "/api/health"}
 	
 	var testResults []string
@@ -1013,94 +1322,188 @@ func (at *ApplicationTester) testAPIByLanguage(appPath string, appReq *requireme
 		}
 	}
 
-	// Stop the application
-	if cmd.Process != nil {
-		cmd.Process.Kill()
+	// Exercise the application's own endpoints
+	endpointResults, endpointErrors := at.probeEndpoints(baseURL, appReq, auth)
+	for _, endpoint := range endpointResults {
+		endpointResult := endpoint["result"].(map[string]interface{})
+		if code, ok := endpointResult["status_code"]; ok {
+			testResults = append(testResults, fmt.Sprintf("%s %s: %v", endpoint["method"], endpoint["endpoint"], code))
+		} else {
+			testResults = append(testResults, fmt.Sprintf("%s %s: error - %v", endpoint["method"], endpoint["endpoint"], endpointResult["error"]))
+		}
+	}
+	if len(endpointResults) > 0 {
+		details["endpoints"] = endpointResults
+		addResponseTimes(details, endpointResults)
 	}
 
 	result.Duration = time.Since(start)
 	result.Output = strings.Join(testResults, "\n")
 
-	if successCount > 0 {
-		result.Status = "pass"
-		result.Details = map[string]interface{}{
-			"endpoints_tested": len(endpoints),
-			"successful_responses": successCount,
-		}
-	} else {
+	switch {
+	case successCount == 0:
 		result.Status = "fail"
 		result.Error = "No endpoints responded successfully"
+	case len(endpointErrors) > 0:
+		result.Status = "fail"
+		result.Error = strings.Join(endpointErrors, "; ")
+	default:
+		result.Status = "pass"
+		details["endpoints_tested"] = len(endpoints)
+		details["successful_responses"] = successCount
 	}
 
 	return result
 }
 
-// testSecurityByLanguage runs security tests specific to the detected language
-func (at *ApplicationTester) testSecurityByLanguage(appPath string, appReq *requirements.ApplicationRequirement, language string) TestResult {
+// testSmoke runs the generated smoke script, which builds and starts the
+// application and exercises its CRUD endpoints over HTTP
+func (at *ApplicationTester) testSmoke(ctx context.Context, appPath string) TestResult {
+	result := TestResult{
+		Name: "Smoke Test",
+		Type: "smoke",
+	}
+	start := time.Now()
+
+	scriptPath := filepath.Join(appPath, "scripts", "smoke_test.sh")
+	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
+		result.Status = "skip"
+		result.Output = "No smoke test script found"
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	// A zero timeout waits for the script like it waits for every phase
+	if at.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, at.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", scriptPath)
+	cmd.Dir = appPath
+	output, err := combinedOutput(ctx, cmd)
+
+	result.Duration = time.Since(start)
+	result.Output = string(output)
+
+	if err != nil {
+		result.Status = "fail"
+		result.Error = fmt.Sprintf("Smoke test failed: %v", err)
+	} else {
+		result.Status = "pass"
+	}
+
+	return result
+}
+
+// testSecurityByLanguage runs security tests specific to the detected language.
+// Structured scanner output is parsed into findings; findings at or above the
+// configured fail severity fail the test.
+func (at *ApplicationTester) testSecurityByLanguage(ctx context.Context, appPath string, appReq *requirements.ApplicationRequirement, language string) TestResult {
 	result := TestResult{
 		Name: "Security Tests",
 		Type: "security",
 	}
 	start := time.Now()
 
-	var commands [][]string
+	var tools []securityTool
 	switch language {
 	case "javascript", "node", "nodejs":
 		if _, err := exec.LookPath("npm"); err == nil {
-			commands = append(commands, []string{"npm", "audit"})
+			tools = append(tools, securityTool{args: []string{"npm", "audit"}})
 		}
 	case "go", "golang":
 		if _, err := exec.LookPath("gosec"); err == nil {
-			commands = append(commands, []string{"gosec", "./..."})
+			tools = append(tools, securityTool{args: []string{"gosec", "-fmt=json", "./..."}, parse: parseGosecOutput})
+		}
+		if _, err := exec.LookPath("govulncheck"); err == nil {
+			tools = append(tools, securityTool{args: []string{"govulncheck", "-json", "./..."}, parse: parseGovulncheckOutput})
 		}
 	case "python":
 		if _, err := exec.LookPath("safety"); err == nil {
-			commands = append(commands, []string{"safety", "check"})
+			tools = append(tools, securityTool{args: []string{"safety", "check"}})
 		}
 		if _, err := exec.LookPath("bandit"); err == nil {
-			commands = append(commands, []string{"bandit", "-r", "."})
+			tools = append(tools, securityTool{args: []string{"bandit", "-r", "."}})
 		}
 	}
 
-	if len(commands) == 0 {
+	var outputs []string
+	var warnings []string
+	findings := []SecurityFinding{}
+
+	// The built-in scans need no tools, so they run even when none is installed
+	if language == "go" || language == "golang" {
+		sourceFindings, err := at.scanSource(appPath)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+		}
+		findings = append(findings, sourceFindings...)
+		outputs = append(outputs, fmt.Sprintf("%s: found %d potential issues", sourceScanTool, len(sourceFindings)))
+	}
+
+	if len(tools) == 0 && len(outputs) == 0 {
 		result.Status = "pass"
 		result.Output = fmt.Sprintf("No security scanning tools available for language: %s, marking as pass", language)
 		result.Duration = time.Since(start)
 		return result
 	}
 
-	var outputs []string
-	var errors []string
-	allPassed := true
-
-	for _, cmdArgs := range commands {
-		cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	for _, tool := range tools {
+		name := strings.Join(tool.args, " ")
+		var stdout, stderr bytes.Buffer
+		ctx, cancel := at.commandContext(ctx, language)
+		cmd := exec.CommandContext(ctx, tool.args[0], tool.args[1:]...)
 		cmd.Dir = appPath
-		output, err := cmd.CombinedOutput()
-		outputs = append(outputs, fmt.Sprintf("%s: %s", strings.Join(cmdArgs, " "), string(output)))
-		
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := runCommand(ctx, cmd)
+		cancel()
+		if message := timeoutError(ctx, cmd, at.commandTimeout(language)); message != "" {
+			warnings = append(warnings, message)
+			continue
+		}
+		outputs = append(outputs, fmt.Sprintf("%s: %s%s", name, stdout.String(), stderr.String()))
+
+		if tool.parse != nil {
+			// Scanners exit non-zero when they report issues, so parse regardless
+			parsed, parseErr := tool.parse(stdout.Bytes())
+			if parseErr == nil {
+				findings = append(findings, parsed...)
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("%s: %v", name, parseErr))
+		}
 		if err != nil {
 			// For security tools, some "errors" might be warnings, so we're more lenient
-			errors = append(errors, fmt.Sprintf("%s: %s", strings.Join(cmdArgs, " "), err.Error()))
+			warnings = append(warnings, fmt.Sprintf("%s: %s", name, err.Error()))
 		}
 	}
 
 	result.Duration = time.Since(start)
 	result.Output = strings.Join(outputs, "\n")
+	details := map[string]interface{}{
+		"findings": findings,
+	}
+	if len(warnings) > 0 {
+		details["warnings"] = warnings
+	}
+	result.Details = details
 
-	if allPassed {
-		result.Status = "pass"
+	if blocking := at.blockingFindings(findings); len(blocking) > 0 {
+		result.Status = "fail"
+		result.Error = fmt.Sprintf("%d security findings at or above %s severity", len(blocking), at.securityFailSeverity)
 	} else {
-		result.Status = "pass" // Mark as pass but include warnings in output
-		result.Details = map[string]interface{}{
-			"warnings": errors,
-		}
+		result.Status = "pass"
 	}
 
 	return result
 }
 
-// testPerformanceByLanguage runs performance tests specific to the detected language
+// testPerformanceByLanguage runs performance tests specific to the detected
+// language: the size of the project, without test artifacts, and of the
+// binary the API tests built, if any
 func (at *ApplicationTester) testPerformanceByLanguage(appPath string, appReq *requirements.ApplicationRequirement, language string) TestResult {
 	result := TestResult{
 		Name: "Performance Tests",
@@ -1112,10 +1515,20 @@ func (at *ApplicationTester) testPerformanceByLanguage(appPath string, appReq *r
 	var totalSize int64
 	var fileCount int
 
+	at.artifactsMutex.Lock()
+	artifacts := append([]string(nil), at.artifacts[appPath]...)
+	at.artifactsMutex.Unlock()
+
 	err := filepath.Walk(appPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if absPath, err := filepath.Abs(path); err == nil && containsPath(artifacts, absPath) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if !info.IsDir() {
 			totalSize += info.Size()
 			fileCount++
@@ -1131,11 +1544,19 @@ func (at *ApplicationTester) testPerformanceByLanguage(appPath string, appReq *r
 	} else {
 		result.Status = "pass"
 		result.Output = fmt.Sprintf("Project size: %d bytes, Files: %d", totalSize, fileCount)
-		result.Details = map[string]interface{}{
+		details := map[string]interface{}{
 			"total_size_bytes": totalSize,
 			"file_count": fileCount,
 			"language": language,
 		}
+		if binaryPath := at.builtBinary(appPath); binaryPath != "" {
+			if info, err := os.Stat(binaryPath); err == nil {
+				size := info.Size()
+				result.Output += fmt.Sprintf("\nBinary size: %d bytes (%.2f MB)", size, float64(size)/1024/1024)
+				details["binary_size_bytes"] = size
+			}
+		}
+		result.Details = details
 	}
 
 	return result
@@ -0,0 +1,1398 @@
+package apptesting
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/codegen"
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+func TestCoverageThreshold(t *testing.T) {
+	at := NewApplicationTester(t.TempDir())
+	at.SetCoverageThreshold(60)
+
+	below := &TestSuite{
+		Results: []TestResult{
+			{Name: "Build Test", Type: "build", Status: "pass"},
+			{Name: "Unit Tests", Type: "unit", Status: "pass", Coverage: 42.5},
+		},
+	}
+	at.summarizeSuite(below)
+	if below.OverallStatus != "failure" {
+		t.Errorf("expected failure for coverage below threshold, got %s", below.OverallStatus)
+	}
+	if !below.BelowThreshold {
+		t.Error("expected suite to be flagged as below threshold")
+	}
+
+	above := &TestSuite{
+		Results: []TestResult{
+			{Name: "Build Test", Type: "build", Status: "pass"},
+			{Name: "Unit Tests", Type: "unit", Status: "pass", Coverage: 75},
+		},
+	}
+	at.summarizeSuite(above)
+	if above.OverallStatus != "success" {
+		t.Errorf("expected success for coverage above threshold, got %s", above.OverallStatus)
+	}
+	if above.BelowThreshold {
+		t.Error("suite above threshold should not be flagged")
+	}
+}
+
+// smokeFixtureServer is a dependency-free stand-in for a generated Go API so
+// the generated smoke script can be exercised without fetching modules
+const smokeFixtureServer = `package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+func main() {
+	var mutex sync.Mutex
+	users := map[int]map[string]interface{}{}
+	nextID := 1
+
+	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{\"status\":\"ok\"}"))
+	})
+	http.HandleFunc("/api/users", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if r.Method == http.MethodPost {
+			var user map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&user)
+			user["id"] = nextID
+			users[nextID] = user
+			nextID++
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": user})
+			return
+		}
+		json.NewEncoder(w).Encode(users)
+	})
+	http.HandleFunc("/api/users/", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/users/"))
+		if _, ok := users[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(users, id)
+		}
+		json.NewEncoder(w).Encode(users[id])
+	})
+	http.ListenAndServe(":"+os.Getenv("PORT"), nil)
+}
+`
+
+func TestSmokeTestGate(t *testing.T) {
+	for _, tool := range []string{"go", "curl", "sh"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not available", tool)
+		}
+	}
+
+	outputDir := t.TempDir()
+	appReq := &requirements.ApplicationRequirement{
+		Name:     "Smoke App",
+		Type:     "api",
+		Language: "go",
+		Entities: []requirements.Entity{
+			{
+				Name: "User",
+				Fields: []requirements.EntityField{
+					{Name: "id", Type: "int", Required: true},
+					{Name: "username", Type: "string", Required: true},
+					{Name: "email", Type: "email", Required: true},
+				},
+			},
+		},
+		Config: map[string]interface{}{"port": 8080},
+	}
+	if _, err := codegen.NewCodeGenerator(outputDir).GenerateApplication(appReq); err != nil {
+		t.Fatalf("GenerateApplication failed: %v", err)
+	}
+	appPath := filepath.Join(outputDir, "smoke-app")
+
+	// Swap the generated server for the fixture, keeping the generated script
+	for _, name := range []string{"main.go", "go.mod", "internal"} {
+		if err := os.RemoveAll(filepath.Join(appPath, name)); err != nil {
+			t.Fatalf("failed to remove %s: %v", name, err)
+		}
+	}
+	files := map[string]string{
+		"go.mod":  "module smoke-app\n\ngo 1.18\n",
+		"main.go": smokeFixtureServer,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(appPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	t.Setenv("SMOKE_PORT", "18931")
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOWORK", "off")
+
+	at := NewApplicationTester(outputDir)
+	result := at.testSmoke(context.Background(), appPath)
+	if result.Status != "pass" {
+		t.Fatalf("expected smoke test to pass, got %s: %s\n%s", result.Status, result.Error, result.Output)
+	}
+	if result.Type != "smoke" {
+		t.Errorf("expected the smoke result to have type smoke, got %q", result.Type)
+	}
+
+	// Breaking an endpoint must fail the gate
+	broken := strings.Replace(smokeFixtureServer, "http.StatusCreated", "http.StatusOK", 1)
+	if err := os.WriteFile(filepath.Join(appPath, "main.go"), []byte(broken), 0644); err != nil {
+		t.Fatalf("failed to write main.go: %v", err)
+	}
+	if result := at.testSmoke(context.Background(), appPath); result.Status != "fail" {
+		t.Errorf("expected smoke test to fail against a broken server, got %s\n%s", result.Status, result.Output)
+	}
+}
+
+func TestDetectApplicationLanguage(t *testing.T) {
+	at := NewApplicationTester(t.TempDir())
+
+	jsApp := t.TempDir()
+	if err := os.WriteFile(filepath.Join(jsApp, "package.json"), []byte(`{"name": "js-app"}`), 0644); err != nil {
+		t.Fatalf("failed to write package.json: %v", err)
+	}
+	goApp := t.TempDir()
+	if err := os.WriteFile(filepath.Join(goApp, "go.mod"), []byte("module go-app\n"), 0644); err != nil {
+		t.Fatalf("failed to write go.mod: %v", err)
+	}
+	rustApp := t.TempDir()
+	if err := os.WriteFile(filepath.Join(rustApp, "Cargo.toml"), []byte("[package]\nname = \"rust-app\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write Cargo.toml: %v", err)
+	}
+	csharpApp := t.TempDir()
+	if err := os.WriteFile(filepath.Join(csharpApp, "Api.csproj"), []byte("<Project Sdk=\"Microsoft.NET.Sdk.Web\" />\n"), 0644); err != nil {
+		t.Fatalf("failed to write Api.csproj: %v", err)
+	}
+	emptyApp := t.TempDir()
+
+	tests := []struct {
+		name     string
+		appPath  string
+		language string
+		expected string
+	}{
+		{"js app with defaulted language", jsApp, "go", "javascript"},
+		{"js app with unset language", jsApp, "", "javascript"},
+		{"js app with explicit language", jsApp, "python", "python"},
+		{"go app with unset language", goApp, "", "go"},
+		{"go app with defaulted language", goApp, "go", "go"},
+		{"rust app with defaulted language", rustApp, "go", "rust"},
+		{"csharp app with unset language", csharpApp, "", "csharp"},
+		{"no indicators with explicit language", emptyApp, "ruby", "ruby"},
+		{"no indicators", emptyApp, "", "go"},
+	}
+
+	for _, tt := range tests {
+		appReq := &requirements.ApplicationRequirement{Language: tt.language}
+		if got := at.detectApplicationLanguage(tt.appPath, appReq); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+// writeFixtureApp writes the stdlib fixture server as a Go module in a temp dir
+func writeFixtureApp(t *testing.T) string {
+	t.Helper()
+	appPath := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module fixture-app\n\ngo 1.18\n",
+		"main.go": smokeFixtureServer,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(appPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOWORK", "off")
+	return appPath
+}
+
+func TestAPITestUsesInjectedPort(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	appPath := writeFixtureApp(t)
+
+	// Occupy the port the API test used to hardcode so a fixed port would fail
+	if listener, err := net.Listen("tcp", "127.0.0.1:8080"); err == nil {
+		defer listener.Close()
+	}
+
+	at := NewApplicationTester(t.TempDir())
+	result := at.testAPIByLanguage(context.Background(), appPath, &requirements.ApplicationRequirement{Type: "api"}, "go")
+	if result.Status != "pass" {
+		t.Fatalf("expected API test to pass on a free port, got %s: %s\n%s", result.Status, result.Error, result.Output)
+	}
+	details, ok := result.Details.(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected map details, got %T", result.Details)
+	}
+	if startup, ok := details[StartupTimeDetail].(float64); !ok || startup <= 0 {
+		t.Errorf("expected a measured startup time, got %v", details[StartupTimeDetail])
+	}
+}
+
+func TestCleanupRemovesArtifacts(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	appPath := writeFixtureApp(t)
+	// Like a generated SQLite app, the fixture creates its DATABASE_URL
+	server := strings.Replace(smokeFixtureServer, "func main() {\n", "func main() {\n\tos.WriteFile(os.Getenv(\"DATABASE_URL\"), nil, 0644)\n", 1)
+	if err := os.WriteFile(filepath.Join(appPath, "main.go"), []byte(server), 0644); err != nil {
+		t.Fatalf("failed to write main.go: %v", err)
+	}
+	appReq := &requirements.ApplicationRequirement{Name: "Fixture", Type: "api", Language: "go"}
+	artifacts := []string{BinaryName, testDatabaseName}
+
+	// run tests the app and returns its API and performance results
+	run := func(at *ApplicationTester) (api, performance TestResult) {
+		t.Helper()
+		suite, err := at.TestApplication(appPath, appReq)
+		if err != nil {
+			t.Fatalf("TestApplication failed: %v", err)
+		}
+		for _, result := range suite.Results {
+			switch result.Type {
+			case "api":
+				api = result
+			case "performance":
+				performance = result
+			}
+		}
+		if api.Status != "pass" {
+			t.Fatalf("expected the API test to pass, got %s: %s\n%s", api.Status, api.Error, api.Output)
+		}
+		return api, performance
+	}
+
+	at := NewApplicationTester(t.TempDir())
+	_, performance := run(at)
+	details := performance.Details.(map[string]interface{})
+	if size, ok := details["binary_size_bytes"].(int64); !ok || size == 0 {
+		t.Errorf("expected the performance test to measure the built binary, got %v", details)
+	}
+	for _, name := range artifacts {
+		if _, err := os.Stat(filepath.Join(appPath, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed after the run, got %v", name, err)
+		}
+	}
+	// Nothing but the artifacts is removed, and they were left out of the project size
+	entries, err := os.ReadDir(appPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files := details["file_count"]; files != len(entries) {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("expected the project size to count %v, got %v files", names, files)
+	}
+
+	at.SetKeepArtifacts(true)
+	run(at)
+	for _, name := range artifacts {
+		if _, err := os.Stat(filepath.Join(appPath, name)); err != nil {
+			t.Errorf("expected %s to be kept: %v", name, err)
+		}
+	}
+	if err := at.Cleanup(appPath); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	for _, name := range artifacts {
+		os.Remove(filepath.Join(appPath, name))
+	}
+
+	// A separate artifacts directory keeps the app directory clean
+	artifactsDir := t.TempDir()
+	at = NewApplicationTester(t.TempDir())
+	at.SetArtifactsDir(artifactsDir)
+	at.SetKeepArtifacts(true)
+	run(at)
+	for _, name := range artifacts {
+		if _, err := os.Stat(filepath.Join(artifactsDir, filepath.Base(appPath), name)); err != nil {
+			t.Errorf("expected %s in the artifacts directory: %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(appPath, name)); !os.IsNotExist(err) {
+			t.Errorf("expected no %s in the app directory, got %v", name, err)
+		}
+	}
+}
+
+func TestWaitForServer(t *testing.T) {
+	// Any HTTP response counts as ready, even an error status on both paths
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	if err := waitForServer(context.Background(), server.URL, time.Second); err != nil {
+		t.Errorf("expected server to be ready: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve port: %v", err)
+	}
+	closedURL := "http://" + listener.Addr().String()
+	listener.Close()
+	if err := waitForServer(context.Background(), closedURL, 300*time.Millisecond); err == nil {
+		t.Error("expected timeout for a server that never starts")
+	}
+}
+
+func TestEndpointResponseTime(t *testing.T) {
+	delay := 50 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	at := NewApplicationTester(t.TempDir())
+	result := at.testEndpoint("GET", server.URL, nil, nil)
+	ms, ok := result["response_time_ms"].(float64)
+	if !ok {
+		t.Fatalf("expected response_time_ms in %v", result)
+	}
+	if ms < float64(delay/time.Millisecond) {
+		t.Errorf("expected a response time of at least %v, got %.2fms", delay, ms)
+	}
+
+	details := map[string]interface{}{}
+	addResponseTimes(details, []map[string]interface{}{
+		{"endpoint": "/fast", "result": map[string]interface{}{"response_time_ms": 10.0}},
+		{"endpoint": "/slow", "result": map[string]interface{}{"response_time_ms": 30.0}},
+		{"endpoint": "/down", "result": map[string]interface{}{"success": false}},
+		{"endpoint": "/measured", "result": result},
+	})
+	if details[ResponseTimeMinDetail] != 10.0 || details[ResponseTimeMaxDetail] != ms {
+		t.Errorf("expected min 10 and max %.2f, got %v", ms, details)
+	}
+	if avg := details[ResponseTimeAvgDetail]; avg != (40+ms)/3 {
+		t.Errorf("expected the average over responding endpoints, got %v", avg)
+	}
+}
+
+func TestAPIPort(t *testing.T) {
+	at := NewApplicationTester(t.TempDir())
+
+	port, err := at.apiPort()
+	if err != nil {
+		t.Fatalf("apiPort failed: %v", err)
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		t.Fatalf("expected ephemeral port %d to be free: %v", port, err)
+	}
+	listener.Close()
+
+	at.SetAPITestPort(9123)
+	if port, _ := at.apiPort(); port != 9123 {
+		t.Errorf("expected configured port 9123, got %d", port)
+	}
+}
+
+func TestCheckBuild(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	at := NewApplicationTester(t.TempDir())
+	appReq := &requirements.ApplicationRequirement{Language: "go"}
+
+	appPath := writeFixtureApp(t)
+	if result := at.CheckBuild(appPath, appReq); result.Status != "pass" {
+		t.Fatalf("expected the fixture app to build, got %s: %s\n%s", result.Status, result.Error, result.Output)
+	}
+
+	// A broken package outside main must fail the check too
+	if err := os.MkdirAll(filepath.Join(appPath, "internal", "broken"), 0755); err != nil {
+		t.Fatalf("failed to create package dir: %v", err)
+	}
+	broken := "package broken\n\nfunc Broken() int {\n\treturn undefinedName\n}\n"
+	if err := os.WriteFile(filepath.Join(appPath, "internal", "broken", "broken.go"), []byte(broken), 0644); err != nil {
+		t.Fatalf("failed to write broken.go: %v", err)
+	}
+	result := at.CheckBuild(appPath, appReq)
+	if result.Status != "fail" || !strings.Contains(result.Output, "undefinedName") {
+		t.Errorf("expected a failed build mentioning undefinedName, got %s:\n%s", result.Status, result.Output)
+	}
+}
+
+func TestHasTestFiles(t *testing.T) {
+	at := NewApplicationTester(t.TempDir())
+
+	writeFile := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	withTests := t.TempDir()
+	writeFile(filepath.Join(withTests, "main.go"))
+	writeFile(filepath.Join(withTests, "internal", "models", "user.go"))
+	writeFile(filepath.Join(withTests, "internal", "handlers", "user.go"))
+	writeFile(filepath.Join(withTests, "internal", "handlers", "user_test.go"))
+
+	withoutTests := t.TempDir()
+	writeFile(filepath.Join(withoutTests, "main.go"))
+	writeFile(filepath.Join(withoutTests, "internal", "models", "user.go"))
+	// A directory named like a test file is not a test file
+	if err := os.MkdirAll(filepath.Join(withoutTests, "fixtures_test.go"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	if found, err := at.hasTestFiles(withTests); err != nil || !found {
+		t.Errorf("expected nested test file to be found, got %v, %v", found, err)
+	}
+	if found, err := at.hasTestFiles(withoutTests); err != nil || found {
+		t.Errorf("expected no test files, got %v, %v", found, err)
+	}
+	if found, err := at.hasTestFiles(filepath.Join(withoutTests, "missing")); err == nil || found {
+		t.Errorf("expected error for missing path, got %v, %v", found, err)
+	}
+
+	unreadable := filepath.Join(withoutTests, "internal", "locked")
+	writeFile(filepath.Join(unreadable, "locked.go"))
+	if err := os.Chmod(unreadable, 0); err != nil {
+		t.Fatalf("failed to chmod: %v", err)
+	}
+	defer os.Chmod(unreadable, 0755)
+	if _, err := os.ReadDir(unreadable); err == nil {
+		t.Skip("permissions are not enforced for this user")
+	}
+	if found, err := at.hasTestFiles(withoutTests); err == nil || found {
+		t.Errorf("expected unreadable directory error to be surfaced, got %v, %v", found, err)
+	}
+}
+
+// secretsFixture plants one fake secret of each kind, a line matching several
+// patterns, and values that must not be reported
+const secretsFixture = `package config
+
+const apiKey = "sk_test_1234567890abcdef"
+
+var settings = map[string]string{
+	"secret_key": 'single-quoted-secret',
+}
+
+func load() {
+	githubToken := ` + "`ghp_abcdefghijklmnop`" + `
+	password := "hunter2hunter2"
+	apiKeyToken := "overlapping_value_123"
+	shortToken := "abc"
+	envToken := os.Getenv("TOKEN")
+	_, _, _, _ = githubToken, password, apiKeyToken, shortToken
+	_ = envToken
+}
+`
+
+func TestScanForHardcodedSecrets(t *testing.T) {
+	appPath := t.TempDir()
+	path := filepath.Join(appPath, "config.go")
+	if err := os.WriteFile(path, []byte(secretsFixture), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+	// Non-Go files, tests and seed placeholders are not reported
+	others := map[string]string{
+		"notes.txt":      `api_key = "sk_test_1234567890abcdef"`,
+		"config_test.go": secretsFixture,
+		"seed.go":        "package config\n\nvar seed = map[string]string{\"password\": \"Sample User password 1\"}\n",
+	}
+	for name, content := range others {
+		if err := os.WriteFile(filepath.Join(appPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	at := NewApplicationTester(t.TempDir())
+	secrets, err := at.scanForHardcodedSecrets(appPath)
+	if err != nil {
+		t.Fatalf("scanForHardcodedSecrets failed: %v", err)
+	}
+
+	expected := []string{
+		fmt.Sprintf("Potential hardcoded API key in %s:3", path),
+		fmt.Sprintf("Potential hardcoded secret key in %s:6", path),
+		fmt.Sprintf("Potential hardcoded token in %s:10", path),
+		fmt.Sprintf("Potential hardcoded password in %s:11", path),
+		fmt.Sprintf("Potential hardcoded API key in %s:12", path),
+	}
+	if len(secrets) != len(expected) {
+		t.Fatalf("expected %d findings, got %d: %v", len(expected), len(secrets), secrets)
+	}
+	for i, want := range expected {
+		if got := fmt.Sprintf("%s in %s:%d", secrets[i].Message, secrets[i].File, secrets[i].Line); got != want {
+			t.Errorf("finding %d: expected %q, got %q", i, want, got)
+		}
+	}
+}
+
+func TestScanForSecurityIssues(t *testing.T) {
+	appPath := t.TempDir()
+	path := filepath.Join(appPath, "store.go")
+	source := "package store\n\n" +
+		"var config = map[string]string{\"password\": \"hunter2\"}\n" +
+		"var empty = struct{ Password string }{Password: \"\"}\n\n" +
+		"func remove(db DB, id string) { db.Exec(\"DELETE FROM users WHERE id = \" + id) }\n"
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	at := NewApplicationTester(t.TempDir())
+	findings, err := at.scanForSecurityIssues(appPath)
+	if err != nil {
+		t.Fatalf("scanForSecurityIssues failed: %v", err)
+	}
+	expected := []SecurityFinding{
+		{Tool: sourceScanTool, RuleID: "sql-injection", Severity: "MEDIUM", File: path, Message: "Potential SQL injection"},
+		{Tool: sourceScanTool, RuleID: "hardcoded-password", Severity: "HIGH", File: path, Line: 3, Message: "Potential hardcoded password"},
+	}
+	if fmt.Sprint(findings) != fmt.Sprint(expected) {
+		t.Errorf("unexpected findings:\n got %+v\nwant %+v", findings, expected)
+	}
+}
+
+func TestApplicationReportsHardcodedSecrets(t *testing.T) {
+	appPath := t.TempDir()
+	path := filepath.Join(appPath, "config.go")
+	if err := os.WriteFile(path, []byte(secretsFixture), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+	// No scanner on PATH, so the findings can only come from the source scan
+	t.Setenv("PATH", t.TempDir())
+
+	at := NewApplicationTester(t.TempDir())
+	at.SetSecurityFailSeverity("high")
+	suite, err := at.TestApplication(appPath, &requirements.ApplicationRequirement{Name: "Secrets", Type: "cli", Language: "go"})
+	if err != nil {
+		t.Fatalf("TestApplication failed: %v", err)
+	}
+
+	var security *TestResult
+	for i := range suite.Results {
+		if suite.Results[i].Type == "security" {
+			security = &suite.Results[i]
+		}
+	}
+	if security == nil {
+		t.Fatalf("expected a security result, got %+v", suite.Results)
+	}
+	if security.Status != "fail" {
+		t.Errorf("expected the hardcoded secrets to fail the security test, got %s: %s", security.Status, security.Output)
+	}
+	findings := security.Details.(map[string]interface{})["findings"].([]SecurityFinding)
+	lines := make(map[int]string)
+	for _, finding := range findings {
+		if finding.File == path {
+			lines[finding.Line] = finding.RuleID
+		}
+	}
+	if lines[3] != "hardcoded-api-key" || lines[11] != "hardcoded-password" || len(lines) != 5 {
+		t.Errorf("expected the five planted secrets to be reported once each, got %+v", findings)
+	}
+}
+
+func TestGeneratedApplicationPassesSecurityScan(t *testing.T) {
+	appReq, err := requirements.NewRequirementAnalyzer(nil).AnalyzeRequirements("user management api with login")
+	if err != nil {
+		t.Fatalf("AnalyzeRequirements failed: %v", err)
+	}
+	appPath, err := codegen.NewCodeGenerator(t.TempDir()).GenerateApplication(appReq)
+	if err != nil {
+		t.Fatalf("GenerateApplication failed: %v", err)
+	}
+	// No scanner on PATH, so only the source scan runs
+	t.Setenv("PATH", t.TempDir())
+
+	at := NewApplicationTester(t.TempDir())
+	at.SetSecurityFailSeverity("high")
+	result := at.testSecurityByLanguage(context.Background(), appPath, appReq, "go")
+	if result.Status != "pass" {
+		t.Errorf("expected a freshly generated app to pass the security test, got %s: %+v", result.Status, result.Details)
+	}
+}
+
+const gosecReport = `{
+	"Issues": [
+		{"severity": "HIGH", "confidence": "HIGH", "rule_id": "G101", "details": "Potential hardcoded credentials", "file": "/app/config.go", "line": "12"},
+		{"severity": "MEDIUM", "confidence": "HIGH", "rule_id": "G304", "details": "Potential file inclusion via variable", "file": "/app/main.go", "line": "40-42"}
+	],
+	"Stats": {"files": 2, "lines": 80, "found": 2}
+}`
+
+const govulncheckReport = `{"config": {"protocol_version": "v1.0.0", "scanner_name": "govulncheck"}}
+{"progress": {"message": "Scanning your code..."}}
+{"osv": {"id": "GO-2023-0001", "summary": "Path traversal in example.com/lib"}}
+{"osv": {"id": "GO-2023-0002", "summary": "Denial of service in example.com/other"}}
+{"finding": {"osv": "GO-2023-0001", "fixed_version": "v1.2.0", "trace": [{"module": "example.com/lib", "version": "v1.0.0"}]}}
+{"finding": {"osv": "GO-2023-0001", "fixed_version": "v1.2.0", "trace": [
+	{"module": "example.com/lib", "version": "v1.0.0", "package": "example.com/lib", "function": "Open"},
+	{"module": "app", "package": "app", "function": "main", "position": {"filename": "main.go", "line": 17}}
+]}}
+{"finding": {"osv": "GO-2023-0002", "fixed_version": "v0.3.0", "trace": [{"module": "example.com/other", "version": "v0.1.0", "package": "example.com/other"}]}}
+`
+
+func TestParseSecurityOutput(t *testing.T) {
+	gosec, err := parseGosecOutput([]byte(gosecReport))
+	if err != nil {
+		t.Fatalf("parseGosecOutput failed: %v", err)
+	}
+	expected := []SecurityFinding{
+		{Tool: "gosec", RuleID: "G101", Severity: "HIGH", File: "/app/config.go", Line: 12, Message: "Potential hardcoded credentials"},
+		{Tool: "gosec", RuleID: "G304", Severity: "MEDIUM", File: "/app/main.go", Line: 40, Message: "Potential file inclusion via variable"},
+	}
+	if fmt.Sprint(gosec) != fmt.Sprint(expected) {
+		t.Errorf("unexpected gosec findings:\n got %+v\nwant %+v", gosec, expected)
+	}
+
+	vulns, err := parseGovulncheckOutput([]byte(govulncheckReport))
+	if err != nil {
+		t.Fatalf("parseGovulncheckOutput failed: %v", err)
+	}
+	expected = []SecurityFinding{
+		{Tool: "govulncheck", RuleID: "GO-2023-0001", Severity: "HIGH", File: "main.go", Line: 17, Message: "Path traversal in example.com/lib"},
+		{Tool: "govulncheck", RuleID: "GO-2023-0002", Severity: "LOW", Message: "Denial of service in example.com/other"},
+	}
+	if fmt.Sprint(vulns) != fmt.Sprint(expected) {
+		t.Errorf("unexpected govulncheck findings:\n got %+v\nwant %+v", vulns, expected)
+	}
+
+	if _, err := parseGosecOutput([]byte("not json")); err == nil {
+		t.Error("expected error for malformed gosec output")
+	}
+}
+
+func TestSecurityFailSeverity(t *testing.T) {
+	// A fake gosec on PATH reports findings and exits non-zero like the real tool
+	binDir := t.TempDir()
+	script := "#!/bin/sh\ncat <<'EOF'\n" + gosecReport + "\nEOF\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(binDir, "gosec"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake gosec: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if path, err := exec.LookPath("govulncheck"); err == nil && !strings.HasPrefix(path, binDir) {
+		t.Skip("a real govulncheck is installed")
+	}
+
+	tests := []struct {
+		severity string
+		status   string
+	}{
+		{"", "pass"},
+		{"none", "pass"},
+		{"high", "fail"},
+		{"medium", "fail"},
+	}
+	for _, tt := range tests {
+		at := NewApplicationTester(t.TempDir())
+		at.SetSecurityFailSeverity(tt.severity)
+		result := at.testSecurityByLanguage(context.Background(), t.TempDir(), &requirements.ApplicationRequirement{}, "go")
+		if result.Status != tt.status {
+			t.Errorf("severity %q: expected %s, got %s (%s)", tt.severity, tt.status, result.Status, result.Error)
+		}
+		findings := result.Details.(map[string]interface{})["findings"].([]SecurityFinding)
+		if len(findings) != 2 {
+			t.Errorf("severity %q: expected 2 findings, got %d", tt.severity, len(findings))
+		}
+	}
+}
+
+func TestGenerateTestDataSkipsAutoManagedFields(t *testing.T) {
+	at := NewApplicationTester(t.TempDir())
+	data := at.generateTestData(requirements.Entity{
+		Name: "Post",
+		Fields: []requirements.EntityField{
+			{Name: "id", Type: "int"},
+			{Name: "title", Type: "string"},
+			{Name: "created_at", Type: "date"},
+			{Name: "updated_at", Type: "date"},
+			{Name: "published_at", Type: "date", AutoManaged: true},
+		},
+	})
+	if len(data) != 1 || data["title"] != "test_title" {
+		t.Errorf("expected only title in the test data, got %v", data)
+	}
+}
+
+func TestCommandTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+
+	// A fake phpunit starts a child holding the output pipe, so the run only
+	// returns early when the whole process group is killed
+	binDir := t.TempDir()
+	script := "#!/bin/sh\necho started\nsleep 30 &\nwait\n"
+	if err := os.WriteFile(filepath.Join(binDir, "phpunit"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake phpunit: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	at := NewApplicationTester(t.TempDir())
+	at.SetCommandTimeouts(map[string]time.Duration{"php": 200 * time.Millisecond})
+	start := time.Now()
+	result := at.testUnitByLanguage(context.Background(), t.TempDir(), &requirements.ApplicationRequirement{}, "php")
+	elapsed := time.Since(start)
+
+	if result.Status != "fail" {
+		t.Errorf("expected fail, got %s", result.Status)
+	}
+	if !strings.Contains(result.Error, "timed out after 200ms") {
+		t.Errorf("expected a timeout error, got %q", result.Error)
+	}
+	if !strings.Contains(result.Output, "started") {
+		t.Errorf("expected the output before the timeout, got %q", result.Output)
+	}
+	if elapsed > 10*time.Second {
+		t.Errorf("command was not killed, run took %v", elapsed)
+	}
+}
+
+func TestCommandTimeoutDefaults(t *testing.T) {
+	at := NewApplicationTester(t.TempDir())
+	at.SetCommandTimeouts(map[string]time.Duration{"nodejs": time.Minute})
+
+	tests := []struct {
+		language string
+		timeout  time.Duration
+	}{
+		{"javascript", time.Minute},
+		{"node", time.Minute},
+		{"golang", 5 * time.Minute},
+		{"rust", 15 * time.Minute},
+		{"cobol", defaultCommandTimeout},
+	}
+	for _, tt := range tests {
+		if got := at.commandTimeout(tt.language); got != tt.timeout {
+			t.Errorf("%s: expected %v, got %v", tt.language, tt.timeout, got)
+		}
+	}
+}
+
+func TestSaveTestResultsJUnit(t *testing.T) {
+	suite := &TestSuite{
+		Name:         "library",
+		StartTime:    time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Duration:     2500 * time.Millisecond,
+		TotalTests:   3,
+		PassedTests:  1,
+		FailedTests:  1,
+		SkippedTests: 1,
+		Results: []TestResult{
+			{Name: "Build Test", Type: "build", Status: "pass", Duration: 1500 * time.Millisecond, Output: "ok"},
+			{Name: "Unit Tests", Type: "unit", Status: "fail", Duration: 750 * time.Millisecond,
+				Output: "expected <nil> & got \"err\"\x1b[31m", Error: "exit status 1"},
+			{Name: "Security Tests", Type: "security", Status: "skip", Error: "gosec not installed"},
+		},
+	}
+
+	tester := NewApplicationTester(t.TempDir())
+	path := filepath.Join(t.TempDir(), "results.xml")
+	if err := tester.SaveResults(suite, path, FormatJUnit); err != nil {
+		t.Fatalf("SaveResults failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report junitTestSuite
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, data)
+	}
+
+	if report.Name != "library" || report.Tests != 3 || report.Failures != 1 || report.Skipped != 1 || report.Time != "2.500" {
+		t.Errorf("unexpected suite attributes: %+v", report)
+	}
+	if len(report.TestCases) != 3 {
+		t.Fatalf("expected 3 test cases, got %d", len(report.TestCases))
+	}
+
+	pass, fail, skip := report.TestCases[0], report.TestCases[1], report.TestCases[2]
+	if pass.Time != "1.500" || pass.Failure != nil || pass.Skipped != nil || pass.SystemOut != "ok" {
+		t.Errorf("unexpected passing case: %+v", pass)
+	}
+	if fail.Failure == nil || fail.Failure.Message != "exit status 1" || !strings.HasPrefix(fail.Failure.Text, "expected <nil> & got \"err\"") {
+		t.Errorf("unexpected failing case: %+v", fail.Failure)
+	}
+	if fail.Time != "0.750" || fail.Classname != "library.unit" {
+		t.Errorf("unexpected failing case attributes: %+v", fail)
+	}
+	if skip.Skipped == nil || skip.Skipped.Message != "gosec not installed" {
+		t.Errorf("unexpected skipped case: %+v", skip)
+	}
+
+	if err := tester.SaveResults(suite, path, Format("yaml")); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+// sleepPhase is a test phase that takes d to produce a passing result, or
+// fails as soon as its context is cancelled
+func sleepPhase(name string, group int, d time.Duration) testPhase {
+	return testPhase{name, name, group, func(ctx context.Context) TestResult {
+		select {
+		case <-time.After(d):
+			return TestResult{Name: name, Type: name, Status: "pass", Duration: d}
+		case <-ctx.Done():
+			return TestResult{Name: name, Type: name, Status: "fail", Error: ctx.Err().Error()}
+		}
+	}}
+}
+
+func TestRunPhasesParallel(t *testing.T) {
+	phases := []testPhase{
+		sleepPhase("build", chainGroup, 100*time.Millisecond),
+		sleepPhase("static", 1, 200*time.Millisecond),
+		sleepPhase("unit", chainGroup, 100*time.Millisecond),
+		sleepPhase("security", 2, 200*time.Millisecond),
+		sleepPhase("performance", 3, 200*time.Millisecond),
+	}
+
+	for _, parallel := range []bool{true, false} {
+		at := NewApplicationTester(t.TempDir())
+		at.SetParallel(parallel)
+
+		start := time.Now()
+		results := at.runPhases(phases, nil)
+		elapsed := time.Since(start)
+
+		var names []string
+		for _, result := range results {
+			names = append(names, result.Name)
+		}
+		if strings.Join(names, ",") != "build,static,unit,security,performance" {
+			t.Errorf("parallel=%v: expected declaration order, got %v", parallel, names)
+		}
+
+		// Sequential phases take 800ms; in parallel the longest group takes 200ms
+		if parallel && elapsed >= 500*time.Millisecond {
+			t.Errorf("parallel phases took %v", elapsed)
+		}
+		if !parallel && elapsed < 800*time.Millisecond {
+			t.Errorf("sequential phases took only %v", elapsed)
+		}
+	}
+}
+
+func TestRunPhasesTimeout(t *testing.T) {
+	at := NewApplicationTester(t.TempDir())
+	at.SetTimeout(200 * time.Millisecond)
+
+	// running counts the phases that have not returned yet
+	var running int32
+	counted := func(phase testPhase) testPhase {
+		run := phase.run
+		atomic.AddInt32(&running, 1)
+		phase.run = func(ctx context.Context) TestResult {
+			defer atomic.AddInt32(&running, -1)
+			return run(ctx)
+		}
+		return phase
+	}
+
+	var reported []string
+	start := time.Now()
+	results := at.runPhases([]testPhase{
+		counted(sleepPhase("build", chainGroup, 10*time.Millisecond)),
+		counted(sleepPhase("security", 1, 2*time.Second)),
+		counted(sleepPhase("unit", chainGroup, 2*time.Second)),
+	}, func(kind string, result TestResult) {
+		reported = append(reported, kind+":"+result.Status)
+	})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("runPhases ignored the timeout, took %v", elapsed)
+	}
+	if n := atomic.LoadInt32(&running); n != 0 {
+		t.Errorf("expected runPhases to wait for the cancelled phases, %d still running", n)
+	}
+	if got := strings.Join(reported, ","); got != "build:pass,security:fail,unit:fail" {
+		t.Errorf("expected every phase to be reported once, got %s", got)
+	}
+
+	if results[0].Status != "pass" {
+		t.Errorf("expected the finished phase to keep its result, got %+v", results[0])
+	}
+	for _, result := range results[1:] {
+		if result.Status != "fail" || result.Error != "did not finish within 200ms" {
+			t.Errorf("expected %s to time out, got %+v", result.Name, result)
+		}
+	}
+}
+
+func TestApplicationTimeoutStopsCommands(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+
+	// A fake phpunit outlives the run unless the timeout kills it; it would
+	// then write a marker into the application
+	appPath := t.TempDir()
+	marker := filepath.Join(appPath, "still-running")
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nsleep 2\ntouch " + marker + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, "phpunit"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake phpunit: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	at := NewApplicationTester(t.TempDir())
+	at.SetTimeout(300 * time.Millisecond)
+	start := time.Now()
+	suite, err := at.TestApplication(appPath, &requirements.ApplicationRequirement{Name: "Slow", Type: "cli", Language: "php"})
+	if err != nil {
+		t.Fatalf("TestApplication failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 1500*time.Millisecond {
+		t.Errorf("TestApplication ignored the timeout, took %v", elapsed)
+	}
+	for _, result := range suite.Results {
+		if result.Type == "unit" && result.Error != "did not finish within 300ms" {
+			t.Errorf("expected the unit tests to time out, got %+v", result)
+		}
+	}
+
+	time.Sleep(2500 * time.Millisecond)
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Errorf("expected the unit test command to be killed when the run timed out, got %v", err)
+	}
+}
+
+func TestSmokeWithoutTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	appPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(appPath, "scripts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(appPath, "scripts", "smoke_test.sh"), []byte("sleep 0.1\necho ok\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A zero timeout waits for the script instead of expiring at once
+	at := NewApplicationTester(t.TempDir())
+	at.SetTimeout(0)
+	if result := at.testSmoke(context.Background(), appPath); result.Status != "pass" {
+		t.Errorf("expected the smoke test to pass without a timeout, got %s: %s\n%s", result.Status, result.Error, result.Output)
+	}
+}
+
+// BenchmarkTestApplication compares sequential and parallel phases on a
+// sample API; run with go test -bench TestApplication ./internal/apptesting
+func BenchmarkTestApplication(b *testing.B) {
+	if _, err := exec.LookPath("go"); err != nil {
+		b.Skip("go not available")
+	}
+	appPath := b.TempDir()
+	files := map[string]string{
+		"go.mod":  "module sample-app\n\ngo 1.18\n",
+		"main.go": smokeFixtureServer,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(appPath, name), []byte(content), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	appReq := &requirements.ApplicationRequirement{Name: "sample-app", Type: "api", Language: "go"}
+
+	for _, parallel := range []bool{false, true} {
+		b.Run(fmt.Sprintf("parallel=%v", parallel), func(b *testing.B) {
+			at := NewApplicationTester(b.TempDir())
+			at.SetParallel(parallel)
+			for i := 0; i < b.N; i++ {
+				if _, err := at.TestApplication(appPath, appReq); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// writeCoverageFixture writes a module whose test covers one of its two
+// single-statement functions, for 50% coverage
+func writeCoverageFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":       "module fixture\n\ngo 1.21\n",
+		"calc.go":      "package fixture\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n",
+		"calc_test.go": "package fixture\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fatal(\"wrong sum\")\n\t}\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestUnitCoverageProfile(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not installed")
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		status    string
+	}{
+		{"no threshold", 0, "pass"},
+		{"met", 50, "pass"},
+		{"missed", 60, "fail"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appPath := writeCoverageFixture(t)
+			at := NewApplicationTester(t.TempDir())
+			at.SetCoverageThreshold(tt.threshold)
+
+			result := at.testUnitByLanguage(context.Background(), appPath, &requirements.ApplicationRequirement{}, "go")
+			if result.Status != tt.status {
+				t.Fatalf("expected %s, got %s: %s\n%s", tt.status, result.Status, result.Error, result.Output)
+			}
+			if result.Coverage != 50 {
+				t.Errorf("expected 50%% coverage, got %.2f", result.Coverage)
+			}
+			details, _ := result.Details.(map[string]interface{})
+			profile, _ := details[CoverageProfileDetail].(string)
+			if profile != filepath.Join(appPath, CoverageProfileFile) {
+				t.Errorf("expected the profile path in the details, got %v", result.Details)
+			}
+			if _, err := os.Stat(profile); err != nil {
+				t.Errorf("coverage profile missing: %v", err)
+			}
+
+			suite := &TestSuite{Results: []TestResult{result}}
+			at.summarizeSuite(suite)
+			if tt.status == "fail" {
+				if !strings.Contains(result.Error, "coverage 50.0% is below the minimum of 60.0%") {
+					t.Errorf("unexpected error %q", result.Error)
+				}
+				if !suite.BelowThreshold || suite.OverallStatus != "failure" {
+					t.Errorf("expected the suite to fail below the threshold, got %s (below=%v)", suite.OverallStatus, suite.BelowThreshold)
+				}
+			} else if suite.BelowThreshold {
+				t.Error("suite meeting the threshold should not be flagged")
+			}
+		})
+	}
+}
+
+func TestCoverageProfileRemovedAfterRun(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	appPath := writeCoverageFixture(t)
+	appReq := &requirements.ApplicationRequirement{Name: "Fixture", Type: "cli", Language: "go"}
+
+	at := NewApplicationTester(t.TempDir())
+	suite, err := at.TestApplication(appPath, appReq)
+	if err != nil {
+		t.Fatalf("TestApplication failed: %v", err)
+	}
+	for _, result := range suite.Results {
+		if result.Type == "unit" && result.Coverage != 50 {
+			t.Errorf("expected the unit tests to read 50%% coverage from the profile, got %+v", result)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(appPath, CoverageProfileFile)); !os.IsNotExist(err) {
+		t.Errorf("expected the coverage profile to be removed after the run, got %v", err)
+	}
+
+	// Kept artifacts include the profile
+	at.SetKeepArtifacts(true)
+	if _, err := at.TestApplication(appPath, appReq); err != nil {
+		t.Fatalf("TestApplication failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(appPath, CoverageProfileFile)); err != nil {
+		t.Errorf("expected the coverage profile to be kept: %v", err)
+	}
+}
+
+func TestParseCoverProfile(t *testing.T) {
+	// The second package's run covers the block the first one missed
+	profile := "mode: set\n" +
+		"a/x.go:3.20,5.2 2 0\n" +
+		"a/x.go:7.20,9.2 1 1\n" +
+		"a/x.go:3.20,5.2 2 1\n" +
+		"a/y.go:1.1,2.2 1 0\n"
+	path := filepath.Join(t.TempDir(), CoverageProfileFile)
+	if err := os.WriteFile(path, []byte(profile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	coverage, err := parseCoverProfile(path)
+	if err != nil {
+		t.Fatalf("parseCoverProfile failed: %v", err)
+	}
+	if coverage != 75 {
+		t.Errorf("expected 75%% coverage, got %.2f", coverage)
+	}
+
+	if err := os.WriteFile(path, []byte("mode: set\nbroken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseCoverProfile(path); err == nil {
+		t.Error("expected an error for a malformed profile")
+	}
+}
+
+func TestAPITestStopsServerOnFailure(t *testing.T) {
+	if _, err := exec.LookPath("npm"); err != nil {
+		t.Skip("npm not available")
+	}
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("/proc not available")
+	}
+
+	// npm starts node in a child process; the server answers every request
+	// with a 500 so the API test fails after it started
+	appPath := t.TempDir()
+	pidFile := filepath.Join(appPath, "server.pid")
+	files := map[string]string{
+		"package.json": `{"name": "failing-app", "scripts": {"start": "node app.js"}}`,
+		"app.js": `require("fs").writeFileSync("server.pid", String(process.pid));
+require("http").createServer((req, res) => { res.statusCode = 500; res.end(); }).listen(process.env.PORT);
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(appPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	at := NewApplicationTester(t.TempDir())
+	result := at.testAPIByLanguage(context.Background(), appPath, &requirements.ApplicationRequirement{Type: "api"}, "javascript")
+	if result.Status != "fail" {
+		t.Fatalf("expected the API test to fail, got %s: %s", result.Status, result.Output)
+	}
+
+	pid, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("server did not start: %v", err)
+	}
+	// The node process is killed with npm; once reparented it may linger
+	// briefly as a zombie until it is reaped
+	deadline := time.Now().Add(2 * time.Second)
+	for processRunning(string(pid)) {
+		if time.Now().After(deadline) {
+			t.Fatalf("server process %s is still running after the API test returned", pid)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// processRunning reports whether the process with the given PID exists and
+// is not a zombie
+func processRunning(pid string) bool {
+	stat, err := os.ReadFile(filepath.Join("/proc", pid, "stat"))
+	if err != nil {
+		return false
+	}
+	// The state follows the parenthesized command name
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+// authFixtureServer guards /api like the generated auth middleware: with the
+// X-API-Key from API_KEY, a bearer token signed with JWT_SECRET, or, with
+// neither set, as an OAuth2 app whose tokens the tests cannot obtain
+const authFixtureServer = `package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"strings"
+)
+
+func authorized(r *http.Request) bool {
+	if key := os.Getenv("API_KEY"); key != "" {
+		return r.Header.Get("X-API-Key") == key
+	}
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return false
+	}
+	parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+	if len(parts) != 3 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	return parts[2] == base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func main() {
+	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{\"status\":\"ok\"}"))
+	})
+	http.HandleFunc("/api/users", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write([]byte("[]"))
+	})
+	http.ListenAndServe(":"+os.Getenv("PORT"), nil)
+}
+`
+
+func TestAPITestAuthenticatesProbes(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	appPath := writeFixtureApp(t)
+	if err := os.WriteFile(filepath.Join(appPath, "main.go"), []byte(authFixtureServer), 0644); err != nil {
+		t.Fatalf("failed to write main.go: %v", err)
+	}
+
+	for _, strategy := range []string{"jwt", "apikey", "oauth2"} {
+		t.Run(strategy, func(t *testing.T) {
+			appReq := &requirements.ApplicationRequirement{
+				Type:         "api",
+				Language:     "go",
+				AuthStrategy: strategy,
+				Entities: []requirements.Entity{{
+					Name:   "User",
+					Fields: []requirements.EntityField{{Name: "username", Type: "string"}},
+				}},
+				Endpoints: []requirements.APIEndpoint{
+					{Method: "GET", Path: "/api/users"},
+					{Method: "POST", Path: "/api/users"},
+				},
+			}
+			at := NewApplicationTester(t.TempDir())
+			result := at.testAPIByLanguage(context.Background(), appPath, appReq, "go")
+			if result.Status != "pass" {
+				t.Fatalf("expected the API test to pass, got %s: %s\n%s", result.Status, result.Error, result.Output)
+			}
+			want := "GET /api/users: 200"
+			if strategy == "oauth2" {
+				want = "GET /api/users: 401"
+			}
+			if !strings.Contains(result.Output, want) {
+				t.Errorf("expected %q in the output:\n%s", want, result.Output)
+			}
+		})
+	}
+
+	// Without credentials a protected endpoint still fails the phase
+	appReq := &requirements.ApplicationRequirement{
+		Type:      "api",
+		Language:  "go",
+		Endpoints: []requirements.APIEndpoint{{Method: "GET", Path: "/api/users"}},
+	}
+	result := NewApplicationTester(t.TempDir()).testAPIByLanguage(context.Background(), appPath, appReq, "go")
+	if result.Status != "fail" || !strings.Contains(result.Error, "GET /api/users: status 401") {
+		t.Errorf("expected the unauthorized endpoint to fail the test, got %s: %s", result.Status, result.Error)
+	}
+}
+
+func TestAPITestByLanguageProbesEndpoints(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not available")
+	}
+
+	appPath := t.TempDir()
+	app := `require("http").createServer((req, res) => {
+  if (req.url === "/api/users" && req.method === "GET") {
+    res.setHeader("Content-Type", "application/json");
+    res.end(JSON.stringify([{ id: 1, username: "alice" }]));
+    return;
+  }
+  if (req.url === "/api/users" && req.method === "POST") {
+    let body = "";
+    req.on("data", (chunk) => (body += chunk));
+    req.on("end", () => { res.statusCode = 201; res.end(body); });
+    return;
+  }
+  res.statusCode = req.url === "/health" ? 200 : 404;
+  res.end();
+}).listen(process.env.PORT);
+`
+	if err := os.WriteFile(filepath.Join(appPath, "app.js"), []byte(app), 0644); err != nil {
+		t.Fatalf("failed to write app.js: %v", err)
+	}
+
+	appReq := &requirements.ApplicationRequirement{
+		Type: "api",
+		Entities: []requirements.Entity{{
+			Name:   "User",
+			Fields: []requirements.EntityField{{Name: "username", Type: "string"}},
+		}},
+		Endpoints: []requirements.APIEndpoint{
+			{Method: "GET", Path: "/api/users"},
+			{Method: "POST", Path: "/api/users"},
+		},
+	}
+	at := NewApplicationTester(t.TempDir())
+	result := at.testAPIByLanguage(context.Background(), appPath, appReq, "javascript")
+	if result.Status != "pass" {
+		t.Fatalf("expected the API test to pass, got %s: %s\n%s", result.Status, result.Error, result.Output)
+	}
+	if !strings.Contains(result.Output, "GET /api/users: 200") || !strings.Contains(result.Output, "POST /api/users: 201") {
+		t.Errorf("expected the output to list the probed endpoints:\n%s", result.Output)
+	}
+
+	details := result.Details.(map[string]interface{})
+	endpoints, ok := details["endpoints"].([]map[string]interface{})
+	if !ok || len(endpoints) != 2 {
+		t.Fatalf("expected two endpoint results, got %v", details["endpoints"])
+	}
+	list := endpoints[0]["result"].(map[string]interface{})
+	if list["status_code"] != 200 || !strings.Contains(list["response"].(string), "alice") {
+		t.Errorf("expected the GET response to be captured, got %v", list)
+	}
+	created := endpoints[1]["result"].(map[string]interface{})
+	if !strings.Contains(created["response"].(string), `"username"`) {
+		t.Errorf("expected POST to send a generated body, got %v", created)
+	}
+	if _, ok := details[ResponseTimeAvgDetail]; !ok {
+		t.Errorf("expected aggregated response times in %v", details)
+	}
+
+	// An endpoint the app does not serve fails the phase
+	appReq.Endpoints = append(appReq.Endpoints, requirements.APIEndpoint{Method: "GET", Path: "/api/users/{id}"})
+	result = at.testAPIByLanguage(context.Background(), appPath, appReq, "javascript")
+	if result.Status != "fail" || !strings.Contains(result.Error, "GET /api/users/{id}: status 404") {
+		t.Errorf("expected the missing endpoint to fail the test, got %s: %s", result.Status, result.Error)
+	}
+}
@@ -0,0 +1,78 @@
+// Package buildsys recognizes a project's build system from its marker files
+// and chooses the commands that build and test it.
+package buildsys
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// marker is a build system recognized by a file in the project root
+type marker struct {
+	patterns []string // glob patterns, any of which marks the project
+	build    []string
+	test     []string // nil when the build system has no standard test command
+}
+
+// markers are checked in order; the first one present wins
+var markers = []marker{
+	{patterns: []string{"go.mod"}, build: []string{"go", "build", "./..."}, test: []string{"go", "test", "./..."}},
+	{patterns: []string{"Cargo.toml"}, build: []string{"cargo", "build"}, test: []string{"cargo", "test"}},
+	{patterns: []string{"*.sln", "*.csproj"}, build: []string{"dotnet", "build"}, test: []string{"dotnet", "test"}},
+	{patterns: []string{"package.json"}, build: []string{"npm", "install"}, test: []string{"npm", "test"}},
+	{patterns: []string{"Makefile"}, build: []string{"make"}},
+}
+
+// DetectBuildCommand returns the command building the project in repoPath,
+// or an empty cmd when no known build system is found
+func DetectBuildCommand(repoPath string) (cmd string, args []string) {
+	m, ok := detect(repoPath)
+	if !ok {
+		return "", nil
+	}
+	return m.build[0], append([]string{}, m.build[1:]...)
+}
+
+// DetectTestCommand returns the command running the tests of the project in
+// repoPath, or an empty cmd when its build system has no test command
+func DetectTestCommand(repoPath string) (cmd string, args []string) {
+	m, ok := detect(repoPath)
+	if !ok || m.test == nil {
+		return "", nil
+	}
+	return m.test[0], append([]string{}, m.test[1:]...)
+}
+
+// matches reports whether one of the files in the project root matches one
+// of the patterns
+func (m marker) matches(files []string) bool {
+	for _, pattern := range m.patterns {
+		for _, file := range files {
+			if ok, _ := filepath.Match(pattern, file); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// detect returns the marker of the project in repoPath
+func detect(repoPath string) (marker, bool) {
+	entries, err := os.ReadDir(repoPath)
+	if err != nil {
+		return marker{}, false
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, entry.Name())
+		}
+	}
+
+	for _, m := range markers {
+		if m.matches(files) {
+			return m, true
+		}
+	}
+	return marker{}, false
+}
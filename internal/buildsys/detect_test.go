@@ -0,0 +1,72 @@
+package buildsys
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fixture creates a project directory containing the given files
+func fixture(t *testing.T, files ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte{}, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+	return dir
+}
+
+// commandLine joins a detected command and its arguments, returning nil when
+// nothing was detected
+func commandLine(cmd string, args []string) []string {
+	if cmd == "" {
+		return nil
+	}
+	return append([]string{cmd}, args...)
+}
+
+func TestDetectCommands(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		build []string
+		test  []string
+	}{
+		{"go", []string{"go.mod"}, []string{"go", "build", "./..."}, []string{"go", "test", "./..."}},
+		{"rust", []string{"Cargo.toml"}, []string{"cargo", "build"}, []string{"cargo", "test"}},
+		{"csharp project", []string{"Api.csproj"}, []string{"dotnet", "build"}, []string{"dotnet", "test"}},
+		{"csharp solution", []string{"Shop.sln"}, []string{"dotnet", "build"}, []string{"dotnet", "test"}},
+		{"npm", []string{"package.json"}, []string{"npm", "install"}, []string{"npm", "test"}},
+		{"make", []string{"Makefile"}, []string{"make"}, nil},
+		{"go before make", []string{"Makefile", "go.mod"}, []string{"go", "build", "./..."}, []string{"go", "test", "./..."}},
+		{"rust before npm", []string{"package.json", "Cargo.toml"}, []string{"cargo", "build"}, []string{"cargo", "test"}},
+		{"unknown", []string{"README.md"}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		dir := fixture(t, tt.files...)
+
+		if got := commandLine(DetectBuildCommand(dir)); !reflect.DeepEqual(got, tt.build) {
+			t.Errorf("%s: expected build command %v, got %v", tt.name, tt.build, got)
+		}
+		if got := commandLine(DetectTestCommand(dir)); !reflect.DeepEqual(got, tt.test) {
+			t.Errorf("%s: expected test command %v, got %v", tt.name, tt.test, got)
+		}
+	}
+}
+
+func TestDetectIgnoresDirectories(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "Cargo.toml"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if cmd, _ := DetectBuildCommand(dir); cmd != "" {
+		t.Errorf("Expected no build command for a directory marker, got %s", cmd)
+	}
+	if cmd, _ := DetectBuildCommand(filepath.Join(dir, "missing")); cmd != "" {
+		t.Errorf("Expected no build command for a missing directory, got %s", cmd)
+	}
+}
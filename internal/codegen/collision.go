@@ -0,0 +1,81 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+// ErrAppExists is returned under the error collision policy when the
+// application directory already exists
+var ErrAppExists = errors.New("application already exists")
+
+// Collision policies decide what happens when an application is generated
+// into a directory that already exists
+const (
+	CollisionError     = "error"     // fail with ErrAppExists
+	CollisionOverwrite = "overwrite" // replace the existing application
+	CollisionSuffix    = "suffix"    // generate into name-2, name-3, ...
+)
+
+// SetCollisionPolicy sets how existing application directories are handled;
+// an empty policy means overwrite
+func (cg *CodeGenerator) SetCollisionPolicy(policy string) error {
+	if policy == "" {
+		policy = CollisionOverwrite
+	}
+	switch policy {
+	case CollisionError, CollisionOverwrite, CollisionSuffix:
+	default:
+		return fmt.Errorf("unknown collision policy: %s (supported: error, overwrite, suffix)", policy)
+	}
+
+	cg.mutex.Lock()
+	defer cg.mutex.Unlock()
+	cg.collisionPolicy = policy
+	return nil
+}
+
+// ResolveAppDir returns the directory an application would be generated into
+// under the collision policy, without creating it
+func (cg *CodeGenerator) ResolveAppDir(appReq *requirements.ApplicationRequirement) (string, error) {
+	cg.mutex.Lock()
+	defer cg.mutex.Unlock()
+	appDir, err := cg.resolveAppDir(appReq)
+	if err != nil {
+		return "", &GenerationError{Err: err}
+	}
+	return appDir, nil
+}
+
+// resolveAppDir applies the collision policy to the application's AppDir
+func (cg *CodeGenerator) resolveAppDir(appReq *requirements.ApplicationRequirement) (string, error) {
+	appDir, err := AppDir(cg.outputDir, appReq)
+	if err != nil {
+		return "", err
+	}
+	if !exists(appDir) {
+		return appDir, nil
+	}
+
+	switch cg.collisionPolicy {
+	case CollisionError:
+		return "", fmt.Errorf("%w: %s", ErrAppExists, appDir)
+	case CollisionSuffix:
+		for n := 2; ; n++ {
+			candidate := fmt.Sprintf("%s-%d", appDir, n)
+			if !exists(candidate) {
+				return candidate, nil
+			}
+		}
+	}
+	return appDir, nil
+}
+
+// exists reports whether anything is at path
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,59 @@
+package codegen
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+// composeDatabase is the database service started next to a generated app
+type composeDatabase struct {
+	Image       string
+	Environment map[string]string
+	Healthcheck string // shell command reporting the database ready
+	DataPath    string // where the image keeps its data, mounted on a volume
+}
+
+// composeDatabases are keyed by dialect name; SQLite needs no service
+var composeDatabases = map[string]composeDatabase{
+	postgresDialect.Name: {
+		Image: "postgres:16-alpine",
+		Environment: map[string]string{
+			"POSTGRES_USER":     "postgres",
+			"POSTGRES_PASSWORD": "postgres",
+			"POSTGRES_DB":       "app",
+		},
+		Healthcheck: "pg_isready -U postgres -d app",
+		DataPath:    "/var/lib/postgresql/data",
+	},
+	mysqlDialect.Name: {
+		Image: "mysql:8.0",
+		Environment: map[string]string{
+			"MYSQL_ROOT_PASSWORD": "root",
+			"MYSQL_DATABASE":      "app",
+		},
+		Healthcheck: "mysqladmin ping -h localhost -proot",
+		DataPath:    "/var/lib/mysql",
+	},
+}
+
+// generateDockerCompose generates docker-compose.yml, running the app from its
+// Dockerfile next to its Postgres or MySQL database. SQLite apps keep their
+// database file on a volume instead.
+func (cg *CodeGenerator) generateDockerCompose(appDir string, appReq *requirements.ApplicationRequirement) error {
+	dialect := databaseDialect(appReq)
+	data := map[string]interface{}{
+		"Port": fmt.Sprintf("%v", appReq.Config["port"]),
+	}
+	if database, ok := composeDatabases[dialect.Name]; ok {
+		// The database is reached by its service name
+		data["Database"] = database
+		data["DatabaseURL"] = strings.Replace(dialect.DefaultURL, "localhost", "db", 1)
+	} else {
+		data["DatabaseURL"] = "/data/app.db"
+	}
+
+	return cg.writeTemplate("go/docker-compose.yml", filepath.Join(appDir, "docker-compose.yml"), data)
+}
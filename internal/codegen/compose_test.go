@@ -0,0 +1,82 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// composeFile is the part of docker-compose.yml the tests check
+type composeFile struct {
+	Services map[string]struct {
+		Build       string                 `yaml:"build"`
+		Image       string                 `yaml:"image"`
+		Environment map[string]string      `yaml:"environment"`
+		DependsOn   map[string]interface{} `yaml:"depends_on"`
+		Volumes     []string               `yaml:"volumes"`
+	} `yaml:"services"`
+	Volumes map[string]interface{} `yaml:"volumes"`
+}
+
+func TestGenerateDockerCompose(t *testing.T) {
+	tests := []struct {
+		database    string
+		image       string
+		databaseURL string
+	}{
+		{"postgresql", "postgres:16-alpine", "postgres://postgres:postgres@db:5432/app?sslmode=disable"},
+		{"mysql", "mysql:8.0", "root:root@tcp(db:3306)/app?parseTime=true"},
+		{"sqlite", "", "/data/app.db"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.database, func(t *testing.T) {
+			appReq := testRequirement()
+			appReq.Database = tt.database
+			appDir := generateTestApp(t, appReq)
+
+			data, err := os.ReadFile(filepath.Join(appDir, "docker-compose.yml"))
+			if err != nil {
+				t.Fatalf("failed to read docker-compose.yml: %v", err)
+			}
+			var compose composeFile
+			if err := yaml.Unmarshal(data, &compose); err != nil {
+				t.Fatalf("docker-compose.yml is not valid YAML: %v\n%s", err, data)
+			}
+
+			app, ok := compose.Services["app"]
+			if !ok || app.Build != "." {
+				t.Fatalf("expected an app service built from the Dockerfile:\n%s", data)
+			}
+			if app.Environment["DATABASE_URL"] != tt.databaseURL {
+				t.Errorf("expected DATABASE_URL %s, got %s", tt.databaseURL, app.Environment["DATABASE_URL"])
+			}
+			if app.Environment["PORT"] != "8080" {
+				t.Errorf("expected PORT 8080, got %s", app.Environment["PORT"])
+			}
+
+			db, hasDB := compose.Services["db"]
+			if tt.image == "" {
+				if hasDB || len(compose.Services) != 1 {
+					t.Errorf("expected only the app service for %s:\n%s", tt.database, data)
+				}
+				if len(app.Volumes) != 1 || !strings.HasSuffix(app.Volumes[0], ":/data") {
+					t.Errorf("expected a volume for the database file, got %v", app.Volumes)
+				}
+				return
+			}
+			if !hasDB || db.Image != tt.image {
+				t.Fatalf("expected a db service running %s:\n%s", tt.image, data)
+			}
+			if _, ok := app.DependsOn["db"]; !ok {
+				t.Error("expected the app to depend on the db service")
+			}
+			if _, ok := compose.Volumes["db-data"]; !ok {
+				t.Error("expected a volume for the database data")
+			}
+		})
+	}
+}
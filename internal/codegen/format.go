@@ -0,0 +1,146 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// stdImports maps package names used in generated code to their import paths
+// so that missing standard library imports can be added
+var stdImports = map[string]string{
+	"bufio":    "bufio",
+	"bytes":    "bytes",
+	"context":  "context",
+	"errors":   "errors",
+	"filepath": "path/filepath",
+	"fmt":      "fmt",
+	"http":     "net/http",
+	"httptest": "net/http/httptest",
+	"io":       "io",
+	"json":     "encoding/json",
+	"log":      "log",
+	"math":     "math",
+	"os":       "os",
+	"regexp":   "regexp",
+	"signal":   "os/signal",
+	"sort":     "sort",
+	"sql":      "database/sql",
+	"strconv":  "strconv",
+	"strings":  "strings",
+	"sync":     "sync",
+	"syscall":  "syscall",
+	"testing":  "testing",
+	"time":     "time",
+	"url":      "net/url",
+}
+
+// formatGoSource fixes the standard library imports of generated Go source and
+// gofmts it. An error means the template produced invalid Go.
+func formatGoSource(name string, src []byte) ([]byte, error) {
+	fixed, err := fixImports(name, src)
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := format.Source(fixed)
+	if err != nil {
+		return nil, fmt.Errorf("generated %s is not valid Go: %v", name, err)
+	}
+	return formatted, nil
+}
+
+// fixImports removes unused standard library imports and adds missing ones for
+// the packages in stdImports. Other imports are left alone since their package
+// names cannot be known without loading them.
+func fixImports(name string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("generated %s is not valid Go: %v", name, err)
+	}
+
+	// Package references are selectors on identifiers not declared in the file
+	used := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	imported := map[string]bool{}
+	var unused []*ast.ImportSpec
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		pkgName := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			pkgName = spec.Name.Name
+		}
+		imported[pkgName] = true
+
+		isStd := !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+		if isStd && pkgName != "_" && pkgName != "." && !used[pkgName] {
+			unused = append(unused, spec)
+		}
+	}
+
+	var missing []string
+	for pkgName := range used {
+		if path, ok := stdImports[pkgName]; ok && !imported[pkgName] {
+			missing = append(missing, path)
+		}
+	}
+	sort.Strings(missing)
+
+	if len(unused) == 0 && len(missing) == 0 {
+		return src, nil
+	}
+
+	// Drop the lines of unused imports, last first so offsets stay valid
+	out := append([]byte(nil), src...)
+	for i := len(unused) - 1; i >= 0; i-- {
+		start, end := lineBounds(out, fset.Position(unused[i].Pos()).Offset, fset.Position(unused[i].End()).Offset)
+		out = append(out[:start], out[end:]...)
+	}
+
+	if len(missing) > 0 {
+		var block bytes.Buffer
+		block.WriteString("\nimport (\n")
+		for _, path := range missing {
+			fmt.Fprintf(&block, "\t%q\n", path)
+		}
+		block.WriteString(")\n")
+
+		// Insert after the package clause; gofmt keeps separate import decls valid
+		pkgEnd := bytes.IndexByte(out[fset.Position(file.Name.End()).Offset:], '\n')
+		at := len(out)
+		if pkgEnd >= 0 {
+			at = fset.Position(file.Name.End()).Offset + pkgEnd + 1
+		}
+		out = append(out[:at], append(block.Bytes(), out[at:]...)...)
+	}
+
+	return out, nil
+}
+
+// lineBounds widens [start, end) to the whole lines containing it when they
+// hold nothing else, so a single-line import spec is removed with its newline
+func lineBounds(src []byte, start, end int) (int, int) {
+	lineStart := bytes.LastIndexByte(src[:start], '\n') + 1
+	lineEnd := len(src)
+	if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+		lineEnd = end + i + 1
+	}
+	if strings.TrimSpace(string(src[lineStart:start])) == "" && strings.TrimSpace(string(src[end:lineEnd])) == "" {
+		return lineStart, lineEnd
+	}
+	return start, end
+}
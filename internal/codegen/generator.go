@@ -1,37 +1,223 @@
 package codegen
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 
 	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
 )
 
+// ErrGenerationLimit is returned when an application grows past the configured size limits
+var ErrGenerationLimit = errors.New("generation limit exceeded")
+
+// GenerationError is returned when generating or planning an application
+// fails. It wraps the cause, such as ErrGenerationLimit, ErrAppExists or a
+// filesystem error.
+type GenerationError struct {
+	Err error
+}
+
+func (e *GenerationError) Error() string { return e.Err.Error() }
+
+func (e *GenerationError) Unwrap() error { return e.Err }
+
 // CodeGenerator handles the generation of application code
 type CodeGenerator struct {
 	outputDir string
 	templates map[string]*template.Template
+
+	// Size limits for a single application; zero disables a limit
+	maxFiles int
+	maxBytes int64
+
+	// collisionPolicy handles application directories that already exist
+	collisionPolicy string
+
+	// hooks run in order on every generated application
+	hooks []PostGenerateHook
+
+	// Usage of the application currently being generated
+	mutex        sync.Mutex
+	filesWritten int
+	bytesWritten int64
+
+	// When planning, files are recorded in planned instead of being written
+	planning bool
+	planned  []string
 }
 
 // NewCodeGenerator creates a new code generator
 func NewCodeGenerator(outputDir string) *CodeGenerator {
 	return &CodeGenerator{
-		outputDir: outputDir,
-		templates: make(map[string]*template.Template),
+		outputDir:       outputDir,
+		templates:       defaultTemplates,
+		collisionPolicy: CollisionOverwrite,
 	}
 }
 
+// SetLimits sets the maximum number of files and bytes a single application may produce
+func (cg *CodeGenerator) SetLimits(maxFiles int, maxBytes int64) {
+	cg.mutex.Lock()
+	defer cg.mutex.Unlock()
+	cg.maxFiles = maxFiles
+	cg.maxBytes = maxBytes
+}
+
 // GenerateApplication generates a complete application based on requirements
-func (cg *CodeGenerator) GenerateApplication(appReq *requirements.ApplicationRequirement) error {
+// and returns the directory it was written to, which depends on the
+// collision policy when the application already exists
+func (cg *CodeGenerator) GenerateApplication(appReq *requirements.ApplicationRequirement) (string, error) {
+	return cg.GenerateApplicationWith(appReq, nil)
+}
+
+// GenerateApplicationWith generates an application like GenerateApplication,
+// calling started with the directory chosen by the collision policy before
+// anything is written to it. Generations run one at a time, so no other
+// generation can claim that directory in between.
+func (cg *CodeGenerator) GenerateApplicationWith(appReq *requirements.ApplicationRequirement, started func(appDir string)) (string, error) {
+	appDir, _, err := cg.generate(appReq, false, started)
+	if err != nil {
+		return appDir, &GenerationError{Err: err}
+	}
+	return appDir, nil
+}
+
+// PlanApplication returns the paths, relative to the application directory,
+// of the files GenerateApplication would write for appReq, without touching
+// the filesystem. Generation limits apply as they would when generating.
+func (cg *CodeGenerator) PlanApplication(appReq *requirements.ApplicationRequirement) ([]string, error) {
+	_, paths, err := cg.generate(appReq, true, nil)
+	if err != nil {
+		return nil, &GenerationError{Err: err}
+	}
+	return paths, nil
+}
+
+// generate runs the generators for appReq, writing the files unless planning,
+// and returns the application directory and the relative paths of the files
+// planned. Plans ignore the collision policy. started, when set, is called
+// with the application directory before anything is written to it.
+func (cg *CodeGenerator) generate(appReq *requirements.ApplicationRequirement, planning bool, started func(appDir string)) (string, []string, error) {
+	// Generations share the usage counters, so run them one at a time
+	cg.mutex.Lock()
+	defer cg.mutex.Unlock()
+	cg.filesWritten = 0
+	cg.bytesWritten = 0
+	cg.planning = planning
+	cg.planned = nil
+	defer func() { cg.planning = false }()
+
 	// Create output directory
-	appDir := filepath.Join(cg.outputDir, strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-")))
-	if err := os.MkdirAll(appDir, 0755); err != nil {
-		return fmt.Errorf("failed to create app directory: %v", err)
+	var appDir string
+	var err error
+	if planning {
+		appDir, err = AppDir(cg.outputDir, appReq)
+	} else {
+		appDir, err = cg.resolveAppDir(appReq)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if started != nil {
+		started(appDir)
+	}
+	if !planning && cg.collisionPolicy == CollisionOverwrite {
+		// Files of the previous generation would otherwise linger
+		if err := os.RemoveAll(appDir); err != nil {
+			return "", nil, fmt.Errorf("failed to remove existing app directory: %v", err)
+		}
+	}
+	if err := cg.mkdirAll(appDir); err != nil {
+		return "", nil, fmt.Errorf("failed to create app directory: %v", err)
+	}
+
+	err = cg.generateByLanguage(appDir, appReq)
+	if err == nil {
+		err = cg.saveRequirements(appDir, appReq)
 	}
+	if err == nil && !planning {
+		err = cg.runHooks(appDir, appReq)
+	}
+	if errors.Is(err, ErrGenerationLimit) && !planning {
+		// Don't leave a truncated application behind
+		os.RemoveAll(appDir)
+	}
+	if err != nil {
+		return appDir, nil, err
+	}
+
+	paths := make([]string, 0, len(cg.planned))
+	for _, path := range cg.planned {
+		rel, err := filepath.Rel(appDir, path)
+		if err != nil {
+			return appDir, nil, err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	sort.Strings(paths)
+	return appDir, paths, nil
+}
 
+// AppDir returns the directory in outputDir an application is generated into,
+// named by requirements.SanitizeAppName, and rejects names that would resolve
+// outside outputDir
+func AppDir(outputDir string, appReq *requirements.ApplicationRequirement) (string, error) {
+	name, err := requirements.SanitizeAppName(appReq.Name)
+	if err != nil {
+		return "", err
+	}
+	appDir := filepath.Join(outputDir, name)
+	rel, err := filepath.Rel(outputDir, appDir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %q resolves outside %s", requirements.ErrInvalidAppName, appReq.Name, outputDir)
+	}
+	return appDir, nil
+}
+
+// appSlug returns the sanitized application name used for module, package and
+// image names in generated files. Names SanitizeAppName rejects never reach
+// generation, since AppDir fails first; "app" stands in for them.
+func appSlug(appReq *requirements.ApplicationRequirement) string {
+	name, err := requirements.SanitizeAppName(appReq.Name)
+	if err != nil {
+		return "app"
+	}
+	return name
+}
+
+// saveRequirements writes the analyzed requirements next to the generated code
+// so the application can later be tested against what it was generated from
+func (cg *CodeGenerator) saveRequirements(appDir string, appReq *requirements.ApplicationRequirement) error {
+	data, err := json.MarshalIndent(appReq, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal requirements: %v", err)
+	}
+
+	file, err := cg.createFile(filepath.Join(appDir, requirements.RequirementsFile))
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", requirements.RequirementsFile, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", requirements.RequirementsFile, err)
+	}
+	return nil
+}
+
+// generateByLanguage dispatches to the generator for the requested language
+func (cg *CodeGenerator) generateByLanguage(appDir string, appReq *requirements.ApplicationRequirement) error {
 	// Generate application based on language and type
 	switch appReq.Language {
 	case "javascript":
@@ -51,6 +237,98 @@ func (cg *CodeGenerator) GenerateApplication(appReq *requirements.ApplicationReq
 	}
 }
 
+// limitedFile counts bytes written to a generated file against the generator's limits
+type limitedFile struct {
+	io.WriteCloser
+	cg *CodeGenerator
+}
+
+// discardFile stands in for a planned file, dropping what is written to it
+type discardFile struct{}
+
+func (discardFile) Write(p []byte) (int, error) { return len(p), nil }
+func (discardFile) Close() error                { return nil }
+
+// Write writes p to the file unless it would exceed the byte limit
+func (f *limitedFile) Write(p []byte) (int, error) {
+	if f.cg.maxBytes > 0 && f.cg.bytesWritten+int64(len(p)) > f.cg.maxBytes {
+		return 0, fmt.Errorf("%w: more than %d bytes", ErrGenerationLimit, f.cg.maxBytes)
+	}
+	n, err := f.WriteCloser.Write(p)
+	f.cg.bytesWritten += int64(n)
+	return n, err
+}
+
+// WriteString writes s to the file unless it would exceed the byte limit
+func (f *limitedFile) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+// createFile creates a generated file, enforcing the file count limit
+func (cg *CodeGenerator) createFile(path string) (*limitedFile, error) {
+	if cg.maxFiles > 0 && cg.filesWritten >= cg.maxFiles {
+		return nil, fmt.Errorf("%w: more than %d files", ErrGenerationLimit, cg.maxFiles)
+	}
+
+	cg.planned = append(cg.planned, path)
+	if cg.planning {
+		cg.filesWritten++
+		return &limitedFile{WriteCloser: discardFile{}, cg: cg}, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	cg.filesWritten++
+
+	return &limitedFile{WriteCloser: file, cg: cg}, nil
+}
+
+// mkdirAll creates a directory of the generated application, unless planning
+func (cg *CodeGenerator) mkdirAll(path string) error {
+	if cg.planning {
+		return nil
+	}
+	return os.MkdirAll(path, 0755)
+}
+
+// chmod changes the mode of a generated file, unless planning
+func (cg *CodeGenerator) chmod(path string, mode os.FileMode) error {
+	if cg.planning {
+		return nil
+	}
+	return os.Chmod(path, mode)
+}
+
+// renderFile executes tmpl into path. Go files are gofmt'd with their standard
+// library imports fixed first, so a template producing invalid Go fails here.
+func (cg *CodeGenerator) renderFile(path string, tmpl *template.Template, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	content := buf.Bytes()
+	if strings.HasSuffix(path, ".go") {
+		formatted, err := formatGoSource(filepath.Base(path), content)
+		if err != nil {
+			return err
+		}
+		content = formatted
+	}
+
+	file, err := cg.createFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
 // generateGoApplication generates a Go application
 func (cg *CodeGenerator) generateGoApplication(appDir string, appReq *requirements.ApplicationRequirement) error {
 	// Generate different components based on application type
@@ -59,6 +337,8 @@ func (cg *CodeGenerator) generateGoApplication(appDir string, appReq *requiremen
 		return cg.generateGoAPIApplication(appDir, appReq)
 	case "web":
 		return cg.generateGoWebApplication(appDir, appReq)
+	case "graphql":
+		return cg.generateGoGraphQLApplication(appDir, appReq)
 	case "cli":
 		return cg.generateGoCLIApplication(appDir, appReq)
 	default:
@@ -143,11 +423,24 @@ func (cg *CodeGenerator) generateGoAPIApplication(appDir string, appReq *require
 		return err
 	}
 
+	// Generate go.sum and .gitignore
+	if err := cg.generateGoSum(appDir); err != nil {
+		return err
+	}
+	if err := cg.generateGitignore(appDir, appReq); err != nil {
+		return err
+	}
+
 	// Generate models
 	if err := cg.generateModels(appDir, appReq); err != nil {
 		return err
 	}
 
+	// Generate repositories
+	if err := cg.generateRepositories(appDir, appReq); err != nil {
+		return err
+	}
+
 	// Generate handlers
 	if err := cg.generateHandlers(appDir, appReq); err != nil {
 		return err
@@ -168,10 +461,50 @@ func (cg *CodeGenerator) generateGoAPIApplication(appDir string, appReq *require
 		return err
 	}
 
-	// Generate Dockerfile
+	// Generate background job scaffolding
+	if hasFeature(appReq, "background_jobs") {
+		if err := cg.generateWorker(appDir, appReq); err != nil {
+			return err
+		}
+	}
+
+	// Generate authentication middleware
+	if err := cg.generateAuthMiddleware(appDir, appReq); err != nil {
+		return err
+	}
+
+	// Generate model and handler unit tests and the integration test
+	if generateTestsEnabled(appReq) {
+		if err := cg.generateEntityTests(appDir, appReq); err != nil {
+			return err
+		}
+	}
+	if integrationTestsEnabled(appReq) {
+		if err := cg.generateIntegrationTest(appDir, appReq); err != nil {
+			return err
+		}
+	}
+
+	// Generate OpenAPI spec
+	if err := cg.generateOpenAPISpec(appDir, appReq); err != nil {
+		return err
+	}
+
+	// Generate end-to-end smoke test
+	if err := cg.generateSmokeTest(appDir, appReq); err != nil {
+		return err
+	}
+
+	// Generate Dockerfile and the compose file running it with its database
 	if err := cg.generateDockerfile(appDir, appReq); err != nil {
 		return err
 	}
+	if err := cg.generateDockerCompose(appDir, appReq); err != nil {
+		return err
+	}
+	if err := cg.generateMakefile(appDir, appReq); err != nil {
+		return err
+	}
 
 	// Generate README
 	if err := cg.generateReadme(appDir, appReq); err != nil {
@@ -190,7 +523,7 @@ func (cg *CodeGenerator) generateWebApplication(appDir string, appReq *requireme
 
 	// Generate static files directory
 	staticDir := filepath.Join(appDir, "static")
-	if err := os.MkdirAll(staticDir, 0755); err != nil {
+	if err := cg.mkdirAll(staticDir); err != nil {
 		return fmt.Errorf("failed to create static directory: %v", err)
 	}
 
@@ -234,131 +567,99 @@ func (cg *CodeGenerator) generateCLIApplication(appDir string, appReq *requireme
 
 // generateMainFile generates the main.go file
 func (cg *CodeGenerator) generateMainFile(appDir string, appReq *requirements.ApplicationRequirement) error {
-	mainTemplate := `package main
-
-import (
-	"log"
-	"net/http"
-	"os"
-
-	"github.com/gin-gonic/gin"
-	"{{.ModuleName}}/internal/config"
-	"{{.ModuleName}}/internal/database"
-	"{{.ModuleName}}/internal/handlers"
-	"{{.ModuleName}}/internal/routes"
-)
-
-func main() {
-	// Load configuration
-	cfg := config.Load()
-
-	// Initialize database
-	db, err := database.Initialize(cfg.DatabaseURL)
-	if err != nil {
-		log.Fatal("Failed to initialize database:", err)
-	}
-	defer db.Close()
-
-	// Initialize Gin router
-	r := gin.Default()
-
-	// Setup CORS
-	r.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		
-		c.Next()
-	})
-
-	// Initialize handlers
-	h := handlers.New(db)
-
-	// Setup routes
-	routes.Setup(r, h)
-
-	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "{{.Port}}"
-	}
-
-	log.Printf("Server starting on port %s", port)
-	log.Fatal(http.ListenAndServe("0.0.0.0:"+port, r))
-}
-`
-
-	tmpl, err := template.New("main").Parse(mainTemplate)
+	tmpl, err := cg.lookupTemplate("go/main.go")
 	if err != nil {
 		return err
 	}
 
 	data := struct {
-		ModuleName string
-		Port       string
+		ModuleName     string
+		Port           string
+		BackgroundJobs bool
 	}{
-		ModuleName: strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-")),
-		Port:       fmt.Sprintf("%v", appReq.Config["port"]),
-	}
-
-	file, err := os.Create(filepath.Join(appDir, "main.go"))
-	if err != nil {
-		return err
+		ModuleName:     appSlug(appReq),
+		Port:           fmt.Sprintf("%v", appReq.Config["port"]),
+		BackgroundJobs: hasFeature(appReq, "background_jobs"),
 	}
-	defer file.Close()
 
-	return tmpl.Execute(file, data)
+	return cg.renderFile(filepath.Join(appDir, "main.go"), tmpl, data)
 }
 
 // generateGoMod generates the go.mod file
 func (cg *CodeGenerator) generateGoMod(appDir string, appReq *requirements.ApplicationRequirement) error {
-	modTemplate := `module {{.ModuleName}}
-
-go 1.21
-
-require (
-	github.com/gin-gonic/gin v1.9.1
-	github.com/mattn/go-sqlite3 v1.14.17
-{{range .Dependencies}}	{{.}}
-{{end}})
-`
-
-	tmpl, err := template.New("gomod").Parse(modTemplate)
+	tmpl, err := cg.lookupTemplate("go/go.mod")
 	if err != nil {
 		return err
 	}
 
 	data := struct {
-		ModuleName   string
-		Dependencies []string
+		ModuleName     string
+		Dependencies   []string
+		DriverModule   string
+		BackgroundJobs bool
+		CLI            bool
+		GraphQL        bool
 	}{
-		ModuleName:   strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-")),
-		Dependencies: appReq.Dependencies,
+		ModuleName:     appSlug(appReq),
+		Dependencies:   goModDependencies(appReq.Dependencies),
+		DriverModule:   databaseDialect(appReq).Module,
+		BackgroundJobs: hasFeature(appReq, "background_jobs"),
+		CLI:            appReq.Type == "cli",
+		GraphQL:        appReq.Type == "graphql",
+	}
+
+	return cg.renderFile(filepath.Join(appDir, "go.mod"), tmpl, data)
+}
+
+// goModRequirement matches a dependency written as a go.mod requirement: a
+// module path and its version
+var goModRequirement = regexp.MustCompile(`^\S+ v\d+\.\d+\.\d+\S*$`)
+
+// goModDependencies returns the dependencies that are go.mod requirements. The
+// analyzer lists framework modules and packages without versions, which would
+// make go.mod unparseable; the template already pins the modules the generated
+// code imports, so those are left out.
+func goModDependencies(dependencies []string) []string {
+	var required []string
+	for _, dependency := range dependencies {
+		if goModRequirement.MatchString(dependency) {
+			required = append(required, dependency)
+		}
 	}
+	return required
+}
 
-	file, err := os.Create(filepath.Join(appDir, "go.mod"))
+// generateGoSum generates an empty go.sum, so the Dockerfile can copy it
+// before the module checksums are filled in by go mod tidy
+func (cg *CodeGenerator) generateGoSum(appDir string) error {
+	file, err := cg.createFile(filepath.Join(appDir, "go.sum"))
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to create go.sum: %w", err)
 	}
-	defer file.Close()
+	return file.Close()
+}
 
-	return tmpl.Execute(file, data)
+// generateGitignore generates a .gitignore keeping the built binary, the
+// SQLite database and test results out of version control
+func (cg *CodeGenerator) generateGitignore(appDir string, appReq *requirements.ApplicationRequirement) error {
+	data := map[string]interface{}{
+		"Binary": appSlug(appReq),
+	}
+
+	return cg.writeTemplate("go/gitignore", filepath.Join(appDir, ".gitignore"), data)
 }
 
 // generateModels generates model files for each entity
 func (cg *CodeGenerator) generateModels(appDir string, appReq *requirements.ApplicationRequirement) error {
 	modelsDir := filepath.Join(appDir, "internal", "models")
-	if err := os.MkdirAll(modelsDir, 0755); err != nil {
+	if err := cg.mkdirAll(modelsDir); err != nil {
 		return err
 	}
 
+	schema := buildRelationSchema(appReq.Entities)
+	dialect := databaseDialect(appReq)
 	for _, entity := range appReq.Entities {
-		if err := cg.generateModelFile(modelsDir, entity); err != nil {
+		if err := cg.generateModelFile(modelsDir, entity, schema, dialect); err != nil {
 			return err
 		}
 	}
@@ -367,114 +668,33 @@ func (cg *CodeGenerator) generateModels(appDir string, appReq *requirements.Appl
 }
 
 // generateModelFile generates a single model file
-func (cg *CodeGenerator) generateModelFile(modelsDir string, entity requirements.Entity) error {
-	modelTemplate := `package models
-
-import (
-	"time"
-	"database/sql"
-)
-
-// {{.Name}} represents the {{.Name}} entity
-type {{.Name}} struct {
-{{range .Fields}}	{{.GoName}} {{.GoType}} ` + "`json:\"{{.JSONName}}\"{{if .Required}} validate:\"required\"{{end}}`" + `
-{{end}}}
-
-// Create{{.Name}} creates a new {{.Name}} in the database
-func Create{{.Name}}(db *sql.DB, {{.LowerName}} *{{.Name}}) error {
-	query := ` + "`INSERT INTO {{.TableName}} ({{.InsertFields}}) VALUES ({{.InsertPlaceholders}})`" + `
-	
-	result, err := db.Exec(query{{range .InsertValues}}, {{$.LowerName}}.{{.}}{{end}})
-	if err != nil {
-		return err
-	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return err
-	}
-
-	{{.LowerName}}.ID = int(id)
-	return nil
-}
-
-// Get{{.Name}}ByID retrieves a {{.Name}} by ID
-func Get{{.Name}}ByID(db *sql.DB, id int) (*{{.Name}}, error) {
-	{{.LowerName}} := &{{.Name}}{}
-	query := ` + "`SELECT {{.SelectFields}} FROM {{.TableName}} WHERE id = ?`" + `
-	
-	err := db.QueryRow(query, id).Scan({{range .ScanFields}}&{{$.LowerName}}.{{.}}{{end}})
-	if err != nil {
-		return nil, err
-	}
-
-	return {{.LowerName}}, nil
-}
-
-// GetAll{{.Name}}s retrieves all {{.Name}}s
-func GetAll{{.Name}}s(db *sql.DB) ([]{{.Name}}, error) {
-	query := ` + "`SELECT {{.SelectFields}} FROM {{.TableName}}`" + `
-	
-	rows, err := db.Query(query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var {{.LowerName}}s []{{.Name}}
-	for rows.Next() {
-		{{.LowerName}} := {{.Name}}{}
-		err := rows.Scan({{range .ScanFields}}&{{$.LowerName}}.{{.}}{{end}})
-		if err != nil {
-			return nil, err
-		}
-		{{.LowerName}}s = append({{.LowerName}}s, {{.LowerName}})
-	}
-
-	return {{.LowerName}}s, nil
-}
-
-// Update{{.Name}} updates a {{.Name}} in the database
-func Update{{.Name}}(db *sql.DB, {{.LowerName}} *{{.Name}}) error {
-	query := ` + "`UPDATE {{.TableName}} SET {{.UpdateFields}} WHERE id = ?`" + `
-	
-	_, err := db.Exec(query{{range .UpdateValues}}, {{$.LowerName}}.{{.}}{{end}}, {{.LowerName}}.ID)
-	return err
-}
-
-// Delete{{.Name}} deletes a {{.Name}} from the database
-func Delete{{.Name}}(db *sql.DB, id int) error {
-	query := ` + "`DELETE FROM {{.TableName}} WHERE id = ?`" + `
-	
-	_, err := db.Exec(query, id)
-	return err
-}
-`
-
+func (cg *CodeGenerator) generateModelFile(modelsDir string, entity requirements.Entity, schema *relationSchema, dialect sqlDialect) error {
 	// Prepare template data
-	data := cg.prepareModelData(entity)
+	data := cg.prepareModelData(entity, schema.foreignKeys[entity.Name], dialect)
+	cg.prepareRelationData(data, entity, schema, dialect)
 
-	tmpl, err := template.New("model").Parse(modelTemplate)
+	tmpl, err := cg.lookupTemplate("go/model.go")
 	if err != nil {
 		return err
 	}
 
 	fileName := fmt.Sprintf("%s.go", strings.ToLower(entity.Name))
-	file, err := os.Create(filepath.Join(modelsDir, fileName))
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	return tmpl.Execute(file, data)
+	return cg.renderFile(filepath.Join(modelsDir, fileName), tmpl, data)
 }
 
-// prepareModelData prepares template data for model generation
-func (cg *CodeGenerator) prepareModelData(entity requirements.Entity) map[string]interface{} {
+// prepareModelData prepares template data for model generation; foreign keys
+// not already declared as fields become nullable columns after the fields.
+// Query placeholders follow the dialect's placeholderStyle.
+func (cg *CodeGenerator) prepareModelData(entity requirements.Entity, foreignKeys []foreignKey, dialect sqlDialect) map[string]interface{} {
+	placeholder := placeholderStyle(dialect)
 	data := map[string]interface{}{
-		"Name":      entity.Name,
-		"LowerName": strings.ToLower(entity.Name),
-		"TableName": strings.ToLower(entity.Name) + "s",
+		"Name":              entity.Name,
+		"LowerName":         strings.ToLower(entity.Name),
+		"TableName":         tableName(entity),
+		"IDField":           "ID",
+		"Placeholder":       placeholder(1),
+		"SecondPlaceholder": placeholder(2),
+		"ReturningID":       dialect.Name == postgresDialect.Name,
 	}
 
 	var fields []map[string]interface{}
@@ -485,44 +705,143 @@ func (cg *CodeGenerator) prepareModelData(entity requirements.Entity) map[string
 	var scanFields []string
 	var updateFields []string
 	var updateValues []string
+	var createTimestamps []string
+	var updateTimestamps []string
+	var defaults []map[string]string
+	needsTime := false
 
 	// Fix template execution issue by ensuring all fields are properly set
 	for _, field := range entity.Fields {
 		goType := cg.mapFieldTypeToGo(field.Type)
-		goName := strings.Title(field.Name)
+		goName := goFieldName(field.Name)
 		jsonName := strings.ToLower(field.Name)
+		if strings.HasPrefix(goType, "time.") {
+			needsTime = true
+		}
 
+		// Clients never send auto-managed timestamps, so they are not required
+		autoManaged := field.IsAutoManaged()
 		fields = append(fields, map[string]interface{}{
 			"GoName":   goName,
 			"GoType":   goType,
 			"JSONName": jsonName,
-			"Required": field.Required,
+			"Required": field.Required && !autoManaged,
 		})
 
-		if field.Name != "id" && field.Name != "created_at" {
+		if field.Name != "id" {
 			insertFields = append(insertFields, field.Name)
-			insertPlaceholders = append(insertPlaceholders, "?")
+			insertPlaceholders = append(insertPlaceholders, placeholder(len(insertPlaceholders)+1))
 			insertValues = append(insertValues, goName)
-			updateFields = append(updateFields, field.Name+" = ?")
-			updateValues = append(updateValues, goName)
+			if !autoManaged || field.SetOnUpdate() {
+				updateFields = append(updateFields, field.Name+" = "+placeholder(len(updateFields)+1))
+				updateValues = append(updateValues, goName)
+			}
+		}
+
+		if autoManaged {
+			createTimestamps = append(createTimestamps, goName)
+			if field.SetOnUpdate() {
+				updateTimestamps = append(updateTimestamps, goName)
+			}
+		} else if literal, zero, ok := goDefault(field); ok {
+			defaults = append(defaults, map[string]string{"GoName": goName, "Literal": literal, "Zero": zero})
+		}
+
+		if field.Name == "id" {
+			data["IDField"] = goName
 		}
 
 		selectFields = append(selectFields, field.Name)
 		scanFields = append(scanFields, goName)
 	}
 
+	for _, fk := range foreignKeys {
+		if fk.Existing {
+			continue
+		}
+		fields = append(fields, map[string]interface{}{
+			"GoName":   fk.GoName,
+			"GoType":   "*int",
+			"JSONName": fk.Column,
+			"Required": false,
+		})
+		insertFields = append(insertFields, fk.Column)
+		insertPlaceholders = append(insertPlaceholders, placeholder(len(insertPlaceholders)+1))
+		insertValues = append(insertValues, fk.GoName)
+		updateFields = append(updateFields, fk.Column+" = "+placeholder(len(updateFields)+1))
+		updateValues = append(updateValues, fk.GoName)
+		selectFields = append(selectFields, fk.Column)
+		scanFields = append(scanFields, fk.GoName)
+	}
+
+	// Argument lists are joined here so templates cannot drop the separators
+	lowerName := data["LowerName"].(string)
+	valueArgs := func(names []string, prefix string) string {
+		args := make([]string, len(names))
+		for i, name := range names {
+			args[i] = prefix + lowerName + "." + name
+		}
+		return strings.Join(args, ", ")
+	}
+
 	data["Fields"] = fields
+	data["NeedsTime"] = needsTime
+	data["CreateTimestamps"] = createTimestamps
+	data["UpdateTimestamps"] = updateTimestamps
+	data["Defaults"] = defaults
 	data["InsertFields"] = strings.Join(insertFields, ", ")
 	data["InsertPlaceholders"] = strings.Join(insertPlaceholders, ", ")
 	data["InsertValues"] = insertValues
+	data["InsertArgs"] = valueArgs(insertValues, "")
 	data["SelectFields"] = strings.Join(selectFields, ", ")
+	data["SelectColumns"] = selectFields
 	data["ScanFields"] = scanFields
+	data["ScanArgs"] = valueArgs(scanFields, "&")
 	data["UpdateFields"] = strings.Join(updateFields, ", ")
 	data["UpdateValues"] = updateValues
+	data["UpdateIDPlaceholder"] = placeholder(len(updateValues) + 1)
+	data["UpdateArgs"] = valueArgs(append(append([]string(nil), updateValues...), data["IDField"].(string)), "")
 
 	return data
 }
 
+// goDefault returns the Go literal of a field's default and the zero value
+// that Create replaces with it. Booleans and dates have no zero value to tell
+// an omitted field from a set one, so only their column default applies.
+func goDefault(field requirements.EntityField) (literal, zero string, ok bool) {
+	if field.Default == "" || field.Name == "id" {
+		return "", "", false
+	}
+	switch field.Type {
+	case "string", "email":
+		return strconv.Quote(field.Default), `""`, true
+	case "int", "float":
+		return field.Default, "0", true
+	default:
+		return "", "", false
+	}
+}
+
+// goFieldName converts a snake_case field name to an exported Go identifier,
+// keeping ID as an initialism: user_id becomes UserID, created_at CreatedAt
+func goFieldName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if strings.ToLower(part) == "id" {
+			b.WriteString("ID")
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	if b.Len() == 0 {
+		return "Field"
+	}
+	return b.String()
+}
+
 // mapFieldTypeToGo maps field types to Go types
 func (cg *CodeGenerator) mapFieldTypeToGo(fieldType string) string {
 	switch fieldType {
@@ -541,21 +860,26 @@ func (cg *CodeGenerator) mapFieldTypeToGo(fieldType string) string {
 	}
 }
 
-// generateHandlers generates handler files
-func (cg *CodeGenerator) generateHandlers(appDir string, appReq *requirements.ApplicationRequirement) error {
-	handlersDir := filepath.Join(appDir, "internal", "handlers")
-	if err := os.MkdirAll(handlersDir, 0755); err != nil {
+// generateRepositories generates a repository interface and SQL implementation per entity
+func (cg *CodeGenerator) generateRepositories(appDir string, appReq *requirements.ApplicationRequirement) error {
+	repoDir := filepath.Join(appDir, "internal", "repository")
+	if err := cg.mkdirAll(repoDir); err != nil {
 		return err
 	}
 
-	// Generate base handler
-	if err := cg.generateBaseHandler(handlersDir); err != nil {
+	moduleName := appSlug(appReq)
+
+	tmpl, err := cg.lookupTemplate("go/repositories.go")
+	if err != nil {
+		return err
+	}
+
+	if err := cg.renderFile(filepath.Join(repoDir, "repository.go"), tmpl, map[string]interface{}{"Entities": appReq.Entities}); err != nil {
 		return err
 	}
 
-	// Generate handlers for each entity
 	for _, entity := range appReq.Entities {
-		if err := cg.generateEntityHandler(handlersDir, entity, appReq.Name); err != nil {
+		if err := cg.generateEntityRepository(repoDir, entity, moduleName); err != nil {
 			return err
 		}
 	}
@@ -563,179 +887,142 @@ func (cg *CodeGenerator) generateHandlers(appDir string, appReq *requirements.Ap
 	return nil
 }
 
-// generateBaseHandler generates the base handler file
-func (cg *CodeGenerator) generateBaseHandler(handlersDir string) error {
-	handlerTemplate := `package handlers
-
-import (
-	"database/sql"
-)
-
-// Handler contains the database connection and other dependencies
-type Handler struct {
-	DB *sql.DB
-}
-
-// New creates a new handler instance
-func New(db *sql.DB) *Handler {
-	return &Handler{
-		DB: db,
+// generateEntityRepository generates the repository for a specific entity
+func (cg *CodeGenerator) generateEntityRepository(repoDir string, entity requirements.Entity, moduleName string) error {
+	data := map[string]interface{}{
+		"Name":       entity.Name,
+		"LowerName":  strings.ToLower(entity.Name),
+		"ModuleName": moduleName,
 	}
-}
-
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error string ` + "`json:\"error\"`" + `
-}
 
-// SuccessResponse represents a success response
-type SuccessResponse struct {
-	Message string      ` + "`json:\"message\"`" + `
-	Data    interface{} ` + "`json:\"data,omitempty\"`" + `
-}
-`
-
-	file, err := os.Create(filepath.Join(handlersDir, "handler.go"))
+	tmpl, err := cg.lookupTemplate("go/repository.go")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	_, err = file.WriteString(handlerTemplate)
-	return err
+	fileName := fmt.Sprintf("%s_repository.go", strings.ToLower(entity.Name))
+	return cg.renderFile(filepath.Join(repoDir, fileName), tmpl, data)
 }
 
-// generateEntityHandler generates handler for a specific entity
-func (cg *CodeGenerator) generateEntityHandler(handlersDir string, entity requirements.Entity, appName string) error {
-	handlerTemplate := `package handlers
-
-import (
-	"net/http"
-	"strconv"
-
-	"github.com/gin-gonic/gin"
-	"{{.ModuleName}}/internal/models"
-)
-
-// Create{{.Name}} creates a new {{.Name}}
-func (h *Handler) Create{{.Name}}(c *gin.Context) {
-	var {{.LowerName}} models.{{.Name}}
-	
-	if err := c.ShouldBindJSON(&{{.LowerName}}); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-		return
-	}
-
-	if err := models.Create{{.Name}}(h.DB, &{{.LowerName}}); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
-		return
+// generateHandlers generates handler files
+func (cg *CodeGenerator) generateHandlers(appDir string, appReq *requirements.ApplicationRequirement) error {
+	handlersDir := filepath.Join(appDir, "internal", "handlers")
+	if err := cg.mkdirAll(handlersDir); err != nil {
+		return err
 	}
 
-	c.JSON(http.StatusCreated, SuccessResponse{
-		Message: "{{.Name}} created successfully",
-		Data:    {{.LowerName}},
-	})
-}
-
-// Get{{.Name}} retrieves a {{.Name}} by ID
-func (h *Handler) Get{{.Name}}(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
-		return
+	// Generate base handler
+	if err := cg.generateBaseHandler(handlersDir, appReq); err != nil {
+		return err
 	}
 
-	{{.LowerName}}, err := models.Get{{.Name}}ByID(h.DB, id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: "{{.Name}} not found"})
-		return
+	// Generate handlers for each entity
+	for _, entity := range appReq.Entities {
+		if err := cg.generateEntityHandler(handlersDir, entity, appReq); err != nil {
+			return err
+		}
 	}
 
-	c.JSON(http.StatusOK, SuccessResponse{Data: {{.LowerName}}})
+	// Generate register and login handlers
+	return cg.generateAuthHandlers(handlersDir, appReq)
 }
 
-// GetAll{{.Name}}s retrieves all {{.Name}}s
-func (h *Handler) GetAll{{.Name}}s(c *gin.Context) {
-	{{.LowerName}}s, err := models.GetAll{{.Name}}s(h.DB)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
-		return
+// generateAuthHandlers generates register and login handlers issuing JWTs
+// for the User entity, when the app authenticates with JWTs
+func (cg *CodeGenerator) generateAuthHandlers(handlersDir string, appReq *requirements.ApplicationRequirement) error {
+	data := jwtLoginData(appReq)
+	if data == nil {
+		return nil
 	}
+	data["Envelope"] = responseEnvelope(appReq)
 
-	c.JSON(http.StatusOK, SuccessResponse{Data: {{.LowerName}}s})
+	return cg.writeTemplate("go/auth_handlers.go", filepath.Join(handlersDir, "auth.go"), data)
 }
 
-// Update{{.Name}} updates a {{.Name}}
-func (h *Handler) Update{{.Name}}(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
-		return
+// jwtLoginData returns the template data of the register and login handlers:
+// the User entity and its login and password fields. It is nil unless the app
+// authenticates with JWTs and has a User entity with a password and a
+// username or email field.
+func jwtLoginData(appReq *requirements.ApplicationRequirement) map[string]interface{} {
+	if authStrategy(appReq) != "jwt" {
+		return nil
 	}
 
-	var {{.LowerName}} models.{{.Name}}
-	if err := c.ShouldBindJSON(&{{.LowerName}}); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-		return
-	}
+	for _, entity := range appReq.Entities {
+		if !strings.EqualFold(entity.Name, "user") {
+			continue
+		}
 
-	{{.LowerName}}.ID = id
-	if err := models.Update{{.Name}}(h.DB, &{{.LowerName}}); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
-		return
-	}
+		fields := map[string]bool{}
+		for _, field := range entity.Fields {
+			fields[field.Name] = true
+		}
+		login := "username"
+		if !fields[login] {
+			login = "email"
+		}
+		if !fields["password"] || !fields[login] || !fields["id"] {
+			return nil
+		}
 
-	c.JSON(http.StatusOK, SuccessResponse{
-		Message: "{{.Name}} updated successfully",
-		Data:    {{.LowerName}},
-	})
+		return map[string]interface{}{
+			"ModuleName":    appSlug(appReq),
+			"Name":          entity.Name,
+			"LowerName":     strings.ToLower(entity.Name),
+			"LoginField":    goFieldName(login),
+			"LoginJSON":     login,
+			"PasswordField": goFieldName("password"),
+			"IDField":       goFieldName("id"),
+		}
+	}
+	return nil
 }
 
-// Delete{{.Name}} deletes a {{.Name}}
-func (h *Handler) Delete{{.Name}}(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.Atoi(idStr)
+// generateBaseHandler generates the base handler file
+func (cg *CodeGenerator) generateBaseHandler(handlersDir string, appReq *requirements.ApplicationRequirement) error {
+	tmpl, err := cg.lookupTemplate("go/handler_base.go")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
-		return
+		return err
 	}
 
-	if err := models.Delete{{.Name}}(h.DB, id); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
-		return
+	data := map[string]interface{}{
+		"ModuleName":     appSlug(appReq),
+		"BackgroundJobs": hasFeature(appReq, "background_jobs"),
+		"Envelope":       responseEnvelope(appReq),
 	}
 
-	c.JSON(http.StatusOK, SuccessResponse{Message: "{{.Name}} deleted successfully"})
+	return cg.renderFile(filepath.Join(handlersDir, "handler.go"), tmpl, data)
+}
+
+// responseEnvelope reports whether handlers wrap every response in a
+// {data, error, meta} envelope, as Config["response_style"] = "envelope"
+// asks, instead of the plain default
+func responseEnvelope(appReq *requirements.ApplicationRequirement) bool {
+	return appReq.Config["response_style"] == "envelope"
 }
-`
 
+// generateEntityHandler generates handler for a specific entity
+func (cg *CodeGenerator) generateEntityHandler(handlersDir string, entity requirements.Entity, appReq *requirements.ApplicationRequirement) error {
 	data := map[string]interface{}{
-		"Name":       entity.Name,
-		"LowerName":  strings.ToLower(entity.Name),
-		"ModuleName": strings.ToLower(strings.ReplaceAll(appName, " ", "-")),
+		"Name":           entity.Name,
+		"LowerName":      strings.ToLower(entity.Name),
+		"ModuleName":     appSlug(appReq),
+		"BackgroundJobs": hasFeature(appReq, "background_jobs"),
 	}
 
-	tmpl, err := template.New("handler").Parse(handlerTemplate)
+	tmpl, err := cg.lookupTemplate("go/handler.go")
 	if err != nil {
 		return err
 	}
 
 	fileName := fmt.Sprintf("%s_handler.go", strings.ToLower(entity.Name))
-	file, err := os.Create(filepath.Join(handlersDir, fileName))
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	return tmpl.Execute(file, data)
+	return cg.renderFile(filepath.Join(handlersDir, fileName), tmpl, data)
 }
 
 // generateDatabase generates database setup files
 func (cg *CodeGenerator) generateDatabase(appDir string, appReq *requirements.ApplicationRequirement) error {
 	dbDir := filepath.Join(appDir, "internal", "database")
-	if err := os.MkdirAll(dbDir, 0755); err != nil {
+	if err := cg.mkdirAll(dbDir); err != nil {
 		return err
 	}
 
@@ -749,104 +1036,105 @@ func (cg *CodeGenerator) generateDatabase(appDir string, appReq *requirements.Ap
 		return err
 	}
 
+	// Generate seed data
+	if err := cg.generateSeed(dbDir, appReq); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // generateDatabaseInit generates database initialization file
 func (cg *CodeGenerator) generateDatabaseInit(dbDir string, appReq *requirements.ApplicationRequirement) error {
-	dbTemplate := `package database
-
-import (
-	"database/sql"
-	"fmt"
-	"log"
-
-	_ "github.com/mattn/go-sqlite3"
-)
-
-// Initialize initializes the database connection and runs migrations
-func Initialize(databaseURL string) (*sql.DB, error) {
-	if databaseURL == "" {
-		databaseURL = "./app.db"
+	dialect := databaseDialect(appReq)
+	data := map[string]interface{}{
+		"Migrations":   cg.migrationStatements(appReq.Entities, dialect),
+		"Driver":       dialect.Driver,
+		"DriverImport": dialect.Import,
+		"DefaultURL":   dialect.DefaultURL,
 	}
 
-	db, err := sql.Open("sqlite3", databaseURL)
+	tmpl, err := cg.lookupTemplate("go/database.go")
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %v", err)
-	}
-
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %v", err)
-	}
-
-	// Run migrations
-	if err := runMigrations(db); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %v", err)
+		return err
 	}
 
-	log.Println("Database initialized successfully")
-	return db, nil
+	return cg.renderFile(filepath.Join(dbDir, "database.go"), tmpl, data)
 }
 
-// runMigrations runs database migrations
-func runMigrations(db *sql.DB) error {
-	migrations := []string{
-{{range .Migrations}}		` + "`{{.}}`" + `,
-{{end}}	}
-
-	for _, migration := range migrations {
-		if _, err := db.Exec(migration); err != nil {
-			return fmt.Errorf("failed to execute migration: %v", err)
+// generateCreateTableSQL generates CREATE TABLE SQL for an entity, including
+// the foreign-key columns its relations require. MySQL has no CREATE INDEX IF
+// NOT EXISTS, so its foreign-key indexes are declared in the table instead.
+func (cg *CodeGenerator) generateCreateTableSQL(entity requirements.Entity, foreignKeys []foreignKey, dialect sqlDialect) string {
+	references := map[string]string{}
+	for _, fk := range foreignKeys {
+		references[fk.Column] = fmt.Sprintf(" REFERENCES %s(id)", fk.ParentTable)
+		if !fk.Existing {
+			references[fk.Column] += " ON DELETE SET NULL"
 		}
 	}
 
-	return nil
-}
-`
+	var fields []string
 
-	var migrations []string
-	for _, entity := range appReq.Entities {
-		migration := cg.generateCreateTableSQL(entity)
-		migrations = append(migrations, migration)
-	}
+	for _, field := range entity.Fields {
+		if field.Name == "id" {
+			fields = append(fields, dialect.primaryKey(field.Name))
+			continue
+		}
 
-	data := map[string]interface{}{
-		"Migrations": migrations,
+		sqlType := cg.mapFieldTypeToSQL(field.Type)
+		if dialect.Name == postgresDialect.Name {
+			sqlType = strings.Replace(sqlType, "DATETIME", "TIMESTAMP", 1)
+		}
+		if field.Default != "" {
+			// An explicit default replaces the CURRENT_TIMESTAMP of dates
+			sqlType = strings.Split(sqlType, " ")[0] + " DEFAULT " + sqlDefault(field, dialect)
+		}
+		fieldDef := fmt.Sprintf("%s %s", field.Name, sqlType)
+		if field.Required {
+			fieldDef += " NOT NULL"
+		}
+		fieldDef += references[field.Name]
+
+		fields = append(fields, fieldDef)
 	}
 
-	tmpl, err := template.New("database").Parse(dbTemplate)
-	if err != nil {
-		return err
+	for _, fk := range foreignKeys {
+		if !fk.Existing {
+			fields = append(fields, fk.Column+" INTEGER"+references[fk.Column])
+		}
 	}
 
-	file, err := os.Create(filepath.Join(dbDir, "database.go"))
-	if err != nil {
-		return err
+	if dialect.Name == mysqlDialect.Name {
+		for _, fk := range foreignKeys {
+			fields = append(fields, fmt.Sprintf("INDEX idx_%s_%s (%s)", tableName(entity), fk.Column, fk.Column))
+		}
 	}
-	defer file.Close()
 
-	return tmpl.Execute(file, data)
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", tableName(entity), strings.Join(fields, ", "))
 }
 
-// generateCreateTableSQL generates CREATE TABLE SQL for an entity
-func (cg *CodeGenerator) generateCreateTableSQL(entity requirements.Entity) string {
-	tableName := strings.ToLower(entity.Name) + "s"
-	var fields []string
-
-	for _, field := range entity.Fields {
-		sqlType := cg.mapFieldTypeToSQL(field.Type)
-		fieldDef := fmt.Sprintf("%s %s", field.Name, sqlType)
-		
-		if field.Name == "id" {
-			fieldDef += " PRIMARY KEY AUTOINCREMENT"
-		} else if field.Required {
-			fieldDef += " NOT NULL"
+// sqlDefault returns the SQL literal of a field's default. MySQL only allows
+// defaults on TEXT columns as parenthesized expressions.
+func sqlDefault(field requirements.EntityField, dialect sqlDialect) string {
+	switch field.Type {
+	case "int", "float":
+		return field.Default
+	case "bool":
+		if value, _ := strconv.ParseBool(field.Default); value {
+			return "TRUE"
+		}
+		return "FALSE"
+	case "date":
+		if upper := strings.ToUpper(field.Default); upper == "CURRENT_TIMESTAMP" || upper == "NOW" || upper == "NOW()" {
+			return "CURRENT_TIMESTAMP"
 		}
-
-		fields = append(fields, fieldDef)
 	}
-
-	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", tableName, strings.Join(fields, ", "))
+	literal := "'" + strings.ReplaceAll(field.Default, "'", "''") + "'"
+	if dialect.Name == mysqlDialect.Name && field.Type != "date" {
+		return "(" + literal + ")"
+	}
+	return literal
 }
 
 // mapFieldTypeToSQL maps field types to SQL types
@@ -867,6 +1155,116 @@ func (cg *CodeGenerator) mapFieldTypeToSQL(fieldType string) string {
 	}
 }
 
+// generateSeed generates seed data, preferring sample records from the requirements
+// and falling back to synthetic values
+func (cg *CodeGenerator) generateSeed(dbDir string, appReq *requirements.ApplicationRequirement) error {
+	type seedTable struct {
+		Table string
+		Query string
+		Rows  []string
+	}
+
+	placeholder := placeholderStyle(databaseDialect(appReq))
+	var tables []seedTable
+	for _, entity := range appReq.Entities {
+		var fields []requirements.EntityField
+		for _, field := range entity.Fields {
+			// IDs and timestamps are filled in by the database
+			if field.Name == "id" || field.Type == "date" {
+				continue
+			}
+			fields = append(fields, field)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		columns := make([]string, len(fields))
+		placeholders := make([]string, len(fields))
+		for i, field := range fields {
+			columns[i] = field.Name
+			placeholders[i] = placeholder(i + 1)
+		}
+
+		samples := entity.SampleData
+		if len(samples) == 0 {
+			samples = make([]map[string]interface{}, 3)
+		}
+
+		var rows []string
+		for i, sample := range samples {
+			values := make([]string, len(fields))
+			for j, field := range fields {
+				value, ok := sample[field.Name]
+				if !ok {
+					value = cg.syntheticValue(entity, field, i+1)
+				}
+				values[j] = cg.seedLiteral(field.Type, value)
+			}
+			rows = append(rows, "{"+strings.Join(values, ", ")+"}")
+		}
+
+		tableName := strings.ToLower(entity.Name) + "s"
+		tables = append(tables, seedTable{
+			Table: tableName,
+			Query: fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", tableName, strings.Join(columns, ", "), strings.Join(placeholders, ", ")),
+			Rows:  rows,
+		})
+	}
+
+	tmpl, err := cg.lookupTemplate("go/seed.go")
+	if err != nil {
+		return err
+	}
+
+	return cg.renderFile(filepath.Join(dbDir, "seed.go"), tmpl, map[string]interface{}{"Tables": tables})
+}
+
+// syntheticValue produces a placeholder value for the nth seeded record
+func (cg *CodeGenerator) syntheticValue(entity requirements.Entity, field requirements.EntityField, n int) interface{} {
+	switch field.Type {
+	case "email":
+		return fmt.Sprintf("%s%d@example.com", strings.ToLower(entity.Name), n)
+	case "int":
+		return n
+	case "float":
+		return float64(n) * 10
+	case "bool":
+		return n%2 == 1
+	default:
+		return fmt.Sprintf("Sample %s %s %d", entity.Name, field.Name, n)
+	}
+}
+
+// seedLiteral renders a value as a Go literal matching the field type
+func (cg *CodeGenerator) seedLiteral(fieldType string, value interface{}) string {
+	switch fieldType {
+	case "int":
+		switch v := value.(type) {
+		case int:
+			return strconv.Itoa(v)
+		case float64:
+			return strconv.Itoa(int(v))
+		}
+	case "float":
+		switch v := value.(type) {
+		case int:
+			return strconv.FormatFloat(float64(v), 'f', -1, 64) + ".0"
+		case float64:
+			literal := strconv.FormatFloat(v, 'f', -1, 64)
+			if !strings.Contains(literal, ".") {
+				literal += ".0"
+			}
+			return literal
+		}
+	case "bool":
+		if v, ok := value.(bool); ok {
+			return strconv.FormatBool(v)
+		}
+	}
+	return strconv.Quote(fmt.Sprintf("%v", value))
+}
+
 // generateMigrations generates migration files
 func (cg *CodeGenerator) generateMigrations(dbDir string, appReq *requirements.ApplicationRequirement) error {
 	// For now, we'll keep it simple and not generate separate migration files
@@ -877,38 +1275,10 @@ func (cg *CodeGenerator) generateMigrations(dbDir string, appReq *requirements.A
 // generateRoutes generates route setup
 func (cg *CodeGenerator) generateRoutes(appDir string, appReq *requirements.ApplicationRequirement) error {
 	routesDir := filepath.Join(appDir, "internal", "routes")
-	if err := os.MkdirAll(routesDir, 0755); err != nil {
+	if err := cg.mkdirAll(routesDir); err != nil {
 		return err
 	}
 
-	routesTemplate := `package routes
-
-import (
-	"github.com/gin-gonic/gin"
-	"{{.ModuleName}}/internal/handlers"
-)
-
-// Setup configures all routes
-func Setup(r *gin.Engine, h *handlers.Handler) {
-	// Health check
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
-
-	// API routes
-	api := r.Group("/api")
-	{
-{{range .Entities}}		// {{.Name}} routes
-		api.GET("/{{.LowerPlural}}", h.GetAll{{.Name}}s)
-		api.GET("/{{.LowerPlural}}/:id", h.Get{{.Name}})
-		api.POST("/{{.LowerPlural}}", h.Create{{.Name}})
-		api.PUT("/{{.LowerPlural}}/:id", h.Update{{.Name}})
-		api.DELETE("/{{.LowerPlural}}/:id", h.Delete{{.Name}})
-
-{{end}}	}
-}
-`
-
 	var entities []map[string]interface{}
 	for _, entity := range appReq.Entities {
 		entities = append(entities, map[string]interface{}{
@@ -918,270 +1288,261 @@ func Setup(r *gin.Engine, h *handlers.Handler) {
 	}
 
 	data := map[string]interface{}{
-		"ModuleName": strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-")),
-		"Entities":   entities,
-	}
-
-	tmpl, err := template.New("routes").Parse(routesTemplate)
-	if err != nil {
-		return err
+		"ModuleName":   appSlug(appReq),
+		"Entities":     entities,
+		"AuthStrategy": authStrategy(appReq),
+		"AuthHandlers": jwtLoginData(appReq) != nil,
 	}
 
-	file, err := os.Create(filepath.Join(routesDir, "routes.go"))
+	tmpl, err := cg.lookupTemplate("go/routes.go")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	return tmpl.Execute(file, data)
+	return cg.renderFile(filepath.Join(routesDir, "routes.go"), tmpl, data)
 }
 
 // generateConfig generates configuration files
 func (cg *CodeGenerator) generateConfig(appDir string, appReq *requirements.ApplicationRequirement) error {
 	configDir := filepath.Join(appDir, "internal", "config")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := cg.mkdirAll(configDir); err != nil {
 		return err
 	}
 
-	configTemplate := `package config
+	data := map[string]interface{}{
+		"Port":           fmt.Sprintf("%v", appReq.Config["port"]),
+		"DatabaseURL":    databaseDialect(appReq).DefaultURL,
+		"BackgroundJobs": hasFeature(appReq, "background_jobs"),
+	}
 
-import (
-	"os"
-)
+	tmpl, err := cg.lookupTemplate("go/config.go")
+	if err != nil {
+		return err
+	}
 
-// Config holds application configuration
-type Config struct {
-	Port        string
-	DatabaseURL string
+	return cg.renderFile(filepath.Join(configDir, "config.go"), tmpl, data)
 }
 
-// Load loads configuration from environment variables
-func Load() *Config {
-	return &Config{
-		Port:        getEnv("PORT", "{{.Port}}"),
-		DatabaseURL: getEnv("DATABASE_URL", "{{.DatabaseURL}}"),
+// withDependency returns deps with dependency appended when it is not already listed
+func withDependency(deps []string, dependency string) []string {
+	for _, dep := range deps {
+		if dep == dependency {
+			return deps
+		}
 	}
+	return append(append([]string{}, deps...), dependency)
 }
 
-// getEnv gets an environment variable with a default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// hasFeature reports whether the requirements ask for the given feature
+func hasFeature(appReq *requirements.ApplicationRequirement, feature string) bool {
+	for _, f := range appReq.Features {
+		if f == feature {
+			return true
+		}
 	}
-	return defaultValue
+	return false
 }
-`
-
-	data := map[string]interface{}{
-		"Port":        fmt.Sprintf("%v", appReq.Config["port"]),
-		"DatabaseURL": "./app.db",
-	}
 
-	tmpl, err := template.New("config").Parse(configTemplate)
-	if err != nil {
+// generateWorker generates the background job queue and worker entrypoint
+func (cg *CodeGenerator) generateWorker(appDir string, appReq *requirements.ApplicationRequirement) error {
+	workerDir := filepath.Join(appDir, "internal", "worker")
+	if err := cg.mkdirAll(workerDir); err != nil {
 		return err
 	}
 
-	file, err := os.Create(filepath.Join(configDir, "config.go"))
-	if err != nil {
+	cmdDir := filepath.Join(appDir, "cmd", "worker")
+	if err := cg.mkdirAll(cmdDir); err != nil {
 		return err
 	}
-	defer file.Close()
-
-	return tmpl.Execute(file, data)
-}
-
-// generateDockerfile generates Dockerfile
-func (cg *CodeGenerator) generateDockerfile(appDir string, appReq *requirements.ApplicationRequirement) error {
-	dockerfileTemplate := `# Build stage
-FROM golang:1.21-alpine AS builder
-
-WORKDIR /app
-
-# Copy go mod files
-COPY go.mod go.sum ./
-RUN go mod download
-
-# Copy source code
-COPY . .
-
-# Build the application
-RUN CGO_ENABLED=1 GOOS=linux go build -a -installsuffix cgo -o main .
-
-# Final stage
-FROM alpine:latest
-
-RUN apk --no-cache add ca-certificates
-WORKDIR /root/
 
-# Copy the binary from builder stage
-COPY --from=builder /app/main .
-
-# Expose port
-EXPOSE {{.Port}}
-
-# Run the application
-CMD ["./main"]
-`
+	entities := make([]map[string]string, 0, len(appReq.Entities))
+	for _, entity := range appReq.Entities {
+		entities = append(entities, map[string]string{
+			"Name":      entity.Name,
+			"LowerName": strings.ToLower(entity.Name),
+		})
+	}
 
 	data := map[string]interface{}{
-		"Port": fmt.Sprintf("%v", appReq.Config["port"]),
+		"ModuleName": appSlug(appReq),
+		"Entities":   entities,
 	}
 
-	tmpl, err := template.New("dockerfile").Parse(dockerfileTemplate)
-	if err != nil {
-		return err
+	files := []struct {
+		template string
+		path     string
+	}{
+		{"go/worker_queue.go", filepath.Join(workerDir, "queue.go")},
+		{"go/worker_jobs.go", filepath.Join(workerDir, "jobs.go")},
+		{"go/worker_redis.go", filepath.Join(workerDir, "redis.go")},
+		{"go/worker_redis_stub.go", filepath.Join(workerDir, "redis_stub.go")},
+		{"go/worker_main.go", filepath.Join(cmdDir, "main.go")},
 	}
 
-	file, err := os.Create(filepath.Join(appDir, "Dockerfile"))
-	if err != nil {
-		return err
+	for _, f := range files {
+		if err := cg.writeTemplate(f.template, f.path, data); err != nil {
+			return err
+		}
 	}
-	defer file.Close()
 
-	return tmpl.Execute(file, data)
+	return nil
 }
 
-// generateReadme generates README.md
-func (cg *CodeGenerator) generateReadme(appDir string, appReq *requirements.ApplicationRequirement) error {
-	readmeTemplate := `# {{.Name}}
-
-{{.Description}}
-
-## Features
-
-{{range .Features}}- {{.}}
-{{end}}
-
-## API Endpoints
-
-{{range .Endpoints}}### {{.Method}} {{.Path}}
-{{.Description}}
-
-{{if .Parameters}}**Parameters:**
-{{range .Parameters}}- {{.Name}} ({{.Type}}) - {{if .Required}}Required{{else}}Optional{{end}} - {{.Source}}
-{{end}}{{end}}
-
-{{end}}
-
-## Getting Started
-
-### Prerequisites
-
-- Go 1.21 or higher
-- SQLite (for development)
-
-### Installation
-
-1. Clone the repository
-2. Install dependencies:
-   ` + "```bash" + `
-   go mod tidy
-   ` + "```" + `
-
-3. Run the application:
-   ` + "```bash" + `
-   go run main.go
-   ` + "```" + `
+// generateSmokeTest generates an end-to-end smoke script that exercises the
+// CRUD endpoints of every entity against the running server
+func (cg *CodeGenerator) generateSmokeTest(appDir string, appReq *requirements.ApplicationRequirement) error {
+	scriptsDir := filepath.Join(appDir, "scripts")
+	if err := cg.mkdirAll(scriptsDir); err != nil {
+		return err
+	}
 
-The server will start on port {{.Port}}.
+	var entities []map[string]string
+	for _, entity := range appReq.Entities {
+		body := make(map[string]interface{})
+		for _, field := range entity.Fields {
+			if field.Name == "id" || field.Type == "date" {
+				continue
+			}
+			body[strings.ToLower(field.Name)] = cg.syntheticValue(entity, field, 1)
+		}
 
-### Docker
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
 
-Build and run with Docker:
+		entities = append(entities, map[string]string{
+			"Name":   entity.Name,
+			"Plural": strings.ToLower(entity.Name) + "s",
+			// Quote for use inside a single-quoted shell string
+			"Body": strings.ReplaceAll(string(encoded), "'", `'\''`),
+		})
+	}
 
-` + "```bash" + `
-docker build -t {{.DockerName}} .
-docker run -p {{.Port}}:{{.Port}} {{.DockerName}}
-` + "```" + `
+	scriptPath := filepath.Join(scriptsDir, "smoke_test.sh")
+	if err := cg.writeTemplate("go/smoke_test.sh", scriptPath, map[string]interface{}{
+		"Name":         appReq.Name,
+		"Entities":     entities,
+		"AuthStrategy": authStrategy(appReq),
+		"SQLite":       databaseDialect(appReq) == sqliteDialect,
+	}); err != nil {
+		return err
+	}
 
-## Configuration
+	return cg.chmod(scriptPath, 0755)
+}
 
-Environment variables:
+// authStrategy returns the auth strategy to generate
+func authStrategy(appReq *requirements.ApplicationRequirement) string {
+	return appReq.EffectiveAuthStrategy()
+}
 
-- ` + "`PORT`" + ` - Server port (default: {{.Port}})
-- ` + "`DATABASE_URL`" + ` - Database connection string (default: ./app.db)
+// generateAuthMiddleware generates the middleware for the requested auth strategy
+func (cg *CodeGenerator) generateAuthMiddleware(appDir string, appReq *requirements.ApplicationRequirement) error {
+	strategy := authStrategy(appReq)
+	if strategy == "none" {
+		return nil
+	}
 
-## Testing
+	middlewareDir := filepath.Join(appDir, "internal", "middleware")
+	if err := cg.mkdirAll(middlewareDir); err != nil {
+		return err
+	}
 
-Run tests:
+	templates := map[string]string{
+		"apikey": "go/auth_apikey.go",
+		"jwt":    "go/auth_jwt.go",
+		"oauth2": "go/auth_oauth2.go",
+	}
 
-` + "```bash" + `
-go test ./...
-` + "```" + `
+	key, ok := templates[strategy]
+	if !ok {
+		return fmt.Errorf("unsupported auth strategy: %s", strategy)
+	}
 
-## License
+	return cg.writeTemplate(key, filepath.Join(middlewareDir, "auth.go"), nil)
+}
 
-This project is generated by Golang AI Agent.
-`
+// securitySchemes returns the OpenAPI security scheme for the auth strategy, keyed by scheme name
+func (cg *CodeGenerator) securitySchemes(strategy string) map[string]interface{} {
+	switch strategy {
+	case "apikey":
+		return map[string]interface{}{
+			"ApiKeyAuth": map[string]interface{}{
+				"type": "apiKey",
+				"in":   "header",
+				"name": "X-API-Key",
+			},
+		}
+	case "jwt":
+		return map[string]interface{}{
+			"BearerAuth": map[string]interface{}{
+				"type":         "http",
+				"scheme":       "bearer",
+				"bearerFormat": "JWT",
+			},
+		}
+	case "oauth2":
+		return map[string]interface{}{
+			"OAuth2": map[string]interface{}{
+				"type": "oauth2",
+				"flows": map[string]interface{}{
+					"authorizationCode": map[string]interface{}{
+						"authorizationUrl": "https://provider.example.com/oauth/authorize",
+						"tokenUrl":         "https://provider.example.com/oauth/token",
+						"scopes":           map[string]interface{}{},
+					},
+				},
+			},
+		}
+	default:
+		return map[string]interface{}{}
+	}
+}
 
+// generateDockerfile generates Dockerfile
+func (cg *CodeGenerator) generateDockerfile(appDir string, appReq *requirements.ApplicationRequirement) error {
 	data := map[string]interface{}{
-		"Name":        appReq.Name,
-		"Description": appReq.Description,
-		"Features":    appReq.Features,
-		"Endpoints":   appReq.Endpoints,
-		"Port":        fmt.Sprintf("%v", appReq.Config["port"]),
-		"DockerName":  strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-")),
+		"Port":    fmt.Sprintf("%v", appReq.Config["port"]),
+		"GraphQL": appReq.Type == "graphql",
 	}
 
-	tmpl, err := template.New("readme").Parse(readmeTemplate)
+	tmpl, err := cg.lookupTemplate("go/Dockerfile")
 	if err != nil {
 		return err
 	}
 
-	file, err := os.Create(filepath.Join(appDir, "README.md"))
+	return cg.renderFile(filepath.Join(appDir, "Dockerfile"), tmpl, data)
+}
+
+// generateReadme generates README.md
+func (cg *CodeGenerator) generateReadme(appDir string, appReq *requirements.ApplicationRequirement) error {
+	data := map[string]interface{}{
+		"Name":             appReq.Name,
+		"Description":      appReq.Description,
+		"Features":         appReq.Features,
+		"Endpoints":        appReq.Endpoints,
+		"Port":             fmt.Sprintf("%v", appReq.Config["port"]),
+		"DatabaseURL":      databaseDialect(appReq).DefaultURL,
+		"DockerName":       appSlug(appReq),
+		"IntegrationTests": integrationTestsEnabled(appReq),
+	}
+
+	tmpl, err := cg.lookupTemplate("go/README.md")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	return tmpl.Execute(file, data)
+	return cg.renderFile(filepath.Join(appDir, "README.md"), tmpl, data)
 }
 
 // generateHTMLTemplates generates basic HTML templates for web applications
 func (cg *CodeGenerator) generateHTMLTemplates(staticDir string, appReq *requirements.ApplicationRequirement) error {
 	// Create templates directory
 	templatesDir := filepath.Join(staticDir, "templates")
-	if err := os.MkdirAll(templatesDir, 0755); err != nil {
-		return err
-	}
-
-	// Generate index.html
-	indexTemplate := `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>{{.Name}}</title>
-    <link rel="stylesheet" href="/static/css/style.css">
-</head>
-<body>
-    <header>
-        <nav>
-            <h1>{{.Name}}</h1>
-            <ul>
-                <li><a href="/">Home</a></li>
-{{range .Pages}}                <li><a href="{{.Route}}">{{.Name}}</a></li>
-{{end}}            </ul>
-        </nav>
-    </header>
-
-    <main>
-        <h2>Welcome to {{.Name}}</h2>
-        <p>{{.Description}}</p>
-        
-        <div class="features">
-            <h3>Features:</h3>
-            <ul>
-{{range .Features}}                <li>{{.}}</li>
-{{end}}            </ul>
-        </div>
-    </main>
-
-    <script src="/static/js/app.js"></script>
-</body>
-</html>
-`
+	if err := cg.mkdirAll(templatesDir); err != nil {
+		return err
+	}
 
 	data := map[string]interface{}{
 		"Name":        appReq.Name,
@@ -1190,24 +1551,18 @@ func (cg *CodeGenerator) generateHTMLTemplates(staticDir string, appReq *require
 		"Pages":       appReq.Pages,
 	}
 
-	tmpl, err := template.New("index").Parse(indexTemplate)
-	if err != nil {
-		return err
-	}
-
-	file, err := os.Create(filepath.Join(templatesDir, "index.html"))
+	tmpl, err := cg.lookupTemplate("go/index.html")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	return tmpl.Execute(file, data)
+	return cg.renderFile(filepath.Join(templatesDir, "index.html"), tmpl, data)
 }
 
 // generateCSS generates basic CSS
 func (cg *CodeGenerator) generateCSS(staticDir string, appReq *requirements.ApplicationRequirement) error {
 	cssDir := filepath.Join(staticDir, "css")
-	if err := os.MkdirAll(cssDir, 0755); err != nil {
+	if err := cg.mkdirAll(cssDir); err != nil {
 		return err
 	}
 
@@ -1309,7 +1664,7 @@ h2 {
 }
 `
 
-	file, err := os.Create(filepath.Join(cssDir, "style.css"))
+	file, err := cg.createFile(filepath.Join(cssDir, "style.css"))
 	if err != nil {
 		return err
 	}
@@ -1322,7 +1677,7 @@ h2 {
 // generateJavaScript generates basic JavaScript
 func (cg *CodeGenerator) generateJavaScript(staticDir string, appReq *requirements.ApplicationRequirement) error {
 	jsDir := filepath.Join(staticDir, "js")
-	if err := os.MkdirAll(jsDir, 0755); err != nil {
+	if err := cg.mkdirAll(jsDir); err != nil {
 		return err
 	}
 
@@ -1365,7 +1720,7 @@ document.addEventListener('DOMContentLoaded', function() {
 });
 `
 
-	file, err := os.Create(filepath.Join(jsDir, "app.js"))
+	file, err := cg.createFile(filepath.Join(jsDir, "app.js"))
 	if err != nil {
 		return err
 	}
@@ -1405,132 +1760,107 @@ async function update%s(id, data) {
         method: 'PUT',
         body: JSON.stringify(data)
     });
-}
-
-async function delete%s(id) {
-    return await apiCall('/%s/' + id, {
-        method: 'DELETE'
-    });
-}`, entity.Name, entityPlural, entityPlural, entity.Name, entityPlural, entity.Name, entityPlural, entity.Name, entityPlural, entity.Name, entityPlural))
-	}
-
-	return strings.Join(functions, "\n")
-}
-
-// generateCLIMain generates main.go for CLI applications
-func (cg *CodeGenerator) generateCLIMain(appDir string, appReq *requirements.ApplicationRequirement) error {
-	cliTemplate := `package main
-
-import (
-	"fmt"
-	"os"
-
-	"{{.ModuleName}}/internal/commands"
-)
+}
 
-func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: {{.AppName}} <command> [args...]")
-		fmt.Println("Available commands:")
-{{range .Commands}}		fmt.Println("  {{.}}")
-{{end}}		os.Exit(1)
+async function delete%s(id) {
+    return await apiCall('/%s/' + id, {
+        method: 'DELETE'
+    });
+}`, entity.Name, entityPlural, entityPlural, entity.Name, entityPlural, entity.Name, entityPlural, entity.Name, entityPlural, entity.Name, entityPlural))
 	}
 
-	command := os.Args[1]
-	args := os.Args[2:]
-
-	switch command {
-{{range .Commands}}	case "{{.}}":
-		commands.{{.Title}}(args)
-{{end}}	default:
-		fmt.Printf("Unknown command: %s\n", command)
-		os.Exit(1)
-	}
+	return strings.Join(functions, "\n")
 }
-`
-
-	var commands []string
-	for _, entity := range appReq.Entities {
-		entityLower := strings.ToLower(entity.Name)
-		commands = append(commands, "list-"+entityLower+"s")
-		commands = append(commands, "create-"+entityLower)
-	}
 
+// generateCLIMain generates main.go for CLI applications
+func (cg *CodeGenerator) generateCLIMain(appDir string, appReq *requirements.ApplicationRequirement) error {
 	data := map[string]interface{}{
-		"ModuleName": strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-")),
-		"AppName":    strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-")),
-		"Commands":   commands,
+		"ModuleName": appSlug(appReq),
 	}
 
-	tmpl, err := template.New("climain").Parse(cliTemplate)
+	tmpl, err := cg.lookupTemplate("go/cli_main.go")
 	if err != nil {
 		return err
 	}
 
-	file, err := os.Create(filepath.Join(appDir, "main.go"))
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	return tmpl.Execute(file, data)
+	return cg.renderFile(filepath.Join(appDir, "main.go"), tmpl, data)
 }
 
-// generateCLICommands generates CLI command files
+// generateCLICommands generates the cobra root command and a command group per
+// entity with create, list, get, update and delete subcommands
 func (cg *CodeGenerator) generateCLICommands(appDir string, appReq *requirements.ApplicationRequirement) error {
-	commandsDir := filepath.Join(appDir, "internal", "commands")
-	if err := os.MkdirAll(commandsDir, 0755); err != nil {
+	cmdDir := filepath.Join(appDir, "cmd")
+	if err := cg.mkdirAll(cmdDir); err != nil {
 		return err
 	}
 
-	// Generate basic command structure
-	commandTemplate := `package commands
-
-import (
-	"fmt"
-)
+	moduleName := appSlug(appReq)
 
-// Example command functions
-{{range .Commands}}
-func {{.Function}}(args []string) {
-	fmt.Println("Executing {{.Name}} command with args:", args)
-	// TODO: Implement {{.Name}} logic
-}
-{{end}}
-`
+	if err := cg.writeTemplate("go/cli_root.go", filepath.Join(cmdDir, "root.go"), map[string]interface{}{
+		"ModuleName":  moduleName,
+		"Description": appReq.Description,
+		"DefaultURL":  databaseDialect(appReq).DefaultURL,
+		"SQLite":      databaseDialect(appReq) == sqliteDialect,
+	}); err != nil {
+		return err
+	}
 
-	var commands []map[string]string
 	for _, entity := range appReq.Entities {
-		entityLower := strings.ToLower(entity.Name)
-		commands = append(commands, map[string]string{
-			"Name":     "list-" + entityLower + "s",
-			"Function": "List" + entity.Name + "s",
-		})
-		commands = append(commands, map[string]string{
-			"Name":     "create-" + entityLower,
-			"Function": "Create" + entity.Name,
-		})
-	}
+		data := cg.prepareCLICommandData(entity)
+		data["ModuleName"] = moduleName
 
-	data := map[string]interface{}{
-		"Commands": commands,
+		path := filepath.Join(cmdDir, strings.ToLower(entity.Name)+".go")
+		if err := cg.writeTemplate("go/commands.go", path, data); err != nil {
+			return err
+		}
 	}
 
-	tmpl, err := template.New("commands").Parse(commandTemplate)
-	if err != nil {
-		return err
-	}
+	return nil
+}
 
-	file, err := os.Create(filepath.Join(commandsDir, "commands.go"))
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+// prepareCLICommandData prepares template data for an entity's commands, mapping
+// each writable field to a flag of the matching type
+func (cg *CodeGenerator) prepareCLICommandData(entity requirements.Entity) map[string]interface{} {
+	// Only the field names are used here, so any dialect will do
+	data := cg.prepareModelData(entity, nil, sqliteDialect)
 
-	return tmpl.Execute(file, data)
-}
+	var flags []map[string]interface{}
+	hasDate := false
+	for i, field := range entity.Fields {
+		// Match the fields the model takes from its callers
+		if field.Name == "id" || field.IsAutoManaged() {
+			continue
+		}
 
+		goType := cg.mapFieldTypeToGo(field.Type)
+		flag := map[string]interface{}{
+			"GoName":   data["Fields"].([]map[string]interface{})[i]["GoName"],
+			"Flag":     strings.ReplaceAll(strings.ToLower(field.Name), "_", "-"),
+			"Usage":    strings.ReplaceAll(field.Name, "_", " "),
+			"Required": field.Required,
+			"IsDate":   goType == "time.Time",
+		}
+		switch goType {
+		case "int":
+			flag["FlagType"], flag["FlagFunc"], flag["FlagDefault"] = "int", "IntVar", "0"
+		case "float64":
+			flag["FlagType"], flag["FlagFunc"], flag["FlagDefault"] = "float64", "Float64Var", "0"
+		case "bool":
+			flag["FlagType"], flag["FlagFunc"], flag["FlagDefault"] = "bool", "BoolVar", "false"
+		default:
+			flag["FlagType"], flag["FlagFunc"], flag["FlagDefault"] = "string", "StringVar", `""`
+		}
+		if goType == "time.Time" {
+			hasDate = true
+			flag["Usage"] = flag["Usage"].(string) + " (RFC 3339)"
+		}
+		flags = append(flags, flag)
+	}
 
+	data["Flags"] = flags
+	data["HasDate"] = hasDate
+	return data
+}
 
 // generateJavaScriptAPIApplication generates a REST API application in Node.js/JavaScript
 func (cg *CodeGenerator) generateJavaScriptAPIApplication(appDir string, appReq *requirements.ApplicationRequirement) error {
@@ -1574,10 +1904,21 @@ func (cg *CodeGenerator) generateJavaScriptAPIApplication(appDir string, appReq
 		return err
 	}
 
-	// Generate Dockerfile
+	// Generate OpenAPI spec
+	if err := cg.generateOpenAPISpec(appDir, appReq); err != nil {
+		return err
+	}
+
+	// Generate Dockerfile and the health check script it runs
 	if err := cg.generateJavaScriptDockerfile(appDir, appReq); err != nil {
 		return err
 	}
+	if err := cg.generateJavaScriptHealthcheck(appDir, appReq); err != nil {
+		return err
+	}
+	if err := cg.generateMakefile(appDir, appReq); err != nil {
+		return err
+	}
 
 	// Generate README
 	if err := cg.generateJavaScriptReadme(appDir, appReq); err != nil {
@@ -1589,54 +1930,34 @@ func (cg *CodeGenerator) generateJavaScriptAPIApplication(appDir string, appReq
 
 // generatePackageJSON generates package.json for Node.js application
 func (cg *CodeGenerator) generatePackageJSON(appDir string, appReq *requirements.ApplicationRequirement) error {
-	packageJSON := `{
-  "name": "{{.AppName}}",
-  "version": "1.0.0",
-  "description": "{{.Description}}",
-  "main": "app.js",
-  "scripts": {
-    "start": "node app.js",
-    "dev": "nodemon app.js",
-    "test": "jest"
-  },
-  "dependencies": {
-{{range $i, $dep := .Dependencies}}    "{{$dep}}": "latest"{{if ne $i (sub (len $.Dependencies) 1)}},{{end}}
-{{end}}  },
-  "devDependencies": {
-    "nodemon": "^3.0.0",
-    "jest": "^29.0.0"
-  },
-  "keywords": [
-    "api",
-    "{{.Framework}}",
-    "rest"
-  ],
-  "author": "",
-  "license": "MIT"
-}`
-
-	tmpl, err := template.New("package.json").Funcs(template.FuncMap{
-		"sub": func(a, b int) int { return a - b },
-	}).Parse(packageJSON)
+	tmpl, err := cg.lookupTemplate("javascript/package.json")
 	if err != nil {
 		return fmt.Errorf("failed to parse package.json template: %v", err)
 	}
 
+	dependencies := withDependency(appReq.Dependencies, "compression")
+	driver, hasDatabase := nodeDatabase(appReq)
+	if hasDatabase {
+		dependencies = withDependency(dependencies, driver.Package)
+	}
+
 	data := struct {
 		AppName      string
 		Description  string
 		Framework    string
 		Dependencies []string
+		Migrate      bool
 	}{
-		AppName:      strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-")),
+		AppName:      appSlug(appReq),
 		Description:  appReq.Description,
 		Framework:    appReq.Framework,
-		Dependencies: appReq.Dependencies,
+		Dependencies: dependencies,
+		Migrate:      hasDatabase,
 	}
 
-	file, err := os.Create(filepath.Join(appDir, "package.json"))
+	file, err := cg.createFile(filepath.Join(appDir, "package.json"))
 	if err != nil {
-		return fmt.Errorf("failed to create package.json: %v", err)
+		return fmt.Errorf("failed to create package.json: %w", err)
 	}
 	defer file.Close()
 
@@ -1645,76 +1966,11 @@ func (cg *CodeGenerator) generatePackageJSON(appDir string, appReq *requirements
 
 // generateJavaScriptMainFile generates the main server file (app.js)
 func (cg *CodeGenerator) generateJavaScriptMainFile(appDir string, appReq *requirements.ApplicationRequirement) error {
-	mainFile := `const express = require('express');
-const cors = require('cors');
-const helmet = require('helmet');
-const morgan = require('morgan');
-{{if .HasDatabase}}const db = require('./config/database');{{end}}
-
-// Import routes
-{{range .Entities}}const {{.LowerName}}Routes = require('./routes/{{.LowerName}}Routes');
-{{end}}
-
-const app = express();
-const PORT = process.env.PORT || {{.Port}};
-
-// Middleware
-app.use(helmet());
-app.use(cors());
-app.use(morgan('combined'));
-app.use(express.json());
-app.use(express.urlencoded({ extended: true }));
-
-// Routes
-app.get('/', (req, res) => {
-  res.json({
-    message: 'Welcome to {{.AppName}} API',
-    version: '1.0.0',
-    endpoints: [
-{{range .Endpoints}}      '{{.Method}} {{.Path}}',
-{{end}}    ]
-  });
-});
-
-{{range .Entities}}app.use('/api/{{.LowerName}}s', {{.LowerName}}Routes);
-{{end}}
-
-// Error handling middleware
-app.use((err, req, res, next) => {
-  console.error(err.stack);
-  res.status(500).json({
-    error: 'Something went wrong!',
-    message: err.message
-  });
-});
-
-// 404 handler
-app.use('*', (req, res) => {
-  res.status(404).json({
-    error: 'Route not found'
-  });
-});
-
-{{if .HasDatabase}}// Initialize database connection
-db.connect().then(() => {
-  console.log('Database connected successfully');
-  
-  app.listen(PORT, '0.0.0.0', () => {
-    console.log('Server is running on port ' + PORT);
-    console.log('API Documentation: http://localhost:' + PORT);
-  });
-}).catch(err => {
-  console.error('Database connection failed:', err);
-  process.exit(1);
-});{{else}}app.listen(PORT, '0.0.0.0', () => {
-  console.log('Server is running on port ' + PORT);
-  console.log('API Documentation: http://localhost:' + PORT);
-});{{end}}`
-
-	tmpl, err := template.New("app.js").Parse(mainFile)
+	tmpl, err := cg.lookupTemplate("javascript/app.js")
 	if err != nil {
 		return fmt.Errorf("failed to parse app.js template: %v", err)
 	}
+	_, hasDatabase := nodeDatabase(appReq)
 
 	// Prepare entities with lowercase names
 	var entities []map[string]interface{}
@@ -1734,14 +1990,14 @@ db.connect().then(() => {
 	}{
 		AppName:     appReq.Name,
 		Port:        appReq.Config["port"],
-		HasDatabase: appReq.Database != "",
+		HasDatabase: hasDatabase,
 		Entities:    entities,
 		Endpoints:   appReq.Endpoints,
 	}
 
-	file, err := os.Create(filepath.Join(appDir, "app.js"))
+	file, err := cg.createFile(filepath.Join(appDir, "app.js"))
 	if err != nil {
-		return fmt.Errorf("failed to create app.js: %v", err)
+		return fmt.Errorf("failed to create app.js: %w", err)
 	}
 	defer file.Close()
 
@@ -1751,12 +2007,13 @@ db.connect().then(() => {
 // generateJavaScriptModels generates model files for JavaScript application
 func (cg *CodeGenerator) generateJavaScriptModels(appDir string, appReq *requirements.ApplicationRequirement) error {
 	modelsDir := filepath.Join(appDir, "models")
-	if err := os.MkdirAll(modelsDir, 0755); err != nil {
+	if err := cg.mkdirAll(modelsDir); err != nil {
 		return fmt.Errorf("failed to create models directory: %v", err)
 	}
 
+	driver, hasDatabase := nodeDatabase(appReq)
 	for _, entity := range appReq.Entities {
-		if err := cg.generateJavaScriptModel(modelsDir, entity); err != nil {
+		if err := cg.generateJavaScriptModel(modelsDir, entity, driver, hasDatabase); err != nil {
 			return err
 		}
 	}
@@ -1764,48 +2021,32 @@ func (cg *CodeGenerator) generateJavaScriptModels(appDir string, appReq *require
 	return nil
 }
 
-// generateJavaScriptModel generates a single model file
-func (cg *CodeGenerator) generateJavaScriptModel(modelsDir string, entity requirements.Entity) error {
-	modelTemplate := `class {{.Name}} {
-  constructor(data = {}) {
-{{range .Fields}}    this.{{.Name}} = data.{{.Name}} || {{.DefaultValue}};
-{{end}}  }
-
-  // Validation method
-  validate() {
-    const errors = [];
-{{range .Fields}}{{if .Required}}
-    if (!this.{{.Name}}) {
-      errors.push('{{.Name}} is required');
-    }{{end}}{{if .Validation}}
-    // Add validation for {{.Name}}: {{.Validation}}{{end}}
-{{end}}
-    return errors;
-  }
-
-  // Convert to JSON
-  toJSON() {
-    return {
-{{range .Fields}}      {{.Name}}: this.{{.Name}},
-{{end}}    };
-  }
-
-  // Create from database row
-  static fromRow(row) {
-    return new {{.Name}}(row);
-  }
-}
-
-module.exports = {{.Name}};`
-
-	tmpl, err := template.New("model").Parse(modelTemplate)
+// generateJavaScriptModel generates a single model file. With a database
+// driver the model reads and writes its table through config/database.js;
+// without one it keeps records in memory.
+func (cg *CodeGenerator) generateJavaScriptModel(modelsDir string, entity requirements.Entity, driver nodeDriver, hasDatabase bool) error {
+	tmpl, err := cg.lookupTemplate("javascript/model.js")
 	if err != nil {
 		return fmt.Errorf("failed to parse model template: %v", err)
 	}
 
-	// Prepare fields with default values
+	// Prepare fields with default values; the ID is assigned by the store
 	var fields []map[string]interface{}
+	var updateTimestamps []string
+	var insertColumns, updateColumns []string
 	for _, field := range entity.Fields {
+		if field.Name == "id" {
+			continue
+		}
+		if field.SetOnUpdate() {
+			updateTimestamps = append(updateTimestamps, field.Name)
+		}
+		insertColumns = append(insertColumns, field.Name)
+		// created_at and other dates set once are never updated
+		if !field.IsAutoManaged() || field.SetOnUpdate() {
+			updateColumns = append(updateColumns, field.Name)
+		}
+
 		defaultValue := "null"
 		switch field.Type {
 		case "string", "email":
@@ -1828,17 +2069,65 @@ module.exports = {{.Name}};`
 	}
 
 	data := struct {
-		Name   string
-		Fields []map[string]interface{}
+		Name             string
+		LowerName        string
+		Fields           []map[string]interface{}
+		UpdateTimestamps []string
+		SQL              bool
+		Table            string
+		IDPlaceholder    string
+		InsertSQL        string
+		InsertArgs       string
+		UpdateSQL        string
+		UpdateArgs       string
 	}{
-		Name:   entity.Name,
-		Fields: fields,
+		Name:             entity.Name,
+		LowerName:        strings.ToLower(entity.Name),
+		Fields:           fields,
+		UpdateTimestamps: updateTimestamps,
+		SQL:              hasDatabase,
+		Table:            tableName(entity),
+	}
+	if hasDatabase {
+		lowerName := strings.ToLower(entity.Name)
+		placeholder := placeholderStyle(driver.Dialect)
+		args := func(columns []string) string {
+			values := make([]string, len(columns))
+			for i, column := range columns {
+				values[i] = lowerName + "." + column
+			}
+			return strings.Join(values, ", ")
+		}
+
+		data.IDPlaceholder = placeholder(1)
+		values := make([]string, len(insertColumns))
+		for i := range insertColumns {
+			values[i] = placeholder(i + 1)
+		}
+		switch {
+		case len(insertColumns) > 0:
+			data.InsertSQL = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", data.Table, strings.Join(insertColumns, ", "), strings.Join(values, ", "))
+		case driver.Dialect.Name == postgresDialect.Name:
+			data.InsertSQL = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", data.Table)
+		default:
+			data.InsertSQL = fmt.Sprintf("INSERT INTO %s () VALUES ()", data.Table)
+		}
+		data.InsertArgs = args(insertColumns)
+
+		if len(updateColumns) > 0 {
+			assignments := make([]string, len(updateColumns))
+			for i, column := range updateColumns {
+				assignments[i] = fmt.Sprintf("%s = %s", column, placeholder(i+1))
+			}
+			data.UpdateSQL = fmt.Sprintf("UPDATE %s SET %s WHERE id = %s", data.Table, strings.Join(assignments, ", "), placeholder(len(updateColumns)+1))
+			data.UpdateArgs = args(append(append([]string(nil), updateColumns...), "id"))
+		}
 	}
 
 	filename := filepath.Join(modelsDir, fmt.Sprintf("%s.js", entity.Name))
-	file, err := os.Create(filename)
+	file, err := cg.createFile(filename)
 	if err != nil {
-		return fmt.Errorf("failed to create model file %s: %v", filename, err)
+		return fmt.Errorf("failed to create model file %s: %w", filename, err)
 	}
 	defer file.Close()
 
@@ -1848,7 +2137,7 @@ module.exports = {{.Name}};`
 // generateJavaScriptRoutes generates route files for JavaScript application
 func (cg *CodeGenerator) generateJavaScriptRoutes(appDir string, appReq *requirements.ApplicationRequirement) error {
 	routesDir := filepath.Join(appDir, "routes")
-	if err := os.MkdirAll(routesDir, 0755); err != nil {
+	if err := cg.mkdirAll(routesDir); err != nil {
 		return fmt.Errorf("failed to create routes directory: %v", err)
 	}
 
@@ -1863,28 +2152,7 @@ func (cg *CodeGenerator) generateJavaScriptRoutes(appDir string, appReq *require
 
 // generateJavaScriptRoute generates a single route file
 func (cg *CodeGenerator) generateJavaScriptRoute(routesDir string, entity requirements.Entity) error {
-	routeTemplate := `const express = require('express');
-const router = express.Router();
-const {{.LowerName}}Controller = require('../controllers/{{.LowerName}}Controller');
-
-// GET /api/{{.LowerName}}s - Get all {{.LowerName}}s
-router.get('/', {{.LowerName}}Controller.getAll);
-
-// GET /api/{{.LowerName}}s/:id - Get {{.LowerName}} by ID
-router.get('/:id', {{.LowerName}}Controller.getById);
-
-// POST /api/{{.LowerName}}s - Create new {{.LowerName}}
-router.post('/', {{.LowerName}}Controller.create);
-
-// PUT /api/{{.LowerName}}s/:id - Update {{.LowerName}}
-router.put('/:id', {{.LowerName}}Controller.update);
-
-// DELETE /api/{{.LowerName}}s/:id - Delete {{.LowerName}}
-router.delete('/:id', {{.LowerName}}Controller.delete);
-
-module.exports = router;`
-
-	tmpl, err := template.New("route").Parse(routeTemplate)
+	tmpl, err := cg.lookupTemplate("javascript/route.js")
 	if err != nil {
 		return fmt.Errorf("failed to parse route template: %v", err)
 	}
@@ -1898,9 +2166,9 @@ module.exports = router;`
 	}
 
 	filename := filepath.Join(routesDir, fmt.Sprintf("%sRoutes.js", strings.ToLower(entity.Name)))
-	file, err := os.Create(filename)
+	file, err := cg.createFile(filename)
 	if err != nil {
-		return fmt.Errorf("failed to create route file %s: %v", filename, err)
+		return fmt.Errorf("failed to create route file %s: %w", filename, err)
 	}
 	defer file.Close()
 
@@ -1910,7 +2178,7 @@ module.exports = router;`
 // generateJavaScriptControllers generates controller files for JavaScript application
 func (cg *CodeGenerator) generateJavaScriptControllers(appDir string, appReq *requirements.ApplicationRequirement) error {
 	controllersDir := filepath.Join(appDir, "controllers")
-	if err := os.MkdirAll(controllersDir, 0755); err != nil {
+	if err := cg.mkdirAll(controllersDir); err != nil {
 		return fmt.Errorf("failed to create controllers directory: %v", err)
 	}
 
@@ -1925,152 +2193,7 @@ func (cg *CodeGenerator) generateJavaScriptControllers(appDir string, appReq *re
 
 // generateJavaScriptController generates a single controller file
 func (cg *CodeGenerator) generateJavaScriptController(controllersDir string, entity requirements.Entity) error {
-	controllerTemplate := `const {{.Name}} = require('../models/{{.Name}}');
-
-class {{.Name}}Controller {
-  // Get all {{.LowerName}}s
-  static async getAll(req, res) {
-    try {
-      // TODO: Implement database query to get all {{.LowerName}}s
-      const {{.LowerName}}s = [];
-      
-      res.json({
-        success: true,
-        data: {{.LowerName}}s,
-        count: {{.LowerName}}s.length
-      });
-    } catch (error) {
-      console.error('Error getting {{.LowerName}}s:', error);
-      res.status(500).json({
-        success: false,
-        error: 'Failed to retrieve {{.LowerName}}s'
-      });
-    }
-  }
-
-  // Get {{.LowerName}} by ID
-  static async getById(req, res) {
-    try {
-      const { id } = req.params;
-      
-      // TODO: Implement database query to get {{.LowerName}} by ID
-      const {{.LowerName}} = null;
-      
-      if (!{{.LowerName}}) {
-        return res.status(404).json({
-          success: false,
-          error: '{{.Name}} not found'
-        });
-      }
-
-      res.json({
-        success: true,
-        data: {{.LowerName}}
-      });
-    } catch (error) {
-      console.error('Error getting {{.LowerName}}:', error);
-      res.status(500).json({
-        success: false,
-        error: 'Failed to retrieve {{.LowerName}}'
-      });
-    }
-  }
-
-  // Create new {{.LowerName}}
-  static async create(req, res) {
-    try {
-      const {{.LowerName}}Data = req.body;
-      const {{.LowerName}} = new {{.Name}}({{.LowerName}}Data);
-      
-      // Validate {{.LowerName}} data
-      const validationErrors = {{.LowerName}}.validate();
-      if (validationErrors.length > 0) {
-        return res.status(400).json({
-          success: false,
-          error: 'Validation failed',
-          details: validationErrors
-        });
-      }
-
-      // TODO: Implement database insert
-      const created{{.Name}} = {{.LowerName}};
-      
-      res.status(201).json({
-        success: true,
-        data: created{{.Name}},
-        message: '{{.Name}} created successfully'
-      });
-    } catch (error) {
-      console.error('Error creating {{.LowerName}}:', error);
-      res.status(500).json({
-        success: false,
-        error: 'Failed to create {{.LowerName}}'
-      });
-    }
-  }
-
-  // Update {{.LowerName}}
-  static async update(req, res) {
-    try {
-      const { id } = req.params;
-      const updateData = req.body;
-      
-      // TODO: Implement database update
-      const updated{{.Name}} = null;
-      
-      if (!updated{{.Name}}) {
-        return res.status(404).json({
-          success: false,
-          error: '{{.Name}} not found'
-        });
-      }
-
-      res.json({
-        success: true,
-        data: updated{{.Name}},
-        message: '{{.Name}} updated successfully'
-      });
-    } catch (error) {
-      console.error('Error updating {{.LowerName}}:', error);
-      res.status(500).json({
-        success: false,
-        error: 'Failed to update {{.LowerName}}'
-      });
-    }
-  }
-
-  // Delete {{.LowerName}}
-  static async delete(req, res) {
-    try {
-      const { id } = req.params;
-      
-      // TODO: Implement database delete
-      const deleted = false;
-      
-      if (!deleted) {
-        return res.status(404).json({
-          success: false,
-          error: '{{.Name}} not found'
-        });
-      }
-
-      res.json({
-        success: true,
-        message: '{{.Name}} deleted successfully'
-      });
-    } catch (error) {
-      console.error('Error deleting {{.LowerName}}:', error);
-      res.status(500).json({
-        success: false,
-        error: 'Failed to delete {{.LowerName}}'
-      });
-    }
-  }
-}
-
-module.exports = {{.Name}}Controller;`
-
-	tmpl, err := template.New("controller").Parse(controllerTemplate)
+	tmpl, err := cg.lookupTemplate("javascript/controller.js")
 	if err != nil {
 		return fmt.Errorf("failed to parse controller template: %v", err)
 	}
@@ -2084,9 +2207,9 @@ module.exports = {{.Name}}Controller;`
 	}
 
 	filename := filepath.Join(controllersDir, fmt.Sprintf("%sController.js", strings.ToLower(entity.Name)))
-	file, err := os.Create(filename)
+	file, err := cg.createFile(filename)
 	if err != nil {
-		return fmt.Errorf("failed to create controller file %s: %v", filename, err)
+		return fmt.Errorf("failed to create controller file %s: %w", filename, err)
 	}
 	defer file.Close()
 
@@ -2096,7 +2219,7 @@ module.exports = {{.Name}}Controller;`
 // generateJavaScriptMiddleware generates middleware files
 func (cg *CodeGenerator) generateJavaScriptMiddleware(appDir string, appReq *requirements.ApplicationRequirement) error {
 	middlewareDir := filepath.Join(appDir, "middleware")
-	if err := os.MkdirAll(middlewareDir, 0755); err != nil {
+	if err := cg.mkdirAll(middlewareDir); err != nil {
 		return fmt.Errorf("failed to create middleware directory: %v", err)
 	}
 
@@ -2128,129 +2251,80 @@ const auth = (req, res, next) => {
 
 module.exports = auth;`
 
-	authFile, err := os.Create(filepath.Join(middlewareDir, "auth.js"))
+	authFile, err := cg.createFile(filepath.Join(middlewareDir, "auth.js"))
 	if err != nil {
-		return fmt.Errorf("failed to create auth middleware: %v", err)
+		return fmt.Errorf("failed to create auth middleware: %w", err)
 	}
 	defer authFile.Close()
 
 	if _, err := authFile.WriteString(authMiddleware); err != nil {
-		return fmt.Errorf("failed to write auth middleware: %v", err)
+		return fmt.Errorf("failed to write auth middleware: %w", err)
 	}
 
 	return nil
 }
 
-// generateJavaScriptDatabase generates database configuration
+// generateJavaScriptDatabase generates config/database.js, a connection pool
+// for the app's database with query helpers, and config/migrate.js, which
+// creates the entities' tables. Apps whose models keep records in memory get
+// neither.
 func (cg *CodeGenerator) generateJavaScriptDatabase(appDir string, appReq *requirements.ApplicationRequirement) error {
+	driver, hasDatabase := nodeDatabase(appReq)
+	if !hasDatabase {
+		return nil
+	}
+
 	configDir := filepath.Join(appDir, "config")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := cg.mkdirAll(configDir); err != nil {
 		return fmt.Errorf("failed to create config directory: %v", err)
 	}
 
-	dbConfig := `// Database configuration
-const config = {
-  development: {
-    host: process.env.DB_HOST || 'localhost',
-    port: process.env.DB_PORT || 5432,
-    database: process.env.DB_NAME || '{{.AppName}}_dev',
-    username: process.env.DB_USER || 'postgres',
-    password: process.env.DB_PASSWORD || 'password',
-    dialect: '{{.Database}}',
-    logging: console.log
-  },
-  production: {
-    host: process.env.DB_HOST,
-    port: process.env.DB_PORT,
-    database: process.env.DB_NAME,
-    username: process.env.DB_USER,
-    password: process.env.DB_PASSWORD,
-    dialect: '{{.Database}}',
-    logging: false
-  }
-};
-
-const env = process.env.NODE_ENV || 'development';
-const dbConfig = config[env];
-
-// Simple database connection (placeholder)
-const db = {
-  connect: async () => {
-    console.log('Connecting to ' + dbConfig.dialect + ' database...');
-    // TODO: Implement actual database connection
-    return Promise.resolve();
-  },
-  
-  disconnect: async () => {
-    console.log('Disconnecting from database...');
-    // TODO: Implement actual database disconnection
-    return Promise.resolve();
-  }
-};
-
-module.exports = db;`
-
-	tmpl, err := template.New("database").Parse(dbConfig)
-	if err != nil {
-		return fmt.Errorf("failed to parse database template: %v", err)
+	// Statements are embedded as JSON strings, which are valid JavaScript
+	// whatever quotes the column defaults contain
+	var migrations []string
+	for _, entity := range appReq.Entities {
+		statement, err := json.Marshal(cg.generateCreateTableSQL(entity, nil, driver.Dialect))
+		if err != nil {
+			return err
+		}
+		migrations = append(migrations, string(statement))
 	}
 
-	data := struct {
-		AppName  string
-		Database string
-	}{
-		AppName:  strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-")),
-		Database: appReq.Database,
+	data := map[string]interface{}{
+		"Dialect":    driver.Dialect.Name,
+		"DefaultURL": driver.DefaultURL,
+		"Migrations": migrations,
 	}
-
-	file, err := os.Create(filepath.Join(configDir, "database.js"))
-	if err != nil {
-		return fmt.Errorf("failed to create database config: %v", err)
+	if err := cg.writeTemplate("javascript/database.js", filepath.Join(configDir, "database.js"), data); err != nil {
+		return fmt.Errorf("failed to generate database config: %w", err)
 	}
-	defer file.Close()
-
-	return tmpl.Execute(file, data)
+	if err := cg.writeTemplate("javascript/migrate.js", filepath.Join(configDir, "migrate.js"), data); err != nil {
+		return fmt.Errorf("failed to generate migrations: %w", err)
+	}
+	return nil
 }
 
 // generateJavaScriptEnvConfig generates environment configuration
 func (cg *CodeGenerator) generateJavaScriptEnvConfig(appDir string, appReq *requirements.ApplicationRequirement) error {
-	envContent := `# Environment Configuration
-NODE_ENV=development
-PORT={{.Port}}
-
-# Database Configuration
-DB_HOST=localhost
-DB_PORT=5432
-DB_NAME={{.AppName}}_dev
-DB_USER=postgres
-DB_PASSWORD=password
-
-# JWT Configuration
-JWT_SECRET=your-super-secret-jwt-key-change-this-in-production
-JWT_EXPIRES_IN=24h
-
-# CORS Configuration
-CORS_ORIGIN=*
-
-# Logging
-LOG_LEVEL=info`
-
-	tmpl, err := template.New("env").Parse(envContent)
+	tmpl, err := cg.lookupTemplate("javascript/env")
 	if err != nil {
 		return fmt.Errorf("failed to parse env template: %v", err)
 	}
 
+	driver, _ := nodeDatabase(appReq)
 	data := struct {
-		AppName string
-		Port    interface{}
+		AppName     string
+		Port        interface{}
+		DatabaseURL string
 	}{
-		AppName: strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-")),
-		Port:    appReq.Config["port"],
+		AppName:     appSlug(appReq),
+		Port:        appReq.Config["port"],
+		DatabaseURL: driver.DefaultURL,
 	}
 
-	file, err := os.Create(filepath.Join(appDir, ".env.example"))
+	file, err := cg.createFile(filepath.Join(appDir, ".env.example"))
 	if err != nil {
-		return fmt.Errorf("failed to create .env.example: %v", err)
+		return fmt.Errorf("failed to create .env.example: %w", err)
 	}
 	defer file.Close()
 
@@ -2259,40 +2333,7 @@ LOG_LEVEL=info`
 
 // generateJavaScriptDockerfile generates Dockerfile for JavaScript application
 func (cg *CodeGenerator) generateJavaScriptDockerfile(appDir string, appReq *requirements.ApplicationRequirement) error {
-	dockerfile := `# Use official Node.js runtime as base image
-FROM node:18-alpine
-
-# Set working directory
-WORKDIR /app
-
-# Copy package files
-COPY package*.json ./
-
-# Install dependencies
-RUN npm ci --only=production
-
-# Copy application code
-COPY . .
-
-# Create non-root user
-RUN addgroup -g 1001 -S nodejs
-RUN adduser -S nodejs -u 1001
-
-# Change ownership of the app directory
-RUN chown -R nodejs:nodejs /app
-USER nodejs
-
-# Expose port
-EXPOSE {{.Port}}
-
-# Health check
-HEALTHCHECK --interval=30s --timeout=3s --start-period=5s --retries=3 \
-  CMD node healthcheck.js
-
-# Start the application
-CMD ["npm", "start"]`
-
-	tmpl, err := template.New("dockerfile").Parse(dockerfile)
+	tmpl, err := cg.lookupTemplate("javascript/Dockerfile.js")
 	if err != nil {
 		return fmt.Errorf("failed to parse dockerfile template: %v", err)
 	}
@@ -2303,97 +2344,32 @@ CMD ["npm", "start"]`
 		Port: appReq.Config["port"],
 	}
 
-	file, err := os.Create(filepath.Join(appDir, "Dockerfile"))
+	file, err := cg.createFile(filepath.Join(appDir, "Dockerfile"))
 	if err != nil {
-		return fmt.Errorf("failed to create Dockerfile: %v", err)
+		return fmt.Errorf("failed to create Dockerfile: %w", err)
 	}
 	defer file.Close()
 
 	return tmpl.Execute(file, data)
 }
 
-// generateJavaScriptReadme generates README for JavaScript application
-func (cg *CodeGenerator) generateJavaScriptReadme(appDir string, appReq *requirements.ApplicationRequirement) error {
-	readme := `# {{.AppName}}
-
-{{.Description}}
-
-## Features
-
-{{range .Features}}- {{.}}
-{{end}}
-
-## Prerequisites
-
-- Node.js 18+ 
-- npm or yarn
-{{if .HasDatabase}}- {{.Database}} database{{end}}
-
-## Installation
-
-1. Clone the repository
-2. Install dependencies:
-   ` + "`" + `bash
-   npm install
-   ` + "`" + `
-
-3. Copy environment configuration:
-   ` + "`" + `bash
-   cp .env.example .env
-   ` + "`" + `
-
-4. Update the ` + "`" + `.env` + "`" + ` file with your configuration
-
-{{if .HasDatabase}}5. Set up your {{.Database}} database
-
-6. Start the application:{{else}}5. Start the application:{{end}}
-   ` + "`" + `bash
-   npm run dev
-   ` + "`" + `
-
-## API Endpoints
-
-{{range .Endpoints}}- ` + "`" + `{{.Method}} {{.Path}}` + "`" + ` - {{.Description}}
-{{end}}
-
-## Project Structure
-
-` + "`" + `
-{{.AppName}}/
-├── app.js              # Main application file
-├── package.json        # Dependencies and scripts
-├── .env.example        # Environment configuration template
-├── Dockerfile          # Docker configuration
-├── controllers/        # Request handlers
-├── models/            # Data models
-├── routes/            # API routes
-├── middleware/        # Custom middleware
-└── config/            # Configuration files
-` + "`" + `
-
-## Development
-
-- ` + "`" + `npm run dev` + "`" + ` - Start development server with auto-reload
-- ` + "`" + `npm start` + "`" + ` - Start production server
-- ` + "`" + `npm test` + "`" + ` - Run tests
-
-## Docker
-
-Build and run with Docker:
-
-` + "`" + `bash
-docker build -t {{.AppName}} .
-docker run -p {{.Port}}:{{.Port}} {{.AppName}}
-` + "`" + `
-
-## License
+// generateJavaScriptHealthcheck generates healthcheck.js, which the Dockerfile's
+// HEALTHCHECK runs to probe the server's /health route
+func (cg *CodeGenerator) generateJavaScriptHealthcheck(appDir string, appReq *requirements.ApplicationRequirement) error {
+	data := map[string]interface{}{
+		"Port": appReq.Config["port"],
+	}
 
-MIT`
+	return cg.writeTemplate("javascript/healthcheck.js", filepath.Join(appDir, "healthcheck.js"), data)
+}
 
-	tmpl, err := template.New("readme").Parse(readme)
+// generateJavaScriptReadme generates README for JavaScript application
+func (cg *CodeGenerator) generateJavaScriptReadme(appDir string, appReq *requirements.ApplicationRequirement) error {
+	tmpl, err := cg.lookupTemplate("javascript/README.js.md")
 	if err != nil {
 		return fmt.Errorf("failed to parse readme template: %v", err)
 	}
+	_, hasDatabase := nodeDatabase(appReq)
 
 	data := struct {
 		AppName     string
@@ -2407,15 +2383,15 @@ MIT`
 		AppName:     appReq.Name,
 		Description: appReq.Description,
 		Features:    appReq.Features,
-		HasDatabase: appReq.Database != "",
+		HasDatabase: hasDatabase,
 		Database:    appReq.Database,
 		Endpoints:   appReq.Endpoints,
 		Port:        appReq.Config["port"],
 	}
 
-	file, err := os.Create(filepath.Join(appDir, "README.md"))
+	file, err := cg.createFile(filepath.Join(appDir, "README.md"))
 	if err != nil {
-		return fmt.Errorf("failed to create README.md: %v", err)
+		return fmt.Errorf("failed to create README.md: %w", err)
 	}
 	defer file.Close()
 
@@ -2428,11 +2404,6 @@ func (cg *CodeGenerator) generateJavaScriptWebApplication(appDir string, appReq
 	return fmt.Errorf("JavaScript web application generation not yet implemented")
 }
 
-func (cg *CodeGenerator) generatePythonAPIApplication(appDir string, appReq *requirements.ApplicationRequirement) error {
-	// TODO: Implement Python API application generation
-	return fmt.Errorf("Python API application generation not yet implemented")
-}
-
 func (cg *CodeGenerator) generatePythonWebApplication(appDir string, appReq *requirements.ApplicationRequirement) error {
 	// TODO: Implement Python web application generation
 	return fmt.Errorf("Python web application generation not yet implemented")
@@ -2473,8 +2444,47 @@ func (cg *CodeGenerator) generateGoWebApplication(appDir string, appReq *require
 	return fmt.Errorf("Go web application generation not yet implemented")
 }
 
+// generateGoCLIApplication generates a cobra CLI that manages entities stored in SQLite
 func (cg *CodeGenerator) generateGoCLIApplication(appDir string, appReq *requirements.ApplicationRequirement) error {
-	// TODO: Implement Go CLI application generation
-	return fmt.Errorf("Go CLI application generation not yet implemented")
+	// Generate main.go
+	if err := cg.generateCLIMain(appDir, appReq); err != nil {
+		return err
+	}
+
+	// Generate go.mod
+	if err := cg.generateGoMod(appDir, appReq); err != nil {
+		return err
+	}
+
+	// Generate go.sum and .gitignore
+	if err := cg.generateGoSum(appDir); err != nil {
+		return err
+	}
+	if err := cg.generateGitignore(appDir, appReq); err != nil {
+		return err
+	}
+
+	// Generate models
+	if err := cg.generateModels(appDir, appReq); err != nil {
+		return err
+	}
+
+	// Generate repositories
+	if err := cg.generateRepositories(appDir, appReq); err != nil {
+		return err
+	}
+
+	// Generate database
+	if err := cg.generateDatabase(appDir, appReq); err != nil {
+		return err
+	}
+
+	// Generate commands
+	if err := cg.generateCLICommands(appDir, appReq); err != nil {
+		return err
+	}
+
+	// Generate Makefile
+	return cg.generateMakefile(appDir, appReq)
 }
 
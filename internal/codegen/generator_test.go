@@ -0,0 +1,1928 @@
+package codegen
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+	_ "github.com/mattn/go-sqlite3"
+	"gopkg.in/yaml.v3"
+)
+
+// testRequirement returns a small Go API requirement with a single User entity
+func testRequirement() *requirements.ApplicationRequirement {
+	return &requirements.ApplicationRequirement{
+		Name:      "Test App",
+		Type:      "api",
+		Language:  "go",
+		Framework: "gin",
+		Database:  "sqlite",
+		Entities: []requirements.Entity{
+			{
+				Name: "User",
+				Fields: []requirements.EntityField{
+					{Name: "id", Type: "int", Required: true},
+					{Name: "username", Type: "string", Required: true},
+					{Name: "email", Type: "email", Required: true},
+					{Name: "created_at", Type: "date", Required: true},
+				},
+				Operations: []string{"create", "read", "update", "delete"},
+			},
+		},
+		Config: map[string]interface{}{"port": 8080},
+	}
+}
+
+// generateTestApp generates the application into a temp dir and returns its path
+func generateTestApp(t *testing.T, appReq *requirements.ApplicationRequirement) string {
+	t.Helper()
+	outputDir := t.TempDir()
+	cg := NewCodeGenerator(outputDir)
+	if _, err := cg.GenerateApplication(appReq); err != nil {
+		t.Fatalf("GenerateApplication failed: %v", err)
+	}
+	return filepath.Join(outputDir, "test-app")
+}
+
+// parseGoFile parses a generated Go file, failing the test on syntax errors
+func parseGoFile(t *testing.T, path string) *ast.File {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", path, err)
+	}
+	return file
+}
+
+// findType returns the type expression declared under name in file
+func findType(file *ast.File, name string) ast.Expr {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+				return ts.Type
+			}
+		}
+	}
+	return nil
+}
+
+// mockUserRepository mirrors the method set a hand-written mock would provide
+var mockUserRepository = map[string]int{
+	"Create":  1,
+	"GetByID": 1,
+	"GetAll":  0,
+	"GetPage": 3,
+	"Update":  1,
+	"Delete":  1,
+}
+
+func TestGenerateRepositoryInterface(t *testing.T) {
+	appDir := generateTestApp(t, testRequirement())
+
+	repoFile := parseGoFile(t, filepath.Join(appDir, "internal", "repository", "user_repository.go"))
+	iface, ok := findType(repoFile, "UserRepository").(*ast.InterfaceType)
+	if !ok {
+		t.Fatal("UserRepository interface not generated")
+	}
+
+	var methods []string
+	for _, m := range iface.Methods.List {
+		name := m.Names[0].Name
+		methods = append(methods, name)
+		params, ok := mockUserRepository[name]
+		if !ok {
+			t.Errorf("mock repository does not implement %s", name)
+			continue
+		}
+		if got := m.Type.(*ast.FuncType).Params.NumFields(); got != params {
+			t.Errorf("%s takes %d params, mock takes %d", name, got, params)
+		}
+	}
+	if len(methods) != len(mockUserRepository) {
+		sort.Strings(methods)
+		t.Errorf("interface methods %v do not match mock", methods)
+	}
+
+	// The registry must expose the interface so a mock can be plugged in
+	registry := parseGoFile(t, filepath.Join(appDir, "internal", "repository", "repository.go"))
+	repos, ok := findType(registry, "Repositories").(*ast.StructType)
+	if !ok {
+		t.Fatal("Repositories struct not generated")
+	}
+	found := false
+	for _, field := range repos.Fields.List {
+		if ident, ok := field.Type.(*ast.Ident); ok && ident.Name == "UserRepository" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Repositories.User should be typed as the UserRepository interface")
+	}
+
+	// Handlers depend on the repositories rather than *sql.DB
+	handler := parseGoFile(t, filepath.Join(appDir, "internal", "handlers", "handler.go"))
+	h, ok := findType(handler, "Handler").(*ast.StructType)
+	if !ok {
+		t.Fatal("Handler struct not generated")
+	}
+	for _, field := range h.Fields.List {
+		star, ok := field.Type.(*ast.StarExpr)
+		if !ok {
+			t.Errorf("unexpected handler dependency %v", field.Names)
+			continue
+		}
+		if sel, ok := star.X.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Repositories" {
+			t.Errorf("handler should depend on *repository.Repositories")
+		}
+	}
+}
+
+func TestGenerateBackgroundJobs(t *testing.T) {
+	appReq := testRequirement()
+	appReq.Features = []string{"background_jobs"}
+	appDir := generateTestApp(t, appReq)
+
+	for _, name := range []string{"queue.go", "jobs.go", "redis.go", "redis_stub.go"} {
+		parseGoFile(t, filepath.Join(appDir, "internal", "worker", name))
+	}
+	for _, path := range []string{"main.go", "cmd/worker/main.go", "internal/config/config.go", "internal/handlers/handler.go", "internal/handlers/user_handler.go"} {
+		parseGoFile(t, filepath.Join(appDir, path))
+	}
+
+	gomod, err := os.ReadFile(filepath.Join(appDir, "go.mod"))
+	if err != nil {
+		t.Fatalf("failed to read go.mod: %v", err)
+	}
+	if !strings.Contains(string(gomod), "github.com/hibiken/asynq") {
+		t.Errorf("go.mod does not require asynq:\n%s", gomod)
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	cmd := exec.Command("go", "build", "./internal/worker", "./cmd/worker")
+	cmd.Dir = appDir
+	cmd.Env = append(os.Environ(), "GOPROXY=off", "GOFLAGS=-mod=mod", "GOWORK=off")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated worker does not compile: %v\n%s", err, output)
+	}
+}
+
+func TestGenerateWithoutBackgroundJobs(t *testing.T) {
+	appDir := generateTestApp(t, testRequirement())
+
+	if _, err := os.Stat(filepath.Join(appDir, "internal", "worker")); !os.IsNotExist(err) {
+		t.Errorf("worker package generated without background_jobs feature")
+	}
+	handler, err := os.ReadFile(filepath.Join(appDir, "internal", "handlers", "handler.go"))
+	if err != nil {
+		t.Fatalf("failed to read handler.go: %v", err)
+	}
+	if strings.Contains(string(handler), "worker") {
+		t.Errorf("handler.go references worker without background_jobs feature:\n%s", handler)
+	}
+}
+
+func TestGenerateSeedUsesSampleData(t *testing.T) {
+	appReq := testRequirement()
+	appReq.Entities = append(appReq.Entities, requirements.Entity{
+		Name: "Product",
+		Fields: []requirements.EntityField{
+			{Name: "id", Type: "int", Required: true},
+			{Name: "name", Type: "string", Required: true},
+			{Name: "price", Type: "float", Required: true},
+		},
+		SampleData: []map[string]interface{}{
+			{"name": "Widget", "price": 10.0},
+			{"name": "Gadget", "price": 20.0},
+		},
+	})
+	appDir := generateTestApp(t, appReq)
+
+	seedPath := filepath.Join(appDir, "internal", "database", "seed.go")
+	parseGoFile(t, seedPath)
+	seed, err := os.ReadFile(seedPath)
+	if err != nil {
+		t.Fatalf("failed to read seed.go: %v", err)
+	}
+	for _, expected := range []string{`"Widget", 10.0`, `"Gadget", 20.0`, `"user1@example.com"`} {
+		if !strings.Contains(string(seed), expected) {
+			t.Errorf("seed.go does not contain %s:\n%s", expected, seed)
+		}
+	}
+	if strings.Contains(string(seed), "Sample Product") {
+		t.Errorf("seed.go uses synthetic products despite sample data:\n%s", seed)
+	}
+}
+
+func TestGenerateApplicationFileLimit(t *testing.T) {
+	outputDir := t.TempDir()
+	cg := NewCodeGenerator(outputDir)
+	cg.SetLimits(3, 0)
+
+	_, err := cg.GenerateApplication(testRequirement())
+	if !errors.Is(err, ErrGenerationLimit) {
+		t.Fatalf("expected ErrGenerationLimit, got %v", err)
+	}
+	var generationErr *GenerationError
+	if !errors.As(err, &generationErr) {
+		t.Errorf("expected a *GenerationError, got %T", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "test-app")); !os.IsNotExist(err) {
+		t.Error("expected partially generated application to be removed")
+	}
+
+	cg.SetLimits(0, 1024)
+	if _, err := cg.GenerateApplication(testRequirement()); !errors.Is(err, ErrGenerationLimit) {
+		t.Fatalf("expected ErrGenerationLimit for byte limit, got %v", err)
+	}
+}
+
+func TestGenerateApplicationContainsAppDir(t *testing.T) {
+	parent := t.TempDir()
+	outputDir := filepath.Join(parent, "generated_apps")
+	cg := NewCodeGenerator(outputDir)
+
+	appReq := testRequirement()
+	appReq.Name = "../../escaped"
+	if _, err := cg.GenerateApplication(appReq); err != nil {
+		t.Fatalf("GenerateApplication failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "escaped", "go.mod")); err != nil {
+		t.Errorf("expected the application inside the output directory: %v", err)
+	}
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected nothing written outside the output directory, got %d entries", len(entries))
+	}
+
+	appReq.Name = "../.."
+	if _, err := cg.GenerateApplication(appReq); !errors.Is(err, requirements.ErrInvalidAppName) {
+		t.Errorf("expected ErrInvalidAppName, got %v", err)
+	}
+	if _, err := AppDir(outputDir, appReq); !errors.Is(err, requirements.ErrInvalidAppName) {
+		t.Errorf("expected AppDir to reject %q, got %v", appReq.Name, err)
+	}
+}
+
+func TestGenerateApplicationCollisionPolicies(t *testing.T) {
+	t.Run("overwrite", func(t *testing.T) {
+		outputDir := t.TempDir()
+		cg := NewCodeGenerator(outputDir)
+		first, err := cg.GenerateApplication(testRequirement())
+		if err != nil {
+			t.Fatalf("GenerateApplication failed: %v", err)
+		}
+		stale := filepath.Join(first, "stale.txt")
+		if err := os.WriteFile(stale, []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		second, err := cg.GenerateApplication(testRequirement())
+		if err != nil {
+			t.Fatalf("GenerateApplication failed: %v", err)
+		}
+		if second != first {
+			t.Errorf("expected the app to be regenerated into %s, got %s", first, second)
+		}
+		if _, err := os.Stat(stale); !os.IsNotExist(err) {
+			t.Error("expected files of the previous generation to be removed")
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		outputDir := t.TempDir()
+		cg := NewCodeGenerator(outputDir)
+		if err := cg.SetCollisionPolicy(CollisionError); err != nil {
+			t.Fatal(err)
+		}
+		appDir, err := cg.GenerateApplication(testRequirement())
+		if err != nil {
+			t.Fatalf("GenerateApplication failed: %v", err)
+		}
+		marker := filepath.Join(appDir, "marker.txt")
+		if err := os.WriteFile(marker, []byte("keep"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := cg.GenerateApplication(testRequirement()); !errors.Is(err, ErrAppExists) {
+			t.Fatalf("expected ErrAppExists, got %v", err)
+		}
+		if _, err := cg.ResolveAppDir(testRequirement()); !errors.Is(err, ErrAppExists) {
+			t.Errorf("expected ResolveAppDir to report ErrAppExists, got %v", err)
+		}
+		if _, err := os.Stat(marker); err != nil {
+			t.Error("expected the existing application to be left untouched")
+		}
+	})
+
+	t.Run("suffix", func(t *testing.T) {
+		outputDir := t.TempDir()
+		cg := NewCodeGenerator(outputDir)
+		if err := cg.SetCollisionPolicy(CollisionSuffix); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"test-app", "test-app-2", "test-app-3"} {
+			expected := filepath.Join(outputDir, name)
+			if resolved, err := cg.ResolveAppDir(testRequirement()); err != nil || resolved != expected {
+				t.Errorf("expected ResolveAppDir to return %s, got %s (%v)", expected, resolved, err)
+			}
+			var started string
+			appDir, err := cg.GenerateApplicationWith(testRequirement(), func(dir string) {
+				started = dir
+				if exists(dir) {
+					t.Errorf("expected started to be called before %s is written", dir)
+				}
+			})
+			if err != nil {
+				t.Fatalf("GenerateApplicationWith failed: %v", err)
+			}
+			if appDir != expected || started != expected {
+				t.Errorf("expected %s, got %s (started with %s)", expected, appDir, started)
+			}
+			if _, err := os.Stat(filepath.Join(appDir, "go.mod")); err != nil {
+				t.Errorf("expected the application in %s: %v", appDir, err)
+			}
+		}
+	})
+
+	if err := NewCodeGenerator(t.TempDir()).SetCollisionPolicy("rename"); err == nil {
+		t.Error("expected an unknown collision policy to be rejected")
+	}
+}
+
+func TestPlanApplication(t *testing.T) {
+	for _, language := range []string{"go", "javascript", "python"} {
+		t.Run(language, func(t *testing.T) {
+			appReq := testRequirement()
+			appReq.Language = language
+			appReq.Framework = ""
+
+			outputDir := t.TempDir()
+			cg := NewCodeGenerator(outputDir)
+			planned, err := cg.PlanApplication(appReq)
+			if err != nil {
+				t.Fatalf("PlanApplication failed: %v", err)
+			}
+			if entries, err := os.ReadDir(outputDir); err != nil || len(entries) != 0 {
+				t.Fatalf("expected planning to leave the output dir empty, got %v (%v)", entries, err)
+			}
+
+			if _, err := cg.GenerateApplication(appReq); err != nil {
+				t.Fatalf("GenerateApplication failed: %v", err)
+			}
+			appDir := filepath.Join(outputDir, "test-app")
+			var written []string
+			err = filepath.Walk(appDir, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(appDir, path)
+				written = append(written, filepath.ToSlash(rel))
+				return err
+			})
+			if err != nil {
+				t.Fatalf("failed to walk generated app: %v", err)
+			}
+			sort.Strings(written)
+
+			if strings.Join(planned, "\n") != strings.Join(written, "\n") {
+				t.Errorf("planned files differ from generated ones\nplanned: %v\nwritten: %v", planned, written)
+			}
+		})
+	}
+
+	// Limits apply to plans as they do to generation
+	cg := NewCodeGenerator(t.TempDir())
+	cg.SetLimits(3, 0)
+	if _, err := cg.PlanApplication(testRequirement()); !errors.Is(err, ErrGenerationLimit) {
+		t.Errorf("expected ErrGenerationLimit, got %v", err)
+	}
+}
+
+func TestGenerateGitignoreAndGoSum(t *testing.T) {
+	for _, appType := range []string{"api", "cli"} {
+		appReq := testRequirement()
+		appReq.Type = appType
+		appDir := generateTestApp(t, appReq)
+
+		if _, err := os.Stat(filepath.Join(appDir, "go.sum")); err != nil {
+			t.Errorf("%s: go.sum not generated: %v", appType, err)
+		}
+		gitignore, err := os.ReadFile(filepath.Join(appDir, ".gitignore"))
+		if err != nil {
+			t.Fatalf("%s: .gitignore not generated: %v", appType, err)
+		}
+		for _, pattern := range []string{"/test-app\n", "*.db\n", "test_results.json\n"} {
+			if !strings.Contains(string(gitignore), pattern) {
+				t.Errorf("%s: .gitignore does not contain %q:\n%s", appType, pattern, gitignore)
+			}
+		}
+	}
+
+	dockerfile, err := os.ReadFile(filepath.Join(generateTestApp(t, testRequirement()), "Dockerfile"))
+	if err != nil {
+		t.Fatalf("failed to read Dockerfile: %v", err)
+	}
+	if !strings.Contains(string(dockerfile), "RUN go mod tidy") {
+		t.Errorf("expected the Dockerfile to complete go.sum:\n%s", dockerfile)
+	}
+}
+
+func TestGenerateGoModFromAnalyzedRequirements(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+
+	analyzer := requirements.NewRequirementAnalyzer(nil)
+	for _, description := range []string{
+		"user management api in go with gin",
+		"product catalog api in go with echo",
+		"order tracking api in go with fiber",
+	} {
+		appReq, err := analyzer.AnalyzeRequirements(description)
+		if err != nil {
+			t.Fatalf("%s: AnalyzeRequirements failed: %v", description, err)
+		}
+		if len(appReq.Dependencies) == 0 {
+			t.Fatalf("%s: expected the analyzer to list framework dependencies", description)
+		}
+		appReq.Name = "Test App"
+		appReq.Dependencies = append(appReq.Dependencies, "github.com/google/uuid v1.6.0")
+		appDir := generateTestApp(t, appReq)
+
+		// go mod edit parses go.mod without downloading anything
+		cmd := exec.Command("go", "mod", "edit", "-json")
+		cmd.Dir = appDir
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Errorf("%s: go.mod does not parse: %v\n%s", description, err, output)
+			continue
+		}
+		var goMod struct {
+			Require []struct{ Path, Version string }
+		}
+		if err := json.Unmarshal(output, &goMod); err != nil {
+			t.Fatalf("%s: failed to decode go mod edit output: %v", description, err)
+		}
+		required := map[string]string{}
+		for _, require := range goMod.Require {
+			required[require.Path] = require.Version
+		}
+		if required["github.com/gin-gonic/gin"] == "" || required["github.com/google/uuid"] != "v1.6.0" {
+			t.Errorf("%s: expected gin and the versioned dependency to be required, got %v", description, required)
+		}
+	}
+}
+
+func TestGenerateSmokeTestScript(t *testing.T) {
+	appDir := generateTestApp(t, testRequirement())
+
+	scriptPath := filepath.Join(appDir, "scripts", "smoke_test.sh")
+	info, err := os.Stat(scriptPath)
+	if err != nil {
+		t.Fatalf("smoke test script not generated: %v", err)
+	}
+	if info.Mode()&0111 == 0 {
+		t.Error("smoke test script is not executable")
+	}
+
+	script, err := os.ReadFile(scriptPath)
+	if err != nil {
+		t.Fatalf("failed to read smoke test script: %v", err)
+	}
+	for _, expected := range []string{"request POST /api/users 201", `request DELETE "/api/users/$id" 200`, `"email":"user1@example.com"`} {
+		if !strings.Contains(string(script), expected) {
+			t.Errorf("smoke test script does not contain %s:\n%s", expected, script)
+		}
+	}
+
+	if _, err := exec.LookPath("sh"); err == nil {
+		if output, err := exec.Command("sh", "-n", scriptPath).CombinedOutput(); err != nil {
+			t.Errorf("smoke test script has syntax errors: %v\n%s", err, output)
+		}
+	}
+}
+
+func TestLoadTemplatesOverridesBuiltin(t *testing.T) {
+	templatesDir := t.TempDir()
+	override := "# Custom Dockerfile\nFROM scratch\nEXPOSE {{.Port}}\n"
+	if err := os.WriteFile(filepath.Join(templatesDir, "Dockerfile.tmpl"), []byte(override), 0644); err != nil {
+		t.Fatalf("failed to write override: %v", err)
+	}
+
+	outputDir := t.TempDir()
+	cg := NewCodeGenerator(outputDir)
+	if err := cg.LoadTemplates(templatesDir); err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+	if _, err := cg.GenerateApplication(testRequirement()); err != nil {
+		t.Fatalf("GenerateApplication failed: %v", err)
+	}
+
+	dockerfile, err := os.ReadFile(filepath.Join(outputDir, "test-app", "Dockerfile"))
+	if err != nil {
+		t.Fatalf("failed to read Dockerfile: %v", err)
+	}
+	if string(dockerfile) != "# Custom Dockerfile\nFROM scratch\nEXPOSE 8080\n" {
+		t.Errorf("override was not used:\n%s", dockerfile)
+	}
+
+	// Files without an override still use the built-in templates
+	parseGoFile(t, filepath.Join(outputDir, "test-app", "main.go"))
+}
+
+func TestLoadTemplatesValidation(t *testing.T) {
+	tests := map[string]string{
+		"unknown.go.tmpl": "package main\n",
+		"main.go.tmpl":    "package main {{.Broken",
+	}
+
+	for name, content := range tests {
+		templatesDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(templatesDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if err := NewCodeGenerator(t.TempDir()).LoadTemplates(templatesDir); err == nil {
+			t.Errorf("expected LoadTemplates to reject %s", name)
+		}
+	}
+}
+
+func TestLoadTemplatesLanguageDirectory(t *testing.T) {
+	templatesDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(templatesDir, "go"), 0755); err != nil {
+		t.Fatalf("failed to create go directory: %v", err)
+	}
+	override := "// Company standard entrypoint for {{.ModuleName}}\npackage main\n\nfunc main() {}\n"
+	if err := os.WriteFile(filepath.Join(templatesDir, "go", "main.go.tmpl"), []byte(override), 0644); err != nil {
+		t.Fatalf("failed to write override: %v", err)
+	}
+
+	outputDir := t.TempDir()
+	cg := NewCodeGenerator(outputDir)
+	if err := cg.LoadTemplates(templatesDir); err != nil {
+		t.Fatalf("LoadTemplates failed: %v", err)
+	}
+	if _, err := cg.GenerateApplication(testRequirement()); err != nil {
+		t.Fatalf("GenerateApplication failed: %v", err)
+	}
+
+	main, err := os.ReadFile(filepath.Join(outputDir, "test-app", "main.go"))
+	if err != nil {
+		t.Fatalf("failed to read main.go: %v", err)
+	}
+	if string(main) != "// Company standard entrypoint for test-app\npackage main\n\nfunc main() {}\n" {
+		t.Errorf("override was not used:\n%s", main)
+	}
+	parseGoFile(t, filepath.Join(outputDir, "test-app", "internal", "routes", "routes.go"))
+
+	tests := map[string]string{
+		"wrong language": filepath.Join("python", "main.go.tmpl"),
+		"unknown dir":    filepath.Join("cobol", "main.go.tmpl"),
+		"twice":          "main.go.tmpl",
+	}
+	for name, path := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range []string{filepath.Join("go", "main.go.tmpl"), path} {
+				os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755)
+				if err := os.WriteFile(filepath.Join(dir, file), []byte("package main\n"), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", file, err)
+				}
+			}
+			if err := NewCodeGenerator(t.TempDir()).LoadTemplates(dir); err == nil {
+				t.Errorf("expected LoadTemplates to reject %s", path)
+			}
+		})
+	}
+}
+
+func TestGenerateAuthStrategies(t *testing.T) {
+	tests := []struct {
+		strategy   string
+		middleware string // declaration expected in internal/middleware/auth.go
+		scheme     string
+		schemeType string
+	}{
+		{"none", "", "", ""},
+		{"apikey", "func Auth()", "ApiKeyAuth", "apiKey"},
+		{"jwt", "func ParseToken(", "BearerAuth", "http"},
+		{"oauth2", "func OAuthCallback(", "OAuth2", "oauth2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			appReq := testRequirement()
+			appReq.AuthStrategy = tt.strategy
+			appDir := generateTestApp(t, appReq)
+
+			routes, err := os.ReadFile(filepath.Join(appDir, "internal", "routes", "routes.go"))
+			if err != nil {
+				t.Fatalf("failed to read routes.go: %v", err)
+			}
+			parseGoFile(t, filepath.Join(appDir, "internal", "routes", "routes.go"))
+
+			middlewarePath := filepath.Join(appDir, "internal", "middleware", "auth.go")
+			if tt.middleware == "" {
+				if _, err := os.Stat(middlewarePath); !os.IsNotExist(err) {
+					t.Error("auth middleware generated without an auth strategy")
+				}
+				if strings.Contains(string(routes), "middleware.Auth()") {
+					t.Error("routes use auth middleware without an auth strategy")
+				}
+			} else {
+				parseGoFile(t, middlewarePath)
+				middleware, err := os.ReadFile(middlewarePath)
+				if err != nil {
+					t.Fatalf("failed to read auth.go: %v", err)
+				}
+				if !strings.Contains(string(middleware), tt.middleware) {
+					t.Errorf("auth.go does not declare %s:\n%s", tt.middleware, middleware)
+				}
+				if !strings.Contains(string(routes), "api.Use(middleware.Auth())") {
+					t.Errorf("routes do not protect the API:\n%s", routes)
+				}
+			}
+
+			hasCallback := strings.Contains(string(routes), `r.GET("/auth/callback", middleware.OAuthCallback)`)
+			if hasCallback != (tt.strategy == "oauth2") {
+				t.Errorf("OAuth2 callback route present = %v for strategy %s", hasCallback, tt.strategy)
+			}
+
+			specData, err := os.ReadFile(filepath.Join(appDir, "openapi.json"))
+			if err != nil {
+				t.Fatalf("failed to read openapi.json: %v", err)
+			}
+			var spec struct {
+				Components struct {
+					SecuritySchemes map[string]struct {
+						Type string `json:"type"`
+					} `json:"securitySchemes"`
+				} `json:"components"`
+				Security []map[string][]string `json:"security"`
+			}
+			if err := json.Unmarshal(specData, &spec); err != nil {
+				t.Fatalf("invalid openapi.json: %v", err)
+			}
+
+			schemes := spec.Components.SecuritySchemes
+			if tt.scheme == "" {
+				if len(schemes) != 0 || len(spec.Security) != 0 {
+					t.Errorf("expected no security definitions, got %v / %v", schemes, spec.Security)
+				}
+				return
+			}
+			if len(schemes) != 1 || schemes[tt.scheme].Type != tt.schemeType {
+				t.Errorf("expected security scheme %s of type %s, got %+v", tt.scheme, tt.schemeType, schemes)
+			}
+			if len(spec.Security) != 1 {
+				t.Fatalf("expected one global security requirement, got %v", spec.Security)
+			}
+			if _, ok := spec.Security[0][tt.scheme]; !ok {
+				t.Errorf("global security does not reference %s: %v", tt.scheme, spec.Security)
+			}
+		})
+	}
+}
+
+func TestGenerateAuthenticationFeature(t *testing.T) {
+	appReq := testRequirement()
+	appReq.Features = []string{"user_management", "authentication"}
+	appReq.Entities[0].Fields = append(appReq.Entities[0].Fields, requirements.EntityField{Name: "password", Type: "string", Required: true})
+	appDir := generateTestApp(t, appReq)
+
+	middleware, err := os.ReadFile(filepath.Join(appDir, "internal", "middleware", "auth.go"))
+	if err != nil {
+		t.Fatalf("expected the authentication feature to generate auth middleware: %v", err)
+	}
+	for _, want := range []string{"func Auth()", "func ParseToken(", "func IssueToken("} {
+		if !strings.Contains(string(middleware), want) {
+			t.Errorf("auth.go does not declare %s", want)
+		}
+	}
+
+	routes, err := os.ReadFile(filepath.Join(appDir, "internal", "routes", "routes.go"))
+	if err != nil {
+		t.Fatalf("failed to read routes.go: %v", err)
+	}
+	for _, want := range []string{"api.Use(middleware.Auth())", `r.POST("/auth/register", h.Register)`, `r.POST("/auth/login", h.Login)`} {
+		if !strings.Contains(string(routes), want) {
+			t.Errorf("routes.go does not contain %s:\n%s", want, routes)
+		}
+	}
+	// Login must stay reachable without a token
+	if strings.Index(string(routes), "h.Login") > strings.Index(string(routes), "api.Use(") {
+		t.Errorf("login route is registered inside the protected group:\n%s", routes)
+	}
+
+	handlersPath := filepath.Join(appDir, "internal", "handlers", "auth.go")
+	handlers := parseGoFile(t, handlersPath)
+	declared := map[string]bool{}
+	for _, decl := range handlers.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			declared[fn.Name.Name] = true
+		}
+	}
+	for _, name := range []string{"Register", "Login", "findUser", "hashPassword", "checkPassword"} {
+		if !declared[name] {
+			t.Errorf("handlers/auth.go does not declare %s", name)
+		}
+	}
+	source, err := os.ReadFile(handlersPath)
+	if err != nil {
+		t.Fatalf("failed to read handlers/auth.go: %v", err)
+	}
+	if formatted, err := format.Source(source); err != nil || string(formatted) != string(source) {
+		t.Errorf("handlers/auth.go is not gofmt-formatted (%v)", err)
+	}
+
+	// Without a password field there is nothing to log in with, but the API
+	// is still protected
+	appReq = testRequirement()
+	appReq.Features = []string{"authentication"}
+	appDir = generateTestApp(t, appReq)
+	if _, err := os.Stat(filepath.Join(appDir, "internal", "handlers", "auth.go")); !os.IsNotExist(err) {
+		t.Error("login handlers generated for a User without a password")
+	}
+	if _, err := os.Stat(filepath.Join(appDir, "internal", "middleware", "auth.go")); err != nil {
+		t.Errorf("expected auth middleware: %v", err)
+	}
+
+	// An explicit strategy wins over the feature
+	appReq.AuthStrategy = "none"
+	appDir = generateTestApp(t, appReq)
+	if _, err := os.Stat(filepath.Join(appDir, "internal", "middleware", "auth.go")); !os.IsNotExist(err) {
+		t.Error("auth middleware generated with auth_strategy none")
+	}
+}
+
+func TestGenerateCompressionMiddleware(t *testing.T) {
+	appDir := generateTestApp(t, testRequirement())
+
+	// The gzip middleware must be registered on the router before routes.Setup
+	var gzipPos, setupPos token.Pos
+	ast.Inspect(parseGoFile(t, filepath.Join(appDir, "main.go")), func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, _ := sel.X.(*ast.Ident)
+		switch {
+		case pkg != nil && pkg.Name == "gzip" && sel.Sel.Name == "Gzip":
+			gzipPos = call.Pos()
+		case pkg != nil && pkg.Name == "routes" && sel.Sel.Name == "Setup":
+			setupPos = call.Pos()
+		}
+		return true
+	})
+	if gzipPos == token.NoPos {
+		t.Fatal("main.go does not register the gzip middleware")
+	}
+	if setupPos == token.NoPos || gzipPos > setupPos {
+		t.Error("gzip middleware must be registered ahead of routes.Setup")
+	}
+
+	config, err := os.ReadFile(filepath.Join(appDir, "internal", "config", "config.go"))
+	if err != nil {
+		t.Fatalf("failed to read config.go: %v", err)
+	}
+	if !strings.Contains(string(config), `"ENABLE_COMPRESSION"`) {
+		t.Error("config does not read ENABLE_COMPRESSION")
+	}
+	goMod, err := os.ReadFile(filepath.Join(appDir, "go.mod"))
+	if err != nil {
+		t.Fatalf("failed to read go.mod: %v", err)
+	}
+	if !strings.Contains(string(goMod), "github.com/gin-contrib/gzip") {
+		t.Error("go.mod does not require gin-contrib/gzip")
+	}
+	readme, err := os.ReadFile(filepath.Join(appDir, "README.md"))
+	if err != nil {
+		t.Fatalf("failed to read README.md: %v", err)
+	}
+	if !strings.Contains(string(readme), "ENABLE_COMPRESSION") {
+		t.Error("README does not document ENABLE_COMPRESSION")
+	}
+}
+
+func TestGenerateJavaScriptHealthcheck(t *testing.T) {
+	appReq := testRequirement()
+	appReq.Language = "javascript"
+	appReq.Framework = "express"
+	appDir := generateTestApp(t, appReq)
+
+	dockerfile, err := os.ReadFile(filepath.Join(appDir, "Dockerfile"))
+	if err != nil {
+		t.Fatalf("failed to read Dockerfile: %v", err)
+	}
+	if !strings.Contains(string(dockerfile), "CMD node healthcheck.js") {
+		t.Fatal("Dockerfile does not run healthcheck.js")
+	}
+	script := filepath.Join(appDir, "healthcheck.js")
+	if _, err := os.Stat(script); err != nil {
+		t.Fatalf("healthcheck.js referenced by the Dockerfile was not generated: %v", err)
+	}
+	app, err := os.ReadFile(filepath.Join(appDir, "app.js"))
+	if err != nil {
+		t.Fatalf("failed to read app.js: %v", err)
+	}
+	if !strings.Contains(string(app), "app.get('/health'") {
+		t.Error("app.js does not serve /health")
+	}
+
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not available")
+	}
+
+	// The script passes against a healthy server and fails once it is gone
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+	runHealthcheck := func() error {
+		cmd := exec.Command("node", script)
+		cmd.Env = append(os.Environ(), "PORT="+port)
+		return cmd.Run()
+	}
+	if err := runHealthcheck(); err != nil {
+		t.Errorf("expected the health check to pass against a healthy server: %v", err)
+	}
+	server.Close()
+	if err := runHealthcheck(); err == nil {
+		t.Error("expected the health check to fail without a server")
+	}
+}
+
+func TestGenerateJavaScriptControllersUseModels(t *testing.T) {
+	appReq := testRequirement()
+	appReq.Language = "javascript"
+	appReq.Framework = "express"
+	appDir := generateTestApp(t, appReq)
+
+	controller, err := os.ReadFile(filepath.Join(appDir, "controllers", "userController.js"))
+	if err != nil {
+		t.Fatalf("failed to read controller: %v", err)
+	}
+	for _, call := range []string{
+		"require('../models/User')",
+		"await User.findAll()",
+		"await User.findById(id)",
+		"await User.create(",
+		"await User.update(id, updateData)",
+		"await User.delete(id)",
+	} {
+		if !strings.Contains(string(controller), call) {
+			t.Errorf("controller does not contain %q", call)
+		}
+	}
+	if strings.Contains(string(controller), "TODO") {
+		t.Error("controller still contains TODO stubs")
+	}
+
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not available")
+	}
+
+	// Drive the controller with stub requests to check a created user is listed
+	script := `
+const UserController = require('./controllers/userController');
+const call = (handler, req) => new Promise((resolve) => {
+  const res = { statusCode: 200 };
+  res.status = (code) => { res.statusCode = code; return res; };
+  res.json = (body) => resolve({ status: res.statusCode, body });
+  handler(req, res);
+});
+(async () => {
+  const created = await call(UserController.create, { body: { username: 'alice', email: 'alice@example.com' } });
+  const list = await call(UserController.getAll, {});
+  const found = await call(UserController.getById, { params: { id: String(created.body.data.id) } });
+  console.log(JSON.stringify({ created, list, found }));
+})();
+`
+	cmd := exec.Command("node", "-e", script)
+	cmd.Dir = appDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to run the controller: %v\n%s", err, output)
+	}
+
+	type response struct {
+		Status int `json:"status"`
+		Body   struct {
+			Data  json.RawMessage `json:"data"`
+			Count int             `json:"count"`
+		} `json:"body"`
+	}
+	var result struct {
+		Created response `json:"created"`
+		List    response `json:"list"`
+		Found   response `json:"found"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		t.Fatalf("failed to parse controller output %s: %v", output, err)
+	}
+	if result.Created.Status != 201 {
+		t.Errorf("expected create to return 201, got %d: %s", result.Created.Status, result.Created.Body.Data)
+	}
+	if result.List.Status != 200 || result.List.Body.Count != 1 {
+		t.Errorf("expected the created user to be listed, got %d with %d users", result.List.Status, result.List.Body.Count)
+	}
+	if result.Found.Status != 200 || !strings.Contains(string(result.Found.Body.Data), `"username":"alice"`) {
+		t.Errorf("expected the created user to be found by ID, got %d: %s", result.Found.Status, result.Found.Body.Data)
+	}
+}
+
+func TestGenerateJavaScriptDatabaseDrivers(t *testing.T) {
+	tests := []struct {
+		database string
+		driver   string // npm package, empty for in-memory models
+		require  string
+		query    string
+	}{
+		{"postgresql", "pg", "require('pg')", "SELECT * FROM users WHERE id = $1"},
+		{"mysql", "mysql2", "require('mysql2/promise')", "SELECT * FROM users WHERE id = ?"},
+		{"sqlite", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.database, func(t *testing.T) {
+			appReq := testRequirement()
+			appReq.Language = "javascript"
+			appReq.Framework = "express"
+			appReq.Database = tt.database
+			appDir := generateTestApp(t, appReq)
+
+			var pkg struct {
+				Scripts      map[string]string `json:"scripts"`
+				Dependencies map[string]string `json:"dependencies"`
+			}
+			data, err := os.ReadFile(filepath.Join(appDir, "package.json"))
+			if err != nil {
+				t.Fatalf("failed to read package.json: %v", err)
+			}
+			if err := json.Unmarshal(data, &pkg); err != nil {
+				t.Fatalf("package.json is not valid JSON: %v", err)
+			}
+			model, err := os.ReadFile(filepath.Join(appDir, "models", "User.js"))
+			if err != nil {
+				t.Fatalf("failed to read model: %v", err)
+			}
+
+			if tt.driver == "" {
+				if _, err := os.Stat(filepath.Join(appDir, "config", "database.js")); !os.IsNotExist(err) {
+					t.Error("in-memory apps should not get a database module")
+				}
+				if !strings.Contains(string(model), "new Map()") {
+					t.Error("model without a database should keep records in memory")
+				}
+				return
+			}
+
+			if _, ok := pkg.Dependencies[tt.driver]; !ok {
+				t.Errorf("package.json does not depend on %s: %v", tt.driver, pkg.Dependencies)
+			}
+			if pkg.Scripts["migrate"] != "node config/migrate.js" {
+				t.Errorf("package.json has no migrate script: %v", pkg.Scripts)
+			}
+			database, err := os.ReadFile(filepath.Join(appDir, "config", "database.js"))
+			if err != nil {
+				t.Fatalf("failed to read database.js: %v", err)
+			}
+			if !strings.Contains(string(database), tt.require) {
+				t.Errorf("database.js does not import %s:\n%s", tt.require, database)
+			}
+			for _, other := range tests {
+				if other.require != "" && other.require != tt.require && strings.Contains(string(database), other.require) {
+					t.Errorf("database.js also imports %s", other.require)
+				}
+			}
+			if !strings.Contains(string(model), "require('../config/database')") || !strings.Contains(string(model), tt.query) {
+				t.Errorf("model does not query the database with %q:\n%s", tt.query, model)
+			}
+			if strings.Contains(string(model), "new Map()") {
+				t.Error("model still keeps records in memory")
+			}
+			migrate, err := os.ReadFile(filepath.Join(appDir, "config", "migrate.js"))
+			if err != nil {
+				t.Fatalf("failed to read migrate.js: %v", err)
+			}
+			if !strings.Contains(string(migrate), "CREATE TABLE IF NOT EXISTS users") {
+				t.Errorf("migrate.js does not create the users table:\n%s", migrate)
+			}
+			app, err := os.ReadFile(filepath.Join(appDir, "app.js"))
+			if err != nil {
+				t.Fatalf("failed to read app.js: %v", err)
+			}
+			if !strings.Contains(string(app), "db.connect().then(() => migrate())") {
+				t.Error("app.js does not migrate the database before serving")
+			}
+
+			if _, err := exec.LookPath("node"); err != nil {
+				return
+			}
+			for _, file := range []string{"app.js", "config/database.js", "config/migrate.js", "models/User.js"} {
+				if output, err := exec.Command("node", "--check", filepath.Join(appDir, file)).CombinedOutput(); err != nil {
+					t.Errorf("%s is not valid JavaScript: %v\n%s", file, err, output)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateJavaScriptCompression(t *testing.T) {
+	appReq := testRequirement()
+	appReq.Language = "javascript"
+	appReq.Framework = "express"
+	appReq.Dependencies = []string{"express", "cors", "helmet", "morgan"}
+	appDir := generateTestApp(t, appReq)
+
+	app, err := os.ReadFile(filepath.Join(appDir, "app.js"))
+	if err != nil {
+		t.Fatalf("failed to read app.js: %v", err)
+	}
+	register := strings.Index(string(app), "app.use(compression())")
+	routes := strings.Index(string(app), "app.use('/api/")
+	if register < 0 {
+		t.Fatal("app.js does not register the compression middleware")
+	}
+	if routes < 0 || register > routes {
+		t.Error("compression middleware must be registered ahead of the routes")
+	}
+	if !strings.Contains(string(app), "process.env.ENABLE_COMPRESSION") {
+		t.Error("compression is not configurable through ENABLE_COMPRESSION")
+	}
+
+	var pkg struct {
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	data, err := os.ReadFile(filepath.Join(appDir, "package.json"))
+	if err != nil {
+		t.Fatalf("failed to read package.json: %v", err)
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		t.Fatalf("package.json is not valid JSON: %v", err)
+	}
+	if _, ok := pkg.Dependencies["compression"]; !ok {
+		t.Error("package.json does not depend on compression")
+	}
+
+	readme, err := os.ReadFile(filepath.Join(appDir, "README.md"))
+	if err != nil {
+		t.Fatalf("failed to read README.md: %v", err)
+	}
+	if !strings.Contains(string(readme), "ENABLE_COMPRESSION") {
+		t.Error("README does not document ENABLE_COMPRESSION")
+	}
+}
+
+func TestSupportedCombinationsGenerate(t *testing.T) {
+	// A file each framework's application is recognized by
+	markers := map[string][2]string{
+		"gin":     {"main.go", "package main"},
+		"gqlgen":  {"graph/schema.graphql", "type User"},
+		"express": {"package.json", "express"},
+		"flask":   {"requirements.txt", "flask"},
+		"fastapi": {"requirements.txt", "fastapi"},
+	}
+
+	ra := requirements.NewRequirementAnalyzer(nil)
+	for _, language := range requirements.SupportedLanguages() {
+		for _, framework := range requirements.SupportedFrameworks[language] {
+			validated := 0
+			for _, appType := range requirements.SupportedLanguageTypes[language] {
+				appReq := testRequirement()
+				appReq.Language, appReq.Framework, appReq.Type = language, framework, appType
+				if ra.ValidateRequirements(appReq) != nil {
+					// gqlgen and graphql only go together
+					continue
+				}
+				validated++
+
+				t.Run(language+"-"+framework+"-"+appType, func(t *testing.T) {
+					appDir := generateTestApp(t, appReq)
+					marker, ok := markers[framework]
+					if !ok {
+						t.Fatalf("no marker for framework %s", framework)
+					}
+					if appType == "cli" {
+						marker = [2]string{"main.go", "cmd.Execute()"}
+					}
+					content, err := os.ReadFile(filepath.Join(appDir, marker[0]))
+					if err != nil || !strings.Contains(string(content), marker[1]) {
+						t.Errorf("expected %s to contain %q (%v)", marker[0], marker[1], err)
+					}
+				})
+			}
+			if validated == 0 {
+				t.Errorf("framework %s of %s validates for no application type", framework, language)
+			}
+		}
+	}
+}
+
+func TestGenerateSavesRequirements(t *testing.T) {
+	appReq := testRequirement()
+	appReq.Description = "user directory"
+	appDir := generateTestApp(t, appReq)
+
+	loaded, err := requirements.LoadFromFile(filepath.Join(appDir, requirements.RequirementsFile))
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if loaded.Name != appReq.Name || loaded.Description != "user directory" || loaded.Type != "api" || loaded.Language != "go" {
+		t.Errorf("loaded requirements do not match: %+v", loaded)
+	}
+	if len(loaded.Entities) != 1 || len(loaded.Entities[0].Fields) != len(appReq.Entities[0].Fields) {
+		t.Errorf("loaded entities do not match: %+v", loaded.Entities)
+	}
+}
+
+func TestGeneratePythonApplication(t *testing.T) {
+	for _, framework := range []string{"flask", "fastapi"} {
+		appReq := testRequirement()
+		appReq.Language = "python"
+		appReq.Framework = framework
+		appReq.Entities[0].Fields = append(appReq.Entities[0].Fields,
+			requirements.EntityField{Name: "balance", Type: "float", Required: true},
+			requirements.EntityField{Name: "active", Type: "bool"},
+		)
+		appDir := generateTestApp(t, appReq)
+
+		expected := []string{"app.py", "requirements.txt", "database.py", "Dockerfile", "test_app.py",
+			filepath.Join("models", "__init__.py"), filepath.Join("models", "user.py"), filepath.Join("routes", "user.py")}
+		if framework == "fastapi" {
+			expected = append(expected, filepath.Join("schemas", "user.py"))
+		} else {
+			expected = append(expected, "validation.py")
+		}
+		for _, name := range expected {
+			if _, err := os.Stat(filepath.Join(appDir, name)); err != nil {
+				t.Errorf("%s: expected %s to be generated", framework, name)
+			}
+		}
+
+		deps, err := os.ReadFile(filepath.Join(appDir, "requirements.txt"))
+		if err != nil {
+			t.Fatalf("failed to read requirements.txt: %v", err)
+		}
+		if !strings.Contains(string(deps), framework) {
+			t.Errorf("%s: requirements.txt does not list the framework:\n%s", framework, deps)
+		}
+
+		// Field types map through to the Flask field table or the pydantic schema
+		typed, declaration := filepath.Join(appDir, "routes", "user.py"), `"balance": (float, True)`
+		if framework == "fastapi" {
+			typed, declaration = filepath.Join(appDir, "schemas", "user.py"), "balance: float"
+		}
+		content, err := os.ReadFile(typed)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", typed, err)
+		}
+		if !strings.Contains(string(content), declaration) {
+			t.Errorf("%s: expected %q in %s:\n%s", framework, declaration, typed, content)
+		}
+
+		if _, err := exec.LookPath("python3"); err != nil {
+			continue
+		}
+		var sources []string
+		filepath.Walk(appDir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && strings.HasSuffix(path, ".py") {
+				sources = append(sources, path)
+			}
+			return nil
+		})
+		cmd := exec.Command("python3", append([]string{"-m", "py_compile"}, sources...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("%s: generated Python does not compile: %v\n%s", framework, err, output)
+		}
+	}
+}
+
+func TestGenerateGoCLIApplication(t *testing.T) {
+	appReq := testRequirement()
+	appReq.Type = "cli"
+	appReq.Entities[0].Fields = append(appReq.Entities[0].Fields,
+		requirements.EntityField{Name: "age", Type: "int"},
+		requirements.EntityField{Name: "active", Type: "bool"},
+	)
+	appDir := generateTestApp(t, appReq)
+
+	goMod, err := os.ReadFile(filepath.Join(appDir, "go.mod"))
+	if err != nil {
+		t.Fatalf("failed to read go.mod: %v", err)
+	}
+	if !strings.Contains(string(goMod), "github.com/spf13/cobra") || strings.Contains(string(goMod), "gin-gonic") {
+		t.Errorf("expected go.mod to require cobra instead of gin:\n%s", goMod)
+	}
+
+	parseGoFile(t, filepath.Join(appDir, "main.go"))
+	parseGoFile(t, filepath.Join(appDir, "cmd", "root.go"))
+	commands := parseGoFile(t, filepath.Join(appDir, "cmd", "user.go"))
+
+	// Collect the subcommand names and the flag registrations by type
+	uses := map[string]bool{}
+	flags := map[string]string{}
+	ast.Inspect(commands, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.KeyValueExpr:
+			if key, ok := node.Key.(*ast.Ident); ok && key.Name == "Use" {
+				if lit, ok := node.Value.(*ast.BasicLit); ok {
+					uses[strings.Fields(strings.Trim(lit.Value, `"`))[0]] = true
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok || !strings.HasSuffix(sel.Sel.Name, "Var") || len(node.Args) < 2 {
+				return true
+			}
+			if lit, ok := node.Args[1].(*ast.BasicLit); ok {
+				flags[strings.Trim(lit.Value, `"`)] = sel.Sel.Name
+			}
+		}
+		return true
+	})
+
+	for _, use := range []string{"user", "create", "list", "get", "update", "delete"} {
+		if !uses[use] {
+			t.Errorf("expected a %q command, got %v", use, uses)
+		}
+	}
+	expectedFlags := map[string]string{
+		"username": "StringVar",
+		"email":    "StringVar",
+		"age":      "IntVar",
+		"active":   "BoolVar",
+	}
+	for flag, function := range expectedFlags {
+		if flags[flag] != function {
+			t.Errorf("expected --%s registered with %s, got %q", flag, function, flags[flag])
+		}
+	}
+	if _, ok := flags["id"]; ok {
+		t.Error("id is assigned by the database and should not be a flag")
+	}
+}
+
+// relationRequirement returns users owning posts, with posts tagged many-to-many
+func relationRequirement() *requirements.ApplicationRequirement {
+	appReq := testRequirement()
+	appReq.Entities[0].Relations = []requirements.EntityRelation{{Type: "one-to-many", Target: "Post"}}
+	appReq.Entities = append(appReq.Entities,
+		requirements.Entity{
+			Name: "Post",
+			Fields: []requirements.EntityField{
+				{Name: "id", Type: "int", Required: true},
+				{Name: "title", Type: "string", Required: true},
+			},
+			Relations:  []requirements.EntityRelation{{Type: "many-to-many", Target: "Tag"}},
+			Operations: []string{"create", "read", "update", "delete"},
+		},
+		requirements.Entity{
+			Name: "Tag",
+			Fields: []requirements.EntityField{
+				{Name: "id", Type: "int", Required: true},
+				{Name: "label", Type: "string", Required: true},
+			},
+			// Declaring the inverse side must reuse the same join table
+			Relations:  []requirements.EntityRelation{{Type: "many-to-many", Target: "post"}},
+			Operations: []string{"create", "read", "update", "delete"},
+		},
+	)
+	return appReq
+}
+
+func TestGeneratedModelsCompile(t *testing.T) {
+	appDir := generateTestApp(t, relationRequirement())
+
+	models, err := os.ReadFile(filepath.Join(appDir, "internal", "models", "user.go"))
+	if err != nil {
+		t.Fatalf("failed to read user.go: %v", err)
+	}
+	for _, want := range []string{".Scan(&user.ID, &user.Username, &user.Email, &user.CreatedAt)", "db.Exec(query, user.Username, user.Email, user.CreatedAt)", "user.ID = int(id)"} {
+		if !strings.Contains(string(models), want) {
+			t.Errorf("expected user model to contain %q", want)
+		}
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	// Build the models on their own so the check needs no third-party modules
+	moduleDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte("module modelcheck\n\ngo 1.18\n"), 0644); err != nil {
+		t.Fatalf("failed to write go.mod: %v", err)
+	}
+	sources, err := filepath.Glob(filepath.Join(appDir, "internal", "models", "*.go"))
+	if err != nil || len(sources) == 0 {
+		t.Fatalf("no model files generated: %v", err)
+	}
+	for _, source := range sources {
+		content, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", source, err)
+		}
+		if err := os.WriteFile(filepath.Join(moduleDir, filepath.Base(source)), content, 0644); err != nil {
+			t.Fatalf("failed to copy %s: %v", source, err)
+		}
+	}
+
+	cmd := exec.Command("go", "build", "./...")
+	cmd.Dir = moduleDir
+	cmd.Env = append(os.Environ(), "GOPROXY=off", "GOFLAGS=-mod=mod", "GOWORK=off")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated models do not compile: %v\n%s", err, output)
+	}
+}
+
+func TestGeneratedListPagination(t *testing.T) {
+	appDir := generateTestApp(t, testRequirement())
+
+	model, err := os.ReadFile(filepath.Join(appDir, "internal", "models", "user.go"))
+	if err != nil {
+		t.Fatalf("failed to read user.go: %v", err)
+	}
+	for _, want := range []string{
+		"func GetAllUsersPaged(db *sql.DB, limit, offset int, sort string) ([]User, int, error)",
+		"SELECT COUNT(*) FROM users",
+		"LIMIT ? OFFSET ?",
+		`"username": true`,
+	} {
+		if !strings.Contains(string(model), want) {
+			t.Errorf("expected user model to contain %q", want)
+		}
+	}
+
+	handler, err := os.ReadFile(filepath.Join(appDir, "internal", "handlers", "user_handler.go"))
+	if err != nil {
+		t.Fatalf("failed to read user_handler.go: %v", err)
+	}
+	for _, want := range []string{"pageParams(c, models.UserSortFields)", "h.Repos.User.GetPage(limit, offset, sort)", "respondList(c, users, total, limit, offset)"} {
+		if !strings.Contains(string(handler), want) {
+			t.Errorf("expected user handler to contain %q", want)
+		}
+	}
+
+	base, err := os.ReadFile(filepath.Join(appDir, "internal", "handlers", "handler.go"))
+	if err != nil {
+		t.Fatalf("failed to read handler.go: %v", err)
+	}
+	for _, want := range []string{`c.Query("limit")`, `c.Query("offset")`, `c.Query("sort")`} {
+		if !strings.Contains(string(base), want) {
+			t.Errorf("expected handler.go to read %s", want)
+		}
+	}
+
+	// Postgres queries number their placeholders
+	appReq := testRequirement()
+	appReq.Database = "postgresql"
+	model, err = os.ReadFile(filepath.Join(generateTestApp(t, appReq), "internal", "models", "user.go"))
+	if err != nil {
+		t.Fatalf("failed to read user.go: %v", err)
+	}
+	if !strings.Contains(string(model), "LIMIT $1 OFFSET $2") {
+		t.Error("expected numbered LIMIT and OFFSET placeholders for PostgreSQL")
+	}
+}
+
+// ginStub declares the parts of gin the generated handlers and auth middleware
+// use, so they compile without downloading gin
+const ginStub = `package gin
+
+import "net/http"
+
+type H map[string]interface{}
+
+type HandlerFunc func(*Context)
+
+type Context struct{ Request *http.Request }
+
+func (c *Context) JSON(code int, obj interface{})                {}
+func (c *Context) AbortWithStatusJSON(code int, obj interface{}) {}
+func (c *Context) Param(key string) string                      { return "" }
+func (c *Context) Query(key string) string                      { return "" }
+func (c *Context) GetHeader(key string) string                  { return "" }
+func (c *Context) ShouldBindJSON(obj interface{}) error         { return nil }
+func (c *Context) Set(key string, value interface{})            {}
+func (c *Context) Next()                                        {}
+`
+
+// isIdent reports whether expr is the identifier name
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+func TestGenerateResponseEnvelope(t *testing.T) {
+	appReq := testRequirement()
+	appReq.AuthStrategy = "jwt"
+	appReq.Entities[0].Fields = append(appReq.Entities[0].Fields, requirements.EntityField{Name: "password", Type: "string", Required: true})
+	appReq.Entities = append(appReq.Entities, requirements.Entity{
+		Name:       "Product",
+		Fields:     []requirements.EntityField{{Name: "id", Type: "int", Required: true}, {Name: "name", Type: "string", Required: true}},
+		Operations: []string{"create", "read", "update", "delete"},
+	})
+	appReq.Config["response_style"] = "envelope"
+	appDir := generateTestApp(t, appReq)
+	handlersDir := filepath.Join(appDir, "internal", "handlers")
+
+	// Only the helpers in handler.go write responses, and they write envelopes
+	files, err := filepath.Glob(filepath.Join(handlersDir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handlers := 0
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		name := filepath.Base(path)
+		ast.Inspect(parseGoFile(t, path), func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "JSON" {
+				if name != "handler.go" {
+					t.Errorf("%s writes a response without the envelope helpers", name)
+				} else if lit, ok := call.Args[1].(*ast.CompositeLit); !ok || !isIdent(lit.Type, "Envelope") {
+					t.Errorf("handler.go writes a response that is not an Envelope")
+				}
+			}
+			if ident, ok := call.Fun.(*ast.Ident); ok && strings.HasPrefix(ident.Name, "respond") {
+				handlers++
+			}
+			return true
+		})
+	}
+	if handlers == 0 {
+		t.Fatal("expected the handlers to respond through the envelope helpers")
+	}
+
+	handlerTest, err := os.ReadFile(filepath.Join(handlersDir, "product_handler_test.go"))
+	if err != nil {
+		t.Fatalf("failed to read product_handler_test.go: %v", err)
+	}
+	if !strings.Contains(string(handlerTest), "page.Meta.Total != 1") {
+		t.Error("expected the handler test to read the total from the list metadata")
+	}
+
+	var spec struct {
+		Paths map[string]openAPIPathItem `yaml:"paths"`
+	}
+	data, err := os.ReadFile(filepath.Join(appDir, "openapi.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read openapi.yaml: %v", err)
+	}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("openapi.yaml does not parse: %v", err)
+	}
+	list := spec.Paths["/api/products"].Get.Responses["200"]
+	for _, key := range []string{"content", "application/json", "schema", "properties", "meta", "properties", "total"} {
+		next, ok := list.(map[string]interface{})[key]
+		if !ok {
+			t.Fatalf("expected the list response schema to have meta.total, missing %s in %v", key, list)
+		}
+		list = next
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	ginDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(ginDir, "go.mod"), []byte("module github.com/gin-gonic/gin\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ginDir, "gin.go"), []byte(ginStub), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"mod", "edit", "-replace", "github.com/gin-gonic/gin=" + ginDir},
+		{"build", "./internal/handlers"},
+	} {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = appDir
+		cmd.Env = append(os.Environ(), "GOPROXY=off", "GOFLAGS=-mod=mod", "GOWORK=off")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %s failed: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+}
+
+func TestValidatedDefaultsGenerateValidGo(t *testing.T) {
+	appReq := testRequirement()
+	appReq.Entities = append(appReq.Entities, requirements.Entity{
+		Name: "Member",
+		Fields: []requirements.EntityField{
+			{Name: "id", Type: "int", Required: true},
+			{Name: "title", Type: "string", Default: `it's "new"`},
+			{Name: "balance", Type: "float", Default: "-0.5"},
+		},
+		Operations: []string{"create", "read", "update", "delete"},
+	})
+	if err := requirements.NewRequirementAnalyzer(nil).ValidateRequirements(appReq); err != nil {
+		t.Fatalf("ValidateRequirements failed: %v", err)
+	}
+	appDir := generateTestApp(t, appReq)
+	parseGoFile(t, filepath.Join(appDir, "internal", "database", "database.go"))
+	parseGoFile(t, filepath.Join(appDir, "internal", "models", "member.go"))
+}
+
+// defaultsEntity returns an entity with column defaults and both timestamps
+func defaultsEntity() requirements.Entity {
+	return requirements.Entity{
+		Name: "Member",
+		Fields: []requirements.EntityField{
+			{Name: "id", Type: "int", Required: true},
+			{Name: "name", Type: "string", Required: true},
+			{Name: "role", Type: "string", Default: "member"},
+			{Name: "score", Type: "int", Default: "10"},
+			{Name: "active", Type: "bool", Default: "true"},
+			{Name: "created_at", Type: "date", Required: true},
+			{Name: "updated_at", Type: "date", AutoManaged: true},
+		},
+		Operations: []string{"create", "read", "update", "delete"},
+	}
+}
+
+func TestFieldDefaultsAndTimestamps(t *testing.T) {
+	cg := NewCodeGenerator(t.TempDir())
+	entity := defaultsEntity()
+
+	createSQL := cg.generateCreateTableSQL(entity, nil, sqliteDialect)
+	for _, want := range []string{"role TEXT DEFAULT 'member'", "score INTEGER DEFAULT 10", "active BOOLEAN DEFAULT TRUE", "updated_at DATETIME DEFAULT CURRENT_TIMESTAMP"} {
+		if !strings.Contains(createSQL, want) {
+			t.Errorf("expected %q in %s", want, createSQL)
+		}
+	}
+	if mysqlSQL := cg.generateCreateTableSQL(entity, nil, mysqlDialect); !strings.Contains(mysqlSQL, "role TEXT DEFAULT ('member')") {
+		t.Errorf("expected a parenthesized TEXT default for MySQL, got %s", mysqlSQL)
+	}
+
+	// The column defaults apply to rows inserted without the fields
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(createSQL); err != nil {
+		t.Fatalf("migration failed: %v\n%s", err, createSQL)
+	}
+	if _, err := db.Exec("INSERT INTO members (name) VALUES ('alice')"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	var role string
+	var score int
+	var active bool
+	if err := db.QueryRow("SELECT role, score, active FROM members").Scan(&role, &score, &active); err != nil {
+		t.Fatalf("select failed: %v", err)
+	}
+	if role != "member" || score != 10 || !active {
+		t.Errorf("expected the column defaults, got role=%q score=%d active=%v", role, score, active)
+	}
+
+	appReq := testRequirement()
+	appReq.Entities = append(appReq.Entities, entity)
+	appDir := generateTestApp(t, appReq)
+	path := filepath.Join(appDir, "internal", "models", "member.go")
+	parseGoFile(t, path)
+	model, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read member.go: %v", err)
+	}
+	source := string(model)
+
+	create := source[strings.Index(source, "func CreateMember"):strings.Index(source, "func GetMemberByID")]
+	for _, want := range []string{"member.CreatedAt = now", "member.UpdatedAt = now", `member.Role = "member"`, "member.Score = 10"} {
+		if !strings.Contains(create, want) {
+			t.Errorf("expected CreateMember to contain %q:\n%s", want, create)
+		}
+	}
+	update := source[strings.Index(source, "func UpdateMember"):strings.Index(source, "func DeleteMember")]
+	if !strings.Contains(update, "member.UpdatedAt = now") || !strings.Contains(update, "updated_at = ?") {
+		t.Errorf("expected UpdateMember to set updated_at:\n%s", update)
+	}
+	if strings.Contains(update, "created_at") || strings.Contains(update, "CreatedAt") {
+		t.Errorf("expected UpdateMember to leave created_at alone:\n%s", update)
+	}
+	if strings.Contains(source, `json:"created_at" validate`) {
+		t.Errorf("expected auto-managed timestamps not to be required from clients:\n%s", source)
+	}
+}
+
+func TestRelationMigrationsRunOnSQLite(t *testing.T) {
+	cg := NewCodeGenerator(t.TempDir())
+	migrations := cg.migrationStatements(relationRequirement().Entities, sqliteDialect)
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		t.Fatalf("failed to enable foreign keys: %v", err)
+	}
+
+	joinTables := 0
+	for _, migration := range migrations {
+		if strings.Contains(migration, "post_tag") {
+			joinTables++
+		}
+		if _, err := db.Exec(migration); err != nil {
+			t.Fatalf("migration failed: %v\n%s", err, migration)
+		}
+	}
+	if joinTables != 1 {
+		t.Errorf("expected a single post_tag join table, got %d statements", joinTables)
+	}
+
+	statements := []string{
+		"INSERT INTO users (id, username, email) VALUES (1, 'ada', 'ada@example.com')",
+		"INSERT INTO posts (id, title, user_id) VALUES (1, 'Hello', 1)",
+		"INSERT INTO posts (id, title) VALUES (2, 'Orphan')",
+		"INSERT INTO tags (id, label) VALUES (1, 'go')",
+		"INSERT INTO post_tag (post_id, tag_id) VALUES (1, 1)",
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+
+	var label string
+	query := "SELECT tags.label FROM tags JOIN post_tag ON post_tag.tag_id = tags.id WHERE post_tag.post_id = ?"
+	if err := db.QueryRow(query, 1).Scan(&label); err != nil || label != "go" {
+		t.Errorf("expected tag lookup through join table, got %q, %v", label, err)
+	}
+
+	if _, err := db.Exec("INSERT INTO posts (title, user_id) VALUES ('Bad', 99)"); err == nil {
+		t.Error("expected foreign key violation for unknown user")
+	}
+	if _, err := db.Exec("DELETE FROM posts WHERE id = 1"); err != nil {
+		t.Fatalf("failed to delete post: %v", err)
+	}
+	var links int
+	if err := db.QueryRow("SELECT COUNT(*) FROM post_tag").Scan(&links); err != nil || links != 0 {
+		t.Errorf("expected join rows to cascade on delete, got %d, %v", links, err)
+	}
+}
+
+func TestGenerateRelationHelpers(t *testing.T) {
+	appPath := generateTestApp(t, relationRequirement())
+	modelsDir := filepath.Join(appPath, "internal", "models")
+
+	// methods collects receiver methods and functions declared in a model file
+	methods := func(name string) map[string]string {
+		declared := map[string]string{}
+		for _, decl := range parseGoFile(t, filepath.Join(modelsDir, name)).Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			receiver := ""
+			if fn.Recv != nil {
+				receiver = fn.Recv.List[0].Type.(*ast.StarExpr).X.(*ast.Ident).Name
+			}
+			declared[fn.Name.Name] = receiver
+		}
+		return declared
+	}
+
+	posts := methods("post.go")
+	for _, name := range []string{"AddTag", "RemoveTag", "GetTags"} {
+		if posts[name] != "Post" {
+			t.Errorf("expected method %s on *Post", name)
+		}
+	}
+	if receiver, ok := posts["GetPostsByUserID"]; !ok || receiver != "" {
+		t.Error("expected GetPostsByUserID query function")
+	}
+	if tags := methods("tag.go"); tags["GetPosts"] != "Tag" {
+		t.Error("expected inverse GetPosts method on *Tag")
+	}
+
+	post, ok := findType(parseGoFile(t, filepath.Join(modelsDir, "post.go")), "Post").(*ast.StructType)
+	if !ok {
+		t.Fatal("Post struct not found")
+	}
+	found := false
+	for _, field := range post.Fields.List {
+		if field.Names[0].Name == "UserID" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected UserID foreign key field on Post")
+	}
+
+	database, err := os.ReadFile(filepath.Join(appPath, "internal", "database", "database.go"))
+	if err != nil {
+		t.Fatalf("failed to read database.go: %v", err)
+	}
+	for _, want := range []string{"user_id INTEGER REFERENCES users(id)", "CREATE TABLE IF NOT EXISTS post_tag"} {
+		if !strings.Contains(string(database), want) {
+			t.Errorf("expected migrations to contain %q", want)
+		}
+	}
+}
+
+func TestGeneratedGoIsFormatted(t *testing.T) {
+	appPath := generateTestApp(t, testRequirement())
+
+	count := 0
+	err := filepath.Walk(appPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".go") {
+			return err
+		}
+		count++
+
+		parseGoFile(t, path)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		formatted, err := format.Source(content)
+		if err != nil {
+			t.Errorf("failed to format %s: %v", path, err)
+		} else if string(formatted) != string(content) {
+			t.Errorf("%s is not gofmt'd", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk generated app: %v", err)
+	}
+	if count == 0 {
+		t.Fatal("no Go files generated")
+	}
+}
+
+func TestFormatGoSourceFixesImports(t *testing.T) {
+	src := "package main\n\nimport (\n\t\"os\"\n\t\"time\"\n)\n\nfunc main() {\n\tfmt.Println(strings.ToUpper(os.Args[0]))\n}\n"
+	formatted, err := formatGoSource("main.go", []byte(src))
+	if err != nil {
+		t.Fatalf("formatGoSource failed: %v", err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", formatted, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("formatted source does not parse: %v", err)
+	}
+	var imports []string
+	for _, spec := range file.Imports {
+		imports = append(imports, spec.Path.Value)
+	}
+	if got := strings.Join(imports, " "); got != `"fmt" "strings" "os"` {
+		t.Errorf("unexpected imports %s", got)
+	}
+
+	if _, err := formatGoSource("bad.go", []byte("package main\n\nfunc {")); err == nil || !strings.Contains(err.Error(), "bad.go is not valid Go") {
+		t.Errorf("expected invalid Go error, got %v", err)
+	}
+}
+
+func TestGenerateEntityTests(t *testing.T) {
+	appDir := generateTestApp(t, relationRequirement())
+
+	for _, name := range []string{"user", "post", "tag"} {
+		parseGoFile(t, filepath.Join(appDir, "internal", "models", name+"_test.go"))
+		parseGoFile(t, filepath.Join(appDir, "internal", "handlers", name+"_handler_test.go"))
+	}
+
+	appReq := testRequirement()
+	appReq.Config["generate_tests"] = false
+	if _, err := os.Stat(filepath.Join(generateTestApp(t, appReq), "internal", "models", "user_test.go")); !os.IsNotExist(err) {
+		t.Errorf("expected no tests with generate_tests disabled, got %v", err)
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	cmd := exec.Command("go", "test", "./internal/models")
+	cmd.Dir = appDir
+	cmd.Env = append(os.Environ(), "GOPROXY=off", "GOFLAGS=-mod=mod", "GOWORK=off")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated model tests failed: %v\n%s", err, output)
+	}
+}
+
+func TestGenerateIntegrationTest(t *testing.T) {
+	appDir := generateTestApp(t, testRequirement())
+
+	path := filepath.Join(appDir, "integration_test.go")
+	parseGoFile(t, path)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read integration test: %v", err)
+	}
+	source := string(content)
+	for _, want := range []string{
+		"//go:build integration",
+		"httptest.NewServer",
+		"routes.Setup",
+		"GET /health",
+		"POST /api/users",
+		"GET /api/users",
+		"GET /api/users/:id",
+		"PUT /api/users/:id",
+		"DELETE /api/users/:id",
+	} {
+		if !strings.Contains(source, want) {
+			t.Errorf("expected integration test to contain %q", want)
+		}
+	}
+
+	for _, strategy := range []string{"apikey", "jwt"} {
+		appReq := testRequirement()
+		appReq.AuthStrategy = strategy
+		parseGoFile(t, filepath.Join(generateTestApp(t, appReq), "integration_test.go"))
+	}
+
+	appReq := testRequirement()
+	appReq.AuthStrategy = "oauth2"
+	if _, err := os.Stat(filepath.Join(generateTestApp(t, appReq), "integration_test.go")); !os.IsNotExist(err) {
+		t.Errorf("expected no integration test with OAuth2, got %v", err)
+	}
+}
+
+func TestGenerateDatabaseDrivers(t *testing.T) {
+	tests := []struct {
+		database   string
+		driver     string
+		module     string
+		getQuery   string
+		updateArgs string
+		insert     string
+		link       string
+	}{
+		{"sqlite", `sql.Open("sqlite3"`, "github.com/mattn/go-sqlite3", "WHERE id = ?", "username = ?, email = ? WHERE id = ?", "result.LastInsertId()", "INSERT OR IGNORE INTO post_tag"},
+		{"postgresql", `sql.Open("postgres"`, "github.com/lib/pq", "WHERE id = $1", "username = $1, email = $2 WHERE id = $3", "RETURNING id", "ON CONFLICT DO NOTHING"},
+		{"mysql", `sql.Open("mysql"`, "github.com/go-sql-driver/mysql", "WHERE id = ?", "username = ?, email = ? WHERE id = ?", "result.LastInsertId()", "INSERT IGNORE INTO post_tag"},
+	}
+
+	for _, tt := range tests {
+		appReq := relationRequirement()
+		appReq.Database = tt.database
+		appDir := generateTestApp(t, appReq)
+
+		read := func(path string) string {
+			content, err := os.ReadFile(filepath.Join(appDir, path))
+			if err != nil {
+				t.Fatalf("%s: failed to read %s: %v", tt.database, path, err)
+			}
+			return string(content)
+		}
+
+		database := read("internal/database/database.go")
+		if !strings.Contains(database, tt.driver) || !strings.Contains(database, `_ "`+tt.module+`"`) {
+			t.Errorf("%s: expected %s with driver %s:\n%s", tt.database, tt.driver, tt.module, database)
+		}
+		if !strings.Contains(read("go.mod"), tt.module+" v") {
+			t.Errorf("%s: expected go.mod to require %s", tt.database, tt.module)
+		}
+
+		user := read("internal/models/user.go")
+		for _, want := range []string{tt.getQuery, tt.updateArgs, tt.insert} {
+			if !strings.Contains(user, want) {
+				t.Errorf("%s: expected user model to contain %q", tt.database, want)
+			}
+		}
+		if post := read("internal/models/post.go"); !strings.Contains(post, tt.link) {
+			t.Errorf("%s: expected post model to contain %q", tt.database, tt.link)
+		}
+
+		_, err := os.Stat(filepath.Join(appDir, "internal", "models", "user_test.go"))
+		if hasTests := err == nil; hasTests != (tt.database == "sqlite") {
+			t.Errorf("%s: generated model tests present = %v", tt.database, hasTests)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package codegen
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+// update rewrites the golden files from the current templates:
+// go test ./internal/codegen -run TestGolden -update
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// timestampPattern matches dates and times that would differ between runs
+var timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+
+// goldenApps are the canonical applications whose generated files are kept
+// under testdata/golden/<name>: an API per language, built from
+// goldenRequirement or, when description is set, from what the rule-based
+// analyzer makes of it
+var goldenApps = []struct {
+	name        string
+	language    string
+	appType     string
+	description string
+	// skip says why the app has no golden files yet
+	skip string
+}{
+	{name: "go-api", language: "go", appType: "api"},
+	{name: "go-analyzed", description: "shop api in go with gin for users and products"},
+	{name: "javascript-api", language: "javascript", appType: "api"},
+	{name: "python-api", language: "python", appType: "api"},
+}
+
+// goldenRequirement returns the requirement of the golden apps: an API with
+// User and Product entities
+func goldenRequirement(language string) *requirements.ApplicationRequirement {
+	return &requirements.ApplicationRequirement{
+		Name:        "Golden Shop",
+		Description: "A shop API with users and products",
+		Type:        "api",
+		Language:    language,
+		Database:    "sqlite",
+		Features:    []string{"user_management", "product_management"},
+		Entities: []requirements.Entity{
+			{
+				Name: "User",
+				Fields: []requirements.EntityField{
+					{Name: "id", Type: "int", Required: true},
+					{Name: "username", Type: "string", Required: true, Validation: "min=3,max=50"},
+					{Name: "email", Type: "email", Required: true},
+					{Name: "created_at", Type: "date", Required: true, AutoManaged: true},
+				},
+				Operations: []string{"create", "read", "update", "delete"},
+			},
+			{
+				Name: "Product",
+				Fields: []requirements.EntityField{
+					{Name: "id", Type: "int", Required: true},
+					{Name: "name", Type: "string", Required: true, Validation: "min=1,max=200"},
+					{Name: "description", Type: "string"},
+					{Name: "price", Type: "float", Required: true, Validation: "min=0"},
+					{Name: "in_stock", Type: "bool", Default: "true"},
+				},
+				Operations: []string{"create", "read", "update", "delete"},
+			},
+		},
+		Config: map[string]interface{}{"port": 8080},
+	}
+}
+
+func TestGolden(t *testing.T) {
+	for _, app := range goldenApps {
+		t.Run(app.name, func(t *testing.T) {
+			appReq := goldenRequirement(app.language)
+			appReq.Type = app.appType
+			if app.description != "" {
+				var err error
+				if appReq, err = requirements.NewRequirementAnalyzer(nil).AnalyzeRequirements(app.description); err != nil {
+					t.Fatalf("AnalyzeRequirements failed: %v", err)
+				}
+			}
+
+			outputDir := t.TempDir()
+			_, err := NewCodeGenerator(outputDir).GenerateApplication(appReq)
+			if app.skip != "" {
+				if err == nil {
+					t.Fatalf("%s generates now; add its golden files with -update and drop the skip", app.name)
+				}
+				t.Skip(app.skip)
+			}
+			if err != nil {
+				t.Fatalf("GenerateApplication failed: %v", err)
+			}
+			appDir, err := AppDir(outputDir, appReq)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := readGoldenTree(t, appDir, "")
+			goldenDir := filepath.Join("testdata", "golden", app.name)
+
+			if *update {
+				if err := os.RemoveAll(goldenDir); err != nil {
+					t.Fatal(err)
+				}
+				for name, content := range got {
+					path := filepath.Join(goldenDir, filepath.FromSlash(name)+".golden")
+					if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				return
+			}
+
+			want := readGoldenTree(t, goldenDir, ".golden")
+			for _, name := range sortedKeys(got) {
+				expected, ok := want[name]
+				if !ok {
+					t.Errorf("%s is generated but has no golden file; run with -update if it is new", name)
+					continue
+				}
+				if got[name] != expected {
+					t.Errorf("%s differs from its golden file; run with -update if the change is intended\n%s", name, firstDifference(expected, got[name]))
+				}
+			}
+			for _, name := range sortedKeys(want) {
+				if _, ok := got[name]; !ok {
+					t.Errorf("%s has a golden file but is no longer generated", name)
+				}
+			}
+		})
+	}
+}
+
+// readGoldenTree reads the files under dir, keyed by their slash-separated
+// path relative to dir without suffix, with timestamps normalized
+func readGoldenTree(t *testing.T, dir, suffix string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[strings.TrimSuffix(filepath.ToSlash(rel), suffix)] = timestampPattern.ReplaceAllString(string(content), "<timestamp>")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", dir, err)
+	}
+	return files
+}
+
+// sortedKeys returns the keys of files in order
+func sortedKeys(files map[string]string) []string {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// firstDifference describes the first line where got differs from want
+func firstDifference(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n  want: %s\n  got:  %s", i+1, w, g)
+		}
+	}
+	return ""
+}
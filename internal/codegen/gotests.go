@@ -0,0 +1,119 @@
+package codegen
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+// testValue is a field assigned in generated tests, with literals for the
+// created record and for the update
+type testValue struct {
+	GoName        string
+	JSONName      string
+	Literal       string
+	UpdateLiteral string
+}
+
+// generateTestsEnabled reports whether generated apps get unit tests, which
+// Config["generate_tests"] can turn off. The tests run on in-memory SQLite, so
+// apps using other databases get none.
+func generateTestsEnabled(appReq *requirements.ApplicationRequirement) bool {
+	if databaseDialect(appReq) != sqliteDialect {
+		return false
+	}
+	switch v := appReq.Config["generate_tests"].(type) {
+	case bool:
+		return v
+	case string:
+		return v != "false" && v != "0"
+	}
+	return true
+}
+
+// generateEntityTests generates CRUD tests for every entity's model functions
+// against an in-memory SQLite database and for its gin handlers via httptest
+func (cg *CodeGenerator) generateEntityTests(appDir string, appReq *requirements.ApplicationRequirement) error {
+	modelsDir := filepath.Join(appDir, "internal", "models")
+	handlersDir := filepath.Join(appDir, "internal", "handlers")
+	for _, dir := range []string{modelsDir, handlersDir} {
+		if err := cg.mkdirAll(dir); err != nil {
+			return err
+		}
+	}
+
+	migrations := cg.migrationStatements(appReq.Entities, sqliteDialect)
+	for _, dir := range []struct{ path, pkg string }{{modelsDir, "models"}, {handlersDir, "handlers"}} {
+		data := map[string]interface{}{"Package": dir.pkg, "Migrations": migrations}
+		if err := cg.writeTemplate("go/testdb_test.go", filepath.Join(dir.path, "testdb_test.go"), data); err != nil {
+			return err
+		}
+	}
+
+	moduleName := appSlug(appReq)
+	for _, entity := range appReq.Entities {
+		data := cg.prepareModelData(entity, nil, sqliteDialect)
+		values := cg.entityTestValues(entity)
+		data["Values"] = values
+		data["UpdateValue"] = nil
+		if len(values) > 0 {
+			data["UpdateValue"] = values[0]
+		}
+		data["ModuleName"] = moduleName
+		data["LowerPlural"] = tableName(entity)
+		data["BackgroundJobs"] = hasFeature(appReq, "background_jobs")
+		data["Envelope"] = responseEnvelope(appReq)
+
+		lowerName := strings.ToLower(entity.Name)
+		if err := cg.writeTemplate("go/model_test.go", filepath.Join(modelsDir, lowerName+"_test.go"), data); err != nil {
+			return err
+		}
+		if err := cg.writeTemplate("go/handler_test.go", filepath.Join(handlersDir, lowerName+"_handler_test.go"), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// entityTestValues returns the fields a test can set and compare, skipping the
+// ones the database fills in
+func (cg *CodeGenerator) entityTestValues(entity requirements.Entity) []testValue {
+	var values []testValue
+	for _, field := range entity.Fields {
+		if field.Name == "id" || field.IsAutoManaged() || field.Type == "date" {
+			continue
+		}
+		values = append(values, testValue{
+			GoName:        goFieldName(field.Name),
+			JSONName:      strings.ToLower(field.Name),
+			Literal:       cg.seedLiteral(field.Type, cg.syntheticValue(entity, field, 1)),
+			UpdateLiteral: cg.seedLiteral(field.Type, cg.syntheticValue(entity, field, 2)),
+		})
+	}
+	return values
+}
+
+// integrationTestsEnabled reports whether generated apps get an integration
+// test. It needs an entity to exercise and a token it can create itself, which
+// rules out OAuth2.
+func integrationTestsEnabled(appReq *requirements.ApplicationRequirement) bool {
+	return generateTestsEnabled(appReq) && len(appReq.Entities) > 0 && authStrategy(appReq) != "oauth2"
+}
+
+// generateIntegrationTest generates integration_test.go, which serves the
+// application's routes with httptest.NewServer and runs the CRUD endpoints of
+// the first entity over HTTP. It is behind the integration build tag, so plain
+// go test runs skip it.
+func (cg *CodeGenerator) generateIntegrationTest(appDir string, appReq *requirements.ApplicationRequirement) error {
+	entity := appReq.Entities[0]
+	data := cg.prepareModelData(entity, nil, sqliteDialect)
+	data["Values"] = cg.entityTestValues(entity)
+	data["ModuleName"] = appSlug(appReq)
+	data["LowerPlural"] = tableName(entity)
+	data["BackgroundJobs"] = hasFeature(appReq, "background_jobs")
+	data["AuthStrategy"] = authStrategy(appReq)
+
+	return cg.writeTemplate("go/integration_test.go", filepath.Join(appDir, "integration_test.go"), data)
+}
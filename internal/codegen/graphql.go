@@ -0,0 +1,134 @@
+package codegen
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+// graphQLField is a field of a GraphQL object or input type
+type graphQLField struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+// graphQLEntity is an entity exposed through the GraphQL schema
+type graphQLEntity struct {
+	Name        string
+	Lower       string // query field of a single record, e.g. orderItem
+	Plural      string // query field of the list, e.g. orderItems
+	Fields      []graphQLField
+	InputFields []graphQLField
+}
+
+// graphQLType maps an entity field type to a GraphQL scalar
+func graphQLType(fieldType string) string {
+	switch fieldType {
+	case "int":
+		return "Int"
+	case "float":
+		return "Float"
+	case "bool":
+		return "Boolean"
+	case "date":
+		return "Time"
+	default:
+		return "String"
+	}
+}
+
+// graphQLEntities returns the entities with their GraphQL fields. Object
+// types expose every field under its JSON name; input types leave out the ID
+// and the fields the database fills in.
+func graphQLEntities(appReq *requirements.ApplicationRequirement) ([]graphQLEntity, bool) {
+	var entities []graphQLEntity
+	needsTime := false
+	for _, entity := range appReq.Entities {
+		lower := strings.ToLower(entity.Name[:1]) + entity.Name[1:]
+		e := graphQLEntity{Name: entity.Name, Lower: lower, Plural: lower + "s"}
+		for _, field := range entity.Fields {
+			name := strings.ToLower(field.Name)
+			if name == "id" {
+				e.Fields = append(e.Fields, graphQLField{Name: name, Type: "ID", Required: true})
+				continue
+			}
+			fieldType := graphQLType(field.Type)
+			needsTime = needsTime || fieldType == "Time"
+			e.Fields = append(e.Fields, graphQLField{Name: name, Type: fieldType, Required: true})
+			if !field.IsAutoManaged() {
+				e.InputFields = append(e.InputFields, graphQLField{Name: name, Type: fieldType, Required: field.Required})
+			}
+		}
+		entities = append(entities, e)
+	}
+	return entities, needsTime
+}
+
+// generateGoGraphQLApplication generates a gqlgen GraphQL server: a schema
+// with a type, queries and mutations per entity, resolvers calling the same
+// models and database setup as the REST APIs, and main.go serving the schema
+// at /query with a playground at /. gqlgen writes graph/generated.go from the
+// schema on go generate ./..., which the Dockerfile and Makefile run before
+// building.
+func (cg *CodeGenerator) generateGoGraphQLApplication(appDir string, appReq *requirements.ApplicationRequirement) error {
+	graphDir := filepath.Join(appDir, "graph")
+	if err := cg.mkdirAll(graphDir); err != nil {
+		return err
+	}
+
+	if err := cg.generateGoMod(appDir, appReq); err != nil {
+		return err
+	}
+	if err := cg.generateGoSum(appDir); err != nil {
+		return err
+	}
+	if err := cg.generateGitignore(appDir, appReq); err != nil {
+		return err
+	}
+	if err := cg.generateModels(appDir, appReq); err != nil {
+		return err
+	}
+	if err := cg.generateDatabase(appDir, appReq); err != nil {
+		return err
+	}
+	if err := cg.generateConfig(appDir, appReq); err != nil {
+		return err
+	}
+
+	entities, needsTime := graphQLEntities(appReq)
+	data := map[string]interface{}{
+		"Name":        appReq.Name,
+		"Description": appReq.Description,
+		"ModuleName":  appSlug(appReq),
+		"Entities":    entities,
+		"NeedsTime":   needsTime,
+		"Port":        fmt.Sprintf("%v", appReq.Config["port"]),
+		"DatabaseURL": databaseDialect(appReq).DefaultURL,
+	}
+
+	files := []struct{ template, path string }{
+		{"go/schema.graphql", filepath.Join(graphDir, "schema.graphql")},
+		{"go/gqlgen.yml", filepath.Join(appDir, "gqlgen.yml")},
+		{"go/graphql_resolver.go", filepath.Join(graphDir, "resolver.go")},
+		{"go/schema.resolvers.go", filepath.Join(graphDir, "schema.resolvers.go")},
+		{"go/graphql_tools.go", filepath.Join(appDir, "tools.go")},
+		{"go/graphql_main.go", filepath.Join(appDir, "main.go")},
+		{"go/README.graphql.md", filepath.Join(appDir, "README.md")},
+	}
+	for _, file := range files {
+		if err := cg.writeTemplate(file.template, file.path, data); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", filepath.Base(file.path), err)
+		}
+	}
+
+	if err := cg.generateDockerfile(appDir, appReq); err != nil {
+		return err
+	}
+	if err := cg.generateDockerCompose(appDir, appReq); err != nil {
+		return err
+	}
+	return cg.generateMakefile(appDir, appReq)
+}
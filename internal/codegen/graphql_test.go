@@ -0,0 +1,82 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGraphQLApplication(t *testing.T) {
+	appReq := testRequirement()
+	appReq.Type = "graphql"
+	appReq.Framework = "gqlgen"
+	appDir := generateTestApp(t, appReq)
+
+	schema, err := os.ReadFile(filepath.Join(appDir, "graph", "schema.graphql"))
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+	for _, want := range []string{
+		"scalar Time",
+		"type User {",
+		"  id: ID!",
+		"  created_at: Time!",
+		"input UserInput {",
+		"  users: [User!]!",
+		"  user(id: ID!): User",
+		"  createUser(input: UserInput!): User!",
+		"  updateUser(id: ID!, input: UserInput!): User!",
+		"  deleteUser(id: ID!): Boolean!",
+	} {
+		if !strings.Contains(string(schema), want) {
+			t.Errorf("schema does not contain %q:\n%s", want, schema)
+		}
+	}
+	input := string(schema)[strings.Index(string(schema), "input UserInput"):]
+	input = input[:strings.Index(input, "}")]
+	if strings.Contains(input, "id:") || strings.Contains(input, "created_at") {
+		t.Errorf("input type contains the ID or auto-managed fields:\n%s", input)
+	}
+
+	resolvers, err := os.ReadFile(filepath.Join(appDir, "graph", "schema.resolvers.go"))
+	if err != nil {
+		t.Fatalf("failed to read resolvers: %v", err)
+	}
+	for _, want := range []string{"models.CreateUser(", "models.GetUserByID(", "models.GetAllUsers(", "models.UpdateUser(", "models.DeleteUser("} {
+		if !strings.Contains(string(resolvers), want) {
+			t.Errorf("resolvers do not call %s", want)
+		}
+	}
+
+	config, err := os.ReadFile(filepath.Join(appDir, "gqlgen.yml"))
+	if err != nil {
+		t.Fatalf("failed to read gqlgen.yml: %v", err)
+	}
+	if !strings.Contains(string(config), "model: test-app/internal/models.User") {
+		t.Errorf("gqlgen.yml does not bind User to the model:\n%s", config)
+	}
+
+	main, err := os.ReadFile(filepath.Join(appDir, "main.go"))
+	if err != nil {
+		t.Fatalf("failed to read main.go: %v", err)
+	}
+	if !strings.Contains(string(main), "graph.NewExecutableSchema") || !strings.Contains(string(main), `"/query"`) {
+		t.Errorf("main.go does not serve the schema at /query:\n%s", main)
+	}
+
+	goMod, err := os.ReadFile(filepath.Join(appDir, "go.mod"))
+	if err != nil {
+		t.Fatalf("failed to read go.mod: %v", err)
+	}
+	if !strings.Contains(string(goMod), "github.com/99designs/gqlgen") || strings.Contains(string(goMod), "gin-gonic") {
+		t.Errorf("go.mod should require gqlgen instead of gin:\n%s", goMod)
+	}
+
+	for _, file := range []string{"main.go", "tools.go", "graph/resolver.go", "graph/schema.resolvers.go", "internal/models/user.go"} {
+		parseGoFile(t, filepath.Join(appDir, file))
+	}
+	if _, err := os.Stat(filepath.Join(appDir, "internal", "handlers")); !os.IsNotExist(err) {
+		t.Errorf("GraphQL apps should not generate REST handlers")
+	}
+}
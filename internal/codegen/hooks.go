@@ -0,0 +1,108 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+// PostGenerateHook runs on a freshly generated application, for example to
+// format it, add license headers or lint it
+type PostGenerateHook func(appDir string, appReq *requirements.ApplicationRequirement) error
+
+// RegisterPostGenerateHook adds a hook run at the end of GenerateApplication.
+// Hooks run in the order they were registered and not at all for plans; the
+// first failing hook aborts generation.
+func (cg *CodeGenerator) RegisterPostGenerateHook(hook PostGenerateHook) {
+	cg.mutex.Lock()
+	defer cg.mutex.Unlock()
+	cg.hooks = append(cg.hooks, hook)
+}
+
+// runHooks runs the post-generate hooks on appDir in order
+func (cg *CodeGenerator) runHooks(appDir string, appReq *requirements.ApplicationRequirement) error {
+	for i, hook := range cg.hooks {
+		if err := hook(appDir, appReq); err != nil {
+			return fmt.Errorf("post-generate hook %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// commentPrefixes maps the extensions of generated source files to the
+// prefix of their line comments
+var commentPrefixes = map[string]string{
+	".go":   "//",
+	".js":   "//",
+	".java": "//",
+	".php":  "//",
+	".py":   "#",
+	".rb":   "#",
+}
+
+// LicenseHeaderHook returns a hook inserting header, as line comments, at the
+// top of every generated source file. Shebangs and PHP open tags stay on the
+// first line, and files already starting with the header are left alone.
+func LicenseHeaderHook(header string) PostGenerateHook {
+	return func(appDir string, appReq *requirements.ApplicationRequirement) error {
+		return filepath.Walk(appDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if info.Name() == "node_modules" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			prefix, ok := commentPrefixes[filepath.Ext(path)]
+			if !ok {
+				return nil
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			updated, changed := addLicenseHeader(string(content), header, prefix)
+			if !changed {
+				return nil
+			}
+			if err := os.WriteFile(path, []byte(updated), info.Mode()); err != nil {
+				return fmt.Errorf("failed to add license header to %s: %w", path, err)
+			}
+			return nil
+		})
+	}
+}
+
+// addLicenseHeader returns content with header commented out with prefix in
+// front of it, and whether anything changed
+func addLicenseHeader(content, header, prefix string) (string, bool) {
+	var comment strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(header, "\n"), "\n") {
+		if line == "" {
+			comment.WriteString(prefix + "\n")
+			continue
+		}
+		comment.WriteString(prefix + " " + line + "\n")
+	}
+
+	// Keep a shebang or PHP open tag as the first line
+	var first string
+	if strings.HasPrefix(content, "#!") || strings.HasPrefix(content, "<?php") {
+		if end := strings.IndexByte(content, '\n'); end >= 0 {
+			first, content = content[:end+1], content[end+1:]
+		} else {
+			first, content = content+"\n", ""
+		}
+	}
+
+	if strings.HasPrefix(content, comment.String()) {
+		return first + content, false
+	}
+	return first + comment.String() + "\n" + content, true
+}
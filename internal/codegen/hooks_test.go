@@ -0,0 +1,147 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+func TestPostGenerateHooks(t *testing.T) {
+	cg := NewCodeGenerator(t.TempDir())
+	var order []string
+	calls := 0
+	cg.RegisterPostGenerateHook(func(appDir string, appReq *requirements.ApplicationRequirement) error {
+		calls++
+		order = append(order, "marker")
+		// Hooks see the finished application
+		if _, err := os.Stat(filepath.Join(appDir, "main.go")); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(appDir, "hook.marker"), []byte(appReq.Name), 0644)
+	})
+	cg.RegisterPostGenerateHook(func(appDir string, appReq *requirements.ApplicationRequirement) error {
+		order = append(order, "second")
+		return nil
+	})
+
+	appDir, err := cg.GenerateApplication(testRequirement())
+	if err != nil {
+		t.Fatalf("GenerateApplication failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("hook ran %d times, want 1", calls)
+	}
+	if got := strings.Join(order, ","); got != "marker,second" {
+		t.Errorf("hooks ran as %s, want marker,second", got)
+	}
+	if _, err := os.Stat(filepath.Join(appDir, "hook.marker")); err != nil {
+		t.Errorf("marker file missing: %v", err)
+	}
+
+	if _, err := cg.PlanApplication(testRequirement()); err != nil {
+		t.Fatalf("PlanApplication failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("hook ran %d times after planning, want 1", calls)
+	}
+
+	if _, err := cg.GenerateApplication(testRequirement()); err != nil {
+		t.Fatalf("GenerateApplication failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("hook ran %d times after two generations, want 2", calls)
+	}
+}
+
+func TestPostGenerateHookError(t *testing.T) {
+	errLint := errors.New("lint failed")
+	cg := NewCodeGenerator(t.TempDir())
+	ran := false
+	cg.RegisterPostGenerateHook(func(string, *requirements.ApplicationRequirement) error { return errLint })
+	cg.RegisterPostGenerateHook(func(string, *requirements.ApplicationRequirement) error {
+		ran = true
+		return nil
+	})
+
+	_, err := cg.GenerateApplication(testRequirement())
+	if !errors.Is(err, errLint) {
+		t.Fatalf("expected the hook error, got %v", err)
+	}
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || !strings.Contains(err.Error(), "post-generate hook 1 failed") {
+		t.Errorf("expected a GenerationError naming the hook, got %v", err)
+	}
+	if ran {
+		t.Error("hooks after a failing hook should not run")
+	}
+}
+
+func TestLicenseHeaderHook(t *testing.T) {
+	header := "Copyright 2024 Example Corp\n\nLicensed under the MIT License"
+	cg := NewCodeGenerator(t.TempDir())
+	cg.RegisterPostGenerateHook(LicenseHeaderHook(header))
+
+	appDir, err := cg.GenerateApplication(testRequirement())
+	if err != nil {
+		t.Fatalf("GenerateApplication failed: %v", err)
+	}
+	main, err := os.ReadFile(filepath.Join(appDir, "main.go"))
+	if err != nil {
+		t.Fatalf("failed to read main.go: %v", err)
+	}
+	want := "// Copyright 2024 Example Corp\n//\n// Licensed under the MIT License\n\npackage main"
+	if !strings.HasPrefix(string(main), want) {
+		t.Errorf("main.go does not start with the header:\n%s", main)
+	}
+	parseGoFile(t, filepath.Join(appDir, "main.go"))
+
+	goMod, err := os.ReadFile(filepath.Join(appDir, "go.mod"))
+	if err != nil {
+		t.Fatalf("failed to read go.mod: %v", err)
+	}
+	if strings.Contains(string(goMod), "Copyright") {
+		t.Error("go.mod is not a source file and should have no header")
+	}
+
+	pythonReq := testRequirement()
+	pythonReq.Language = "python"
+	pythonReq.Framework = "flask"
+	appDir, err = cg.GenerateApplication(pythonReq)
+	if err != nil {
+		t.Fatalf("GenerateApplication failed: %v", err)
+	}
+	app, err := os.ReadFile(filepath.Join(appDir, "app.py"))
+	if err != nil {
+		t.Fatalf("failed to read app.py: %v", err)
+	}
+	if !strings.HasPrefix(string(app), "# Copyright 2024 Example Corp\n#\n") {
+		t.Errorf("app.py does not start with the header:\n%s", app)
+	}
+}
+
+func TestAddLicenseHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		prefix  string
+		want    string
+		changed bool
+	}{
+		{"go", "package main\n", "//", "// MIT\n\npackage main\n", true},
+		{"shebang", "#!/usr/bin/env python\nprint()\n", "#", "#!/usr/bin/env python\n# MIT\n\nprint()\n", true},
+		{"php", "<?php\necho 1;\n", "//", "<?php\n// MIT\n\necho 1;\n", true},
+		{"present", "// MIT\n\npackage main\n", "//", "// MIT\n\npackage main\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := addLicenseHeader(tt.content, "MIT", tt.prefix)
+			if got != tt.want || changed != tt.changed {
+				t.Errorf("addLicenseHeader() = %q, %v; want %q, %v", got, changed, tt.want, tt.changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package codegen
+
+import (
+	"path/filepath"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+// generateMakefile generates a Makefile with build, test, run, lint and
+// docker targets for Go and JavaScript applications. Go CLI apps have no
+// Dockerfile, so they get no docker target, and GraphQL apps build and test
+// after a generate target running gqlgen.
+func (cg *CodeGenerator) generateMakefile(appDir string, appReq *requirements.ApplicationRequirement) error {
+	data := map[string]interface{}{
+		"Name":    appSlug(appReq),
+		"Docker":  appReq.Type != "cli",
+		"GraphQL": appReq.Type == "graphql",
+	}
+	path := filepath.Join(appDir, "Makefile")
+	if appReq.Language == "javascript" {
+		return cg.writeTemplate("javascript/Makefile.js", path, data)
+	}
+	return cg.writeTemplate("go/Makefile", path, data)
+}
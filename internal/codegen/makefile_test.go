@@ -0,0 +1,63 @@
+package codegen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerateMakefile(t *testing.T) {
+	tests := []struct {
+		language string
+		appType  string
+		targets  []string
+		commands []string
+	}{
+		{"go", "api", []string{"build", "test", "run", "lint", "docker"}, []string{"go build -o $(BINARY) .", "go test ./...", "go vet ./...", "BINARY := test-app"}},
+		{"go", "cli", []string{"build", "test", "run", "lint"}, []string{"go build -o $(BINARY) .", "go vet ./..."}},
+		{"go", "graphql", []string{"generate", "build", "test", "run", "lint", "docker"}, []string{"go generate ./...", "build: generate", "go build -o $(BINARY) ."}},
+		{"javascript", "api", []string{"build", "test", "run", "lint", "docker"}, []string{"npm install", "npm test", "npm start", "npm run lint", "IMAGE := test-app"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language+"-"+tt.appType, func(t *testing.T) {
+			appReq := testRequirement()
+			appReq.Language = tt.language
+			appReq.Type = tt.appType
+			appReq.Framework = ""
+			appDir := generateTestApp(t, appReq)
+
+			data, err := os.ReadFile(filepath.Join(appDir, "Makefile"))
+			if err != nil {
+				t.Fatalf("failed to read Makefile: %v", err)
+			}
+			makefile := string(data)
+			for _, target := range tt.targets {
+				if !regexp.MustCompile(`(?m)^` + target + `:`).MatchString(makefile) {
+					t.Errorf("Makefile has no %s target:\n%s", target, makefile)
+				}
+			}
+			for _, command := range tt.commands {
+				if !strings.Contains(makefile, command) {
+					t.Errorf("Makefile does not contain %q:\n%s", command, makefile)
+				}
+			}
+			if tt.appType == "cli" && strings.Contains(makefile, "docker") {
+				t.Errorf("CLI apps have no Dockerfile, but the Makefile builds an image:\n%s", makefile)
+			}
+
+			if _, err := exec.LookPath("make"); err != nil {
+				return
+			}
+			// A dry run catches syntax errors such as spaces instead of tabs
+			cmd := exec.Command("make", append([]string{"-n"}, tt.targets...)...)
+			cmd.Dir = appDir
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("make -n failed: %v\n%s", err, output)
+			}
+		})
+	}
+}
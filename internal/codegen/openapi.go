@@ -0,0 +1,405 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIDocument is an OpenAPI 3.0 document; a struct keeps the top-level
+// keys in their conventional order in the YAML output
+type openAPIDocument struct {
+	OpenAPI    string                            `json:"openapi" yaml:"openapi"`
+	Info       map[string]interface{}            `json:"info" yaml:"info"`
+	Paths      map[string]map[string]interface{} `json:"paths" yaml:"paths"`
+	Components map[string]interface{}            `json:"components" yaml:"components"`
+	Security   []interface{}                     `json:"security" yaml:"security"`
+}
+
+// pathParamPattern matches :name and {name} path parameters
+var pathParamPattern = regexp.MustCompile(`[:{]([A-Za-z_][A-Za-z0-9_]*)}?`)
+
+// generateOpenAPISpec generates openapi.yaml, and the same document as
+// openapi.json, describing the entity CRUD routes, the endpoints in the
+// requirements with their parameters and schemas, and the API security
+func (cg *CodeGenerator) generateOpenAPISpec(appDir string, appReq *requirements.ApplicationRequirement) error {
+	paths := map[string]map[string]interface{}{
+		"/health": {
+			"get": map[string]interface{}{
+				"summary":   "Health check",
+				"security":  []interface{}{},
+				"responses": map[string]interface{}{"200": map[string]interface{}{"description": "Service is healthy"}},
+			},
+		},
+	}
+
+	envelope := appReq.Language == "go" && responseEnvelope(appReq)
+	entityNames := map[string]bool{}
+	schemas := map[string]interface{}{}
+	for _, entity := range appReq.Entities {
+		entityNames[entity.Name] = true
+		schemas[entity.Name] = cg.entitySchema(entity)
+
+		plural := tableName(entity)
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + entity.Name}
+		response := func(description string, schema interface{}) map[string]interface{} {
+			if schema == nil {
+				return map[string]interface{}{"description": description}
+			}
+			wrapped := dataSchema(schema)
+			if envelope {
+				wrapped = envelopeSchema(schema, map[string]interface{}{"message": map[string]interface{}{"type": "string"}})
+			}
+			return map[string]interface{}{
+				"description": description,
+				"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": wrapped}},
+			}
+		}
+		body := map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": ref}},
+		}
+		idParam := []interface{}{
+			map[string]interface{}{"name": "id", "in": "path", "required": true, "schema": map[string]interface{}{"type": "integer"}},
+		}
+
+		list := map[string]interface{}{
+			"summary":   "List " + plural,
+			"responses": map[string]interface{}{"200": response("List of "+plural, map[string]interface{}{"type": "array", "items": ref})},
+		}
+		if appReq.Language == "go" {
+			list = pagedListOperation(plural, ref, envelope)
+		}
+		paths["/api/"+plural] = map[string]interface{}{
+			"get": list,
+			"post": map[string]interface{}{
+				"summary":     "Create a " + entity.Name,
+				"requestBody": body,
+				"responses":   map[string]interface{}{"201": response(entity.Name+" created", ref)},
+			},
+		}
+		paths["/api/"+plural+"/{id}"] = map[string]interface{}{
+			"parameters": idParam,
+			"get": map[string]interface{}{
+				"summary":   "Get a " + entity.Name,
+				"responses": map[string]interface{}{"200": response(entity.Name+" found", ref), "404": response(entity.Name+" not found", nil)},
+			},
+			"put": map[string]interface{}{
+				"summary":     "Update a " + entity.Name,
+				"requestBody": body,
+				"responses":   map[string]interface{}{"200": response(entity.Name+" updated", ref)},
+			},
+			"delete": map[string]interface{}{
+				"summary":   "Delete a " + entity.Name,
+				"responses": map[string]interface{}{"200": response(entity.Name+" deleted", nil)},
+			},
+		}
+	}
+
+	// Endpoints from the requirements describe their operations more precisely
+	// than the CRUD defaults, so they replace them
+	for _, endpoint := range appReq.Endpoints {
+		method := strings.ToLower(endpoint.Method)
+		if method == "" || endpoint.Path == "" {
+			continue
+		}
+		path := openAPIPath(endpoint.Path)
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][method] = cg.endpointOperation(endpoint, path, appReq.Entities, entityNames)
+	}
+
+	strategy := authStrategy(appReq)
+	securitySchemes := cg.securitySchemes(strategy)
+	security := []interface{}{}
+	for name := range securitySchemes {
+		security = append(security, map[string]interface{}{name: []string{}})
+	}
+
+	if strategy == "oauth2" {
+		paths["/auth/login"] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary":   "Start the OAuth2 login flow",
+				"security":  []interface{}{},
+				"responses": map[string]interface{}{"302": map[string]interface{}{"description": "Redirect to the provider"}},
+			},
+		}
+		paths["/auth/callback"] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary":   "OAuth2 provider callback",
+				"security":  []interface{}{},
+				"responses": map[string]interface{}{"200": map[string]interface{}{"description": "Access token issued"}},
+			},
+		}
+	}
+
+	spec := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: map[string]interface{}{
+			"title":       appReq.Name,
+			"description": appReq.Description,
+			"version":     "1.0.0",
+		},
+		Paths: paths,
+		Components: map[string]interface{}{
+			"schemas":         schemas,
+			"securitySchemes": securitySchemes,
+		},
+		Security: security,
+	}
+
+	yamlData, err := yaml.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to encode openapi.yaml: %v", err)
+	}
+	jsonData, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode openapi.json: %v", err)
+	}
+
+	for _, out := range []struct {
+		name string
+		data []byte
+	}{{"openapi.yaml", yamlData}, {"openapi.json", jsonData}} {
+		file, err := cg.createFile(filepath.Join(appDir, out.name))
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(out.data); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entitySchema returns the object schema of an entity's JSON representation
+func (cg *CodeGenerator) entitySchema(entity requirements.Entity) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for _, field := range entity.Fields {
+		name := strings.ToLower(field.Name)
+		properties[name] = cg.mapFieldTypeToOpenAPI(field.Type)
+		if field.Required && field.Name != "id" && !field.IsAutoManaged() {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// endpointOperation describes an endpoint from the requirements: query and
+// path parameters become parameters, body parameters the request body and the
+// response fields the success response
+func (cg *CodeGenerator) endpointOperation(endpoint requirements.APIEndpoint, path string, entities []requirements.Entity, entityNames map[string]bool) map[string]interface{} {
+	summary := endpoint.Description
+	if summary == "" {
+		summary = strings.ToUpper(endpoint.Method) + " " + path
+	}
+	operation := map[string]interface{}{"summary": summary}
+
+	parameters := []interface{}{}
+	declared := map[string]bool{}
+	var bodyParams []requirements.EndpointParam
+	for _, param := range endpoint.Parameters {
+		switch param.Source {
+		case "path", "query":
+			declared[param.Name] = true
+			parameters = append(parameters, map[string]interface{}{
+				"name":     param.Name,
+				"in":       param.Source,
+				"required": param.Required || param.Source == "path",
+				"schema":   cg.openAPISchema(param.Type, entityNames),
+			})
+		case "body":
+			bodyParams = append(bodyParams, param)
+		}
+	}
+	// OpenAPI requires every templated path segment to be declared
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		if !declared[match[1]] {
+			parameters = append(parameters, map[string]interface{}{
+				"name": match[1], "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if body := cg.requestBodySchema(bodyParams, entityForPath(path, entities), entityNames); body != nil {
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": body}},
+		}
+	}
+
+	status := "200"
+	if strings.EqualFold(endpoint.Method, "POST") {
+		status = "201"
+	}
+	success := map[string]interface{}{"description": "Successful response"}
+	if len(endpoint.Response) > 0 {
+		properties := map[string]interface{}{}
+		for name, typ := range endpoint.Response {
+			properties[name] = cg.openAPISchema(typ, entityNames)
+		}
+		success["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": map[string]interface{}{"type": "object", "properties": properties}},
+		}
+	}
+	responses := map[string]interface{}{status: success}
+	if strings.Contains(path, "{") {
+		responses["404"] = map[string]interface{}{"description": "Not found"}
+	}
+	operation["responses"] = responses
+
+	return operation
+}
+
+// requestBodySchema returns the schema of an endpoint's body parameters, or
+// nil without any. A lone parameter named body is the whole body; when its
+// type is not an entity it is taken to be the entity the path belongs to.
+func (cg *CodeGenerator) requestBodySchema(params []requirements.EndpointParam, pathEntity string, entityNames map[string]bool) map[string]interface{} {
+	if len(params) == 0 {
+		return nil
+	}
+	if len(params) == 1 && (params[0].Name == "body" || entityNames[params[0].Type]) {
+		typ := params[0].Type
+		if !entityNames[typ] && pathEntity != "" {
+			typ = pathEntity
+		}
+		return cg.openAPISchema(typ, entityNames)
+	}
+
+	properties := map[string]interface{}{}
+	var required []string
+	for _, param := range params {
+		properties[param.Name] = cg.openAPISchema(param.Type, entityNames)
+		if param.Required {
+			required = append(required, param.Name)
+		}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// entityForPath returns the name of the entity whose collection a path such
+// as /api/users/{id} addresses, or "" if none does
+func entityForPath(path string, entities []requirements.Entity) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 1 && segments[0] == "api" {
+		segments = segments[1:]
+	}
+	for _, entity := range entities {
+		if segments[0] == tableName(entity) {
+			return entity.Name
+		}
+	}
+	return ""
+}
+
+// openAPIPath converts gin and express style :name parameters to {name}
+func openAPIPath(path string) string {
+	return pathParamPattern.ReplaceAllString(path, "{$1}")
+}
+
+// openAPISchema returns the schema of a requirement type: an entity name
+// references the entity's schema, []T is an array of T and other types are
+// field types
+func (cg *CodeGenerator) openAPISchema(typ string, entityNames map[string]bool) map[string]interface{} {
+	if strings.HasPrefix(typ, "[]") {
+		return map[string]interface{}{"type": "array", "items": cg.openAPISchema(strings.TrimPrefix(typ, "[]"), entityNames)}
+	}
+	if entityNames[typ] {
+		return map[string]interface{}{"$ref": "#/components/schemas/" + typ}
+	}
+	return cg.mapFieldTypeToOpenAPI(typ)
+}
+
+// mapFieldTypeToOpenAPI maps an entity field type to an OpenAPI schema
+func (cg *CodeGenerator) mapFieldTypeToOpenAPI(fieldType string) map[string]interface{} {
+	switch fieldType {
+	case "int":
+		return map[string]interface{}{"type": "integer"}
+	case "float":
+		return map[string]interface{}{"type": "number"}
+	case "bool":
+		return map[string]interface{}{"type": "boolean"}
+	case "date":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case "email":
+		return map[string]interface{}{"type": "string", "format": "email"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+// pagedListOperation describes a list endpoint of Go apps, which returns a page
+// selected by limit, offset and sort with the total number of records, in the
+// metadata of the response envelope if there is one
+func pagedListOperation(plural string, ref map[string]interface{}, envelope bool) map[string]interface{} {
+	integer := map[string]interface{}{"type": "integer"}
+	items := map[string]interface{}{"type": "array", "items": ref}
+	page := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"data":   items,
+			"total":  integer,
+			"limit":  integer,
+			"offset": integer,
+		},
+	}
+	if envelope {
+		page = envelopeSchema(items, map[string]interface{}{"total": integer, "limit": integer, "offset": integer})
+	}
+	return map[string]interface{}{
+		"summary": "List " + plural,
+		"parameters": []interface{}{
+			map[string]interface{}{"name": "limit", "in": "query", "schema": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 100, "default": 20}},
+			map[string]interface{}{"name": "offset", "in": "query", "schema": map[string]interface{}{"type": "integer", "minimum": 0, "default": 0}},
+			map[string]interface{}{"name": "sort", "in": "query", "description": "Column to sort by, prefixed with - for descending order", "schema": map[string]interface{}{"type": "string"}},
+		},
+		"responses": map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "Page of " + plural,
+				"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": page}},
+			},
+			"400": map[string]interface{}{"description": "Invalid limit, offset or sort"},
+		},
+	}
+}
+
+// dataSchema wraps a schema in the {"data": ...} envelope of generated responses
+func dataSchema(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": map[string]interface{}{"data": schema}}
+}
+
+// envelopeSchema wraps a schema in the {"data", "error", "meta"} envelope of Go
+// apps with the envelope response style, with the given metadata properties
+func envelopeSchema(schema interface{}, meta map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"data":  schema,
+			"error": map[string]interface{}{"type": "string", "nullable": true},
+			"meta":  map[string]interface{}{"type": "object", "properties": meta},
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIOperation is the part of an operation the tests inspect
+type openAPIOperation struct {
+	Parameters []struct {
+		Name     string            `yaml:"name"`
+		In       string            `yaml:"in"`
+		Required bool              `yaml:"required"`
+		Schema   map[string]string `yaml:"schema"`
+	} `yaml:"parameters"`
+	RequestBody struct {
+		Content map[string]struct {
+			Schema map[string]interface{} `yaml:"schema"`
+		} `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]interface{} `yaml:"responses"`
+}
+
+// openAPIPathItem holds the operations of a path
+type openAPIPathItem struct {
+	Parameters []interface{}     `yaml:"parameters"`
+	Get        *openAPIOperation `yaml:"get"`
+	Post       *openAPIOperation `yaml:"post"`
+	Put        *openAPIOperation `yaml:"put"`
+	Delete     *openAPIOperation `yaml:"delete"`
+}
+
+func TestGenerateOpenAPIYAML(t *testing.T) {
+	appReq := testRequirement()
+	appReq.Endpoints = []requirements.APIEndpoint{
+		{Method: "GET", Path: "/api/users", Description: "Search users", Parameters: []requirements.EndpointParam{
+			{Name: "q", Type: "string", Source: "query"},
+			{Name: "limit", Type: "int", Source: "query"},
+		}, Response: map[string]string{"data": "[]User"}},
+		{Method: "PUT", Path: "/api/users/:id", Parameters: []requirements.EndpointParam{
+			{Name: "id", Type: "int", Required: true, Source: "path"},
+			{Name: "body", Type: "string", Required: true, Source: "body"},
+		}, Response: map[string]string{"data": "User"}},
+		{Method: "POST", Path: "/api/users/{userId}/verify", Parameters: []requirements.EndpointParam{
+			{Name: "code", Type: "string", Required: true, Source: "body"},
+			{Name: "remember", Type: "bool", Source: "body"},
+		}},
+	}
+
+	for _, language := range []string{"go", "javascript"} {
+		t.Run(language, func(t *testing.T) {
+			appReq.Language = language
+			appReq.Framework = ""
+			appDir := generateTestApp(t, appReq)
+
+			data, err := os.ReadFile(filepath.Join(appDir, "openapi.yaml"))
+			if err != nil {
+				t.Fatalf("failed to read openapi.yaml: %v", err)
+			}
+			var spec struct {
+				OpenAPI    string                     `yaml:"openapi"`
+				Paths      map[string]openAPIPathItem `yaml:"paths"`
+				Components struct {
+					Schemas map[string]struct {
+						Properties map[string]map[string]string `yaml:"properties"`
+						Required   []string                     `yaml:"required"`
+					} `yaml:"schemas"`
+				} `yaml:"components"`
+			}
+			if err := yaml.Unmarshal(data, &spec); err != nil {
+				t.Fatalf("openapi.yaml does not parse: %v\n%s", err, data)
+			}
+			if spec.OpenAPI != "3.0.3" {
+				t.Errorf("expected OpenAPI 3.0.3, got %q", spec.OpenAPI)
+			}
+
+			search := spec.Paths["/api/users"].Get
+			update := spec.Paths["/api/users/{id}"].Put
+			verifyItem := spec.Paths["/api/users/{userId}/verify"]
+			if search == nil || update == nil || verifyItem.Post == nil {
+				t.Fatalf("expected GET /api/users, PUT /api/users/{id} and POST /api/users/{userId}/verify, got %+v", spec.Paths)
+			}
+			if verifyItem.Get != nil || verifyItem.Put != nil || verifyItem.Delete != nil {
+				t.Error("expected only the declared method on /api/users/{userId}/verify")
+			}
+
+			if len(search.Parameters) != 2 || search.Parameters[0].In != "query" || search.Parameters[1].Schema["type"] != "integer" {
+				t.Errorf("expected q and integer limit query parameters, got %+v", search.Parameters)
+			}
+
+			if len(update.Parameters) != 1 || update.Parameters[0].In != "path" || !update.Parameters[0].Required {
+				t.Errorf("expected a required id path parameter, got %+v", update.Parameters)
+			}
+			if ref := update.RequestBody.Content["application/json"].Schema["$ref"]; ref != "#/components/schemas/User" {
+				t.Errorf("expected the update body to reference User, got %v", update.RequestBody.Content)
+			}
+
+			verify := verifyItem.Post
+			if len(verify.Parameters) != 1 || verify.Parameters[0].Name != "userId" || verify.Parameters[0].In != "path" {
+				t.Errorf("expected the undeclared userId path parameter, got %+v", verify.Parameters)
+			}
+			if _, ok := verify.Responses["201"]; !ok {
+				t.Errorf("expected a 201 response for POST, got %v", verify.Responses)
+			}
+			properties := verify.RequestBody.Content["application/json"].Schema["properties"].(map[string]interface{})
+			if remember := properties["remember"].(map[string]interface{}); remember["type"] != "boolean" {
+				t.Errorf("expected a boolean remember body property, got %v", properties)
+			}
+
+			user := spec.Components.Schemas["User"]
+			if user.Properties["id"]["type"] != "integer" || user.Properties["email"]["format"] != "email" || user.Properties["created_at"]["format"] != "date-time" {
+				t.Errorf("unexpected User schema properties %v", user.Properties)
+			}
+			if len(user.Required) != 2 || user.Required[0] != "username" || user.Required[1] != "email" {
+				t.Errorf("expected username and email to be required, got %v", user.Required)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package codegen
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+// generatePythonAPIApplication generates a Flask or FastAPI application
+func (cg *CodeGenerator) generatePythonAPIApplication(appDir string, appReq *requirements.ApplicationRequirement) error {
+	for _, dir := range []string{"models", "routes", "schemas"} {
+		if dir == "schemas" && !isFastAPI(appReq) {
+			continue
+		}
+		if err := cg.mkdirAll(filepath.Join(appDir, dir)); err != nil {
+			return fmt.Errorf("failed to create %s directory: %v", dir, err)
+		}
+	}
+
+	data := cg.preparePythonAppData(appReq)
+
+	// Generate requirements.txt
+	if err := cg.writeTemplate("python/requirements.txt", filepath.Join(appDir, "requirements.txt"), data); err != nil {
+		return fmt.Errorf("failed to generate requirements.txt: %w", err)
+	}
+
+	// Generate database setup
+	if err := cg.writeTemplate("python/database.py", filepath.Join(appDir, "database.py"), data); err != nil {
+		return fmt.Errorf("failed to generate database.py: %w", err)
+	}
+
+	// Generate models, schemas and routes for each entity
+	if err := cg.generatePythonEntities(appDir, data); err != nil {
+		return err
+	}
+
+	// Generate main application file
+	if err := cg.writeTemplate("python/app.py", filepath.Join(appDir, "app.py"), data); err != nil {
+		return fmt.Errorf("failed to generate app.py: %w", err)
+	}
+
+	// Generate tests
+	if err := cg.writeTemplate("python/test_app.py", filepath.Join(appDir, "test_app.py"), data); err != nil {
+		return fmt.Errorf("failed to generate test_app.py: %w", err)
+	}
+
+	// Generate Dockerfile
+	if err := cg.writeTemplate("python/Dockerfile.py", filepath.Join(appDir, "Dockerfile"), data); err != nil {
+		return fmt.Errorf("failed to generate Dockerfile: %w", err)
+	}
+
+	// Generate README
+	if err := cg.writeTemplate("python/README.py.md", filepath.Join(appDir, "README.md"), data); err != nil {
+		return fmt.Errorf("failed to generate README.md: %w", err)
+	}
+
+	return nil
+}
+
+// generatePythonEntities generates the model, route and (for FastAPI) schema
+// modules of every entity along with their package __init__ files
+func (cg *CodeGenerator) generatePythonEntities(appDir string, data map[string]interface{}) error {
+	routeTemplate := "python/route_flask.py"
+	if data["FastAPI"].(bool) {
+		routeTemplate = "python/route_fastapi.py"
+	}
+
+	entities := data["Entities"].([]map[string]interface{})
+	for _, entity := range entities {
+		entity["FastAPI"] = data["FastAPI"]
+		module := entity["LowerName"].(string) + ".py"
+
+		if err := cg.writeTemplate("python/model.py", filepath.Join(appDir, "models", module), entity); err != nil {
+			return fmt.Errorf("failed to generate model %s: %w", module, err)
+		}
+		if err := cg.writeTemplate(routeTemplate, filepath.Join(appDir, "routes", module), entity); err != nil {
+			return fmt.Errorf("failed to generate route %s: %w", module, err)
+		}
+		if data["FastAPI"].(bool) {
+			if err := cg.writeTemplate("python/schema.py", filepath.Join(appDir, "schemas", module), entity); err != nil {
+				return fmt.Errorf("failed to generate schema %s: %w", module, err)
+			}
+		}
+	}
+
+	// models/__init__.py imports every model so create_all sees their tables
+	var imports strings.Builder
+	for _, entity := range entities {
+		fmt.Fprintf(&imports, "from models.%s import %s  # noqa: F401\n", entity["LowerName"], entity["Name"])
+	}
+	packages := map[string]string{
+		filepath.Join("models", "__init__.py"): imports.String(),
+		filepath.Join("routes", "__init__.py"): "",
+	}
+	if data["FastAPI"].(bool) {
+		packages[filepath.Join("schemas", "__init__.py")] = ""
+	} else if err := cg.writeTemplate("python/validation.py", filepath.Join(appDir, "validation.py"), data); err != nil {
+		return fmt.Errorf("failed to generate validation.py: %w", err)
+	}
+
+	for path, content := range packages {
+		file, err := cg.createFile(filepath.Join(appDir, path))
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		_, err = file.WriteString(content)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// isFastAPI reports whether a Python application should use FastAPI rather than Flask
+func isFastAPI(appReq *requirements.ApplicationRequirement) bool {
+	return appReq.Framework == "fastapi"
+}
+
+// preparePythonAppData prepares the template data shared by all Python files
+func (cg *CodeGenerator) preparePythonAppData(appReq *requirements.ApplicationRequirement) map[string]interface{} {
+	var entities []map[string]interface{}
+	for _, entity := range appReq.Entities {
+		entities = append(entities, cg.preparePythonEntityData(entity))
+	}
+
+	var endpoints []string
+	for _, endpoint := range appReq.Endpoints {
+		endpoints = append(endpoints, strconv.Quote(endpoint.Method+" "+endpoint.Path))
+	}
+
+	return map[string]interface{}{
+		"Name":         appReq.Name,
+		"Description":  appReq.Description,
+		"Features":     appReq.Features,
+		"Endpoints":    appReq.Endpoints,
+		"EndpointList": strings.Join(endpoints, ", "),
+		"Port":         fmt.Sprintf("%v", appReq.Config["port"]),
+		"DockerName":   appSlug(appReq),
+		"FastAPI":      isFastAPI(appReq),
+		"Entities":     entities,
+	}
+}
+
+// preparePythonEntityData prepares the template data for an entity's Python modules
+func (cg *CodeGenerator) preparePythonEntityData(entity requirements.Entity) map[string]interface{} {
+	lowerName := strings.ToLower(entity.Name)
+	data := map[string]interface{}{
+		"Name":      entity.Name,
+		"LowerName": lowerName,
+		"TableName": lowerName + "s",
+	}
+
+	var fields, writable, payload []map[string]interface{}
+	columnTypes := map[string]bool{"Column": true, "Integer": true}
+	autoNow, writableDatetime, hasID := false, false, false
+
+	for _, field := range entity.Fields {
+		name := strings.ToLower(field.Name)
+		autoManaged := field.IsAutoManaged()
+		pyType := cg.mapFieldTypeToPython(field.Type)
+		column := cg.mapFieldTypeToSQLAlchemy(field.Type)
+		columnTypes[strings.SplitN(column, "(", 2)[0]] = true
+
+		info := map[string]interface{}{
+			"Name":       name,
+			"PyType":     pyType,
+			"Column":     column,
+			"Required":   field.Required,
+			"PrimaryKey": name == "id",
+			"AutoNow":    autoManaged,
+			"OnUpdate":   field.SetOnUpdate(),
+			// Dates the app does not set are optional so clients can omit them
+			"Nullable": !field.Required || (field.Type == "date" && !autoManaged),
+		}
+		fields = append(fields, info)
+
+		switch {
+		case name == "id":
+			hasID = true
+		case autoManaged:
+			autoNow = true
+		default:
+			writableDatetime = writableDatetime || pyType == "datetime"
+			writable = append(writable, info)
+			payload = append(payload, map[string]interface{}{
+				"Name":  name,
+				"Value": cg.pythonLiteral(field.Type, cg.syntheticValue(entity, field, 1)),
+			})
+		}
+	}
+
+	if !hasID {
+		fields = append([]map[string]interface{}{{
+			"Name": "id", "PyType": "int", "Column": "Integer", "PrimaryKey": true,
+		}}, fields...)
+	}
+
+	var imports []string
+	for columnType := range columnTypes {
+		imports = append(imports, columnType)
+	}
+	sort.Strings(imports)
+
+	data["Fields"] = fields
+	data["WritableFields"] = writable
+	data["Payload"] = payload
+	data["ColumnImports"] = strings.Join(imports, ", ")
+	// datetime is imported only where it is referenced: the timestamp defaults
+	// in models and the field types of routes and schemas
+	data["ModelDatetime"] = autoNow
+	data["WritableDatetime"] = writableDatetime
+	return data
+}
+
+// mapFieldTypeToPython maps field types to Python types
+func (cg *CodeGenerator) mapFieldTypeToPython(fieldType string) string {
+	switch fieldType {
+	case "string", "email":
+		return "str"
+	case "int":
+		return "int"
+	case "float":
+		return "float"
+	case "bool":
+		return "bool"
+	case "date":
+		return "datetime"
+	default:
+		return "str"
+	}
+}
+
+// mapFieldTypeToSQLAlchemy maps field types to SQLAlchemy column types
+func (cg *CodeGenerator) mapFieldTypeToSQLAlchemy(fieldType string) string {
+	switch fieldType {
+	case "string", "email":
+		return "String(255)"
+	case "int":
+		return "Integer"
+	case "float":
+		return "Float"
+	case "bool":
+		return "Boolean"
+	case "date":
+		return "DateTime"
+	default:
+		return "String(255)"
+	}
+}
+
+// pythonLiteral renders a value as a Python literal matching the field type
+func (cg *CodeGenerator) pythonLiteral(fieldType string, value interface{}) string {
+	switch fieldType {
+	case "bool":
+		if v, ok := value.(bool); ok && v {
+			return "True"
+		}
+		return "False"
+	case "date":
+		return strconv.Quote("2024-01-01T00:00:00")
+	}
+	return cg.seedLiteral(fieldType, value)
+}
@@ -0,0 +1,54 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+// ErrUnknownComponent is returned by RegenerateComponent for a component it cannot regenerate
+var ErrUnknownComponent = errors.New("unknown component")
+
+// ErrNotGoApplication is returned by RegenerateComponent when the target directory has no go.mod
+var ErrNotGoApplication = errors.New("not a generated Go application")
+
+// componentGenerators are the parts of a Go API application that can be
+// regenerated on their own
+var componentGenerators = map[string]func(cg *CodeGenerator, appDir string, appReq *requirements.ApplicationRequirement) error{
+	"models":     (*CodeGenerator).generateModels,
+	"handlers":   (*CodeGenerator).generateHandlers,
+	"routes":     (*CodeGenerator).generateRoutes,
+	"config":     (*CodeGenerator).generateConfig,
+	"dockerfile": (*CodeGenerator).generateDockerfile,
+}
+
+// Components lists the components accepted by RegenerateComponent
+var Components = []string{"models", "handlers", "routes", "config", "dockerfile"}
+
+// RegenerateComponent rewrites one component (models, handlers, routes, config
+// or dockerfile) of the Go application in appDir, leaving its other files,
+// including manual edits to them, untouched
+func (cg *CodeGenerator) RegenerateComponent(appDir string, appReq *requirements.ApplicationRequirement, component string) error {
+	generate, ok := componentGenerators[component]
+	if !ok {
+		return fmt.Errorf("%w: %s (expected one of %s)", ErrUnknownComponent, component, strings.Join(Components, ", "))
+	}
+	if _, err := os.Stat(filepath.Join(appDir, "go.mod")); err != nil {
+		return fmt.Errorf("%w: no go.mod in %s", ErrNotGoApplication, appDir)
+	}
+
+	cg.mutex.Lock()
+	defer cg.mutex.Unlock()
+	cg.filesWritten = 0
+	cg.bytesWritten = 0
+	cg.planned = nil
+
+	if err := generate(cg, appDir, appReq); err != nil {
+		return fmt.Errorf("failed to regenerate %s: %w", component, err)
+	}
+	return nil
+}
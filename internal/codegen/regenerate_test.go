@@ -0,0 +1,72 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegenerateComponent(t *testing.T) {
+	components := map[string]string{
+		"models":     "internal/models/user.go",
+		"handlers":   "internal/handlers/user_handler.go",
+		"routes":     "internal/routes/routes.go",
+		"config":     "internal/config/config.go",
+		"dockerfile": "Dockerfile",
+	}
+	if len(components) != len(Components) {
+		t.Fatalf("expected a case for each of %v", Components)
+	}
+
+	for component, file := range components {
+		t.Run(component, func(t *testing.T) {
+			appDir := generateTestApp(t, testRequirement())
+			target := filepath.Join(appDir, filepath.FromSlash(file))
+			untouched := filepath.Join(appDir, "main.go")
+
+			original, err := os.ReadFile(target)
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", file, err)
+			}
+			const edit = "// manual edit\n"
+			for _, path := range []string{target, untouched} {
+				if err := os.WriteFile(path, []byte(edit), 0644); err != nil {
+					t.Fatalf("failed to edit %s: %v", path, err)
+				}
+			}
+
+			cg := NewCodeGenerator(filepath.Dir(appDir))
+			if err := cg.RegenerateComponent(appDir, testRequirement(), component); err != nil {
+				t.Fatalf("RegenerateComponent failed: %v", err)
+			}
+
+			if got, _ := os.ReadFile(target); string(got) != string(original) {
+				t.Errorf("expected %s to be regenerated, got:\n%s", file, got)
+			}
+			if got, _ := os.ReadFile(untouched); string(got) != edit {
+				t.Errorf("expected main.go to keep its manual edit, got:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestRegenerateComponentValidation(t *testing.T) {
+	appDir := generateTestApp(t, testRequirement())
+	cg := NewCodeGenerator(filepath.Dir(appDir))
+
+	err := cg.RegenerateComponent(appDir, testRequirement(), "frontend")
+	if !errors.Is(err, ErrUnknownComponent) || !strings.Contains(err.Error(), "models") {
+		t.Errorf("expected ErrUnknownComponent listing the components, got %v", err)
+	}
+
+	// Without a go.mod nothing is written
+	emptyDir := t.TempDir()
+	if err := cg.RegenerateComponent(emptyDir, testRequirement(), "models"); !errors.Is(err, ErrNotGoApplication) {
+		t.Errorf("expected ErrNotGoApplication, got %v", err)
+	}
+	if entries, _ := os.ReadDir(emptyDir); len(entries) != 0 {
+		t.Errorf("expected no files written without a go.mod, got %v", entries)
+	}
+}
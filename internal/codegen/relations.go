@@ -0,0 +1,251 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+)
+
+// foreignKey is a column on a child table referencing its parent's id
+type foreignKey struct {
+	Column      string // user_id
+	GoName      string // UserID
+	Parent      string // User
+	ParentTable string // users
+	Existing    bool   // the child already declares the column as a field
+}
+
+// joinTable links two entity tables in a many-to-many relation
+type joinTable struct {
+	Name        string
+	LeftTable   string
+	LeftColumn  string
+	RightTable  string
+	RightColumn string
+}
+
+// manyToMany is one side of a many-to-many relation, seen from the owning entity
+type manyToMany struct {
+	Related       requirements.Entity
+	Table         joinTable
+	OwnColumn     string
+	RelatedColumn string
+}
+
+// relationSchema holds the foreign keys and join tables implied by entity relations
+type relationSchema struct {
+	foreignKeys map[string][]foreignKey // keyed by child entity name
+	manyToMany  map[string][]manyToMany // keyed by owning entity name
+	joinTables  []joinTable
+}
+
+// buildRelationSchema resolves entity relations into foreign keys and join tables.
+// one-to-many puts the key on the target, many-to-one on the declaring entity;
+// relations to unknown entities are ignored.
+func buildRelationSchema(entities []requirements.Entity) *relationSchema {
+	schema := &relationSchema{
+		foreignKeys: map[string][]foreignKey{},
+		manyToMany:  map[string][]manyToMany{},
+	}
+
+	for _, entity := range entities {
+		for _, relation := range entity.Relations {
+			target, ok := findEntity(entities, relation.Target)
+			if !ok {
+				continue
+			}
+			switch strings.ToLower(relation.Type) {
+			case "one-to-many":
+				schema.addForeignKey(target, entity)
+			case "many-to-one":
+				schema.addForeignKey(entity, target)
+			case "many-to-many":
+				schema.addManyToMany(entity, target)
+			}
+		}
+	}
+
+	return schema
+}
+
+// addForeignKey records a key on child referencing parent, once per pair
+func (s *relationSchema) addForeignKey(child, parent requirements.Entity) {
+	column := strings.ToLower(parent.Name) + "_id"
+	for _, fk := range s.foreignKeys[child.Name] {
+		if fk.Column == column {
+			return
+		}
+	}
+
+	existing := false
+	for _, field := range child.Fields {
+		if field.Name == column {
+			existing = true
+		}
+	}
+
+	s.foreignKeys[child.Name] = append(s.foreignKeys[child.Name], foreignKey{
+		Column:      column,
+		GoName:      parent.Name + "ID",
+		Parent:      parent.Name,
+		ParentTable: tableName(parent),
+		Existing:    existing,
+	})
+}
+
+// addManyToMany records the join table for owner and related, named after both
+// entities in alphabetical order so either side declaring the relation shares it
+func (s *relationSchema) addManyToMany(owner, related requirements.Entity) {
+	left, right := owner, related
+	if strings.ToLower(right.Name) < strings.ToLower(left.Name) {
+		left, right = right, left
+	}
+	table := joinTable{
+		Name:        strings.ToLower(left.Name) + "_" + strings.ToLower(right.Name),
+		LeftTable:   tableName(left),
+		LeftColumn:  strings.ToLower(left.Name) + "_id",
+		RightTable:  tableName(right),
+		RightColumn: strings.ToLower(right.Name) + "_id",
+	}
+	if table.LeftColumn == table.RightColumn {
+		table.RightColumn = "related_" + table.RightColumn
+	}
+
+	ownColumn, relatedColumn := table.LeftColumn, table.RightColumn
+	if left.Name != owner.Name {
+		ownColumn, relatedColumn = relatedColumn, ownColumn
+	}
+
+	for _, m := range s.manyToMany[owner.Name] {
+		if m.Table.Name == table.Name {
+			return
+		}
+	}
+	s.manyToMany[owner.Name] = append(s.manyToMany[owner.Name], manyToMany{
+		Related:       related,
+		Table:         table,
+		OwnColumn:     ownColumn,
+		RelatedColumn: relatedColumn,
+	})
+
+	for _, existing := range s.joinTables {
+		if existing.Name == table.Name {
+			return
+		}
+	}
+	s.joinTables = append(s.joinTables, table)
+}
+
+// migrationStatements returns the CREATE statements for every entity table,
+// the join tables and the indexes backing foreign keys. Parent tables are
+// created before the tables referencing them.
+func (cg *CodeGenerator) migrationStatements(entities []requirements.Entity, dialect sqlDialect) []string {
+	schema := buildRelationSchema(entities)
+
+	var statements []string
+	for _, entity := range schema.creationOrder(entities) {
+		statements = append(statements, cg.generateCreateTableSQL(entity, schema.foreignKeys[entity.Name], dialect))
+	}
+
+	for _, table := range schema.joinTables {
+		statements = append(statements, fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (%s INTEGER NOT NULL REFERENCES %s(id) ON DELETE CASCADE, %s INTEGER NOT NULL REFERENCES %s(id) ON DELETE CASCADE, PRIMARY KEY (%s, %s))",
+			table.Name, table.LeftColumn, table.LeftTable, table.RightColumn, table.RightTable, table.LeftColumn, table.RightColumn))
+	}
+
+	if dialect.Name == mysqlDialect.Name {
+		return statements
+	}
+	for _, entity := range entities {
+		for _, fk := range schema.foreignKeys[entity.Name] {
+			table := tableName(entity)
+			statements = append(statements, fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_%s ON %s (%s)", table, fk.Column, table, fk.Column))
+		}
+	}
+
+	return statements
+}
+
+// creationOrder returns entities with every parent ahead of its children, keeping
+// the declared order otherwise; entities in a reference cycle stay in place
+func (s *relationSchema) creationOrder(entities []requirements.Entity) []requirements.Entity {
+	var ordered []requirements.Entity
+	added := map[string]bool{}
+	visiting := map[string]bool{}
+
+	var visit func(entity requirements.Entity)
+	visit = func(entity requirements.Entity) {
+		if added[entity.Name] || visiting[entity.Name] {
+			return
+		}
+		visiting[entity.Name] = true
+		for _, fk := range s.foreignKeys[entity.Name] {
+			if parent, ok := findEntity(entities, fk.Parent); ok {
+				visit(parent)
+			}
+		}
+		added[entity.Name] = true
+		ordered = append(ordered, entity)
+	}
+
+	for _, entity := range entities {
+		visit(entity)
+	}
+	return ordered
+}
+
+// prepareRelationData adds the foreign-key lookups and many-to-many helpers for
+// entity to its model template data
+func (cg *CodeGenerator) prepareRelationData(data map[string]interface{}, entity requirements.Entity, schema *relationSchema, dialect sqlDialect) {
+	var lookups []map[string]interface{}
+	for _, fk := range schema.foreignKeys[entity.Name] {
+		lookups = append(lookups, map[string]interface{}{
+			"Parent":    fk.Parent,
+			"Column":    fk.Column,
+			"ParamName": strings.ToLower(fk.Parent) + "ID",
+		})
+	}
+
+	var associations []map[string]interface{}
+	for _, m := range schema.manyToMany[entity.Name] {
+		related := cg.prepareModelData(m.Related, schema.foreignKeys[m.Related.Name], dialect)
+		relatedTable := related["TableName"].(string)
+
+		var columns []string
+		for _, column := range related["SelectColumns"].([]string) {
+			columns = append(columns, relatedTable+"."+column)
+		}
+
+		associations = append(associations, map[string]interface{}{
+			"Related":       m.Related.Name,
+			"RelatedLower":  related["LowerName"],
+			"RelatedTable":  relatedTable,
+			"JoinTable":     m.Table.Name,
+			"LinkQuery":     dialect.insertIgnore(m.Table.Name, m.OwnColumn, m.RelatedColumn),
+			"OwnColumn":     m.OwnColumn,
+			"RelatedColumn": m.RelatedColumn,
+			"SelectFields":  strings.Join(columns, ", "),
+			"ScanFields":    related["ScanFields"],
+			"ScanArgs":      related["ScanArgs"],
+		})
+	}
+
+	data["ForeignKeyLookups"] = lookups
+	data["ManyToMany"] = associations
+}
+
+// findEntity looks up an entity by name, ignoring case
+func findEntity(entities []requirements.Entity, name string) (requirements.Entity, bool) {
+	for _, entity := range entities {
+		if strings.EqualFold(entity.Name, name) {
+			return entity, true
+		}
+	}
+	return requirements.Entity{}, false
+}
+
+// tableName returns the SQL table for an entity
+func tableName(entity requirements.Entity) string {
+	return entity.TableName()
+}
@@ -0,0 +1,189 @@
+package codegen
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// builtinTemplates holds the default templates in a directory per language,
+// such as templates/go/main.go.tmpl
+//
+//go:embed templates
+var builtinTemplates embed.FS
+
+// templateLanguages are the language directories of the built-in templates,
+// which a templates directory may mirror
+var templateLanguages = []string{"go", "javascript", "python"}
+
+// templateFuncs are available to built-in and override templates alike
+var templateFuncs = template.FuncMap{
+	"sub": func(a, b int) int { return a - b },
+}
+
+// defaultTemplates are the built-in templates keyed by language and file name
+// without the .tmpl extension, such as "go/model.go". They are parsed once
+// when the package loads, so a broken template fails at startup.
+var defaultTemplates = parseBuiltinTemplates()
+
+// TemplateNames lists the built-in templates that can be overridden by
+// placing a file of the same name in the templates directory, or in its
+// subdirectory for the template's language
+var TemplateNames = builtinTemplateNames()
+
+// templateKey returns the key of the template file name of language
+func templateKey(language, name string) string {
+	return language + "/" + strings.TrimSuffix(name, ".tmpl")
+}
+
+// parseBuiltinTemplates parses every embedded template, panicking on the
+// first that does not parse
+func parseBuiltinTemplates() map[string]*template.Template {
+	templates := make(map[string]*template.Template)
+	for _, language := range templateLanguages {
+		entries, err := builtinTemplates.ReadDir(path.Join("templates", language))
+		if err != nil {
+			panic(fmt.Sprintf("codegen: failed to read built-in templates: %v", err))
+		}
+		for _, entry := range entries {
+			content, err := builtinTemplates.ReadFile(path.Join("templates", language, entry.Name()))
+			if err != nil {
+				panic(fmt.Sprintf("codegen: failed to read built-in template: %v", err))
+			}
+			templates[templateKey(language, entry.Name())] = template.Must(template.New(entry.Name()).Funcs(templateFuncs).Parse(string(content)))
+		}
+	}
+	return templates
+}
+
+// builtinTemplateNames returns the file names of the built-in templates,
+// language by language
+func builtinTemplateNames() []string {
+	var names []string
+	for _, language := range templateLanguages {
+		entries, _ := builtinTemplates.ReadDir(path.Join("templates", language))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+// LoadTemplates loads template overrides from dir and from its go, javascript
+// and python subdirectories, so TEMPLATES_DIR/go/main.go.tmpl replaces the
+// main.go of Go applications. Every *.tmpl file must match one of
+// TemplateNames, of the subdirectory's language if it is in one, and parse
+// cleanly; built-in templates are used for the rest.
+func (cg *CodeGenerator) LoadTemplates(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read templates directory: %v", err)
+	}
+
+	templates := make(map[string]*template.Template, len(defaultTemplates))
+	for key, tmpl := range defaultTemplates {
+		templates[key] = tmpl
+	}
+	overridden := make(map[string]bool)
+
+	if err := loadTemplateOverrides(dir, templateLanguages, templates, overridden); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if !isTemplateLanguage(name) {
+			return fmt.Errorf("unknown template language directory: %s", name)
+		}
+		if err := loadTemplateOverrides(filepath.Join(dir, name), []string{name}, templates, overridden); err != nil {
+			return err
+		}
+	}
+
+	cg.mutex.Lock()
+	defer cg.mutex.Unlock()
+	cg.templates = templates
+
+	return nil
+}
+
+// isTemplateLanguage reports whether name is one of templateLanguages
+func isTemplateLanguage(name string) bool {
+	for _, language := range templateLanguages {
+		if language == name {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTemplateOverrides parses the *.tmpl files of dir into templates. Each
+// must name a built-in template of one of languages that is not overridden
+// already.
+func loadTemplateOverrides(dir string, languages []string, templates map[string]*template.Template, overridden map[string]bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read templates directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".tmpl" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+
+		key := ""
+		for _, language := range languages {
+			if _, ok := defaultTemplates[templateKey(language, name)]; ok {
+				key = templateKey(language, name)
+				break
+			}
+		}
+		if key == "" {
+			return fmt.Errorf("unknown template override: %s", path)
+		}
+		if overridden[key] {
+			return fmt.Errorf("template %s is overridden more than once", name)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read template %s: %v", path, err)
+		}
+
+		tmpl, err := template.New(name).Funcs(templateFuncs).Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to parse template %s: %v", path, err)
+		}
+		templates[key] = tmpl
+		overridden[key] = true
+	}
+	return nil
+}
+
+// lookupTemplate returns the template for key, such as "go/model.go": the
+// loaded override if there is one, otherwise the built-in template
+func (cg *CodeGenerator) lookupTemplate(key string) (*template.Template, error) {
+	tmpl, ok := cg.templates[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown template: %s", key)
+	}
+	return tmpl, nil
+}
+
+// writeTemplate renders the template for key, or its override, into the given file
+func (cg *CodeGenerator) writeTemplate(key, path string, data interface{}) error {
+	tmpl, err := cg.lookupTemplate(key)
+	if err != nil {
+		return err
+	}
+
+	return cg.renderFile(path, tmpl, data)
+}
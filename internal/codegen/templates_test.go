@@ -0,0 +1,48 @@
+package codegen
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestBuiltinTemplatesParse(t *testing.T) {
+	count := 0
+	err := fs.WalkDir(builtinTemplates, "templates", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		count++
+
+		content, err := builtinTemplates.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if _, err := template.New(entry.Name()).Funcs(templateFuncs).Parse(string(content)); err != nil {
+			t.Errorf("%s does not parse: %v", path, err)
+		}
+
+		language := strings.Split(path, "/")[1]
+		if _, ok := defaultTemplates[templateKey(language, entry.Name())]; !ok {
+			t.Errorf("%s is not registered as %s", path, templateKey(language, entry.Name()))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk the built-in templates: %v", err)
+	}
+	if count == 0 || count != len(defaultTemplates) || count != len(TemplateNames) {
+		t.Errorf("found %d template files, %d registered and %d names", count, len(defaultTemplates), len(TemplateNames))
+	}
+
+	// Overrides in the top-level templates directory are matched by file
+	// name alone, so no two languages may share one
+	seen := make(map[string]bool)
+	for _, name := range TemplateNames {
+		if seen[name] {
+			t.Errorf("template %s exists for more than one language", name)
+		}
+		seen[name] = true
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
 	"log"
+	"net/url"
 	"os"
+	"strconv"
 	"path/filepath"
 	"time"
 	"strings"
@@ -14,35 +16,88 @@ import (
 const dbFileName = "finetuning.db"
 
 type InteractionLog struct {
-	ID                     string
-	Timestamp              time.Time
-	Endpoint               string
-	RequestPayload         string
-	ResponsePayload        string
-	AppName                string
-	AppPath                string
-	TestResultsJSON        string
-	AnalysisResultsJSON    string
-	FeedbackJSON           string
-	Status                 string
-	ProcessedForFinetuning bool
+	ID                     string    `json:"id"`
+	ParentID               string    `json:"parent_id,omitempty"` // ID of the interaction this one continues, empty for top-level requests
+	Timestamp              time.Time `json:"timestamp"`
+	Endpoint               string    `json:"endpoint"`
+	RequestPayload         string    `json:"request_payload,omitempty"`
+	ResponsePayload        string    `json:"response_payload,omitempty"`
+	AppName                string    `json:"app_name,omitempty"`
+	AppPath                string    `json:"app_path,omitempty"`
+	TestResultsJSON        string    `json:"test_results_json,omitempty"`
+	AnalysisResultsJSON    string    `json:"analysis_results_json,omitempty"`
+	FeedbackJSON           string    `json:"feedback_json,omitempty"`
+	Status                 string    `json:"status"`
+	ProcessedForFinetuning bool      `json:"processed_for_finetuning"`
+}
+
+// PromptHint is corrective guidance for the LLM derived from recurring test failures
+type PromptHint struct {
+	Hint        string    `json:"hint"`
+	TestType    string    `json:"test_type"` // build, static, api...
+	Occurrences int       `json:"occurrences"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// LogFilter selects interaction logs; zero fields do not filter
+type LogFilter struct {
+	Endpoint string
+	Status   string
+	Since    time.Time // inclusive
+	Until    time.Time // exclusive
+	Limit    int
+	Offset   int
 }
 
 type DB struct {
 	*sql.DB
 }
 
+// Options tune the SQLite connection
+type Options struct {
+	JournalMode  string        // e.g. WAL; empty keeps SQLite's default
+	BusyTimeout  time.Duration // how long a statement waits on a locked database before failing
+	MaxOpenConns int           // zero means unlimited
+}
+
+// DefaultOptions enables WAL with a 5s busy timeout over a single connection,
+// which queues concurrent writes from the HTTP handlers and the fine-tuner
+// instead of failing them with "database is locked"
+func DefaultOptions() Options {
+	return Options{JournalMode: "WAL", BusyTimeout: 5 * time.Second, MaxOpenConns: 1}
+}
+
+// NewDB opens the fine-tuning database in dataDir with DefaultOptions
 func NewDB(dataDir string) (*DB, error) {
+	return NewDBWithOptions(dataDir, DefaultOptions())
+}
+
+// NewDBWithOptions opens the fine-tuning database in dataDir, creating it and
+// its tables as needed
+func NewDBWithOptions(dataDir string, opts Options) (*DB, error) {
 	dbPath := filepath.Join(dataDir, dbFileName)
 	// Ensure the directory exists
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
+	params := url.Values{}
+	if opts.JournalMode != "" {
+		params.Set("_journal_mode", opts.JournalMode)
+	}
+	if opts.BusyTimeout > 0 {
+		params.Set("_busy_timeout", strconv.FormatInt(opts.BusyTimeout.Milliseconds(), 10))
+	}
+	dsn := dbPath
+	if len(params) > 0 {
+		dsn += "?" + params.Encode()
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db.SetMaxOpenConns(opts.MaxOpenConns)
 
 	if err = db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -70,13 +125,59 @@ func createTables(db *sql.DB) error {
 		analysis_results_json TEXT,
 		feedback_json TEXT,
 		status TEXT NOT NULL,
-		processed_for_finetuning INTEGER DEFAULT 0
+		processed_for_finetuning INTEGER DEFAULT 0,
+		parent_id TEXT
 	);
 	CREATE INDEX IF NOT EXISTS idx_timestamp ON interactions_log (timestamp);
 	CREATE INDEX IF NOT EXISTS idx_endpoint ON interactions_log (endpoint);
 	CREATE INDEX IF NOT EXISTS idx_processed ON interactions_log (processed_for_finetuning);
+	CREATE TABLE IF NOT EXISTS prompt_hints (
+		hint TEXT PRIMARY KEY,
+		test_type TEXT NOT NULL,
+		occurrences INTEGER NOT NULL DEFAULT 0,
+		updated_at TEXT NOT NULL
+	);
 	`
-	_, err := db.Exec(sqlStmt)
+	if _, err := db.Exec(sqlStmt); err != nil {
+		return err
+	}
+
+	// Databases created before parent_id existed get the column added
+	if err := addColumnIfMissing(db, "interactions_log", "parent_id", "TEXT"); err != nil {
+		return err
+	}
+	_, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_parent_id ON interactions_log (parent_id);`)
+	return err
+}
+
+// addColumnIfMissing adds a column to an existing table unless it is already there
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name, typ  string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &defaultVal, &pk); err != nil {
+			return fmt.Errorf("failed to scan column info: %w", err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
 	return err
 }
 
@@ -84,8 +185,8 @@ func (d *DB) InsertInteractionLog(logEntry InteractionLog) error {
 	stmt, err := d.Prepare(`
 	INSERT INTO interactions_log (
 		id, timestamp, endpoint, request_payload, response_payload, app_name, app_path,
-		test_results_json, analysis_results_json, feedback_json, status, processed_for_finetuning
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		test_results_json, analysis_results_json, feedback_json, status, processed_for_finetuning, parent_id
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare insert statement: %w", err)
@@ -105,14 +206,111 @@ func (d *DB) InsertInteractionLog(logEntry InteractionLog) error {
 		logEntry.FeedbackJSON,
 		logEntry.Status,
 		logEntry.ProcessedForFinetuning,
+		logEntry.ParentID,
 	)
 	return err
 }
 
+// interactionLogColumns is the column list read by scanInteractionLog
+const interactionLogColumns = `id, timestamp, endpoint, request_payload, response_payload, app_name, app_path,
+		test_results_json, analysis_results_json, feedback_json, status, processed_for_finetuning,
+		COALESCE(parent_id, '')`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanInteractionLog reads one row selected with interactionLogColumns
+func scanInteractionLog(row rowScanner) (InteractionLog, error) {
+	var logEntry InteractionLog
+	var timestampStr string
+	var processedInt int
+	if err := row.Scan(
+		&logEntry.ID, &timestampStr, &logEntry.Endpoint, &logEntry.RequestPayload,
+		&logEntry.ResponsePayload, &logEntry.AppName, &logEntry.AppPath,
+		&logEntry.TestResultsJSON, &logEntry.AnalysisResultsJSON, &logEntry.FeedbackJSON,
+		&logEntry.Status, &processedInt, &logEntry.ParentID,
+	); err != nil {
+		return logEntry, err
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, timestampStr)
+	if err != nil {
+		return logEntry, fmt.Errorf("failed to parse timestamp: %w", err)
+	}
+	logEntry.Timestamp = timestamp
+	logEntry.ProcessedForFinetuning = (processedInt == 1)
+	return logEntry, nil
+}
+
+// GetInteractionLog returns the interaction log with the given ID
+func (d *DB) GetInteractionLog(id string) (*InteractionLog, error) {
+	row := d.QueryRow(`SELECT `+interactionLogColumns+` FROM interactions_log WHERE id = ?`, id)
+	logEntry, err := scanInteractionLog(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("interaction log %s not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interaction log: %w", err)
+	}
+	return &logEntry, nil
+}
+
+// QueryInteractionLogs returns the logs matching filter, newest first
+func (d *DB) QueryInteractionLogs(filter LogFilter) ([]InteractionLog, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Endpoint != "" {
+		conditions = append(conditions, "endpoint = ?")
+		args = append(args, filter.Endpoint)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	// julianday compares the RFC 3339 timestamps regardless of their UTC offset
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "julianday(timestamp) >= julianday(?)")
+		args = append(args, filter.Since.Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "julianday(timestamp) < julianday(?)")
+		args = append(args, filter.Until.Format(time.RFC3339))
+	}
+
+	query := `SELECT ` + interactionLogColumns + ` FROM interactions_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// SQLite only accepts OFFSET after a LIMIT, where -1 means no limit
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	query += " ORDER BY julianday(timestamp) DESC, id LIMIT ? OFFSET ?"
+	args = append(args, limit, filter.Offset)
+
+	rows, err := d.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query interaction logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []InteractionLog{}
+	for rows.Next() {
+		logEntry, err := scanInteractionLog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		logs = append(logs, logEntry)
+	}
+	return logs, rows.Err()
+}
+
 func (d *DB) GetUnprocessedLogs() ([]InteractionLog, error) {
 	rows, err := d.Query(`
-	SELECT id, timestamp, endpoint, request_payload, response_payload, app_name, app_path,
-		test_results_json, analysis_results_json, feedback_json, status, processed_for_finetuning
+	SELECT ` + interactionLogColumns + `
 	FROM interactions_log
 	WHERE processed_for_finetuning = 0
 	ORDER BY timestamp ASC
@@ -124,56 +322,168 @@ func (d *DB) GetUnprocessedLogs() ([]InteractionLog, error) {
 
 	var logs []InteractionLog
 	for rows.Next() {
-		var logEntry InteractionLog
-		var timestampStr string
-		var processedInt int
-		if err := rows.Scan(
-			&logEntry.ID, &timestampStr, &logEntry.Endpoint, &logEntry.RequestPayload,
-			&logEntry.ResponsePayload, &logEntry.AppName, &logEntry.AppPath,
-			&logEntry.TestResultsJSON, &logEntry.AnalysisResultsJSON, &logEntry.FeedbackJSON,
-			&logEntry.Status, &processedInt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
-		}
-		logEntry.Timestamp, err = time.Parse(time.RFC3339, timestampStr)
+		logEntry, err := scanInteractionLog(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
-		logEntry.ProcessedForFinetuning = (processedInt == 1)
 		logs = append(logs, logEntry)
 	}
 
 	return logs, nil
 }
 
+// markBatchSize caps the IDs marked per statement, below the 999 bound
+// parameters older SQLite builds allow
+const markBatchSize = 500
+
+// markAttempts is how often a batch of logs is tried before giving up
+const markAttempts = 3
+
+// markRetryDelay is the wait before retrying a batch, growing with each attempt
+const markRetryDelay = 100 * time.Millisecond
+
+// MarkLogsAsProcessed flags logs as processed for fine-tuning in batches of
+// markBatchSize, each in its own transaction and retried on failure. Batches
+// committed before a failure stay marked and marking a log twice changes
+// nothing, so a failed call can simply be repeated.
 func (d *DB) MarkLogsAsProcessed(ids []string) error {
-	if len(ids) == 0 {
-		return nil
-	}
-	// Create a string of \'?\' placeholders for the IN clause
-	placeholders := make([]string, len(ids))
-	for i := range ids {
-		placeholders[i] = "?"
+	for start := 0; start < len(ids); start += markBatchSize {
+		end := start + markBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		var err error
+		for attempt := 1; attempt <= markAttempts; attempt++ {
+			if err = d.markBatch(ids[start:end]); err == nil {
+				break
+			}
+			if attempt < markAttempts {
+				time.Sleep(time.Duration(attempt) * markRetryDelay)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update logs %d to %d of %d: %w", start+1, end, len(ids), err)
+		}
 	}
-	query := fmt.Sprintf(`
-	UPDATE interactions_log
-	SET processed_for_finetuning = 1
-	WHERE id IN (%s)
-	`, strings.Join(placeholders, ","))
+	return nil
+}
 
-	stmt, err := d.Prepare(query)
+// markBatch marks ids as processed in one transaction
+func (d *DB) markBatch(ids []string) error {
+	tx, err := d.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to prepare update statement: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer stmt.Close()
+	if err := markLogs(tx, ids); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
 
+// markLogs marks ids as processed within tx, leaving logs that already are
+// untouched
+func markLogs(tx *sql.Tx, ids []string) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
 	args := make([]interface{}, len(ids))
 	for i, id := range ids {
 		args[i] = id
 	}
 
-	_, err = stmt.Exec(args...)
+	_, err := tx.Exec(`
+	UPDATE interactions_log
+	SET processed_for_finetuning = 1
+	WHERE processed_for_finetuning = 0 AND id IN (`+placeholders+`)
+	`, args...)
 	return err
 }
 
+// addPromptHintQuery adds the occurrences of a hint to the count of an
+// existing identical hint
+const addPromptHintQuery = `
+	INSERT INTO prompt_hints (hint, test_type, occurrences, updated_at) VALUES (?, ?, ?, ?)
+	ON CONFLICT(hint) DO UPDATE SET
+		occurrences = occurrences + excluded.occurrences,
+		updated_at = excluded.updated_at
+	`
+
+// AddPromptHint records occurrences of a hint, adding to the count of an
+// existing identical hint
+func (d *DB) AddPromptHint(hint PromptHint) error {
+	_, err := d.Exec(addPromptHintQuery, hint.Hint, hint.TestType, hint.Occurrences, hint.UpdatedAt.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to save prompt hint: %w", err)
+	}
+	return nil
+}
+
+// RecordPromptHints adds hints like AddPromptHint and marks the logs they were
+// learned from as processed, in one transaction retried on failure. A failed
+// call changes nothing, so the logs are mined again instead of being counted
+// twice. ids should hold at most markBatchSize logs.
+func (d *DB) RecordPromptHints(hints []PromptHint, ids []string) error {
+	var err error
+	for attempt := 1; attempt <= markAttempts; attempt++ {
+		if err = d.recordPromptHints(hints, ids); err == nil {
+			return nil
+		}
+		if attempt < markAttempts {
+			time.Sleep(time.Duration(attempt) * markRetryDelay)
+		}
+	}
+	return fmt.Errorf("failed to record prompt hints of %d logs: %w", len(ids), err)
+}
 
+// recordPromptHints adds hints and marks ids as processed in one transaction
+func (d *DB) recordPromptHints(hints []PromptHint, ids []string) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	for _, hint := range hints {
+		if _, err := tx.Exec(addPromptHintQuery, hint.Hint, hint.TestType, hint.Occurrences, hint.UpdatedAt.Format(time.RFC3339)); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if len(ids) > 0 {
+		if err := markLogs(tx, ids); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetPromptHints returns up to limit hints, most frequent first; a limit of
+// zero returns all of them
+func (d *DB) GetPromptHints(limit int) ([]PromptHint, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := d.Query(`
+	SELECT hint, test_type, occurrences, updated_at
+	FROM prompt_hints
+	ORDER BY occurrences DESC, updated_at DESC, hint
+	LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prompt hints: %w", err)
+	}
+	defer rows.Close()
+
+	var hints []PromptHint
+	for rows.Next() {
+		var hint PromptHint
+		var updatedAt string
+		if err := rows.Scan(&hint.Hint, &hint.TestType, &hint.Occurrences, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan prompt hint: %w", err)
+		}
+		if hint.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+		hints = append(hints, hint)
+	}
+	return hints, rows.Err()
+}
@@ -0,0 +1,262 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInteractionLogParentRoundTrip(t *testing.T) {
+	db, err := NewDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer db.Close()
+
+	timestamp := time.Now().Truncate(time.Second)
+	parent := InteractionLog{ID: "req-1", Timestamp: timestamp, Endpoint: "/generate-and-test", AppName: "shop", Status: "success"}
+	child := InteractionLog{ID: "req-1-test", ParentID: "req-1", Timestamp: timestamp, Endpoint: "/generate-and-test", TestResultsJSON: `{"overall_status":"success"}`, Status: "success"}
+	for _, entry := range []InteractionLog{parent, child} {
+		if err := db.InsertInteractionLog(entry); err != nil {
+			t.Fatalf("InsertInteractionLog failed: %v", err)
+		}
+	}
+
+	got, err := db.GetInteractionLog("req-1-test")
+	if err != nil {
+		t.Fatalf("GetInteractionLog failed: %v", err)
+	}
+	if got.ParentID != "req-1" || got.TestResultsJSON != child.TestResultsJSON || !got.Timestamp.Equal(timestamp) {
+		t.Errorf("Expected child linked to req-1, got %+v", got)
+	}
+
+	got, err = db.GetInteractionLog(got.ParentID)
+	if err != nil {
+		t.Fatalf("GetInteractionLog of parent failed: %v", err)
+	}
+	if got.AppName != "shop" || got.ParentID != "" {
+		t.Errorf("Expected top-level parent for shop, got %+v", got)
+	}
+
+	if _, err := db.GetInteractionLog("missing"); err == nil {
+		t.Error("Expected an error for an unknown ID")
+	}
+}
+
+func TestNewDBAddsParentIDColumn(t *testing.T) {
+	dataDir := t.TempDir()
+
+	// A database created before parent_id existed
+	legacy, err := sql.Open("sqlite3", filepath.Join(dataDir, dbFileName))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	_, err = legacy.Exec(`CREATE TABLE interactions_log (
+		id TEXT PRIMARY KEY, timestamp TEXT NOT NULL, endpoint TEXT NOT NULL,
+		request_payload TEXT, response_payload TEXT, app_name TEXT, app_path TEXT,
+		test_results_json TEXT, analysis_results_json TEXT, feedback_json TEXT,
+		status TEXT NOT NULL, processed_for_finetuning INTEGER DEFAULT 0
+	)`)
+	legacy.Close()
+	if err != nil {
+		t.Fatalf("Failed to create legacy table: %v", err)
+	}
+
+	db, err := NewDB(dataDir)
+	if err != nil {
+		t.Fatalf("NewDB failed on legacy database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.InsertInteractionLog(InteractionLog{ID: "child", ParentID: "parent", Timestamp: time.Now(), Endpoint: "/test-app", Status: "success"}); err != nil {
+		t.Fatalf("InsertInteractionLog failed: %v", err)
+	}
+	if got, err := db.GetInteractionLog("child"); err != nil || got.ParentID != "parent" {
+		t.Errorf("Expected parent_id to round trip, got %+v, %v", got, err)
+	}
+}
+
+func TestQueryInteractionLogs(t *testing.T) {
+	db, err := NewDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer db.Close()
+
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := []InteractionLog{
+		{ID: "a", Timestamp: base, Endpoint: "/generate-app", Status: "success"},
+		{ID: "b", Timestamp: base.Add(1 * time.Hour), Endpoint: "/generate-app", Status: "failure"},
+		{ID: "c", Timestamp: base.Add(2 * time.Hour), Endpoint: "/test-app", Status: "failure"},
+		{ID: "d", Timestamp: base.Add(3 * time.Hour), Endpoint: "/generate-app", Status: "failure"},
+		// Stored with a different UTC offset, 4 hours after base
+		{ID: "e", Timestamp: base.Add(4 * time.Hour).In(time.FixedZone("WIB", 7*3600)), Endpoint: "/generate-app", Status: "success"},
+	}
+	for _, entry := range entries {
+		if err := db.InsertInteractionLog(entry); err != nil {
+			t.Fatalf("InsertInteractionLog failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter LogFilter
+		want   []string
+	}{
+		{"all newest first", LogFilter{}, []string{"e", "d", "c", "b", "a"}},
+		{"endpoint", LogFilter{Endpoint: "/test-app"}, []string{"c"}},
+		{"status", LogFilter{Status: "success"}, []string{"e", "a"}},
+		{"since", LogFilter{Since: base.Add(3 * time.Hour)}, []string{"e", "d"}},
+		{"until", LogFilter{Until: base.Add(1 * time.Hour)}, []string{"a"}},
+		{"limit", LogFilter{Limit: 2}, []string{"e", "d"}},
+		{"offset", LogFilter{Limit: 2, Offset: 3}, []string{"b", "a"}},
+		{"offset without limit", LogFilter{Offset: 4}, []string{"a"}},
+		{"status, endpoint and window", LogFilter{
+			Endpoint: "/generate-app",
+			Status:   "failure",
+			Since:    base.Add(30 * time.Minute),
+			Until:    base.Add(3 * time.Hour),
+		}, []string{"b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, err := db.QueryInteractionLogs(tt.filter)
+			if err != nil {
+				t.Fatalf("QueryInteractionLogs failed: %v", err)
+			}
+			var got []string
+			for _, entry := range logs {
+				got = append(got, entry.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConcurrentInsertInteractionLog(t *testing.T) {
+	db, err := NewDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer db.Close()
+
+	var journalMode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil {
+		t.Fatalf("Failed to read journal mode: %v", err)
+	}
+	if !strings.EqualFold(journalMode, "wal") {
+		t.Errorf("Expected WAL journal mode, got %s", journalMode)
+	}
+
+	const writers, perWriter = 10, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				entry := InteractionLog{ID: fmt.Sprintf("%d-%d", w, i), Timestamp: time.Now(), Endpoint: "/generate-app", Status: "success"}
+				if err := db.InsertInteractionLog(entry); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	// The fine-tuner reads and updates logs while handlers insert them
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < perWriter; i++ {
+			logs, err := db.GetUnprocessedLogs()
+			if err != nil {
+				errs <- err
+				continue
+			}
+			var ids []string
+			for _, entry := range logs {
+				ids = append(ids, entry.ID)
+			}
+			if err := db.MarkLogsAsProcessed(ids); err != nil {
+				errs <- err
+			}
+		}
+	}()
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Concurrent access failed: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM interactions_log").Scan(&count); err != nil {
+		t.Fatalf("Failed to count logs: %v", err)
+	}
+	if count != writers*perWriter {
+		t.Errorf("Expected %d logs, got %d", writers*perWriter, count)
+	}
+}
+
+func TestMarkLogsAsProcessedInBatches(t *testing.T) {
+	db, err := NewDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer db.Close()
+
+	// More logs than SQLite's historical limit of 999 bound parameters
+	const total = 1200
+	var ids []string
+	for i := 0; i < total; i++ {
+		entry := InteractionLog{ID: fmt.Sprintf("log-%04d", i), Timestamp: time.Now(), Endpoint: "/test-app", Status: "success"}
+		if err := db.InsertInteractionLog(entry); err != nil {
+			t.Fatalf("InsertInteractionLog failed: %v", err)
+		}
+		ids = append(ids, entry.ID)
+	}
+
+	unprocessed := func() int {
+		t.Helper()
+		logs, err := db.GetUnprocessedLogs()
+		if err != nil {
+			t.Fatalf("GetUnprocessedLogs failed: %v", err)
+		}
+		return len(logs)
+	}
+
+	// Updating a log of the second batch fails every time
+	if _, err := db.Exec(`CREATE TRIGGER fail_mark BEFORE UPDATE ON interactions_log
+	WHEN NEW.id = 'log-0700' BEGIN SELECT RAISE(ABORT, 'simulated failure'); END`); err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+	err = db.MarkLogsAsProcessed(ids)
+	if err == nil || !strings.Contains(err.Error(), "simulated failure") {
+		t.Fatalf("Expected the simulated failure, got %v", err)
+	}
+	// The first batch stays marked while the failed one is rolled back whole
+	if got := unprocessed(); got != total-markBatchSize {
+		t.Errorf("Expected %d unprocessed logs after the failure, got %d", total-markBatchSize, got)
+	}
+
+	if _, err := db.Exec(`DROP TRIGGER fail_mark`); err != nil {
+		t.Fatalf("Failed to drop trigger: %v", err)
+	}
+	if err := db.MarkLogsAsProcessed(ids); err != nil {
+		t.Fatalf("MarkLogsAsProcessed failed: %v", err)
+	}
+	if got := unprocessed(); got != 0 {
+		t.Errorf("Expected every log to be processed, got %d unprocessed", got)
+	}
+
+	// Marking processed logs again is a no-op
+	if err := db.MarkLogsAsProcessed(ids); err != nil {
+		t.Errorf("Marking processed logs again failed: %v", err)
+	}
+}
@@ -2,7 +2,11 @@ package debugging
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,9 +15,27 @@ import (
 	"time"
 )
 
+// dlvStartTimeout bounds how long dlv may take to build the target and start listening
+const dlvStartTimeout = 2 * time.Minute
+
+// stackTraceDepth is how many frames GetStackTrace reads
+const stackTraceDepth = 50
+
+// defaultMaxDepth is how many directory levels below the project AnalyzeProject scans
+const defaultMaxDepth = 8
+
+// skippedDirs are dependency and VCS directories AnalyzeProject never scans
+var skippedDirs = map[string]bool{
+	".git":         true,
+	"vendor":       true,
+	"node_modules": true,
+}
+
 type Debugger struct {
 	projectPath string
 	logLevel    string
+	maxDepth    int
+	session     *DebugSession
 }
 
 type DebugSession struct {
@@ -25,6 +47,10 @@ type DebugSession struct {
 	Variables   map[string]interface{} `json:"variables"`
 	StackTrace  []StackFrame           `json:"stack_trace"`
 	Logs        []LogEntry             `json:"logs"`
+
+	cmd      *exec.Cmd // the headless dlv server
+	client   *delveClient
+	attached bool // attached to an existing process, which must outlive the session
 }
 
 type Breakpoint struct {
@@ -119,11 +145,87 @@ func NewDebugger(projectPath string) *Debugger {
 	return &Debugger{
 		projectPath: projectPath,
 		logLevel:    "info",
+		maxDepth:    defaultMaxDepth,
 	}
 }
 
+// SetMaxDepth limits how many directory levels below the project are scanned
+func (d *Debugger) SetMaxDepth(depth int) {
+	d.maxDepth = depth
+}
+
+// walkFiles calls fn for each file in the project, skipping dependency and VCS
+// directories and directories nested deeper than maxDepth
+func (d *Debugger) walkFiles(fn func(path string) error) error {
+	return filepath.Walk(d.projectPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fn(path)
+		}
+		if path == d.projectPath {
+			return nil
+		}
+
+		rel, err := filepath.Rel(d.projectPath, path)
+		if err != nil {
+			return err
+		}
+		depth := strings.Count(rel, string(filepath.Separator)) + 1
+		if skippedDirs[info.Name()] || depth > d.maxDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// StartDebugSession builds the project under a headless dlv server and connects
+// to it. The program stays halted until Continue is called.
 func (d *Debugger) StartDebugSession() (*DebugSession, error) {
-	session := &DebugSession{
+	return d.launchDelve(false, "debug")
+}
+
+// launchDelve starts dlv in headless mode with args, waits for its API server
+// and makes the connected session the current one
+func (d *Debugger) launchDelve(attached bool, args ...string) (*DebugSession, error) {
+	if d.session != nil {
+		return nil, fmt.Errorf("debug session %s is already active", d.session.ID)
+	}
+
+	dlvPath, err := exec.LookPath("dlv")
+	if err != nil {
+		return nil, fmt.Errorf("dlv not found in PATH: %v", err)
+	}
+
+	args = append(args, "--headless", "--api-version=2", "--listen=127.0.0.1:0")
+	cmd := exec.Command(dlvPath, args...)
+	cmd.Dir = d.projectPath
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start dlv: %v", err)
+	}
+
+	addr, err := waitForListenAddress(stdout, dlvStartTimeout)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	client, err := dialDelve(addr)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, err
+	}
+
+	d.session = &DebugSession{
 		ID:          fmt.Sprintf("debug_%d", time.Now().Unix()),
 		ProjectPath: d.projectPath,
 		StartTime:   time.Now(),
@@ -132,9 +234,101 @@ func (d *Debugger) StartDebugSession() (*DebugSession, error) {
 		Variables:   make(map[string]interface{}),
 		StackTrace:  []StackFrame{},
 		Logs:        []LogEntry{},
+		cmd:         cmd,
+		client:      client,
+		attached:    attached,
+	}
+	return d.session, nil
+}
+
+// waitForListenAddress reads dlv's stdout until it announces its API server
+// address. The rest of the output, which includes the target's own stdout,
+// is drained so the target never blocks on a full pipe.
+func waitForListenAddress(stdout io.Reader, timeout time.Duration) (string, error) {
+	const prefix = "API server listening at: "
+	found := make(chan string, 1)
+
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, prefix) {
+				found <- strings.TrimSpace(strings.TrimPrefix(line, prefix))
+				break
+			}
+		}
+		close(found)
+		io.Copy(io.Discard, stdout)
+	}()
+
+	select {
+	case addr, ok := <-found:
+		if !ok {
+			return "", fmt.Errorf("dlv exited before starting its API server")
+		}
+		return addr, nil
+	case <-time.After(timeout):
+		return "", fmt.Errorf("timed out waiting for dlv to start")
 	}
-	
-	return session, nil
+}
+
+// activeSession returns the current session or an error when none is running
+func (d *Debugger) activeSession() (*DebugSession, error) {
+	if d.session == nil {
+		return nil, fmt.Errorf("no active debug session")
+	}
+	return d.session, nil
+}
+
+// Continue resumes the target until it hits a breakpoint or exits. On a halt
+// the session's stack trace is refreshed.
+func (d *Debugger) Continue() error {
+	session, err := d.activeSession()
+	if err != nil {
+		return err
+	}
+
+	state, err := session.client.command("continue")
+	if err != nil {
+		return err
+	}
+	if state.Exited {
+		session.Status = "exited"
+		return nil
+	}
+
+	session.Status = "halted"
+	_, err = d.GetStackTrace()
+	return err
+}
+
+// StopDebugSession detaches from the target, killing it unless it was attached
+// to, and shuts down the dlv server
+func (d *Debugger) StopDebugSession() error {
+	session, err := d.activeSession()
+	if err != nil {
+		return err
+	}
+	d.session = nil
+	session.Status = "stopped"
+
+	detachErr := session.client.detach(!session.attached)
+
+	done := make(chan struct{})
+	go func() {
+		session.cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		session.cmd.Process.Kill()
+		<-done
+	}
+
+	if detachErr != nil {
+		return fmt.Errorf("failed to detach from dlv: %v", detachErr)
+	}
+	return nil
 }
 
 func (d *Debugger) AnalyzeProject() DebugResult {
@@ -168,11 +362,7 @@ func (d *Debugger) AnalyzeProject() DebugResult {
 }
 
 func (d *Debugger) analyzeCodeIssues(result *DebugResult) {
-	filepath.Walk(d.projectPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		
+	d.walkFiles(func(path string) error {
 		if !d.isSourceFile(path) {
 			return nil
 		}
@@ -200,24 +390,26 @@ func (d *Debugger) analyzeCodeIssues(result *DebugResult) {
 }
 
 func (d *Debugger) analyzeGoIssues(filePath string, lines []string, result *DebugResult) {
+	// Check for values used before the error returned with them is checked
+	fset := token.NewFileSet()
+	if file, err := parser.ParseFile(fset, filePath, strings.Join(lines, "\n"), 0); err == nil {
+		for _, pos := range uncheckedDerefs(file) {
+			lineNum := fset.Position(pos).Line
+			result.Issues = append(result.Issues, DebugIssue{
+				Type:        "nil_pointer_risk",
+				Severity:    "warning",
+				File:        filePath,
+				Line:        lineNum,
+				Description: "Potential nil pointer dereference - value used before its error is checked",
+				Context:     strings.TrimSpace(lines[lineNum-1]),
+			})
+		}
+	}
+	
 	for i, line := range lines {
 		lineNum := i + 1
 		trimmedLine := strings.TrimSpace(line)
 		
-		// Check for potential nil pointer dereferences
-		if strings.Contains(trimmedLine, ".") && !strings.Contains(trimmedLine, "if") && !strings.Contains(trimmedLine, "!=") {
-			if matched, _ := regexp.MatchString(`\w+\.\w+`, trimmedLine); matched {
-				result.Issues = append(result.Issues, DebugIssue{
-					Type:        "nil_pointer_risk",
-					Severity:    "warning",
-					File:        filePath,
-					Line:        lineNum,
-					Description: "Potential nil pointer dereference - consider nil check",
-					Context:     trimmedLine,
-				})
-			}
-		}
-		
 		// Check for missing error handling
 		if strings.Contains(trimmedLine, ":=") && strings.Contains(trimmedLine, "err") && !strings.Contains(trimmedLine, "if err") {
 			nextLine := ""
@@ -316,11 +508,7 @@ func (d *Debugger) analyzeLogs(result *DebugResult) {
 func (d *Debugger) findLogFiles() []string {
 	var logFiles []string
 	
-	filepath.Walk(d.projectPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		
+	d.walkFiles(func(path string) error {
 		if strings.HasSuffix(path, ".log") || strings.Contains(path, "log") {
 			logFiles = append(logFiles, path)
 		}
@@ -417,9 +605,9 @@ func (d *Debugger) analyzePerformance(result *DebugResult) {
 	}
 	
 	// Check for potential performance issues in code
-	filepath.Walk(d.projectPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil || !strings.HasSuffix(path, ".go") {
-			return err
+	d.walkFiles(func(path string) error {
+		if !strings.HasSuffix(path, ".go") {
+			return nil
 		}
 		
 		content, err := os.ReadFile(path)
@@ -472,9 +660,9 @@ func (d *Debugger) analyzeMemoryLeaks(result *DebugResult) {
 	// This would typically involve running the application with memory profiling
 	// For now, we'll just check for common patterns that can cause leaks
 	
-	filepath.Walk(d.projectPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil || !strings.HasSuffix(path, ".go") {
-			return err
+	d.walkFiles(func(path string) error {
+		if !strings.HasSuffix(path, ".go") {
+			return nil
 		}
 		
 		content, err := os.ReadFile(path)
@@ -560,24 +748,67 @@ func (d *Debugger) RunProfiler(duration time.Duration) (map[string]interface{},
 	return result, nil
 }
 
+// AttachDebugger attaches a headless dlv server to a running process. The
+// process is halted until Continue is called and keeps running after the
+// session stops.
 func (d *Debugger) AttachDebugger(processID int) error {
-	// This would attach a debugger to a running process
-	// For Go, this could use delve
-	
-	cmd := exec.Command("dlv", "attach", fmt.Sprintf("%d", processID))
-	return cmd.Start()
+	_, err := d.launchDelve(true, "attach", fmt.Sprintf("%d", processID))
+	return err
 }
 
+// SetBreakpoint sets a breakpoint at file:line; relative files are resolved
+// against the project path
 func (d *Debugger) SetBreakpoint(file string, line int) error {
-	// This would set a breakpoint in the debugger
-	// Implementation would depend on the specific debugger being used
-	
+	session, err := d.activeSession()
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(d.projectPath, file)
+	}
+
+	breakpoint, err := session.client.createBreakpoint(file, line)
+	if err != nil {
+		return err
+	}
+	session.Breakpoints = append(session.Breakpoints, Breakpoint{
+		File: breakpoint.File,
+		Line: breakpoint.Line,
+		ID:   breakpoint.ID,
+	})
 	return nil
 }
 
+// GetStackTrace returns the stack of the goroutine the target is halted in
 func (d *Debugger) GetStackTrace() ([]StackFrame, error) {
-	// This would get the current stack trace from the debugger
-	
-	return []StackFrame{}, nil
+	session, err := d.activeSession()
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := session.client.state()
+	if err != nil {
+		return nil, err
+	}
+	if state.Running {
+		return nil, fmt.Errorf("target is running")
+	}
+	if state.Exited {
+		return nil, fmt.Errorf("target exited with status %d", state.ExitStatus)
+	}
+
+	goroutineID := int64(-1) // the current goroutine
+	if state.SelectedGoroutine != nil {
+		goroutineID = state.SelectedGoroutine.ID
+	} else if state.CurrentThread != nil && state.CurrentThread.GoroutineID != 0 {
+		goroutineID = state.CurrentThread.GoroutineID
+	}
+
+	frames, err := session.client.stacktrace(goroutineID, stackTraceDepth)
+	if err != nil {
+		return nil, err
+	}
+	session.StackTrace = frames
+	return frames, nil
 }
 
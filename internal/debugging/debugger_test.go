@@ -0,0 +1,75 @@
+package debugging
+
+import (
+	"strings"
+	"testing"
+)
+
+const nilRiskSource = `package app
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type user struct{ Name string }
+
+func findUser(id int) (*user, error) { return &user{}, nil }
+
+func countUsers() (int, error) { return 0, nil }
+
+func unchecked(url string) {
+	resp, err := http.Get(url)
+	defer resp.Body.Close()
+	if err != nil {
+		return
+	}
+}
+
+func discarded() string {
+	u, _ := findUser(1)
+	return u.Name
+}
+
+func safe(url string) error {
+	fmt.Println(strings.NewReader("literal").Len(), time.Now().Unix())
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	u, err := findUser(2)
+	if u != nil {
+		fmt.Println(u.Name)
+	}
+
+	n, _ := countUsers()
+	fmt.Println(n)
+
+	later, err := findUser(3)
+	defer func() { fmt.Println(later.Name) }()
+	return err
+}
+`
+
+func TestAnalyzeGoIssuesNilPointerRisk(t *testing.T) {
+	lines := strings.Split(nilRiskSource, "\n")
+	var result DebugResult
+	NewDebugger(t.TempDir()).analyzeGoIssues("app.go", lines, &result)
+
+	var flagged []string
+	for _, issue := range result.Issues {
+		if issue.Type == "nil_pointer_risk" {
+			flagged = append(flagged, issue.Context)
+		}
+	}
+
+	expected := []string{"defer resp.Body.Close()", "return u.Name"}
+	if strings.Join(flagged, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected nil pointer risks %q, got %q", expected, flagged)
+	}
+}
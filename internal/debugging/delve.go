@@ -0,0 +1,159 @@
+package debugging
+
+import (
+	"fmt"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+)
+
+// delveClient speaks the JSON-RPC v2 API of a headless dlv server
+type delveClient struct {
+	rpc *rpc.Client
+}
+
+// The types below mirror the subset of github.com/go-delve/delve/service/api
+// and service/rpc2 the debugger uses; field names and tags match Delve's wire format.
+
+type delveFunction struct {
+	Name string `json:"name"`
+}
+
+type delveLocation struct {
+	PC       uint64         `json:"pc"`
+	File     string         `json:"file"`
+	Line     int            `json:"line"`
+	Function *delveFunction `json:"function,omitempty"`
+}
+
+type delveBreakpoint struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	File         string `json:"file"`
+	Line         int    `json:"line"`
+	FunctionName string `json:"functionName,omitempty"`
+}
+
+type delveThread struct {
+	ID          int    `json:"id"`
+	GoroutineID int64  `json:"goroutineID"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+}
+
+type delveGoroutine struct {
+	ID int64 `json:"id"`
+}
+
+type delveState struct {
+	Running           bool            `json:"Running"`
+	CurrentThread     *delveThread    `json:"currentThread,omitempty"`
+	SelectedGoroutine *delveGoroutine `json:"currentGoroutine,omitempty"`
+	Exited            bool            `json:"exited"`
+	ExitStatus        int             `json:"exitStatus"`
+}
+
+type delveCommand struct {
+	Name string `json:"name"`
+}
+
+type delveCreateBreakpointIn struct {
+	Breakpoint delveBreakpoint
+}
+
+type delveCreateBreakpointOut struct {
+	Breakpoint delveBreakpoint
+}
+
+type delveCommandOut struct {
+	State delveState
+}
+
+type delveStateIn struct {
+	NonBlocking bool
+}
+
+type delveStateOut struct {
+	State *delveState
+}
+
+type delveStacktraceIn struct {
+	Id    int64
+	Depth int
+}
+
+type delveStacktraceOut struct {
+	Locations []delveLocation
+}
+
+type delveDetachIn struct {
+	Kill bool
+}
+
+type delveDetachOut struct{}
+
+// dialDelve connects to a headless dlv server at addr
+func dialDelve(addr string) (*delveClient, error) {
+	client, err := jsonrpc.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to dlv at %s: %v", addr, err)
+	}
+	return &delveClient{rpc: client}, nil
+}
+
+// createBreakpoint sets a breakpoint at file:line
+func (c *delveClient) createBreakpoint(file string, line int) (delveBreakpoint, error) {
+	var out delveCreateBreakpointOut
+	in := delveCreateBreakpointIn{Breakpoint: delveBreakpoint{File: file, Line: line}}
+	if err := c.rpc.Call("RPCServer.CreateBreakpoint", in, &out); err != nil {
+		return delveBreakpoint{}, fmt.Errorf("failed to create breakpoint at %s:%d: %v", file, line, err)
+	}
+	return out.Breakpoint, nil
+}
+
+// command runs a debugger command such as continue and returns the state the
+// target halted in
+func (c *delveClient) command(name string) (delveState, error) {
+	var out delveCommandOut
+	if err := c.rpc.Call("RPCServer.Command", delveCommand{Name: name}, &out); err != nil {
+		return delveState{}, fmt.Errorf("dlv %s failed: %v", name, err)
+	}
+	return out.State, nil
+}
+
+// state returns the current debugger state without waiting for the target to halt
+func (c *delveClient) state() (delveState, error) {
+	var out delveStateOut
+	if err := c.rpc.Call("RPCServer.State", delveStateIn{NonBlocking: true}, &out); err != nil {
+		return delveState{}, fmt.Errorf("failed to get dlv state: %v", err)
+	}
+	if out.State == nil {
+		return delveState{}, fmt.Errorf("dlv returned no state")
+	}
+	return *out.State, nil
+}
+
+// stacktrace returns up to depth frames of a goroutine's stack
+func (c *delveClient) stacktrace(goroutineID int64, depth int) ([]StackFrame, error) {
+	var out delveStacktraceOut
+	if err := c.rpc.Call("RPCServer.Stacktrace", delveStacktraceIn{Id: goroutineID, Depth: depth}, &out); err != nil {
+		return nil, fmt.Errorf("failed to get stack trace: %v", err)
+	}
+
+	frames := make([]StackFrame, 0, len(out.Locations))
+	for _, location := range out.Locations {
+		frame := StackFrame{File: location.File, Line: location.Line}
+		if location.Function != nil {
+			frame.Function = location.Function.Name
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// detach disconnects from the target, killing it when kill is set
+func (c *delveClient) detach(kill bool) error {
+	var out delveDetachOut
+	err := c.rpc.Call("RPCServer.Detach", delveDetachIn{Kill: kill}, &out)
+	c.rpc.Close()
+	return err
+}
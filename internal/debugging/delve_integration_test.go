@@ -0,0 +1,67 @@
+//go:build dlv
+
+package debugging
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// Run with: go test -tags dlv ./internal/debugging (requires dlv in PATH)
+
+const delveTarget = `package main
+
+import "fmt"
+
+func greet(name string) string {
+	return "hello " + name
+}
+
+func main() {
+	fmt.Println(greet("dlv"))
+}
+`
+
+func TestDelveSession(t *testing.T) {
+	if _, err := exec.LookPath("dlv"); err != nil {
+		t.Skip("dlv not installed")
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module delvetarget\n\ngo 1.18\n",
+		"main.go": delveTarget,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	debugger := NewDebugger(dir)
+	session, err := debugger.StartDebugSession()
+	if err != nil {
+		t.Fatalf("StartDebugSession failed: %v", err)
+	}
+	defer debugger.StopDebugSession()
+
+	if err := debugger.SetBreakpoint("main.go", 6); err != nil {
+		t.Fatalf("SetBreakpoint failed: %v", err)
+	}
+	if err := debugger.Continue(); err != nil {
+		t.Fatalf("Continue failed: %v", err)
+	}
+	if session.Status != "halted" {
+		t.Fatalf("expected target to halt at the breakpoint, status %q", session.Status)
+	}
+
+	frames, err := debugger.GetStackTrace()
+	if err != nil {
+		t.Fatalf("GetStackTrace failed: %v", err)
+	}
+	if len(frames) < 2 || frames[0].Function != "main.greet" || frames[0].Line != 6 || frames[1].Function != "main.main" {
+		t.Errorf("unexpected stack trace: %+v", frames)
+	}
+}
@@ -0,0 +1,137 @@
+package debugging
+
+import (
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"path/filepath"
+	"testing"
+)
+
+// The exported request and response types let net/rpc serve a fake dlv API
+type CreateBreakpointIn delveCreateBreakpointIn
+type CreateBreakpointOut delveCreateBreakpointOut
+type CommandIn delveCommand
+type CommandOut delveCommandOut
+type StateIn delveStateIn
+type StateOut delveStateOut
+type StacktraceIn delveStacktraceIn
+type StacktraceOut delveStacktraceOut
+
+// fakeDelve implements the RPCServer methods the debugger calls
+type fakeDelve struct {
+	breakpoints []delveBreakpoint
+	halted      bool
+	stackFor    int64
+}
+
+func (f *fakeDelve) CreateBreakpoint(in CreateBreakpointIn, out *CreateBreakpointOut) error {
+	in.Breakpoint.ID = len(f.breakpoints) + 1
+	f.breakpoints = append(f.breakpoints, in.Breakpoint)
+	out.Breakpoint = in.Breakpoint
+	return nil
+}
+
+func (f *fakeDelve) Command(in CommandIn, out *CommandOut) error {
+	f.halted = in.Name == "continue"
+	out.State = f.state()
+	return nil
+}
+
+func (f *fakeDelve) State(in StateIn, out *StateOut) error {
+	state := f.state()
+	out.State = &state
+	return nil
+}
+
+func (f *fakeDelve) Stacktrace(in StacktraceIn, out *StacktraceOut) error {
+	f.stackFor = in.Id
+	out.Locations = []delveLocation{
+		{File: "/app/main.go", Line: 12, Function: &delveFunction{Name: "main.handler"}},
+		{File: "/app/main.go", Line: 30, Function: &delveFunction{Name: "main.main"}},
+	}
+	return nil
+}
+
+func (f *fakeDelve) state() delveState {
+	if !f.halted {
+		return delveState{Running: true}
+	}
+	return delveState{SelectedGoroutine: &delveGoroutine{ID: 7}}
+}
+
+func startFakeDelve(t *testing.T, fake *fakeDelve) *delveClient {
+	t.Helper()
+	server := rpc.NewServer()
+	if err := server.RegisterName("RPCServer", fake); err != nil {
+		t.Fatalf("failed to register fake dlv: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+
+	client, err := dialDelve(listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dialDelve failed: %v", err)
+	}
+	t.Cleanup(func() { client.rpc.Close() })
+	return client
+}
+
+func TestDebuggerBreakpointsAndStackTrace(t *testing.T) {
+	fake := &fakeDelve{}
+	debugger := NewDebugger("/app")
+	debugger.session = &DebugSession{client: startFakeDelve(t, fake)}
+
+	if err := debugger.SetBreakpoint("main.go", 12); err != nil {
+		t.Fatalf("SetBreakpoint failed: %v", err)
+	}
+	if len(fake.breakpoints) != 1 || fake.breakpoints[0].File != filepath.Join("/app", "main.go") || fake.breakpoints[0].Line != 12 {
+		t.Errorf("unexpected breakpoints sent to dlv: %+v", fake.breakpoints)
+	}
+	if bps := debugger.session.Breakpoints; len(bps) != 1 || bps[0].ID != 1 {
+		t.Errorf("unexpected session breakpoints: %+v", bps)
+	}
+
+	if _, err := debugger.GetStackTrace(); err == nil {
+		t.Error("expected an error while the target is running")
+	}
+
+	if err := debugger.Continue(); err != nil {
+		t.Fatalf("Continue failed: %v", err)
+	}
+	if debugger.session.Status != "halted" {
+		t.Errorf("expected halted session, got %q", debugger.session.Status)
+	}
+	if fake.stackFor != 7 {
+		t.Errorf("expected the stack of the selected goroutine, got goroutine %d", fake.stackFor)
+	}
+	frames := debugger.session.StackTrace
+	if len(frames) != 2 || frames[0].Function != "main.handler" || frames[0].Line != 12 {
+		t.Errorf("unexpected stack trace: %+v", frames)
+	}
+}
+
+func TestDebuggerRequiresSession(t *testing.T) {
+	debugger := NewDebugger(t.TempDir())
+	if err := debugger.SetBreakpoint("main.go", 1); err == nil {
+		t.Error("expected SetBreakpoint to fail without a session")
+	}
+	if _, err := debugger.GetStackTrace(); err == nil {
+		t.Error("expected GetStackTrace to fail without a session")
+	}
+	if err := debugger.StopDebugSession(); err == nil {
+		t.Error("expected StopDebugSession to fail without a session")
+	}
+}
@@ -0,0 +1,187 @@
+package debugging
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// uncheckedDerefs returns the positions of selector expressions on a value
+// returned together with an error, used before that error is checked:
+//
+//	resp, err := http.Get(url)
+//	defer resp.Body.Close() // flagged: resp is nil when err != nil
+//	if err != nil {
+//
+// Values whose error is discarded with _ are flagged on first use when the
+// callee is declared in the file as returning (*T, error).
+func uncheckedDerefs(file *ast.File) []token.Pos {
+	// Results of functions declared in this file: true when they return (*T, error)
+	pointerWithError := map[string]bool{}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Type.Results == nil {
+			continue
+		}
+		results := fn.Type.Results.List
+		if len(results) != 2 || len(results[0].Names) > 1 {
+			continue
+		}
+		_, pointer := results[0].Type.(*ast.StarExpr)
+		errIdent, isError := results[1].Type.(*ast.Ident)
+		pointerWithError[fn.Name.Name] = pointer && isError && errIdent.Name == "error"
+	}
+
+	var positions []token.Pos
+	ast.Inspect(file, func(n ast.Node) bool {
+		var stmts []ast.Stmt
+		switch block := n.(type) {
+		case *ast.BlockStmt:
+			stmts = block.List
+		case *ast.CaseClause:
+			stmts = block.Body
+		case *ast.CommClause:
+			stmts = block.Body
+		default:
+			return true
+		}
+
+		for i, stmt := range stmts {
+			value, errName, ok := valueWithError(stmt, pointerWithError)
+			if !ok {
+				continue
+			}
+			if pos := firstUncheckedUse(stmts[i+1:], value, errName); pos.IsValid() {
+				positions = append(positions, pos)
+			}
+		}
+		return true
+	})
+
+	return positions
+}
+
+// valueWithError matches `v, err := call()` (or `v, _ := call()` for known
+// pointer-returning functions) and returns the value and error names
+func valueWithError(stmt ast.Stmt, pointerWithError map[string]bool) (string, string, bool) {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+		return "", "", false
+	}
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok {
+		return "", "", false
+	}
+	value, ok := assign.Lhs[0].(*ast.Ident)
+	if !ok || value.Name == "_" {
+		return "", "", false
+	}
+	errIdent, ok := assign.Lhs[1].(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+
+	// A local function returning a non-pointer value cannot yield nil
+	local, isLocal := "", false
+	if ident, ok := call.Fun.(*ast.Ident); ok {
+		local = ident.Name
+		_, isLocal = pointerWithError[local]
+	}
+	if isLocal && !pointerWithError[local] {
+		return "", "", false
+	}
+
+	switch {
+	case errIdent.Name == "_":
+		return value.Name, "", isLocal
+	case errIdent.Name == "err" || strings.HasSuffix(errIdent.Name, "Err"):
+		return value.Name, errIdent.Name, true
+	}
+	return "", "", false
+}
+
+// firstUncheckedUse scans the statements after an assignment and returns the
+// first selector on value reached before errName is checked or used, or before
+// value is compared with nil
+func firstUncheckedUse(stmts []ast.Stmt, value, errName string) token.Pos {
+	for _, stmt := range stmts {
+		if ifStmt, ok := stmt.(*ast.IfStmt); ok {
+			if (errName != "" && mentions(ifStmt.Cond, errName)) || comparesWithNil(ifStmt.Cond, value) {
+				return token.NoPos
+			}
+		}
+		if pos := selectorOn(stmt, value); pos.IsValid() {
+			return pos
+		}
+		if (errName != "" && mentions(stmt, errName)) || reassigns(stmt, value) {
+			return token.NoPos
+		}
+	}
+	return token.NoPos
+}
+
+// selectorOn returns the position of the first name.X selector in node,
+// ignoring function literals, which run later
+func selectorOn(node ast.Node, name string) token.Pos {
+	pos := token.NoPos
+	ast.Inspect(node, func(n ast.Node) bool {
+		if pos.IsValid() {
+			return false
+		}
+		switch expr := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.SelectorExpr:
+			if ident, ok := expr.X.(*ast.Ident); ok && ident.Name == name {
+				pos = expr.Pos()
+				return false
+			}
+		}
+		return true
+	})
+	return pos
+}
+
+// mentions reports whether node refers to name
+func mentions(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// comparesWithNil reports whether expr contains name == nil or name != nil
+func comparesWithNil(expr ast.Expr, name string) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		binary, ok := n.(*ast.BinaryExpr)
+		if !ok || (binary.Op != token.EQL && binary.Op != token.NEQ) {
+			return !found
+		}
+		x, xOK := binary.X.(*ast.Ident)
+		y, yOK := binary.Y.(*ast.Ident)
+		if xOK && yOK && ((x.Name == name && y.Name == "nil") || (x.Name == "nil" && y.Name == name)) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// reassigns reports whether stmt assigns a new value to name
+func reassigns(stmt ast.Stmt, name string) bool {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok {
+		return false
+	}
+	for _, lhs := range assign.Lhs {
+		if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name {
+			return true
+		}
+	}
+	return false
+}
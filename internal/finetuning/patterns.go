@@ -0,0 +1,175 @@
+package finetuning
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// minHintOccurrences is how many failed runs must share an error before it
+// is added to prompts
+const minHintOccurrences = 2
+
+// maxErrorLength truncates error lines quoted in generic hints
+const maxErrorLength = 120
+
+// failurePattern maps an error substring seen in a test type to corrective guidance
+type failurePattern struct {
+	testType  string // empty matches every test type
+	substring string // matched case-insensitively
+	hint      string
+}
+
+// knownFailurePatterns are recurring failures of generated apps and how to avoid them
+var knownFailurePatterns = []failurePattern{
+	{"build", "missing go.sum entry", "Include a go.sum, or a go.mod whose requirements resolve, for every module the generated Go code imports"},
+	{"build", "imported and not used", "Only import packages the generated code uses"},
+	{"build", "undefined:", "Declare every identifier the generated code references, including helpers and model fields"},
+	{"build", "syntax error", "Escape commas, quotes and braces in template output so generated code stays syntactically valid"},
+	{"build", "cannot find module", "List every dependency in the generated manifest (go.mod, package.json or requirements.txt)"},
+	{"static", "gofmt", "Format generated Go code with gofmt"},
+	{"static", "vet", "Keep generated code free of go vet findings such as unkeyed fields and unreachable code"},
+	{"api", "did not respond within", "Make generated servers start quickly and listen on the port given by the PORT environment variable"},
+	{"api", "connection refused", "Make generated servers start quickly and listen on the port given by the PORT environment variable"},
+	{"api", ": 500", "Handle database errors in generated handlers instead of returning 500s for valid requests"},
+	{"unit", "no test files", "Generate unit tests alongside models and handlers"},
+}
+
+// failedTestSuite is the part of a logged apptesting.TestSuite needed to find failures
+type failedTestSuite struct {
+	OverallStatus string `json:"overall_status"`
+	Results       []struct {
+		Type   string `json:"type"`
+		Status string `json:"status"`
+		Output string `json:"output"`
+		Error  string `json:"error"`
+	} `json:"results"`
+}
+
+// failureStats counts, per test type, the failed runs in which each known
+// hint applied and each unrecognized error line appeared
+type failureStats struct {
+	known   map[string]map[string]int
+	unknown map[string]map[string]int
+}
+
+func newFailureStats() *failureStats {
+	return &failureStats{known: map[string]map[string]int{}, unknown: map[string]map[string]int{}}
+}
+
+// add records the failed results of a logged test suite; each hint and error
+// counts once per run however often it repeats in the output
+func (stats *failureStats) add(testResultsJSON string) error {
+	var suite failedTestSuite
+	if err := json.Unmarshal([]byte(testResultsJSON), &suite); err != nil {
+		return fmt.Errorf("failed to parse test results: %w", err)
+	}
+
+	seen := map[string]bool{}
+	count := func(counts map[string]map[string]int, testType, key string) {
+		if seen[testType+"\x00"+key] {
+			return
+		}
+		seen[testType+"\x00"+key] = true
+		if counts[testType] == nil {
+			counts[testType] = map[string]int{}
+		}
+		counts[testType][key]++
+	}
+
+	for _, result := range suite.Results {
+		if result.Status != "fail" {
+			continue
+		}
+		for _, line := range failureLines(result.Error + "\n" + result.Output) {
+			if hint := matchFailurePattern(result.Type, line); hint != "" {
+				count(stats.known, result.Type, hint)
+			} else {
+				count(stats.unknown, result.Type, line)
+			}
+		}
+	}
+	return nil
+}
+
+// matchFailurePattern returns the hint of the first known pattern matching an
+// error line of the given test type, or "" if none does
+func matchFailurePattern(testType, line string) string {
+	line = strings.ToLower(line)
+	for _, pattern := range knownFailurePatterns {
+		if (pattern.testType == "" || pattern.testType == testType) && strings.Contains(line, pattern.substring) {
+			return pattern.hint
+		}
+	}
+	return ""
+}
+
+// failureLines returns the non-empty lines of a failure message,
+// without file positions so the same error in different files counts together
+func failureLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		// Package headers and the exit status say nothing about the cause
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "exit status") {
+			continue
+		}
+		// ./internal/models/user.go:12:5: undefined: foo -> undefined: foo
+		if i := strings.LastIndex(line, ".go:"); i >= 0 {
+			rest := line[i+len(".go:"):]
+			for len(rest) > 0 && (rest[0] >= '0' && rest[0] <= '9' || rest[0] == ':') {
+				rest = rest[1:]
+			}
+			line = strings.TrimSpace(rest)
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// hintCount is a hint and the number of failed runs it addresses
+type hintCount struct {
+	testType    string
+	hint        string
+	occurrences int
+}
+
+// hints turns the failures into guidance. Known patterns give their hint
+// however often they were seen, since counts add up across runs of the
+// fine-tuner; the most frequent unrecognized error of each test type is
+// quoted as something to avoid once it was seen in minHintOccurrences runs.
+func (stats *failureStats) hints() []hintCount {
+	var hints []hintCount
+	for testType, counts := range stats.known {
+		for hint, occurrences := range counts {
+			hints = append(hints, hintCount{testType, hint, occurrences})
+		}
+	}
+	for testType, counts := range stats.unknown {
+		topError, topCount := "", 0
+		for line, occurrences := range counts {
+			if occurrences > topCount || occurrences == topCount && line < topError {
+				topError, topCount = line, occurrences
+			}
+		}
+		if topCount < minHintOccurrences {
+			continue
+		}
+		if len(topError) > maxErrorLength {
+			topError = topError[:maxErrorLength] + "..."
+		}
+		hint := fmt.Sprintf("Avoid this %s failure seen in generated apps: %s", testType, topError)
+		hints = append(hints, hintCount{testType, hint, topCount})
+	}
+
+	sort.Slice(hints, func(i, j int) bool {
+		if hints[i].occurrences != hints[j].occurrences {
+			return hints[i].occurrences > hints[j].occurrences
+		}
+		return hints[i].hint < hints[j].hint
+	})
+	return hints
+}
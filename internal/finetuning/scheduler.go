@@ -0,0 +1,69 @@
+package finetuning
+
+import (
+	"context"
+	"time"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/logging"
+)
+
+// DefaultInterval is how often the scheduler processes logs unless configured otherwise
+const DefaultInterval = 5 * time.Minute
+
+// LogProcessor processes new interaction logs; *Finetuner is one
+type LogProcessor interface {
+	ProcessLogs() error
+}
+
+// Scheduler runs a LogProcessor periodically until its context is cancelled
+type Scheduler struct {
+	processor LogProcessor
+	interval  time.Duration
+	logger    logging.Logger
+}
+
+// NewScheduler returns a scheduler processing logs every interval, or every
+// DefaultInterval when interval is not positive
+func NewScheduler(processor LogProcessor, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Scheduler{processor: processor, interval: interval, logger: logging.Default()}
+}
+
+// SetLogger sets the logger for scheduled runs
+func (s *Scheduler) SetLogger(logger logging.Logger) {
+	s.logger = logger
+}
+
+// Interval returns how often Run processes logs
+func (s *Scheduler) Interval() time.Duration {
+	return s.interval
+}
+
+// RunOnce processes logs once. A failure is logged and returned; it does not
+// stop later runs.
+func (s *Scheduler) RunOnce() error {
+	s.logger.Debug("Running scheduled fine-tuning process")
+	err := s.processor.ProcessLogs()
+	if err != nil {
+		s.logger.Error("Scheduled fine-tuning failed", "error", err)
+	}
+	return err
+}
+
+// Run processes logs immediately and then every interval, returning once ctx
+// is done. A run in progress when ctx is cancelled is allowed to finish.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
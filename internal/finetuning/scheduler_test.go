@@ -0,0 +1,76 @@
+package finetuning
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingProcessor counts its runs and fails every one when err is set
+type countingProcessor struct {
+	runs int32
+	err  error
+}
+
+func (p *countingProcessor) ProcessLogs() error {
+	atomic.AddInt32(&p.runs, 1)
+	return p.err
+}
+
+func (p *countingProcessor) count() int {
+	return int(atomic.LoadInt32(&p.runs))
+}
+
+func TestSchedulerRunOnce(t *testing.T) {
+	processor := &countingProcessor{err: errors.New("database is locked")}
+	scheduler := NewScheduler(processor, time.Minute)
+
+	if err := scheduler.RunOnce(); !errors.Is(err, processor.err) {
+		t.Errorf("Expected RunOnce to return the processing error, got %v", err)
+	}
+	if processor.count() != 1 {
+		t.Errorf("Expected 1 run, got %d", processor.count())
+	}
+}
+
+func TestSchedulerDefaultInterval(t *testing.T) {
+	if got := NewScheduler(&countingProcessor{}, 0).Interval(); got != DefaultInterval {
+		t.Errorf("Expected default interval %v, got %v", DefaultInterval, got)
+	}
+}
+
+func TestSchedulerRunsUntilCancelled(t *testing.T) {
+	// Every run fails, which must not stop the scheduler
+	processor := &countingProcessor{err: errors.New("database is locked")}
+	scheduler := NewScheduler(processor, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for processor.count() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected repeated runs, got %d", processor.count())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+
+	runs := processor.count()
+	time.Sleep(50 * time.Millisecond)
+	if processor.count() != runs {
+		t.Errorf("Expected no runs after Run returned, got %d more", processor.count()-runs)
+	}
+}
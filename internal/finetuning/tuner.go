@@ -1,79 +1,139 @@
 package finetuning
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
+	"sync"
+	"time"
 
 	"github.com/kevinpranata97/golang-ai-agent/internal/database"
+	"github.com/kevinpranata97/golang-ai-agent/internal/logging"
 )
 
 type Finetuner struct {
-	db *database.DB
+	db     *database.DB
+	logger logging.Logger
+
+	mutex   sync.RWMutex
+	lastRun time.Time
+	lastErr error
 	// Tambahkan referensi ke komponen lain yang mungkin perlu di-fine-tune
 	// Misalnya, requirements.Analyzer, codegen.Generator, dll.
 }
 
 func NewFinetuner(db *database.DB) *Finetuner {
-	return &Finetuner{db: db}
+	return &Finetuner{db: db, logger: logging.Default()}
+}
+
+// SetLogger sets the logger for fine-tuning progress
+func (f *Finetuner) SetLogger(logger logging.Logger) {
+	f.logger = logger
+}
+
+// LastRun returns when ProcessLogs last finished and the error it returned, if any
+func (f *Finetuner) LastRun() (time.Time, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.lastRun, f.lastErr
 }
 
 // ProcessLogs mengambil log interaksi yang belum diproses dan menerapkan logika fine-tuning.
 func (f *Finetuner) ProcessLogs() error {
+	err := f.processLogs()
+
+	f.mutex.Lock()
+	f.lastRun = time.Now()
+	f.lastErr = err
+	f.mutex.Unlock()
+
+	return err
+}
+
+func (f *Finetuner) processLogs() error {
 	logs, err := f.db.GetUnprocessedLogs()
 	if err != nil {
 		return fmt.Errorf("failed to get unprocessed logs: %w", err)
 	}
 
 	if len(logs) == 0 {
-		log.Println("No new interaction logs to process for fine-tuning.")
+		f.logger.Debug("No new interaction logs to process for fine-tuning")
 		return nil
 	}
 
-	log.Printf("Processing %d interaction logs for fine-tuning...", len(logs))
+	f.logger.Info("Processing interaction logs for fine-tuning", "count", len(logs))
+	for start := 0; start < len(logs); start += processBatchSize {
+		end := start + processBatchSize
+		if end > len(logs) {
+			end = len(logs)
+		}
+		if err := f.processBatch(logs[start:end]); err != nil {
+			return err
+		}
+	}
+	f.logger.Info("Processed logs for fine-tuning", "count", len(logs))
+
+	return nil
+}
+
+// processBatchSize caps the logs whose hints are recorded, and which are
+// marked as processed, in one transaction
+const processBatchSize = 500
+
+// processBatch mines a batch of logs for prompt hints, recording the hints and
+// marking the logs as processed together so a failure leaves the batch to be
+// mined again rather than counted twice
+func (f *Finetuner) processBatch(logs []database.InteractionLog) error {
 	var processedIDs []string
 
+	// Failed test runs, from /test-app and the test step of /generate-and-test,
+	// are mined for recurring errors
+	stats := newFailureStats()
 	for _, entry := range logs {
-		// Contoh logika fine-tuning sederhana:
-		// Jika generate-app berhasil dan test results menunjukkan kegagalan, analisis mengapa.
-		if entry.Endpoint == "/generate-app" && entry.Status == "success" && entry.TestResultsJSON != "" {
-			// Asumsikan apptesting.TestResults adalah struct yang sesuai
-			// Anda perlu memastikan struktur ini tersedia atau membuat mock-nya
-			// Untuk tujuan demonstrasi, kita akan asumsikan ada struktur TestResults
-			// dan OverallStatus di dalamnya.
-			type MockTestResults struct {
-				OverallStatus string `json:"overall_status"`
-			}
-			var testResults MockTestResults
-			if err := json.Unmarshal([]byte(entry.TestResultsJSON), &testResults); err == nil {
-				if testResults.OverallStatus == "failure" {
-					log.Printf(`Fine-tuning opportunity: App '%s' generated successfully but failed tests. Analyzing...`, entry.AppName)
-					// Di sini, kita akan menambahkan logika untuk menganalisis request_payload,
-					// response_payload, dan test_results_json untuk mengidentifikasi pola kegagalan.
-					// Misalnya, jika sering gagal karena masalah database, mungkin perlu menyesuaikan prompt
-					// atau template kode untuk database.
-					// Ini adalah bagian yang paling kompleks dan akan memerlukan AI/heuristik yang lebih canggih.
-				}
+		if entry.Status == "failure" && entry.TestResultsJSON != "" {
+			if err := stats.add(entry.TestResultsJSON); err != nil {
+				f.logger.Warn("Skipping interaction log", "log_id", entry.ID, "error", err)
 			}
 		}
-		// Tambahkan logika fine-tuning lainnya berdasarkan endpoint dan status
-
 		processedIDs = append(processedIDs, entry.ID)
 	}
 
-	if len(processedIDs) > 0 {
-		if err := f.db.MarkLogsAsProcessed(processedIDs); err != nil {
-			return fmt.Errorf("failed to mark logs as processed: %w", err)
-		}
-		log.Printf("Successfully processed %d logs for fine-tuning.", len(processedIDs))
+	now := time.Now()
+	var hints []database.PromptHint
+	for _, hint := range stats.hints() {
+		hints = append(hints, database.PromptHint{Hint: hint.hint, TestType: hint.testType, Occurrences: hint.occurrences, UpdatedAt: now})
+	}
+	if err := f.db.RecordPromptHints(hints, processedIDs); err != nil {
+		return fmt.Errorf("failed to process logs: %w", err)
+	}
+	for _, hint := range hints {
+		f.logger.Info("Recorded prompt hint", "test_type", hint.TestType, "occurrences", hint.Occurrences, "hint", hint.Hint)
 	}
-
 	return nil
 }
 
+// maxPromptHints caps how many hints GetPromptHints adds to a prompt
+const maxPromptHints = 5
+
+// GetPromptHints returns the most frequent corrective hints learned from failed
+// tests of generated apps, for the requirement analyzer to add to its prompt
+func (f *Finetuner) GetPromptHints() []string {
+	hints, err := f.db.GetPromptHints(0)
+	if err != nil {
+		f.logger.Warn("Failed to load prompt hints", "error", err)
+		return nil
+	}
+
+	var result []string
+	for _, hint := range hints {
+		if hint.Occurrences >= minHintOccurrences && len(result) < maxPromptHints {
+			result = append(result, hint.Hint)
+		}
+	}
+	return result
+}
+
 // Train method is a placeholder for future, more advanced model training.
 func (f *Finetuner) Train() error {
-	log.Println("Starting advanced fine-tuning model training (placeholder).")
+	f.logger.Info("Starting advanced fine-tuning model training (placeholder)")
 	// Implementasi pelatihan model AI yang sebenarnya akan ada di sini.
 	// Ini bisa melibatkan loading model, melatihnya dengan data dari database,
 	// dan menyimpan model yang telah di-fine-tune.
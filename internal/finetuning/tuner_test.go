@@ -0,0 +1,156 @@
+package finetuning
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/database"
+)
+
+// failedRun returns the TestResultsJSON of a run whose build failed with output
+func failedRun(t *testing.T, output string) string {
+	t.Helper()
+	suite := map[string]interface{}{
+		"overall_status": "failure",
+		"results": []map[string]interface{}{
+			{"type": "build", "status": "fail", "error": "exit status 1", "output": output},
+			{"type": "static", "status": "pass", "output": "undefined: ignored because the test passed"},
+		},
+	}
+	data, err := json.Marshal(suite)
+	if err != nil {
+		t.Fatalf("Failed to marshal test suite: %v", err)
+	}
+	return string(data)
+}
+
+func TestProcessLogsProducesPromptHints(t *testing.T) {
+	db, err := database.NewDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer db.Close()
+
+	entries := []database.InteractionLog{
+		{ID: "1", Status: "failure", TestResultsJSON: failedRun(t, "# shop\n./internal/models/user.go:12:5: undefined: Product\n./main.go:3:2: undefined: handlers")},
+		{ID: "2", Status: "failure", TestResultsJSON: failedRun(t, "./internal/models/order.go:40:1: undefined: Order")},
+		{ID: "3", Status: "failure", TestResultsJSON: failedRun(t, "./main.go:9:2: \"os\" imported and not used")},
+		// A successful run is not mined even with failing results in it
+		{ID: "4", Status: "success", TestResultsJSON: failedRun(t, "./main.go:9:2: \"os\" imported and not used")},
+		{ID: "5", Status: "failure", TestResultsJSON: "not json"},
+	}
+	for _, entry := range entries {
+		entry.Timestamp = time.Now()
+		entry.Endpoint = "/test-app"
+		if err := db.InsertInteractionLog(entry); err != nil {
+			t.Fatalf("InsertInteractionLog failed: %v", err)
+		}
+	}
+
+	f := NewFinetuner(db)
+	if err := f.ProcessLogs(); err != nil {
+		t.Fatalf("ProcessLogs failed: %v", err)
+	}
+
+	hints := f.GetPromptHints()
+	if len(hints) != 1 || !strings.Contains(hints[0], "Declare every identifier") {
+		t.Fatalf("Expected only the undefined identifier hint, seen in 2 runs, got %v", hints)
+	}
+
+	stored, err := db.GetPromptHints(0)
+	if err != nil {
+		t.Fatalf("GetPromptHints failed: %v", err)
+	}
+	if len(stored) != 2 || stored[0].TestType != "build" || stored[0].Occurrences != 2 {
+		t.Errorf("Expected the undefined (2) and unused import (1) hints, got %+v", stored)
+	}
+
+	// Hints seen once accumulate across runs of the fine-tuner
+	if err := db.InsertInteractionLog(database.InteractionLog{
+		ID: "6", Timestamp: time.Now(), Endpoint: "/test-app", Status: "failure",
+		TestResultsJSON: failedRun(t, "./cmd/app.go:1:1: \"fmt\" imported and not used"),
+	}); err != nil {
+		t.Fatalf("InsertInteractionLog failed: %v", err)
+	}
+	if err := f.ProcessLogs(); err != nil {
+		t.Fatalf("ProcessLogs failed: %v", err)
+	}
+	if hints := f.GetPromptHints(); len(hints) != 2 {
+		t.Errorf("Expected the unused import hint to reach the prompt, got %v", hints)
+	}
+
+	if unprocessed, err := db.GetUnprocessedLogs(); err != nil || len(unprocessed) != 0 {
+		t.Errorf("Expected every log to be processed, got %d (%v)", len(unprocessed), err)
+	}
+}
+
+func TestProcessLogsCountsHintsOnceAfterMarkFailure(t *testing.T) {
+	db, err := database.NewDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDB failed: %v", err)
+	}
+	defer db.Close()
+
+	for _, id := range []string{"1", "2"} {
+		if err := db.InsertInteractionLog(database.InteractionLog{
+			ID: id, Timestamp: time.Now(), Endpoint: "/test-app", Status: "failure",
+			TestResultsJSON: failedRun(t, "./main.go:3:2: undefined: handlers"),
+		}); err != nil {
+			t.Fatalf("InsertInteractionLog failed: %v", err)
+		}
+	}
+
+	// Marking the logs as processed fails
+	if _, err := db.Exec(`CREATE TRIGGER fail_mark BEFORE UPDATE ON interactions_log
+	BEGIN SELECT RAISE(ABORT, 'simulated failure'); END`); err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+	f := NewFinetuner(db)
+	if err := f.ProcessLogs(); err == nil || !strings.Contains(err.Error(), "simulated failure") {
+		t.Fatalf("Expected the simulated failure, got %v", err)
+	}
+	if stored, err := db.GetPromptHints(0); err != nil || len(stored) != 0 {
+		t.Errorf("Expected no hints from the failed run, got %+v (%v)", stored, err)
+	}
+
+	// The retry mines the logs again and counts them once
+	if _, err := db.Exec(`DROP TRIGGER fail_mark`); err != nil {
+		t.Fatalf("Failed to drop trigger: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := f.ProcessLogs(); err != nil {
+			t.Fatalf("ProcessLogs failed: %v", err)
+		}
+	}
+	stored, err := db.GetPromptHints(0)
+	if err != nil {
+		t.Fatalf("GetPromptHints failed: %v", err)
+	}
+	if len(stored) != 1 || stored[0].Occurrences != 2 {
+		t.Errorf("Expected the undefined identifier hint seen twice, got %+v", stored)
+	}
+}
+
+func TestFailureStatsQuotesRecurringUnknownErrors(t *testing.T) {
+	stats := newFailureStats()
+	for i := 0; i < 3; i++ {
+		if err := stats.add(failedRun(t, "panic: template: model.go.tmpl:3: unexpected EOF")); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+
+	if err := stats.add(failedRun(t, "panic: runtime error: index out of range")); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+
+	hints := stats.hints()
+	if len(hints) != 1 {
+		t.Fatalf("Expected only the most frequent unknown error, got %+v", hints)
+	}
+	want := "Avoid this build failure seen in generated apps: panic: template: model.go.tmpl:3: unexpected EOF"
+	if hints[0].hint != want || hints[0].occurrences != 3 {
+		t.Errorf("Unexpected hint %+v", hints[0])
+	}
+}
@@ -0,0 +1,186 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is how long before it expires an installation token is
+// replaced, so a request never goes out with a token about to lapse
+const tokenRefreshMargin = 5 * time.Minute
+
+// appJWTLifetime is how long the JWTs authenticating as the GitHub App are
+// valid; GitHub accepts at most ten minutes
+const appJWTLifetime = 9 * time.Minute
+
+// ErrInvalidPrivateKey is returned by NewAppClient when the private key is not
+// an RSA key in PEM format
+var ErrInvalidPrivateKey = errors.New("invalid GitHub App private key")
+
+// installationTokens mints installation access tokens for a GitHub App and
+// caches each until shortly before it expires
+type installationTokens struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	client         *Client      // provides the API base URL
+	httpClient     *http.Client // sends the token requests, without the app transport
+	now            func() time.Time
+
+	mutex     sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewAppClient creates a client authenticating as an installation of a GitHub
+// App. Installation tokens expire after an hour, so the client mints one with a
+// JWT signed by privateKeyPEM, caches it and mints a new one before it expires.
+func NewAppClient(appID, installationID int64, privateKeyPEM []byte) (*Client, error) {
+	key, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewClient("")
+	c.tokens = &installationTokens{
+		appID:          appID,
+		installationID: installationID,
+		key:            key,
+		client:         c,
+		httpClient:     &http.Client{},
+		now:            time.Now,
+	}
+	c.httpClient = &http.Client{Transport: &appTransport{tokens: c.tokens, base: http.DefaultTransport}}
+	return c, nil
+}
+
+// parsePrivateKey decodes an RSA private key in PKCS#1 or PKCS#8 PEM format,
+// as downloaded from the GitHub App settings
+func parsePrivateKey(privateKeyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block found", ErrInvalidPrivateKey)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an RSA key", ErrInvalidPrivateKey)
+	}
+	return key, nil
+}
+
+// Token returns a valid installation token, minting a new one when there is
+// none or the cached one expires within tokenRefreshMargin
+func (t *installationTokens) Token(ctx context.Context) (string, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.token != "" && t.now().Add(tokenRefreshMargin).Before(t.expiresAt) {
+		return t.token, nil
+	}
+
+	token, expiresAt, err := t.mint(ctx)
+	if err != nil {
+		return "", err
+	}
+	t.token, t.expiresAt = token, expiresAt
+	return token, nil
+}
+
+// mint exchanges a freshly signed app JWT for a new installation token
+func (t *installationTokens) mint(ctx context.Context) (string, time.Time, error) {
+	jwt, err := t.signJWT()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", t.client.baseURL, t.installationID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to request installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return "", time.Time{}, fmt.Errorf("failed to request installation token: %s", string(body))
+	}
+
+	var result struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to decode installation token: %w", err)
+	}
+	return result.Token, result.ExpiresAt, nil
+}
+
+// signJWT returns an RS256 JWT identifying the GitHub App. It is issued a
+// minute in the past to allow for clock drift.
+func (t *installationTokens) signJWT() (string, error) {
+	now := t.now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": t.appID,
+	})
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
+
+// appTransport authenticates every request with the current installation token
+type appTransport struct {
+	tokens *installationTokens
+	base   http.RoundTripper
+}
+
+// RoundTrip sends req with an Authorization header carrying the installation token
+func (a *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := a.tokens.Token(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	// RoundTrippers must not modify the caller's request
+	authenticated := req.Clone(req.Context())
+	authenticated.Header.Set("Authorization", "Bearer "+token)
+	return a.base.RoundTrip(authenticated)
+}
@@ -0,0 +1,139 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppClientRefreshesExpiredToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	now := time.Now()
+	minted := 0
+	var apiAuth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/app/installations/99/access_tokens":
+			verifyAppJWT(t, &key.PublicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), 42)
+			minted++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": "installation-%d", "expires_at": %q}`, minted, now.Add(time.Hour).Format(time.RFC3339))
+		case r.Method == "GET" && r.URL.Path == "/repos/owner/repo":
+			apiAuth = append(apiAuth, r.Header.Get("Authorization"))
+			w.Write([]byte(`{"name": "repo", "full_name": "owner/repo"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewAppClient(42, 99, keyPEM)
+	if err != nil {
+		t.Fatalf("NewAppClient failed: %v", err)
+	}
+	client.SetBaseURL(server.URL)
+	client.tokens.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetRepository("owner/repo"); err != nil {
+			t.Fatalf("GetRepository failed: %v", err)
+		}
+	}
+	if minted != 1 {
+		t.Errorf("expected the cached token to be reused, minted %d", minted)
+	}
+
+	// Past its expiry the token is replaced before the next call
+	now = now.Add(2 * time.Hour)
+	if _, err := client.GetRepository("owner/repo"); err != nil {
+		t.Fatalf("GetRepository failed: %v", err)
+	}
+	want := []string{"Bearer installation-1", "Bearer installation-1", "Bearer installation-2"}
+	if minted != 2 || strings.Join(apiAuth, ",") != strings.Join(want, ",") {
+		t.Errorf("expected a refreshed token, minted %d and sent %v", minted, apiAuth)
+	}
+}
+
+func TestAppClientTokenFailure(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/app/installations/99/access_tokens" {
+			t.Errorf("API called without a token: %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message": "Bad credentials"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewAppClient(42, 99, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+	if err != nil {
+		t.Fatalf("NewAppClient failed: %v", err)
+	}
+	client.SetBaseURL(server.URL)
+	if _, err := client.GetRepository("owner/repo"); err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("expected the token error, got %v", err)
+	}
+
+	if _, err := NewAppClient(42, 99, []byte("not a key")); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("expected ErrInvalidPrivateKey, got %v", err)
+	}
+}
+
+// verifyAppJWT checks that token is an RS256 JWT signed by key and issued by appID
+func verifyAppJWT(t *testing.T, key *rsa.PublicKey, token string, appID int64) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("malformed JWT %q", token)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("malformed JWT signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("JWT signature does not verify: %v", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("malformed JWT claims: %v", err)
+	}
+	var claims struct {
+		Iat int64 `json:"iat"`
+		Exp int64 `json:"exp"`
+		Iss int64 `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("failed to decode JWT claims: %v", err)
+	}
+	if claims.Iss != appID || claims.Exp-claims.Iat > int64((10*time.Minute).Seconds()) {
+		t.Errorf("unexpected JWT claims %+v", claims)
+	}
+}
@@ -2,6 +2,7 @@ package github
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,12 +10,52 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// DefaultBaseURL is the GitHub REST API endpoint used unless SetBaseURL overrides it
+const DefaultBaseURL = "https://api.github.com"
+
 type Client struct {
 	token      string
+	baseURL    string
 	httpClient *http.Client
+	tokens     *installationTokens // set for GitHub App clients instead of token
+
+	mutex          sync.Mutex
+	rateLimitReset time.Time // set while the API reports no remaining requests
+}
+
+// RateLimitError is returned when the GitHub API rate limit is exhausted.
+// Callers should back off until Reset.
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("GitHub API rate limit exceeded, resets at %s", e.Reset.Format(time.RFC3339))
+}
+
+// PullRequest is an open pull request as returned by the GitHub API
+type PullRequest struct {
+	Number  int            `json:"number"`
+	Title   string         `json:"title"`
+	State   string         `json:"state"`
+	HTMLURL string         `json:"html_url"`
+	Head    PullRequestRef `json:"head"`
+	Base    PullRequestRef `json:"base"`
+}
+
+// PullRequestRef is the head or base branch of a pull request
+type PullRequestRef struct {
+	Ref  string `json:"ref"`
+	SHA  string `json:"sha"`
+	Repo struct {
+		FullName string `json:"full_name"`
+	} `json:"repo"`
 }
 
 type CommitStatus struct {
@@ -34,12 +75,62 @@ type Repository struct {
 func NewClient(token string) *Client {
 	return &Client{
 		token:      token,
+		baseURL:    DefaultBaseURL,
 		httpClient: &http.Client{},
 	}
 }
 
+// SetBaseURL points the client at another API endpoint, such as GitHub Enterprise
+func (c *Client) SetBaseURL(baseURL string) {
+	if baseURL != "" {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// do sends an authenticated API request. While a previous response reported the
+// rate limit as exhausted it fails fast with a RateLimitError instead.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	c.mutex.Lock()
+	reset := c.rateLimitReset
+	c.mutex.Unlock()
+	if time.Now().Before(reset) {
+		return nil, &RateLimitError{Reset: reset}
+	}
+
+	// GitHub App clients are authenticated by their transport
+	if c.tokens == nil {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if req.Body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset := time.Now().Add(time.Minute)
+		if epoch, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			reset = time.Unix(epoch, 0)
+		}
+		c.mutex.Lock()
+		c.rateLimitReset = reset
+		c.mutex.Unlock()
+
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+			resp.Body.Close()
+			return nil, &RateLimitError{Reset: reset}
+		}
+	}
+
+	return resp, nil
+}
+
 func (c *Client) SetCommitStatus(repo, sha, state, description string) error {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/statuses/%s", repo, sha)
+	url := fmt.Sprintf("%s/repos/%s/statuses/%s", c.baseURL, repo, sha)
 	
 	status := CommitStatus{
 		State:       state,
@@ -57,11 +148,7 @@ func (c *Client) SetCommitStatus(repo, sha, state, description string) error {
 		return err
 	}
 	
-	req.Header.Set("Authorization", "token "+c.token)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
@@ -76,17 +163,14 @@ func (c *Client) SetCommitStatus(repo, sha, state, description string) error {
 }
 
 func (c *Client) GetRepository(repo string) (*Repository, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s", repo)
+	url := fmt.Sprintf("%s/repos/%s", c.baseURL, repo)
 	
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	
-	req.Header.Set("Authorization", "token "+c.token)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -104,16 +188,102 @@ func (c *Client) GetRepository(repo string) (*Repository, error) {
 	return &repository, nil
 }
 
+// CreateIssueComment posts a markdown comment on a pull request or issue
+func (c *Client) CreateIssueComment(repo string, prNumber int, body string) error {
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/comments", c.baseURL, repo, prNumber)
+	
+	jsonData, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return err
+	}
+	
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to create comment: %s", string(body))
+	}
+	
+	return nil
+}
+
+// ListOpenPullRequests returns the open pull requests of a repository
+func (c *Client) ListOpenPullRequests(repo string) ([]PullRequest, error) {
+	url := fmt.Sprintf("%s/repos/%s/pulls?state=open&per_page=100", c.baseURL, repo)
+	
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list pull requests: %d", resp.StatusCode)
+	}
+	
+	var pulls []PullRequest
+	if err := json.NewDecoder(resp.Body).Decode(&pulls); err != nil {
+		return nil, err
+	}
+	
+	return pulls, nil
+}
+
+// CloneRepository clones cloneURL into destination, authenticating HTTPS URLs
+// with the client's token
 func (c *Client) CloneRepository(cloneURL, destination string) error {
+	return c.CloneBranch(context.Background(), cloneURL, destination, "")
+}
+
+// CloneBranch clones branch of cloneURL into destination, or the default
+// branch when branch is empty, killing git once ctx is done. The token never
+// appears in the returned error.
+func (c *Client) CloneBranch(ctx context.Context, cloneURL, destination, branch string) error {
 	// Add token to clone URL for authentication
-	authenticatedURL := strings.Replace(cloneURL, "https://", fmt.Sprintf("https://%s@", c.token), 1)
-	
-	cmd := exec.Command("git", "clone", authenticatedURL, destination)
+	token, userinfo := c.token, c.token
+	if c.tokens != nil {
+		installationToken, err := c.tokens.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to clone repository: %w", err)
+		}
+		token, userinfo = installationToken, "x-access-token:"+installationToken
+	}
+	authenticatedURL := cloneURL
+	if token != "" {
+		authenticatedURL = strings.Replace(cloneURL, "https://", fmt.Sprintf("https://%s@", userinfo), 1)
+	}
+
+	args := []string{"clone"}
+	if branch != "" {
+		args = append(args, "--branch", branch)
+	}
+	args = append(args, authenticatedURL, destination)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	// Fail instead of waiting for credentials nobody will type
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		if token != "" {
+			output = bytes.ReplaceAll(output, []byte(token), []byte("***"))
+		}
 		return fmt.Errorf("failed to clone repository: %s, output: %s", err, string(output))
 	}
-	
+
 	return nil
 }
 
@@ -0,0 +1,83 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPullRequestComments(t *testing.T) {
+	var comment string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token secret" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/owner/repo/pulls":
+			if r.URL.Query().Get("state") != "open" {
+				t.Errorf("expected open pull requests, got query %q", r.URL.RawQuery)
+			}
+			w.Write([]byte(`[{"number": 7, "title": "Add feature", "head": {"ref": "feature", "sha": "abc"}, "base": {"ref": "main"}}]`))
+		case r.Method == "POST" && r.URL.Path == "/repos/owner/repo/issues/7/comments":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			comment = body["body"]
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("secret")
+	client.SetBaseURL(server.URL)
+
+	pulls, err := client.ListOpenPullRequests("owner/repo")
+	if err != nil {
+		t.Fatalf("ListOpenPullRequests failed: %v", err)
+	}
+	if len(pulls) != 1 || pulls[0].Number != 7 || pulls[0].Head.Ref != "feature" || pulls[0].Base.Ref != "main" {
+		t.Fatalf("unexpected pull requests %+v", pulls)
+	}
+
+	if err := client.CreateIssueComment("owner/repo", 7, "**done**"); err != nil {
+		t.Fatalf("CreateIssueComment failed: %v", err)
+	}
+	if comment != "**done**" {
+		t.Errorf("unexpected comment body %q", comment)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient("secret")
+	client.SetBaseURL(server.URL)
+
+	for i := 0; i < 2; i++ {
+		err := client.CreateIssueComment("owner/repo", 1, "body")
+		var rateLimit *RateLimitError
+		if !errors.As(err, &rateLimit) {
+			t.Fatalf("expected RateLimitError, got %v", err)
+		}
+		if !rateLimit.Reset.Equal(reset) {
+			t.Errorf("expected reset %s, got %s", reset, rateLimit.Reset)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the client to stop calling the API until reset, made %d requests", requests)
+	}
+}
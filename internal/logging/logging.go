@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Logger is a leveled, structured logger. Arguments after the message are
+// alternating keys and values, such as "endpoint", "/generate-app".
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+	With(args ...interface{}) Logger
+}
+
+// slogLogger implements Logger on top of log/slog
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// New creates a logger writing text records to w at or above level (debug,
+// info, warn or error). Unknown levels select info.
+func New(w io.Writer, level string) Logger {
+	handler := slog.NewTextHandler(w, &slog.HandlerOptions{Level: ParseLevel(level)})
+	return &slogLogger{logger: slog.New(handler)}
+}
+
+// Default returns an info level logger writing to stderr
+func Default() Logger {
+	return New(os.Stderr, "info")
+}
+
+// ParseLevel converts a configured level name to a slog level
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func (l *slogLogger) Debug(msg string, args ...interface{}) { l.logger.Debug(msg, args...) }
+
+func (l *slogLogger) Info(msg string, args ...interface{}) { l.logger.Info(msg, args...) }
+
+func (l *slogLogger) Warn(msg string, args ...interface{}) { l.logger.Warn(msg, args...) }
+
+func (l *slogLogger) Error(msg string, args ...interface{}) { l.logger.Error(msg, args...) }
+
+// With returns a logger adding the given key-value pairs to every record
+func (l *slogLogger) With(args ...interface{}) Logger {
+	return &slogLogger{logger: l.logger.With(args...)}
+}
@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLevelFiltersRecords(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "warn").With("endpoint", "/generate-app")
+
+	logger.Debug("debug record")
+	logger.Info("info record")
+	logger.Warn("warn record", "app_name", "blog")
+	logger.Error("error record")
+
+	output := buf.String()
+	for _, suppressed := range []string{"debug record", "info record"} {
+		if strings.Contains(output, suppressed) {
+			t.Errorf("expected %q to be suppressed at warn level:\n%s", suppressed, output)
+		}
+	}
+	for _, want := range []string{`msg="warn record"`, "endpoint=/generate-app", "app_name=blog", `msg="error record"`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %s:\n%s", want, output)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+		"":        slog.LevelInfo,
+		"verbose": slog.LevelInfo,
+	}
+	for level, expected := range tests {
+		if got := ParseLevel(level); got != expected {
+			t.Errorf("ParseLevel(%q) = %v, want %v", level, got, expected)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounter("requests_total", "Requests served.", "path")
+	durations := registry.NewHistogram("duration_seconds", "Request duration.", []float64{1, 5}, "path")
+	registry.NewGaugeFunc("jobs", "Running jobs.", func() float64 { return 3 })
+
+	requests.Inc("/b")
+	requests.Inc("/a")
+	requests.Add(2, "/a")
+	requests.Inc(`say "hi"`)
+	durations.Observe(0.5, "/a")
+	durations.Observe(2, "/a")
+	durations.Observe(10, "/a")
+
+	var out strings.Builder
+	if err := registry.WriteText(&out); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	want := `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{path="/a"} 3
+requests_total{path="/b"} 1
+requests_total{path="say \"hi\""} 1
+# HELP duration_seconds Request duration.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{path="/a",le="1"} 1
+duration_seconds_bucket{path="/a",le="5"} 2
+duration_seconds_bucket{path="/a",le="+Inf"} 3
+duration_seconds_sum{path="/a"} 12.5
+duration_seconds_count{path="/a"} 3
+# HELP jobs Running jobs.
+# TYPE jobs gauge
+jobs 3
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestLabelMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a missing label value")
+		}
+	}()
+	NewRegistry().NewCounter("requests_total", "Requests served.", "path", "method").Inc("/a")
+}
@@ -1,21 +1,25 @@
 package requirements
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
-	"time"
+	"unicode"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/logging"
 )
 
 // ApplicationRequirement represents the parsed requirements for an application
 type ApplicationRequirement struct {
 	Name         string                 `json:"name"`
 	Description  string                 `json:"description"`
-	Type         string                 `json:"type"` // web, api, cli, etc.
+	Type         string                 `json:"type"` // web, api, graphql, cli, etc.
 	Language     string                 `json:"language"`
 	Framework    string                 `json:"framework"`
 	Database     string                 `json:"database"`
@@ -25,6 +29,20 @@ type ApplicationRequirement struct {
 	Pages        []UIPage               `json:"pages"`
 	Dependencies []string               `json:"dependencies"`
 	Config       map[string]interface{} `json:"config"`
+	AuthStrategy string                 `json:"auth_strategy,omitempty"` // none, apikey, jwt, oauth2
+}
+
+// EffectiveAuthStrategy returns the auth strategy the application is generated
+// with. An unset strategy is jwt for apps with the authentication feature and
+// none otherwise.
+func (r *ApplicationRequirement) EffectiveAuthStrategy() string {
+	if r.AuthStrategy == "" {
+		if contains(r.Features, "authentication") {
+			return "jwt"
+		}
+		return "none"
+	}
+	return r.AuthStrategy
 }
 
 // Entity represents a data entity in the application
@@ -33,6 +51,12 @@ type Entity struct {
 	Fields     []EntityField     `json:"fields"`
 	Relations  []EntityRelation  `json:"relations"`
 	Operations []string          `json:"operations"` // CRUD operations
+	SampleData []map[string]interface{} `json:"sample_data,omitempty"` // example records from the description
+}
+
+// TableName is the database table the code generator stores the entity in
+func (e Entity) TableName() string {
+	return strings.ToLower(e.Name) + "s"
 }
 
 // EntityField represents a field in an entity
@@ -41,6 +65,22 @@ type EntityField struct {
 	Type       string `json:"type"`
 	Required   bool   `json:"required"`
 	Validation string `json:"validation"`
+	// Default is the column default, such as "member", "0" or "CURRENT_TIMESTAMP"
+	Default string `json:"default,omitempty"`
+	// AutoManaged marks a date the application sets itself: on create, and on
+	// every update unless it is created_at
+	AutoManaged bool `json:"auto_managed,omitempty"`
+}
+
+// IsAutoManaged reports whether the application sets the field itself rather
+// than taking it from clients. created_at and updated_at dates always are.
+func (f EntityField) IsAutoManaged() bool {
+	return f.AutoManaged || (f.Type == "date" && (f.Name == "created_at" || f.Name == "updated_at"))
+}
+
+// SetOnUpdate reports whether an auto-managed field is refreshed on every update
+func (f EntityField) SetOnUpdate() bool {
+	return f.IsAutoManaged() && f.Name != "created_at"
 }
 
 // EntityRelation represents relationships between entities
@@ -74,39 +114,184 @@ type UIPage struct {
 	Components  []string `json:"components"`
 }
 
+// RequirementOverrides holds explicit stack choices that take precedence over inference
+type RequirementOverrides struct {
+	Language  string `json:"language,omitempty"`
+	Framework string `json:"framework,omitempty"`
+	Database  string `json:"database,omitempty"`
+	Type      string `json:"type,omitempty"`
+	AuthStrategy string `json:"auth_strategy,omitempty"`
+}
+
+// SupportedFrameworks lists the frameworks the code generator implements for
+// each language, default first
+var SupportedFrameworks = map[string][]string{
+	"go":         {"gin", "gqlgen"},
+	"javascript": {"express"},
+	"python":     {"flask", "fastapi"},
+}
+
+// SupportedDatabases lists the databases applications can be generated for
+var SupportedDatabases = []string{"sqlite", "postgresql", "mysql", "mongodb"}
+
+// SupportedTypes lists the application types that can be generated
+var SupportedTypes = []string{"api", "graphql", "cli"}
+
+// SupportedLanguageTypes lists the application types the code generator
+// implements for each language
+var SupportedLanguageTypes = map[string][]string{
+	"go":         {"api", "graphql", "cli"},
+	"javascript": {"api"},
+	"python":     {"api"},
+}
+
+// SupportedAuthStrategies lists the API authentication schemes that can be generated
+var SupportedAuthStrategies = []string{"none", "apikey", "jwt", "oauth2"}
+
+// SupportedResponseStyles lists the values of Config["response_style"], default
+// first: plain bodies, or every response wrapped in a {data, error, meta} envelope
+var SupportedResponseStyles = []string{"plain", "envelope"}
+
+// SupportedFieldTypes lists the entity field types the generators map to
+// language and SQL types
+var SupportedFieldTypes = []string{"string", "email", "int", "float", "bool", "date"}
+
+// SupportedLanguages returns the languages applications can be generated in, sorted
+func SupportedLanguages() []string {
+	languages := make([]string, 0, len(SupportedFrameworks))
+	for language := range SupportedFrameworks {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// supportedFrameworkList returns the frameworks of every supported language,
+// by language
+func supportedFrameworkList() []string {
+	var frameworks []string
+	for _, language := range SupportedLanguages() {
+		frameworks = append(frameworks, SupportedFrameworks[language]...)
+	}
+	return frameworks
+}
+
+// frameworkDependencies holds the default dependencies for each framework
+var frameworkDependencies = map[string][]string{
+	"gin":     {"github.com/gin-gonic/gin", "github.com/gin-contrib/cors"},
+	"express": {"express", "cors", "helmet", "morgan"},
+	"flask":   {"flask", "flask-cors", "flask-sqlalchemy", "flask-migrate"},
+	"fastapi": {"fastapi", "uvicorn", "pydantic", "sqlalchemy"},
+}
+
 // RequirementAnalyzer handles the analysis of user requirements
 type RequirementAnalyzer struct {
-	geminiAPIKey string
-	httpClient   *http.Client
+	provider LLMProvider
+	limits   RequirementLimits
+	hints    PromptHintSource
+	logger   logging.Logger
+}
+
+// PromptHintSource supplies corrective guidance learned from earlier
+// generations, such as the fine-tuner's hints from failed tests
+type PromptHintSource interface {
+	GetPromptHints() []string
+}
+
+// RequirementLimits caps how many entities and endpoints a requirement may have; zero disables a limit
+type RequirementLimits struct {
+	MaxEntities  int `json:"max_entities"`
+	MaxEndpoints int `json:"max_endpoints"`
+}
+
+// DefaultRequirementLimits returns limits generous enough for any realistic description
+func DefaultRequirementLimits() RequirementLimits {
+	return RequirementLimits{
+		MaxEntities:  20,
+		MaxEndpoints: 100,
+	}
 }
 
-// NewRequirementAnalyzer creates a new requirement analyzer
-func NewRequirementAnalyzer(geminiAPIKey string) *RequirementAnalyzer {
+// NewRequirementAnalyzer creates a new requirement analyzer; a nil provider uses rule-based analysis only
+func NewRequirementAnalyzer(provider LLMProvider) *RequirementAnalyzer {
 	return &RequirementAnalyzer{
-		geminiAPIKey: geminiAPIKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		provider: provider,
+		limits:   DefaultRequirementLimits(),
+		logger:   logging.Default(),
 	}
 }
 
+// SetLogger sets the logger for LLM fallbacks
+func (ra *RequirementAnalyzer) SetLogger(logger logging.Logger) {
+	ra.logger = logger
+}
+
+// SetPromptHints sets where LLM prompts get their corrective hints from
+func (ra *RequirementAnalyzer) SetPromptHints(source PromptHintSource) {
+	ra.hints = source
+}
+
 // AnalyzeRequirements analyzes user requirements and returns structured application requirements
 func (ra *RequirementAnalyzer) AnalyzeRequirements(userDescription string) (*ApplicationRequirement, error) {
-	// First, try to use Gemini API for analysis
-	if ra.geminiAPIKey != "" {
-		result, err := ra.analyzeWithGemini(userDescription)
+	return ra.AnalyzeRequirementsContext(context.Background(), userDescription)
+}
+
+// AnalyzeRequirementsContext is AnalyzeRequirements bound to ctx: when ctx is
+// canceled or times out during the LLM request, it returns an *LLMError
+// wrapping the context's error instead of falling back to rule-based analysis
+func (ra *RequirementAnalyzer) AnalyzeRequirementsContext(ctx context.Context, userDescription string) (*ApplicationRequirement, error) {
+	// First, try to use the LLM provider for analysis
+	if ra.provider != nil {
+		result, err := ra.analyzeWithLLM(ctx, userDescription)
 		if err == nil {
+			result.Entities = dedupeEntities(result.Entities)
 			return result, nil
 		}
-		fmt.Printf("Gemini API failed, falling back to rule-based analysis: %v\n", err)
+		if ctx.Err() != nil {
+			return nil, &LLMError{Err: fmt.Errorf("requirement analysis aborted: %w", ctx.Err())}
+		}
+		ra.logger.Warn("LLM provider failed, falling back to rule-based analysis", "error", err)
 	}
 
 	// Fallback to rule-based analysis
 	return ra.analyzeWithRules(userDescription)
 }
 
-// analyzeWithGemini uses Google Gemini API for requirement analysis
-func (ra *RequirementAnalyzer) analyzeWithGemini(userDescription string) (*ApplicationRequirement, error) {
+// ErrLLMNotConfigured is returned by CheckLLM when no LLM provider is configured
+var ErrLLMNotConfigured = errors.New("LLM provider not configured")
+
+// CheckLLM verifies that the configured LLM provider is reachable with the current API key
+func (ra *RequirementAnalyzer) CheckLLM(ctx context.Context) error {
+	if ra.provider == nil {
+		return ErrLLMNotConfigured
+	}
+	if checker, ok := ra.provider.(providerChecker); ok {
+		return checker.Check(ctx)
+	}
+	return nil
+}
+
+// promptHints renders the hint source's guidance as a prompt section, or ""
+// when there is none
+func (ra *RequirementAnalyzer) promptHints() string {
+	if ra.hints == nil {
+		return ""
+	}
+	hints := ra.hints.GetPromptHints()
+	if len(hints) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\nApplications generated from earlier descriptions failed their tests in these ways, so keep the requirements compatible with this guidance:\n")
+	for _, hint := range hints {
+		b.WriteString("- " + hint + "\n")
+	}
+	return b.String()
+}
+
+// analyzeWithLLM uses the configured LLM provider for requirement analysis
+func (ra *RequirementAnalyzer) analyzeWithLLM(ctx context.Context, userDescription string) (*ApplicationRequirement, error) {
 	prompt := fmt.Sprintf(`
 Analyze the following application requirements and return a structured JSON response:
 
@@ -116,18 +301,19 @@ Please analyze this description and return a JSON object with the following stru
 {
   "name": "application name",
   "description": "detailed description",
-  "type": "web|api|cli|desktop",
-  "language": "go|javascript|python|java",
-  "framework": "gin|echo|react|vue|flask|spring",
+  "type": "%s",
+  "language": "%s",
+  "framework": "%s",
   "database": "postgresql|mysql|sqlite|mongodb",
   "features": ["list of main features"],
+  "auth_strategy": "none|apikey|jwt|oauth2",
   "entities": [
     {
       "name": "entity name",
       "fields": [
         {
           "name": "field name",
-          "type": "string|int|bool|date|email",
+          "type": "string|int|float|bool|date|email",
           "required": true|false,
           "validation": "validation rules"
         }
@@ -138,7 +324,8 @@ Please analyze this description and return a JSON object with the following stru
           "target": "related entity name"
         }
       ],
-      "operations": ["create", "read", "update", "delete"]
+      "operations": ["create", "read", "update", "delete"],
+      "sample_data": [{"field name": "example value taken from the description, only if concrete records are described"}]
     }
   ],
   "endpoints": [
@@ -173,85 +360,22 @@ Please analyze this description and return a JSON object with the following stru
 }
 
 Focus on extracting entities, relationships, and required functionality. Make reasonable assumptions for missing details.
-`, userDescription)
-
-	reqBody := map[string]interface{}{
-		"contents": []map[string]interface{}{
-			{
-				"parts": []map[string]string{
-					{"text": prompt},
-				},
-			},
-		},
-		"generationConfig": map[string]interface{}{
-			"temperature":     0.1,
-			"maxOutputTokens": 2048,
-		},
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %v", err)
-	}
+`, userDescription, strings.Join(SupportedTypes, "|"), strings.Join(SupportedLanguages(), "|"), strings.Join(supportedFrameworkList(), "|"))
+	prompt += ra.promptHints()
 
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/gemini-pro:generateContent?key=%s", ra.geminiAPIKey)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := ra.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %v", err)
+	var responseText string
+	var err error
+	if provider, ok := ra.provider.(contextProvider); ok {
+		responseText, err = provider.AnalyzeRequirementsContext(ctx, prompt)
+	} else {
+		responseText, err = ra.provider.AnalyzeRequirements(prompt)
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var geminiResp struct {
-		Candidates []struct {
-			Content struct {
-				Parts []struct {
-					Text string `json:"text"`
-				} `json:"parts"`
-			} `json:"content"`
-		} `json:"candidates"`
-	}
-
-	if err := json.Unmarshal(body, &geminiResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
-	}
-
-	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no content in response")
-	}
-
-	responseText := geminiResp.Candidates[0].Content.Parts[0].Text
-	
-	// Extract JSON from the response (it might be wrapped in markdown)
-	jsonStart := strings.Index(responseText, "{")
-	jsonEnd := strings.LastIndex(responseText, "}")
-	if jsonStart == -1 || jsonEnd == -1 {
-		return nil, fmt.Errorf("no JSON found in response")
+		return nil, err
 	}
 
-	jsonStr := responseText[jsonStart : jsonEnd+1]
-	
-	var appReq ApplicationRequirement
-	if err := json.Unmarshal([]byte(jsonStr), &appReq); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal application requirements: %v", err)
-	}
-
-	return &appReq, nil
+	// The JSON might be wrapped in markdown and surrounded by prose
+	return extractRequirements(responseText)
 }
 
 // analyzeWithRules provides rule-based analysis as fallback
@@ -329,7 +453,13 @@ func (ra *RequirementAnalyzer) analyzeWithRules(userDescription string) (*Applic
 	}
 
 	// Determine application type
-	if strings.Contains(desc, "web") || strings.Contains(desc, "website") || strings.Contains(desc, "frontend") {
+	if strings.Contains(desc, "graphql") {
+		appReq.Type = "graphql"
+		if appReq.Language == "go" {
+			appReq.Framework = "gqlgen"
+			appReq.Dependencies = []string{}
+		}
+	} else if strings.Contains(desc, "web") || strings.Contains(desc, "website") || strings.Contains(desc, "frontend") {
 		appReq.Type = "web"
 	} else if strings.Contains(desc, "api") || strings.Contains(desc, "rest") || strings.Contains(desc, "service") {
 		appReq.Type = "api"
@@ -340,56 +470,24 @@ func (ra *RequirementAnalyzer) analyzeWithRules(userDescription string) (*Applic
 	}
 
 	// Extract common entities
-	if strings.Contains(desc, "user") || strings.Contains(desc, "account") || strings.Contains(desc, "login") {
-		userEntity := Entity{
-			Name: "User",
-			Fields: []EntityField{
-				{Name: "id", Type: "int", Required: true},
-				{Name: "username", Type: "string", Required: true, Validation: "min=3,max=50"},
-				{Name: "email", Type: "email", Required: true},
-				{Name: "password", Type: "string", Required: true, Validation: "min=8"},
-				{Name: "created_at", Type: "date", Required: true},
-			},
-			Operations: []string{"create", "read", "update", "delete"},
-		}
-		appReq.Entities = append(appReq.Entities, userEntity)
-		appReq.Features = append(appReq.Features, "user_management", "authentication")
+	detectEntities(desc, appReq)
+
+	// Determine how the API is protected; apps with user accounts issue JWTs
+	// unless the description asks for something else
+	appReq.AuthStrategy = detectAuthStrategy(desc)
+	if appReq.AuthStrategy == "none" && contains(appReq.Features, "authentication") {
+		appReq.AuthStrategy = "jwt"
 	}
 
-	if strings.Contains(desc, "product") || strings.Contains(desc, "item") || strings.Contains(desc, "catalog") {
-		productEntity := Entity{
-			Name: "Product",
-			Fields: []EntityField{
-				{Name: "id", Type: "int", Required: true},
-				{Name: "name", Type: "string", Required: true, Validation: "min=1,max=200"},
-				{Name: "description", Type: "string", Required: false},
-				{Name: "price", Type: "float", Required: true, Validation: "min=0"},
-				{Name: "created_at", Type: "date", Required: true},
-			},
-			Operations: []string{"create", "read", "update", "delete"},
-		}
-		appReq.Entities = append(appReq.Entities, productEntity)
-		appReq.Features = append(appReq.Features, "product_management")
-	}
-
-	if strings.Contains(desc, "blog") || strings.Contains(desc, "post") || strings.Contains(desc, "article") {
-		postEntity := Entity{
-			Name: "Post",
-			Fields: []EntityField{
-				{Name: "id", Type: "int", Required: true},
-				{Name: "title", Type: "string", Required: true, Validation: "min=1,max=200"},
-				{Name: "content", Type: "string", Required: true},
-				{Name: "author_id", Type: "int", Required: true},
-				{Name: "published", Type: "bool", Required: true},
-				{Name: "created_at", Type: "date", Required: true},
-			},
-			Relations: []EntityRelation{
-				{Type: "many-to-one", Target: "User"},
-			},
-			Operations: []string{"create", "read", "update", "delete"},
-		}
-		appReq.Entities = append(appReq.Entities, postEntity)
-		appReq.Features = append(appReq.Features, "content_management", "blog")
+	// Capture concrete example records for seeding
+	for i := range appReq.Entities {
+		appReq.Entities[i].SampleData = extractSampleData(userDescription, appReq.Entities[i])
+	}
+
+	// Detect background processing needs
+	if strings.Contains(desc, "background job") || strings.Contains(desc, "queue") || strings.Contains(desc, "async") ||
+		strings.Contains(desc, "email sending") || strings.Contains(desc, "send email") || strings.Contains(desc, "worker") {
+		appReq.Features = append(appReq.Features, "background_jobs")
 	}
 
 	// Generate basic CRUD endpoints for each entity
@@ -473,35 +571,417 @@ func (ra *RequirementAnalyzer) analyzeWithRules(userDescription string) (*Applic
 	return appReq, nil
 }
 
-// ValidateRequirements validates the parsed requirements
+// ErrInvalidAppName is returned by SanitizeAppName for a name with no usable characters
+var ErrInvalidAppName = errors.New("invalid application name")
+
+// SanitizeAppName returns the directory and module name of an application:
+// the name in lower case with whitespace and path separators replaced by
+// hyphens, and anything but ASCII letters, digits, '-', '_' and '.' dropped.
+// Leading dots and hyphens are trimmed, so the result is always a single path
+// element such as "task-manager" and never "..".
+func SanitizeAppName(name string) (string, error) {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		case r == ' ', r == '\t', r == '/', r == '\\':
+			b.WriteRune('-')
+		}
+	}
+
+	sanitized := strings.TrimLeft(b.String(), ".-")
+	if sanitized == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAppName, name)
+	}
+	return sanitized, nil
+}
+
+// ValidateRequirements validates the parsed requirements, returning a
+// *ValidationError for requirements that cannot be generated
 func (ra *RequirementAnalyzer) ValidateRequirements(appReq *ApplicationRequirement) error {
+	if err := ra.validate(appReq); err != nil {
+		return &ValidationError{Err: err}
+	}
+	return nil
+}
+
+// validate returns the first reason appReq cannot be generated
+func (ra *RequirementAnalyzer) validate(appReq *ApplicationRequirement) error {
 	if appReq.Name == "" {
 		return fmt.Errorf("application name is required")
 	}
 
+	if _, err := SanitizeAppName(appReq.Name); err != nil {
+		return err
+	}
+
 	if appReq.Type == "" {
 		return fmt.Errorf("application type is required")
 	}
 
+	if !contains(SupportedTypes, appReq.Type) {
+		return fmt.Errorf("unsupported application type: %s (supported: %s)", appReq.Type, strings.Join(SupportedTypes, ", "))
+	}
+
 	if appReq.Language == "" {
 		return fmt.Errorf("programming language is required")
 	}
 
+	frameworks, ok := SupportedFrameworks[appReq.Language]
+	if !ok {
+		return fmt.Errorf("%w: %s (supported: %s)", ErrUnsupportedLanguage, appReq.Language, strings.Join(SupportedLanguages(), ", "))
+	}
+
+	if appReq.Framework != "" && !contains(frameworks, appReq.Framework) {
+		return fmt.Errorf("unsupported framework %s for language %s (supported: %s)", appReq.Framework, appReq.Language, strings.Join(frameworks, ", "))
+	}
+
+	// Each language only has generators for some types; GraphQL servers are
+	// only generated in Go, with gqlgen
+	if types := SupportedLanguageTypes[appReq.Language]; !contains(types, appReq.Type) {
+		return fmt.Errorf("%s applications are not supported in %s (supported: %s)", appReq.Type, appReq.Language, strings.Join(types, ", "))
+	}
+	if appReq.Framework == "gqlgen" && appReq.Type != "graphql" {
+		return fmt.Errorf("framework gqlgen requires application type graphql, not %s", appReq.Type)
+	}
+	if appReq.Type == "graphql" && appReq.Framework != "" && appReq.Framework != "gqlgen" {
+		return fmt.Errorf("graphql applications are generated with gqlgen, not %s", appReq.Framework)
+	}
+	if appReq.Type == "graphql" && len(appReq.Entities) == 0 {
+		return fmt.Errorf("graphql applications need at least one entity")
+	}
+
+	if appReq.AuthStrategy != "" && !contains(SupportedAuthStrategies, appReq.AuthStrategy) {
+		return fmt.Errorf("unsupported auth strategy: %s", appReq.AuthStrategy)
+	}
+
+	if style, ok := appReq.Config["response_style"]; ok {
+		if s, isString := style.(string); !isString || !contains(SupportedResponseStyles, s) {
+			return fmt.Errorf("unsupported response style: %v (supported: %s)", style, strings.Join(SupportedResponseStyles, ", "))
+		}
+		if style == "envelope" && appReq.Language != "go" {
+			return fmt.Errorf("response style envelope is only supported in go, not %s", appReq.Language)
+		}
+	}
+
+	// Enforce size limits before anything is generated
+	if ra.limits.MaxEntities > 0 && len(appReq.Entities) > ra.limits.MaxEntities {
+		return fmt.Errorf("too many entities: %d exceeds the limit of %d", len(appReq.Entities), ra.limits.MaxEntities)
+	}
+
+	if ra.limits.MaxEndpoints > 0 && len(appReq.Endpoints) > ra.limits.MaxEndpoints {
+		return fmt.Errorf("too many endpoints: %d exceeds the limit of %d", len(appReq.Endpoints), ra.limits.MaxEndpoints)
+	}
+
 	// Validate entities
+	tables := make(map[string]string, len(appReq.Entities))
 	for _, entity := range appReq.Entities {
 		if entity.Name == "" {
 			return fmt.Errorf("entity name is required")
 		}
+		if other, ok := tables[entity.TableName()]; ok {
+			return fmt.Errorf("entities %s and %s both map to table %s", other, entity.Name, entity.TableName())
+		}
+		tables[entity.TableName()] = entity.Name
 		if len(entity.Fields) == 0 {
 			return fmt.Errorf("entity %s must have at least one field", entity.Name)
 		}
+		for _, field := range entity.Fields {
+			if !contains(SupportedFieldTypes, field.Type) {
+				return fmt.Errorf("field %s.%s has unsupported type %q (supported: %s)", entity.Name, field.Name, field.Type, strings.Join(SupportedFieldTypes, ", "))
+			}
+			if field.AutoManaged && field.Type != "date" {
+				return fmt.Errorf("field %s.%s is auto-managed but not a date", entity.Name, field.Name)
+			}
+			if err := validateFieldDefault(field); err != nil {
+				return fmt.Errorf("field %s.%s has an invalid default: %w", entity.Name, field.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetLimits sets the entity and endpoint limits enforced by ValidateRequirements
+func (ra *RequirementAnalyzer) SetLimits(limits RequirementLimits) {
+	ra.limits = limits
+}
+
+// ApplyOverrides replaces the inferred stack with the explicitly requested
+// values, returning a *ValidationError for unsupported ones
+func (ra *RequirementAnalyzer) ApplyOverrides(appReq *ApplicationRequirement, overrides RequirementOverrides) error {
+	if err := ra.applyOverrides(appReq, overrides); err != nil {
+		return &ValidationError{Err: err}
+	}
+	return nil
+}
+
+// applyOverrides checks and applies the overrides
+func (ra *RequirementAnalyzer) applyOverrides(appReq *ApplicationRequirement, overrides RequirementOverrides) error {
+	language := strings.ToLower(overrides.Language)
+	framework := strings.ToLower(overrides.Framework)
+	database := strings.ToLower(overrides.Database)
+	appType := strings.ToLower(overrides.Type)
+	authStrategy := strings.ToLower(overrides.AuthStrategy)
+
+	if language != "" {
+		if _, ok := SupportedFrameworks[language]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnsupportedLanguage, overrides.Language)
+		}
+	} else if framework != "" {
+		language = appReq.Language
+	}
+
+	if framework != "" && !contains(SupportedFrameworks[language], framework) {
+		return fmt.Errorf("unsupported framework %s for language %s", overrides.Framework, language)
+	}
+
+	if database != "" && !contains(SupportedDatabases, database) {
+		return fmt.Errorf("unsupported database: %s", overrides.Database)
+	}
+
+	if appType != "" && !contains(SupportedTypes, appType) {
+		return fmt.Errorf("unsupported application type: %s", overrides.Type)
+	}
+
+	if authStrategy != "" && !contains(SupportedAuthStrategies, authStrategy) {
+		return fmt.Errorf("unsupported auth strategy: %s", overrides.AuthStrategy)
+	}
+
+	if language != "" && language != appReq.Language {
+		appReq.Language = language
+		if framework == "" {
+			// Fall back to the default framework of the new language
+			framework = SupportedFrameworks[language][0]
+		}
+	}
+	if framework != "" && framework != appReq.Framework {
+		appReq.Framework = framework
+		appReq.Dependencies = append([]string{}, frameworkDependencies[framework]...)
+	}
+	if database != "" {
+		appReq.Database = database
+	}
+	if appType != "" {
+		appReq.Type = appType
+		// Go GraphQL servers use gqlgen, other Go apps the default framework
+		if framework == "" && appReq.Language == "go" && (appType == "graphql") != (appReq.Framework == "gqlgen") {
+			framework = SupportedFrameworks["go"][0]
+			if appType == "graphql" {
+				framework = "gqlgen"
+			}
+			appReq.Framework = framework
+			appReq.Dependencies = append([]string{}, frameworkDependencies[framework]...)
+		}
+	}
+	if authStrategy != "" {
+		appReq.AuthStrategy = authStrategy
 	}
 
 	return nil
 }
 
+// detectAuthStrategy infers the authentication scheme from a lowercased description
+func detectAuthStrategy(desc string) string {
+	switch {
+	case strings.Contains(desc, "oauth") || strings.Contains(desc, "social login") || strings.Contains(desc, "sign in with"):
+		return "oauth2"
+	case strings.Contains(desc, "api key") || strings.Contains(desc, "api-key") || strings.Contains(desc, "apikey"):
+		return "apikey"
+	case strings.Contains(desc, "jwt") || strings.Contains(desc, "token") || strings.Contains(desc, "login") || strings.Contains(desc, "authenticat"):
+		return "jwt"
+	default:
+		return "none"
+	}
+}
+
+// Numeric defaults are written into generated Go and SQL as they are, so only
+// plain decimal literals, valid in both, are accepted
+var (
+	intDefaultPattern   = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+	floatDefaultPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+)
+
+// validateFieldDefault checks that a field's default is a value of its type
+// that can be written into the generated code and migrations
+func validateFieldDefault(field EntityField) error {
+	if field.Default == "" {
+		return nil
+	}
+	var err error
+	switch field.Type {
+	case "int":
+		if !intDefaultPattern.MatchString(field.Default) {
+			return fmt.Errorf("%q is not a decimal integer", field.Default)
+		}
+		_, err = strconv.ParseInt(field.Default, 10, 64)
+	case "float":
+		if !floatDefaultPattern.MatchString(field.Default) {
+			return fmt.Errorf("%q is not a decimal number", field.Default)
+		}
+		_, err = strconv.ParseFloat(field.Default, 64)
+	case "bool":
+		_, err = strconv.ParseBool(field.Default)
+	default:
+		// Go migrations are raw string literals, which cannot contain backticks
+		if strings.Contains(field.Default, "`") {
+			return fmt.Errorf("%q contains a backtick", field.Default)
+		}
+	}
+	return err
+}
+
+// dedupeEntities merges entities whose names only differ in case or
+// punctuation, such as "User", "user" and "USER", into the first of them,
+// which gains the fields, relations, operations and sample data it lacks
+func dedupeEntities(entities []Entity) []Entity {
+	var merged []Entity
+	index := make(map[string]int, len(entities))
+	for _, entity := range entities {
+		key := normalizeEntityName(entity.Name)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, entity)
+			continue
+		}
+
+		first := &merged[i]
+		for _, field := range entity.Fields {
+			if !hasField(*first, field.Name) {
+				first.Fields = append(first.Fields, field)
+			}
+		}
+		for _, relation := range entity.Relations {
+			if !hasRelation(*first, relation) {
+				first.Relations = append(first.Relations, relation)
+			}
+		}
+		for _, operation := range entity.Operations {
+			if !contains(first.Operations, operation) {
+				first.Operations = append(first.Operations, operation)
+			}
+		}
+		first.SampleData = append(first.SampleData, entity.SampleData...)
+	}
+	return merged
+}
+
+// normalizeEntityName lower-cases name and drops everything but letters and digits
+func normalizeEntityName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func hasField(entity Entity, name string) bool {
+	for _, field := range entity.Fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRelation(entity Entity, relation EntityRelation) bool {
+	for _, r := range entity.Relations {
+		if strings.EqualFold(r.Type, relation.Type) && r.Target == relation.Target {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether value is present in values
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirementsFile is the name of the file generated apps store their requirements in
+const RequirementsFile = "requirements.json"
+
+// LoadFromFile reads application requirements saved by the code generator
+func LoadFromFile(path string) (*ApplicationRequirement, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read requirements: %w", err)
+	}
+
+	var appReq ApplicationRequirement
+	if err := json.Unmarshal(data, &appReq); err != nil {
+		return nil, fmt.Errorf("failed to parse requirements: %v", err)
+	}
+	return &appReq, nil
+}
+
 // GetGeminiAPIKey gets the Gemini API key from environment
 func GetGeminiAPIKey() string {
 	return os.Getenv("GEMINI_API_KEY")
 }
 
+var (
+	// pricedNamePattern matches "$10 Widget"
+	pricedNamePattern = regexp.MustCompile(`\$(\d+(?:\.\d+)?)\s+([A-Z][\w-]*(?:\s+[A-Z][\w-]*)*)`)
+	// namePricedPattern matches "Widget for $10"
+	namePricedPattern = regexp.MustCompile(`([A-Z][\w-]*(?:\s+[A-Z][\w-]*)*)\s+(?:for|at|costing)\s+\$(\d+(?:\.\d+)?)`)
+	// quotedValuePattern matches "Hello World"
+	quotedValuePattern = regexp.MustCompile(`"([^"]+)"`)
+)
+
+// extractSampleData captures example records for an entity from phrases such as
+// "products like a $10 Widget and a $20 Gadget" or `posts titled "Hello World"`
+func extractSampleData(description string, entity Entity) []map[string]interface{} {
+	clausePattern := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(strings.ToLower(entity.Name)) +
+		`s?\s+(?:like|such as|including|named|titled|called)\s+(.+?)(?:\.(?:\s|$)|;|\n|$)`)
+
+	nameField := ""
+	hasPrice := false
+	for _, field := range entity.Fields {
+		switch field.Name {
+		case "name", "title", "username":
+			if nameField == "" {
+				nameField = field.Name
+			}
+		case "price":
+			hasPrice = true
+		}
+	}
+	if nameField == "" {
+		return nil
+	}
+
+	var samples []map[string]interface{}
+	for _, clause := range clausePattern.FindAllStringSubmatch(description, -1) {
+		text := clause[1]
+
+		if hasPrice {
+			for _, match := range pricedNamePattern.FindAllStringSubmatch(text, -1) {
+				price, _ := strconv.ParseFloat(match[1], 64)
+				samples = append(samples, map[string]interface{}{nameField: match[2], "price": price})
+			}
+			for _, match := range namePricedPattern.FindAllStringSubmatch(text, -1) {
+				price, _ := strconv.ParseFloat(match[2], 64)
+				samples = append(samples, map[string]interface{}{nameField: match[1], "price": price})
+			}
+			if len(samples) > 0 {
+				continue
+			}
+		}
+
+		for _, match := range quotedValuePattern.FindAllStringSubmatch(text, -1) {
+			samples = append(samples, map[string]interface{}{nameField: match[1]})
+		}
+	}
+
+	return samples
+}
@@ -0,0 +1,367 @@
+package requirements
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeDetectsBackgroundJobs(t *testing.T) {
+	ra := NewRequirementAnalyzer(nil)
+
+	tests := []struct {
+		description string
+		expected    bool
+	}{
+		{"Create a user API that sends email notifications via a background job queue", true},
+		{"Build an order API with async worker processing", true},
+		{"Create a simple user API", false},
+	}
+
+	for _, tt := range tests {
+		appReq, err := ra.AnalyzeRequirements(tt.description)
+		if err != nil {
+			t.Fatalf("AnalyzeRequirements(%q) failed: %v", tt.description, err)
+		}
+		if got := contains(appReq.Features, "background_jobs"); got != tt.expected {
+			t.Errorf("AnalyzeRequirements(%q) background_jobs = %v, want %v", tt.description, got, tt.expected)
+		}
+	}
+}
+
+func TestAnalyzeCapturesSampleData(t *testing.T) {
+	ra := NewRequirementAnalyzer(nil)
+
+	appReq, err := ra.AnalyzeRequirements("Create a product catalog API with products like a $10 Widget and a $20.50 Gadget")
+	if err != nil {
+		t.Fatalf("AnalyzeRequirements failed: %v", err)
+	}
+
+	var product *Entity
+	for i := range appReq.Entities {
+		if appReq.Entities[i].Name == "Product" {
+			product = &appReq.Entities[i]
+		}
+	}
+	if product == nil {
+		t.Fatal("expected a Product entity")
+	}
+
+	expected := []map[string]interface{}{
+		{"name": "Widget", "price": 10.0},
+		{"name": "Gadget", "price": 20.5},
+	}
+	if len(product.SampleData) != len(expected) {
+		t.Fatalf("expected %d samples, got %v", len(expected), product.SampleData)
+	}
+	for i, sample := range expected {
+		for key, value := range sample {
+			if product.SampleData[i][key] != value {
+				t.Errorf("sample %d: expected %s=%v, got %v", i, key, value, product.SampleData[i][key])
+			}
+		}
+	}
+}
+
+func TestValidateRequirementsLimits(t *testing.T) {
+	ra := NewRequirementAnalyzer(nil)
+	ra.SetLimits(RequirementLimits{MaxEntities: 1, MaxEndpoints: 5})
+
+	appReq, err := ra.AnalyzeRequirements("Create a user and product API")
+	if err != nil {
+		t.Fatalf("AnalyzeRequirements failed: %v", err)
+	}
+	if err := ra.ValidateRequirements(appReq); err == nil {
+		t.Error("expected too many entities to be rejected")
+	}
+
+	appReq.Entities = appReq.Entities[:1]
+	if err := ra.ValidateRequirements(appReq); err == nil {
+		t.Error("expected too many endpoints to be rejected")
+	}
+
+	ra.SetLimits(DefaultRequirementLimits())
+	if err := ra.ValidateRequirements(appReq); err != nil {
+		t.Errorf("expected requirement within default limits to pass, got %v", err)
+	}
+}
+
+func TestValidateRequirementsSupportedValues(t *testing.T) {
+	valid := func() *ApplicationRequirement {
+		return &ApplicationRequirement{
+			Name:      "Shop",
+			Type:      "api",
+			Language:  "go",
+			Framework: "gin",
+			Entities: []Entity{{
+				Name:   "Product",
+				Fields: []EntityField{{Name: "name", Type: "string"}, {Name: "price", Type: "float"}},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(appReq *ApplicationRequirement)
+		want   string // error substring, empty for a passing requirement
+	}{
+		{"valid", func(appReq *ApplicationRequirement) {}, ""},
+		{"no framework", func(appReq *ApplicationRequirement) { appReq.Framework = "" }, ""},
+		{"unsupported language", func(appReq *ApplicationRequirement) { appReq.Language = "cobol" }, "unsupported language: cobol (supported: go, javascript, python)"},
+		{"unsupported type", func(appReq *ApplicationRequirement) { appReq.Type = "desktop" }, "unsupported application type: desktop (supported: api, graphql, cli)"},
+		{"web application", func(appReq *ApplicationRequirement) { appReq.Type = "web" }, "unsupported application type: web (supported: api, graphql, cli)"},
+		{"framework of another language", func(appReq *ApplicationRequirement) { appReq.Framework = "django" }, "unsupported framework django for language go (supported: gin, gqlgen)"},
+		{"unimplemented framework", func(appReq *ApplicationRequirement) { appReq.Language, appReq.Framework = "python", "django" }, "unsupported framework django for language python (supported: flask, fastapi)"},
+		{"unimplemented language", func(appReq *ApplicationRequirement) { appReq.Language, appReq.Framework = "java", "" }, "unsupported language: java"},
+		{"graphql", func(appReq *ApplicationRequirement) { appReq.Type, appReq.Framework = "graphql", "gqlgen" }, ""},
+		{"graphql outside go", func(appReq *ApplicationRequirement) {
+			appReq.Type, appReq.Language, appReq.Framework = "graphql", "python", ""
+		}, "graphql applications are not supported in python (supported: api)"},
+		{"cli outside go", func(appReq *ApplicationRequirement) {
+			appReq.Type, appReq.Language, appReq.Framework = "cli", "javascript", ""
+		}, "cli applications are not supported in javascript (supported: api)"},
+		{"graphql with gin", func(appReq *ApplicationRequirement) { appReq.Type = "graphql" }, "graphql applications are generated with gqlgen, not gin"},
+		{"gqlgen for rest", func(appReq *ApplicationRequirement) { appReq.Framework = "gqlgen" }, "framework gqlgen requires application type graphql"},
+		{"unsupported field type", func(appReq *ApplicationRequirement) { appReq.Entities[0].Fields[1].Type = "decimal" }, `field Product.price has unsupported type "decimal"`},
+		{"default of the field type", func(appReq *ApplicationRequirement) { appReq.Entities[0].Fields[1].Default = "9.99" }, ""},
+		{"invalid default", func(appReq *ApplicationRequirement) { appReq.Entities[0].Fields[1].Default = "cheap" }, "field Product.price has an invalid default"},
+		{"NaN default", func(appReq *ApplicationRequirement) { appReq.Entities[0].Fields[1].Default = "NaN" }, `field Product.price has an invalid default: "NaN" is not a decimal number`},
+		{"infinite default", func(appReq *ApplicationRequirement) { appReq.Entities[0].Fields[1].Default = "-Inf" }, `field Product.price has an invalid default: "-Inf" is not a decimal number`},
+		{"hex float default", func(appReq *ApplicationRequirement) { appReq.Entities[0].Fields[1].Default = "0x1p4" }, `field Product.price has an invalid default: "0x1p4" is not a decimal number`},
+		{"exponent default", func(appReq *ApplicationRequirement) { appReq.Entities[0].Fields[1].Default = "1e3" }, `field Product.price has an invalid default: "1e3" is not a decimal number`},
+		{"negative default", func(appReq *ApplicationRequirement) { appReq.Entities[0].Fields[1].Default = "-0.5" }, ""},
+		{"quoted string default", func(appReq *ApplicationRequirement) { appReq.Entities[0].Fields[0].Default = `it's "new"` }, ""},
+		{"backtick in string default", func(appReq *ApplicationRequirement) { appReq.Entities[0].Fields[0].Default = "a`b" }, "field Product.name has an invalid default: \"a`b\" contains a backtick"},
+		{"auto-managed non-date", func(appReq *ApplicationRequirement) { appReq.Entities[0].Fields[0].AutoManaged = true }, "field Product.name is auto-managed but not a date"},
+		{"envelope responses", func(appReq *ApplicationRequirement) {
+			appReq.Config = map[string]interface{}{"response_style": "envelope"}
+		}, ""},
+		{"unsupported response style", func(appReq *ApplicationRequirement) {
+			appReq.Config = map[string]interface{}{"response_style": "jsonapi"}
+		}, "unsupported response style: jsonapi (supported: plain, envelope)"},
+		{"envelope outside go", func(appReq *ApplicationRequirement) {
+			appReq.Language, appReq.Framework = "python", ""
+			appReq.Config = map[string]interface{}{"response_style": "envelope"}
+		}, "response style envelope is only supported in go"},
+	}
+	ra := NewRequirementAnalyzer(nil)
+	for _, tt := range tests {
+		appReq := valid()
+		tt.modify(appReq)
+		err := ra.ValidateRequirements(appReq)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: expected to pass, got %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: expected a *ValidationError, got %T", tt.name, err)
+		}
+		if isLanguage := tt.name == "unsupported language" || tt.name == "unimplemented language"; errors.Is(err, ErrUnsupportedLanguage) != isLanguage {
+			t.Errorf("%s: expected errors.Is(err, ErrUnsupportedLanguage) to be %v", tt.name, isLanguage)
+		}
+	}
+}
+
+func TestSanitizeAppName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Task Manager", "task-manager"},
+		{"../../etc", "etc"},
+		{"..\\..\\windows", "windows"},
+		{"/etc/passwd", "etc-passwd"},
+		{"shop\x00\n\x1b[31m", "shop31m"},
+		{".hidden", "hidden"},
+		{"My App!", "my-app"},
+	}
+	for _, tt := range tests {
+		got, err := SanitizeAppName(tt.name)
+		if err != nil || got != tt.want {
+			t.Errorf("SanitizeAppName(%q) = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	for _, name := range []string{"..", "../..", "   ", "\x00\x01"} {
+		if _, err := SanitizeAppName(name); !errors.Is(err, ErrInvalidAppName) {
+			t.Errorf("SanitizeAppName(%q): expected ErrInvalidAppName, got %v", name, err)
+		}
+	}
+
+	ra := NewRequirementAnalyzer(nil)
+	appReq := &ApplicationRequirement{Name: "../..", Type: "api", Language: "go"}
+	if err := ra.ValidateRequirements(appReq); !errors.Is(err, ErrInvalidAppName) {
+		t.Errorf("expected ValidateRequirements to reject %q, got %v", appReq.Name, err)
+	}
+}
+
+func TestAnalyzeDetectsAuthStrategy(t *testing.T) {
+	ra := NewRequirementAnalyzer(nil)
+
+	tests := map[string]string{
+		"Create a product API":                          "none",
+		"Create a user management API":                  "jwt",
+		"Create a product API protected by an API key":  "apikey",
+		"Create a user API with JWT authentication":     "jwt",
+		"Create a user API with OAuth login via GitHub": "oauth2",
+	}
+
+	for description, expected := range tests {
+		appReq, err := ra.AnalyzeRequirements(description)
+		if err != nil {
+			t.Fatalf("AnalyzeRequirements(%q) failed: %v", description, err)
+		}
+		if appReq.AuthStrategy != expected {
+			t.Errorf("AnalyzeRequirements(%q) auth strategy = %s, want %s", description, appReq.AuthStrategy, expected)
+		}
+	}
+
+	appReq, _ := ra.AnalyzeRequirements("Create a product API")
+	if err := ra.ApplyOverrides(appReq, RequirementOverrides{AuthStrategy: "oauth2"}); err != nil || appReq.AuthStrategy != "oauth2" {
+		t.Errorf("expected oauth2 override to apply, got %s (%v)", appReq.AuthStrategy, err)
+	}
+	if err := ra.ApplyOverrides(appReq, RequirementOverrides{AuthStrategy: "basic"}); err == nil {
+		t.Error("expected unsupported auth strategy override to be rejected")
+	}
+}
+
+func TestAnalyzeDetectsGraphQL(t *testing.T) {
+	ra := NewRequirementAnalyzer(nil)
+
+	appReq, err := ra.AnalyzeRequirements("Create a GraphQL API for products")
+	if err != nil {
+		t.Fatalf("AnalyzeRequirements failed: %v", err)
+	}
+	if appReq.Type != "graphql" || appReq.Language != "go" || appReq.Framework != "gqlgen" {
+		t.Errorf("expected a go/gqlgen graphql app, got %s %s/%s", appReq.Type, appReq.Language, appReq.Framework)
+	}
+	if err := ra.ValidateRequirements(appReq); err != nil {
+		t.Errorf("expected the graphql app to be valid, got %v", err)
+	}
+
+	// Switching the type switches between gqlgen and the default framework
+	appReq, _ = ra.AnalyzeRequirements("Create a product API")
+	if err := ra.ApplyOverrides(appReq, RequirementOverrides{Type: "graphql"}); err != nil || appReq.Framework != "gqlgen" {
+		t.Errorf("expected the graphql override to select gqlgen, got %s (%v)", appReq.Framework, err)
+	}
+	if err := ra.ApplyOverrides(appReq, RequirementOverrides{Type: "api"}); err != nil || appReq.Framework != "gin" {
+		t.Errorf("expected the api override to select gin, got %s (%v)", appReq.Framework, err)
+	}
+}
+
+func TestAnalyzeDetectsEntities(t *testing.T) {
+	ra := NewRequirementAnalyzer(nil)
+
+	tests := []struct {
+		description string
+		entities    string
+		relations   map[string]string
+	}{
+		{
+			"Create an e-commerce API with orders and categories",
+			"Category,Order,OrderItem",
+			map[string]string{"Order": "many-to-one User", "OrderItem": "many-to-one Order,many-to-one Product"},
+		},
+		{
+			"Create a blog API where readers comment on articles",
+			"Post,Comment",
+			map[string]string{"Comment": "many-to-one Post,many-to-one User"},
+		},
+		{
+			"Create an API for products grouped into categories and tags",
+			"Product,Category,Tag",
+			map[string]string{"Category": "one-to-many Product,one-to-many Post", "Tag": "many-to-many Product,many-to-many Post"},
+		},
+		// Keywords only match at the start of a word
+		{"Create a staging API for products", "Product", nil},
+	}
+
+	for _, tt := range tests {
+		appReq, err := ra.AnalyzeRequirements(tt.description)
+		if err != nil {
+			t.Fatalf("AnalyzeRequirements(%q) failed: %v", tt.description, err)
+		}
+		var names []string
+		for _, entity := range appReq.Entities {
+			names = append(names, entity.Name)
+
+			var relations []string
+			for _, relation := range entity.Relations {
+				relations = append(relations, relation.Type+" "+relation.Target)
+			}
+			if want, ok := tt.relations[entity.Name]; ok && strings.Join(relations, ",") != want {
+				t.Errorf("AnalyzeRequirements(%q) %s relations = %v, want %s", tt.description, entity.Name, relations, want)
+			}
+		}
+		if got := strings.Join(names, ","); got != tt.entities {
+			t.Errorf("AnalyzeRequirements(%q) entities = %s, want %s", tt.description, got, tt.entities)
+		}
+		if err := ra.ValidateRequirements(appReq); err != nil {
+			t.Errorf("AnalyzeRequirements(%q) is invalid: %v", tt.description, err)
+		}
+	}
+}
+
+func TestAnalyzeDeduplicatesEntities(t *testing.T) {
+	// Every keyword of the User entity is mentioned
+	appReq, err := NewRequirementAnalyzer(nil).AnalyzeRequirements("Create a user account API with login")
+	if err != nil {
+		t.Fatalf("AnalyzeRequirements failed: %v", err)
+	}
+	if len(appReq.Entities) != 1 || appReq.Entities[0].Name != "User" {
+		t.Errorf("expected a single User entity, got %+v", appReq.Entities)
+	}
+
+	// Entities an LLM names twice are merged into the first
+	ra := NewRequirementAnalyzer(stubProvider{response: `{"name": "Shop", "type": "api", "language": "go", "entities": [
+		{"name": "User", "fields": [{"name": "email", "type": "email"}], "operations": ["create", "read"]},
+		{"name": "Product", "fields": [{"name": "name", "type": "string"}]},
+		{"name": "user", "fields": [{"name": "email", "type": "email"}, {"name": "age", "type": "int"}], "operations": ["delete"]}
+	]}`})
+	appReq, err = ra.AnalyzeRequirements("a shop")
+	if err != nil {
+		t.Fatalf("AnalyzeRequirements failed: %v", err)
+	}
+	if len(appReq.Entities) != 2 {
+		t.Fatalf("expected User and Product, got %+v", appReq.Entities)
+	}
+	user := appReq.Entities[0]
+	if user.Name != "User" || len(user.Fields) != 2 || user.Fields[1].Name != "age" || strings.Join(user.Operations, ",") != "create,read,delete" {
+		t.Errorf("expected the duplicates merged into User, got %+v", user)
+	}
+	if err := ra.ValidateRequirements(appReq); err != nil {
+		t.Errorf("expected the merged requirements to be valid, got %v", err)
+	}
+}
+
+func TestValidateRequirementsTableCollision(t *testing.T) {
+	appReq := &ApplicationRequirement{
+		Name:     "People",
+		Type:     "api",
+		Language: "go",
+		Entities: []Entity{
+			{Name: "Person", Fields: []EntityField{{Name: "name", Type: "string"}}},
+			{Name: "PERSON", Fields: []EntityField{{Name: "age", Type: "int"}}},
+		},
+	}
+	err := NewRequirementAnalyzer(nil).ValidateRequirements(appReq)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), "Person and PERSON both map to table persons") {
+		t.Errorf("expected a table collision error, got %v", err)
+	}
+}
+
+func TestLoadFromFileMissing(t *testing.T) {
+	_, err := LoadFromFile(filepath.Join(t.TempDir(), RequirementsFile))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist for a missing file, got %v", err)
+	}
+}
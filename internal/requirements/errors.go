@@ -0,0 +1,27 @@
+package requirements
+
+import "errors"
+
+// ErrUnsupportedLanguage is wrapped by validation errors for a language no
+// generator supports
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// ValidationError is returned for requirements or overrides that cannot be
+// generated, such as a missing name or an unsupported stack
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// LLMError is returned when requirement analysis fails in the LLM provider
+// without falling back to rule-based analysis, as when the request is aborted
+type LLMError struct {
+	Err error
+}
+
+func (e *LLMError) Error() string { return "LLM analysis failed: " + e.Err.Error() }
+
+func (e *LLMError) Unwrap() error { return e.Err }
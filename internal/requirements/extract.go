@@ -0,0 +1,88 @@
+package requirements
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errNoJSON is returned by extractRequirements when a response holds no JSON object
+var errNoJSON = errors.New("no JSON found in response")
+
+// extractRequirements finds the application requirements in an LLM response.
+// Models wrap the JSON in markdown fences, surround it with prose that may
+// contain braces of its own or quote example objects, so every balanced
+// {...} is tried in order of appearance and the first that decodes into
+// requirements naming the application and its language, type or entities is
+// returned.
+func extractRequirements(responseText string) (*ApplicationRequirement, error) {
+	text := stripCodeFences(responseText)
+
+	lastErr := errNoJSON
+	for start := strings.IndexByte(text, '{'); start != -1; {
+		if end := matchingBrace(text, start); end != -1 {
+			var appReq ApplicationRequirement
+			if err := json.Unmarshal([]byte(text[start:end+1]), &appReq); err != nil {
+				lastErr = fmt.Errorf("failed to unmarshal application requirements: %v", err)
+			} else if looksLikeRequirements(&appReq) {
+				return &appReq, nil
+			} else {
+				lastErr = errors.New("no application requirements found in response")
+			}
+		}
+
+		next := strings.IndexByte(text[start+1:], '{')
+		if next == -1 {
+			break
+		}
+		start += next + 1
+	}
+	return nil, lastErr
+}
+
+// stripCodeFences drops the ``` lines of markdown code blocks, keeping their
+// contents and the prose around them
+func stripCodeFences(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "```") {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// matchingBrace returns the index of the brace closing the one at start,
+// skipping braces inside JSON strings, or -1 if it is never closed
+func matchingBrace(text string, start int) int {
+	depth := 0
+	inString, escaped := false, false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// looksLikeRequirements tells application requirements apart from other
+// objects in a response, such as a single entity or an example
+func looksLikeRequirements(appReq *ApplicationRequirement) bool {
+	return appReq.Name != "" && (appReq.Language != "" || appReq.Type != "" || len(appReq.Entities) > 0)
+}
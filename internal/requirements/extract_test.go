@@ -0,0 +1,53 @@
+package requirements
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractRequirements(t *testing.T) {
+	const library = `{"name": "Library", "type": "api", "language": "go", "entities": [{"name": "Book", "fields": [{"name": "title", "type": "string"}]}]}`
+
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"bare", library, "Library"},
+		{"fenced", "```json\n" + library + "\n```", "Library"},
+		{
+			"prose around fences",
+			"Here is the analysis {as requested}:\n```json\n" + library + "\n```\nLet me know if the {entities} need changes.",
+			"Library",
+		},
+		{
+			"example before",
+			"Entities look like {\"name\": \"Example\", \"fields\": []}, so the app is:\n" + library,
+			"Library",
+		},
+		{
+			"two blocks",
+			"```json\n" + library + "\n```\nAn alternative:\n```json\n{\"name\": \"Archive\", \"language\": \"python\"}\n```",
+			"Library",
+		},
+		{"braces in strings", `{"name": "Braces }{", "language": "go", "description": "uses { and }"}`, "Braces }{"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appReq, err := extractRequirements(tt.response)
+			if err != nil {
+				t.Fatalf("extractRequirements failed: %v", err)
+			}
+			if appReq.Name != tt.want {
+				t.Errorf("extracted %q, want %q", appReq.Name, tt.want)
+			}
+		})
+	}
+
+	if _, err := extractRequirements("I cannot help with that."); !errors.Is(err, errNoJSON) {
+		t.Errorf("expected errNoJSON, got %v", err)
+	}
+	if _, err := extractRequirements(`Only an entity: {"name": "Book", "fields": []}`); err == nil {
+		t.Error("expected an entity alone not to be taken for requirements")
+	}
+}
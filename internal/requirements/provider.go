@@ -0,0 +1,355 @@
+package requirements
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// LLMProvider turns an analysis prompt into raw model text
+type LLMProvider interface {
+	AnalyzeRequirements(prompt string) (string, error)
+}
+
+// contextProvider is implemented by providers whose requests can be canceled
+type contextProvider interface {
+	AnalyzeRequirementsContext(ctx context.Context, prompt string) (string, error)
+}
+
+// providerChecker is implemented by providers that can verify their credentials
+type providerChecker interface {
+	Check(ctx context.Context) error
+}
+
+const (
+	defaultOpenAIModel    = "gpt-4o-mini"
+	defaultAnthropicModel = "claude-3-5-haiku-latest"
+	anthropicAPIVersion   = "2023-06-01"
+	llmRequestTimeout     = 30 * time.Second
+	llmMaxOutputTokens    = 2048
+)
+
+// NewProviderFromEnv selects the provider named by LLM_PROVIDER (gemini, openai
+// or anthropic; default gemini). It returns nil when the provider's API key is
+// unset, so the analyzer falls back to rule-based analysis.
+func NewProviderFromEnv() (LLMProvider, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("LLM_PROVIDER")))
+	switch name {
+	case "", "gemini":
+		if key := GetGeminiAPIKey(); key != "" {
+			return NewGeminiProvider(key), nil
+		}
+	case "openai":
+		if key := os.Getenv("OPENAI_API_KEY"); key != "" {
+			return NewOpenAIProvider(key, os.Getenv("OPENAI_MODEL")), nil
+		}
+	case "anthropic":
+		if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
+			return NewAnthropicProvider(key, os.Getenv("ANTHROPIC_MODEL")), nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported LLM provider %q", name)
+	}
+	return nil, nil
+}
+
+// GeminiProvider calls the Google Gemini generateContent API
+type GeminiProvider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	retry      retryPolicy
+}
+
+// NewGeminiProvider creates a Gemini provider for the given API key
+func NewGeminiProvider(apiKey string) *GeminiProvider {
+	return &GeminiProvider{
+		apiKey:     apiKey,
+		baseURL:    "https://generativelanguage.googleapis.com/v1beta",
+		httpClient: &http.Client{Timeout: llmRequestTimeout},
+		retry:      newRetryPolicy(),
+	}
+}
+
+// SetRetry sets how many attempts are made for rate-limited, failing or
+// unreachable requests and the backoff before the first retry, which doubles
+// on each further retry. The client timeout bounds every attempt.
+func (p *GeminiProvider) SetRetry(attempts int, base time.Duration) {
+	p.retry.attempts = attempts
+	p.retry.base = base
+}
+
+// AnalyzeRequirements sends the prompt to Gemini and returns the first candidate's text
+func (p *GeminiProvider) AnalyzeRequirements(prompt string) (string, error) {
+	return p.AnalyzeRequirementsContext(context.Background(), prompt)
+}
+
+// AnalyzeRequirementsContext is AnalyzeRequirements, abandoning the request
+// and any retries when ctx is done
+func (p *GeminiProvider) AnalyzeRequirementsContext(ctx context.Context, prompt string) (string, error) {
+	reqBody := map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{
+				"parts": []map[string]string{
+					{"text": prompt},
+				},
+			},
+		},
+		"generationConfig": map[string]interface{}{
+			"temperature":     0.1,
+			"maxOutputTokens": llmMaxOutputTokens,
+		},
+	}
+
+	url := fmt.Sprintf("%s/models/gemini-pro:generateContent?key=%s", p.baseURL, p.apiKey)
+	body, err := p.retry.do(ctx, func() ([]byte, error) {
+		return postJSON(ctx, p.httpClient, url, reqBody, nil)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var geminiResp struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+		} `json:"candidates"`
+	}
+	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
+		return "", fmt.Errorf("no content in response")
+	}
+	return geminiResp.Candidates[0].Content.Parts[0].Text, nil
+}
+
+// Check lists the available models to verify the API key
+func (p *GeminiProvider) Check(ctx context.Context) error {
+	url := fmt.Sprintf("%s/models?key=%s", p.baseURL, p.apiKey)
+	return checkEndpoint(ctx, p.httpClient, url, nil, "Gemini")
+}
+
+// OpenAIProvider calls the OpenAI chat completions API
+type OpenAIProvider struct {
+	apiKey     string
+	model      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewOpenAIProvider creates an OpenAI provider; an empty model selects the default
+func NewOpenAIProvider(apiKey, model string) *OpenAIProvider {
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+	return &OpenAIProvider{
+		apiKey:     apiKey,
+		model:      model,
+		baseURL:    "https://api.openai.com/v1",
+		httpClient: &http.Client{Timeout: llmRequestTimeout},
+	}
+}
+
+// AnalyzeRequirements sends the prompt as a chat completion and returns the reply text
+func (p *OpenAIProvider) AnalyzeRequirements(prompt string) (string, error) {
+	return p.AnalyzeRequirementsContext(context.Background(), prompt)
+}
+
+// AnalyzeRequirementsContext is AnalyzeRequirements, abandoning the request
+// when ctx is done
+func (p *OpenAIProvider) AnalyzeRequirementsContext(ctx context.Context, prompt string) (string, error) {
+	reqBody := map[string]interface{}{
+		"model": p.model,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+		"temperature": 0.1,
+		"max_tokens":  llmMaxOutputTokens,
+	}
+
+	body, err := postJSON(ctx, p.httpClient, p.baseURL+"/chat/completions", reqBody, p.headers())
+	if err != nil {
+		return "", err
+	}
+
+	var openAIResp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &openAIResp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+	if len(openAIResp.Choices) == 0 {
+		return "", fmt.Errorf("no content in response")
+	}
+	return openAIResp.Choices[0].Message.Content, nil
+}
+
+// Check lists the available models to verify the API key
+func (p *OpenAIProvider) Check(ctx context.Context) error {
+	return checkEndpoint(ctx, p.httpClient, p.baseURL+"/models", p.headers(), "OpenAI")
+}
+
+func (p *OpenAIProvider) headers() map[string]string {
+	return map[string]string{"Authorization": "Bearer " + p.apiKey}
+}
+
+// AnthropicProvider calls the Anthropic messages API
+type AnthropicProvider struct {
+	apiKey     string
+	model      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewAnthropicProvider creates an Anthropic provider; an empty model selects the default
+func NewAnthropicProvider(apiKey, model string) *AnthropicProvider {
+	if model == "" {
+		model = defaultAnthropicModel
+	}
+	return &AnthropicProvider{
+		apiKey:     apiKey,
+		model:      model,
+		baseURL:    "https://api.anthropic.com/v1",
+		httpClient: &http.Client{Timeout: llmRequestTimeout},
+	}
+}
+
+// AnalyzeRequirements sends the prompt as a message and returns the concatenated text blocks
+func (p *AnthropicProvider) AnalyzeRequirements(prompt string) (string, error) {
+	return p.AnalyzeRequirementsContext(context.Background(), prompt)
+}
+
+// AnalyzeRequirementsContext is AnalyzeRequirements, abandoning the request
+// when ctx is done
+func (p *AnthropicProvider) AnalyzeRequirementsContext(ctx context.Context, prompt string) (string, error) {
+	reqBody := map[string]interface{}{
+		"model": p.model,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+		"temperature": 0.1,
+		"max_tokens":  llmMaxOutputTokens,
+	}
+
+	body, err := postJSON(ctx, p.httpClient, p.baseURL+"/messages", reqBody, p.headers())
+	if err != nil {
+		return "", err
+	}
+
+	var anthropicResp struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(body, &anthropicResp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	var text strings.Builder
+	for _, block := range anthropicResp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no content in response")
+	}
+	return text.String(), nil
+}
+
+// Check lists the available models to verify the API key
+func (p *AnthropicProvider) Check(ctx context.Context) error {
+	return checkEndpoint(ctx, p.httpClient, p.baseURL+"/models", p.headers(), "Anthropic")
+}
+
+func (p *AnthropicProvider) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": anthropicAPIVersion,
+	}
+}
+
+// postJSON posts payload as JSON and returns the response body of a 200 reply
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}, headers map[string]string) ([]byte, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", stripURL(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &apiStatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			Body:       string(body),
+		}
+	}
+	return body, nil
+}
+
+// checkEndpoint issues a GET and expects a 200 reply
+func checkEndpoint(ctx context.Context, client *http.Client, url string, headers map[string]string, name string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s API: %v", name, stripURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s API returned status %d", name, resp.StatusCode)
+	}
+	return nil
+}
+
+// stripURL drops the request URL from transport errors so API keys in query
+// strings do not leak into logs or status output
+func stripURL(err error) error {
+	var urlErr *neturl.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
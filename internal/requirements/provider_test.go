@@ -0,0 +1,304 @@
+package requirements
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/logging"
+)
+
+// stubProvider returns a canned response for analyzer tests
+type stubProvider struct {
+	response string
+	err      error
+}
+
+func (p stubProvider) AnalyzeRequirements(prompt string) (string, error) {
+	return p.response, p.err
+}
+
+func TestAnalyzeRequirementsWithProvider(t *testing.T) {
+	ra := NewRequirementAnalyzer(stubProvider{
+		response: "Here you go:\n```json\n{\"name\": \"Library\", \"type\": \"api\", \"language\": \"go\"}\n```",
+	})
+	appReq, err := ra.AnalyzeRequirements("a library api")
+	if err != nil {
+		t.Fatalf("AnalyzeRequirements failed: %v", err)
+	}
+	if appReq.Name != "Library" {
+		t.Errorf("expected provider result, got %+v", appReq)
+	}
+
+	// A failing provider falls back to rule-based analysis and logs why
+	var logs strings.Builder
+	ra = NewRequirementAnalyzer(stubProvider{err: errors.New("quota exceeded")})
+	ra.SetLogger(logging.New(&logs, "info"))
+	appReq, err = ra.AnalyzeRequirements("Create a REST API for managing users")
+	if err != nil {
+		t.Fatalf("AnalyzeRequirements failed: %v", err)
+	}
+	if appReq.Type != "api" {
+		t.Errorf("expected rule-based fallback, got %+v", appReq)
+	}
+	if !strings.Contains(logs.String(), "level=WARN") || !strings.Contains(logs.String(), "quota exceeded") {
+		t.Errorf("expected the fallback to be logged with its reason, got %q", logs.String())
+	}
+}
+
+// stubTransport replies with the queued responses in order, one per request;
+// a zero status stands for a network error
+type stubTransport struct {
+	statuses   []int
+	retryAfter string
+	requests   int
+}
+
+func (st *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := st.statuses[st.requests]
+	st.requests++
+	if status == 0 {
+		return nil, errors.New("connection reset")
+	}
+
+	body := `{"error": "unavailable"}`
+	if status == http.StatusOK {
+		body = `{"candidates": [{"content": {"parts": [{"text": "ok"}]}}]}`
+	}
+	header := http.Header{}
+	if st.retryAfter != "" {
+		header.Set("Retry-After", st.retryAfter)
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+}
+
+func TestGeminiProviderRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		retryAfter string
+		requests   int
+		wantErr    bool
+		firstDelay time.Duration // zero skips the check
+	}{
+		{"server errors then success", []int{503, 500, 200}, "", 3, false, 0},
+		{"network error then success", []int{0, 200}, "", 2, false, 0},
+		{"rate limited with Retry-After", []int{429, 200}, "2", 2, false, 2 * time.Second},
+		{"client error is not retried", []int{400}, "", 1, true, 0},
+		{"gives up after three attempts", []int{502, 502, 502}, "", 3, true, 0},
+	}
+
+	for _, tt := range tests {
+		transport := &stubTransport{statuses: tt.statuses, retryAfter: tt.retryAfter}
+		provider := NewGeminiProvider("test-key")
+		provider.httpClient = &http.Client{Transport: transport}
+		provider.SetRetry(3, 10*time.Millisecond)
+		var delays []time.Duration
+		provider.retry.sleep = func(ctx context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		}
+
+		text, err := provider.AnalyzeRequirements("prompt")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.wantErr && text != "ok" {
+			t.Errorf("%s: unexpected text %q", tt.name, text)
+		}
+		if transport.requests != tt.requests {
+			t.Errorf("%s: expected %d requests, got %d", tt.name, tt.requests, transport.requests)
+		}
+		if len(delays) != tt.requests-1 {
+			t.Errorf("%s: expected %d backoffs, got %v", tt.name, tt.requests-1, delays)
+		}
+		if tt.firstDelay > 0 && len(delays) > 0 && delays[0] != tt.firstDelay {
+			t.Errorf("%s: expected first backoff %v, got %v", tt.name, tt.firstDelay, delays[0])
+		}
+		for i, d := range delays {
+			if tt.firstDelay == 0 && (d < 10*time.Millisecond<<i || d > 15*time.Millisecond<<i) {
+				t.Errorf("%s: backoff %d out of range: %v", tt.name, i, d)
+			}
+		}
+	}
+}
+
+func TestAnalyzeRequirementsContextCanceled(t *testing.T) {
+	// The API holds every request until the test ends
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	provider := NewGeminiProvider("test-key")
+	provider.baseURL = server.URL
+	analyzer := NewRequirementAnalyzer(provider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	appReq, err := analyzer.AnalyzeRequirementsContext(ctx, "Create a todo API with tasks")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v (%+v)", err, appReq)
+	}
+	if appReq != nil {
+		t.Error("expected no rule-based fallback after cancellation")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the analysis to abort promptly, took %v", elapsed)
+	}
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		var body struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if body.Model != defaultOpenAIModel || len(body.Messages) != 1 || body.Messages[0].Content != "prompt" {
+			t.Errorf("unexpected request body %+v", body)
+		}
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "{\"name\": \"x\"}"}}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider("test-key", "")
+	provider.baseURL = server.URL
+	text, err := provider.AnalyzeRequirements("prompt")
+	if err != nil {
+		t.Fatalf("AnalyzeRequirements failed: %v", err)
+	}
+	if text != `{"name": "x"}` {
+		t.Errorf("unexpected response text %q", text)
+	}
+}
+
+func TestAnthropicProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") != anthropicAPIVersion {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		var body struct {
+			Model     string `json:"model"`
+			MaxTokens int    `json:"max_tokens"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if body.Model != "custom-model" || body.MaxTokens == 0 {
+			t.Errorf("unexpected request body %+v", body)
+		}
+		w.Write([]byte(`{"content": [{"type": "text", "text": "{\"name\":"}, {"type": "text", "text": " \"x\"}"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewAnthropicProvider("test-key", "custom-model")
+	provider.baseURL = server.URL
+	text, err := provider.AnalyzeRequirements("prompt")
+	if err != nil {
+		t.Fatalf("AnalyzeRequirements failed: %v", err)
+	}
+	if text != `{"name": "x"}` {
+		t.Errorf("unexpected response text %q", text)
+	}
+}
+
+func TestNewProviderFromEnv(t *testing.T) {
+	tests := []struct {
+		provider string
+		keyVar   string
+		expected string
+	}{
+		{"", "GEMINI_API_KEY", "*requirements.GeminiProvider"},
+		{"openai", "OPENAI_API_KEY", "*requirements.OpenAIProvider"},
+		{"Anthropic", "ANTHROPIC_API_KEY", "*requirements.AnthropicProvider"},
+	}
+
+	for _, tt := range tests {
+		for _, name := range []string{"GEMINI_API_KEY", "OPENAI_API_KEY", "ANTHROPIC_API_KEY"} {
+			t.Setenv(name, "")
+		}
+		t.Setenv("LLM_PROVIDER", tt.provider)
+
+		provider, err := NewProviderFromEnv()
+		if err != nil || provider != nil {
+			t.Errorf("%q without key: expected no provider, got %T, %v", tt.provider, provider, err)
+		}
+
+		t.Setenv(tt.keyVar, "key")
+		provider, err = NewProviderFromEnv()
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", tt.provider, err)
+		}
+		if got := fmt.Sprintf("%T", provider); got != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.provider, tt.expected, got)
+		}
+	}
+
+	t.Setenv("LLM_PROVIDER", "unknown")
+	if _, err := NewProviderFromEnv(); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
+
+// recordingProvider keeps the prompts it is sent
+type recordingProvider struct {
+	prompts *[]string
+}
+
+func (p recordingProvider) AnalyzeRequirements(prompt string) (string, error) {
+	*p.prompts = append(*p.prompts, prompt)
+	return `{"name": "Library", "type": "api", "language": "go"}`, nil
+}
+
+type staticHints []string
+
+func (h staticHints) GetPromptHints() []string { return h }
+
+func TestAnalyzeRequirementsAddsPromptHints(t *testing.T) {
+	var prompts []string
+	ra := NewRequirementAnalyzer(recordingProvider{prompts: &prompts})
+	if _, err := ra.AnalyzeRequirements("a library api"); err != nil {
+		t.Fatalf("AnalyzeRequirements failed: %v", err)
+	}
+
+	ra.SetPromptHints(staticHints{"Include a go.sum", "Only import packages the generated code uses"})
+	if _, err := ra.AnalyzeRequirements("a library api"); err != nil {
+		t.Fatalf("AnalyzeRequirements failed: %v", err)
+	}
+
+	if strings.Contains(prompts[0], "- Include a go.sum") {
+		t.Error("expected no hints without a hint source")
+	}
+	if !strings.Contains(prompts[1], "- Include a go.sum\n- Only import packages the generated code uses\n") {
+		t.Errorf("expected hints in the prompt, got %s", prompts[1])
+	}
+}
@@ -0,0 +1,130 @@
+package requirements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryAttempts = 3
+	defaultRetryBase     = 500 * time.Millisecond
+	maxRetryDelay        = 30 * time.Second
+)
+
+// apiStatusError is a non-200 reply from an LLM API
+type apiStatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // from the Retry-After header, zero when absent
+	Body       string
+}
+
+func (e *apiStatusError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// retryPolicy retries failed API calls with exponential backoff and jitter
+type retryPolicy struct {
+	attempts int
+	base     time.Duration
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+// newRetryPolicy returns the default policy of 3 attempts starting at 500ms
+func newRetryPolicy() retryPolicy {
+	return retryPolicy{attempts: defaultRetryAttempts, base: defaultRetryBase, sleep: sleepContext}
+}
+
+// do calls fn until it succeeds, fails with an error that is not worth
+// retrying, runs out of attempts or ctx is done
+func (p retryPolicy) do(ctx context.Context, fn func() ([]byte, error)) ([]byte, error) {
+	attempts := p.attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := p.sleep(ctx, p.delay(attempt, err)); err != nil {
+				return nil, err
+			}
+		}
+
+		var body []byte
+		body, err = fn()
+		if err == nil || !retryable(err) {
+			return body, err
+		}
+	}
+	return nil, fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+}
+
+// delay returns how long to wait before the given retry: the server's
+// Retry-After when set, otherwise base*2^(retry-1) with up to 50% jitter
+func (p retryPolicy) delay(retry int, err error) time.Duration {
+	var statusErr *apiStatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return minDuration(statusErr.RetryAfter, maxRetryDelay)
+	}
+
+	backoff := p.base << (retry - 1)
+	if backoff > 0 {
+		backoff += time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	}
+	return minDuration(backoff, maxRetryDelay)
+}
+
+// sleepContext waits for d, returning early with the context's error when
+// ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryable reports whether a request may succeed when repeated: network
+// errors, rate limiting and server errors are, other API replies and
+// canceled requests are not
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *apiStatusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+	return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,184 @@
+package requirements
+
+import "strings"
+
+// crudOperations are the operations of every entity found by rule-based analysis
+var crudOperations = []string{"create", "read", "update", "delete"}
+
+// ruleEntity is a group of entities rule-based analysis adds, along with
+// features, when the description mentions one of its keywords
+type ruleEntity struct {
+	keywords []string // matched at the start of a word, so "tag" matches "tags" but not "stage"
+	features []string
+	entities []Entity
+}
+
+// ruleEntities are the entities recognized by rule-based analysis, in the
+// order they are added. Relations to entities the description does not
+// mention are ignored by the code generator.
+var ruleEntities = []ruleEntity{
+	{
+		keywords: []string{"user", "account", "login"},
+		features: []string{"user_management", "authentication"},
+		entities: []Entity{{
+			Name: "User",
+			Fields: []EntityField{
+				{Name: "id", Type: "int", Required: true},
+				{Name: "username", Type: "string", Required: true, Validation: "min=3,max=50"},
+				{Name: "email", Type: "email", Required: true},
+				{Name: "password", Type: "string", Required: true, Validation: "min=8"},
+				{Name: "created_at", Type: "date", Required: true, AutoManaged: true},
+			},
+		}},
+	},
+	{
+		keywords: []string{"product", "item", "catalog"},
+		features: []string{"product_management"},
+		entities: []Entity{{
+			Name: "Product",
+			Fields: []EntityField{
+				{Name: "id", Type: "int", Required: true},
+				{Name: "name", Type: "string", Required: true, Validation: "min=1,max=200"},
+				{Name: "description", Type: "string", Required: false},
+				{Name: "price", Type: "float", Required: true, Validation: "min=0"},
+				{Name: "created_at", Type: "date", Required: true, AutoManaged: true},
+			},
+		}},
+	},
+	{
+		keywords: []string{"blog", "post", "article"},
+		features: []string{"content_management", "blog"},
+		entities: []Entity{{
+			Name: "Post",
+			Fields: []EntityField{
+				{Name: "id", Type: "int", Required: true},
+				{Name: "title", Type: "string", Required: true, Validation: "min=1,max=200"},
+				{Name: "content", Type: "string", Required: true},
+				{Name: "author_id", Type: "int", Required: true},
+				{Name: "published", Type: "bool", Required: true},
+				{Name: "created_at", Type: "date", Required: true, AutoManaged: true},
+			},
+			Relations: []EntityRelation{
+				{Type: "many-to-one", Target: "User"},
+			},
+		}},
+	},
+	{
+		keywords: []string{"category", "categories"},
+		features: []string{"categorization"},
+		entities: []Entity{{
+			Name: "Category",
+			Fields: []EntityField{
+				{Name: "id", Type: "int", Required: true},
+				{Name: "name", Type: "string", Required: true, Validation: "min=1,max=100"},
+				{Name: "description", Type: "string", Required: false},
+			},
+			Relations: []EntityRelation{
+				{Type: "one-to-many", Target: "Product"},
+				{Type: "one-to-many", Target: "Post"},
+			},
+		}},
+	},
+	{
+		keywords: []string{"tag"},
+		features: []string{"tagging"},
+		entities: []Entity{{
+			Name: "Tag",
+			Fields: []EntityField{
+				{Name: "id", Type: "int", Required: true},
+				{Name: "name", Type: "string", Required: true, Validation: "min=1,max=50"},
+			},
+			Relations: []EntityRelation{
+				{Type: "many-to-many", Target: "Product"},
+				{Type: "many-to-many", Target: "Post"},
+			},
+		}},
+	},
+	{
+		keywords: []string{"order", "checkout", "purchase"},
+		features: []string{"order_management"},
+		entities: []Entity{
+			{
+				Name: "Order",
+				Fields: []EntityField{
+					{Name: "id", Type: "int", Required: true},
+					{Name: "status", Type: "string", Required: true, Default: "pending"},
+					{Name: "total", Type: "float", Required: true, Validation: "min=0"},
+					{Name: "created_at", Type: "date", Required: true, AutoManaged: true},
+				},
+				Relations: []EntityRelation{
+					{Type: "many-to-one", Target: "User"},
+				},
+			},
+			// The line items of an order, one per product ordered
+			{
+				Name: "OrderItem",
+				Fields: []EntityField{
+					{Name: "id", Type: "int", Required: true},
+					{Name: "quantity", Type: "int", Required: true, Validation: "min=1"},
+					{Name: "unit_price", Type: "float", Required: true, Validation: "min=0"},
+				},
+				Relations: []EntityRelation{
+					{Type: "many-to-one", Target: "Order"},
+					{Type: "many-to-one", Target: "Product"},
+				},
+			},
+		},
+	},
+	{
+		keywords: []string{"comment"},
+		features: []string{"comments"},
+		entities: []Entity{{
+			Name: "Comment",
+			Fields: []EntityField{
+				{Name: "id", Type: "int", Required: true},
+				{Name: "content", Type: "string", Required: true, Validation: "min=1,max=2000"},
+				{Name: "created_at", Type: "date", Required: true, AutoManaged: true},
+			},
+			Relations: []EntityRelation{
+				{Type: "many-to-one", Target: "Post"},
+				{Type: "many-to-one", Target: "User"},
+			},
+		}},
+	},
+}
+
+// detectEntities adds the entities and features of every ruleEntity whose
+// keywords desc, in lower case, mentions
+func detectEntities(desc string, appReq *ApplicationRequirement) {
+	for _, rule := range ruleEntities {
+		if !mentionsAny(desc, rule.keywords) {
+			continue
+		}
+		for _, entity := range rule.entities {
+			// Each request gets its own copy of the fields and relations
+			entity.Fields = append([]EntityField(nil), entity.Fields...)
+			entity.Relations = append([]EntityRelation(nil), entity.Relations...)
+			entity.Operations = append([]string(nil), crudOperations...)
+			appReq.Entities = append(appReq.Entities, entity)
+		}
+		appReq.Features = append(appReq.Features, rule.features...)
+	}
+}
+
+// mentionsAny reports whether one of keywords starts a word of desc
+func mentionsAny(desc string, keywords []string) bool {
+	for _, keyword := range keywords {
+		for i := strings.Index(desc, keyword); i != -1; {
+			if i == 0 || !isWordChar(desc[i-1]) {
+				return true
+			}
+			next := strings.Index(desc[i+1:], keyword)
+			if next == -1 {
+				break
+			}
+			i += next + 1
+		}
+	}
+	return false
+}
+
+// isWordChar reports whether c is an ASCII letter or digit
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
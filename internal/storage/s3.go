@@ -0,0 +1,277 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrObjectNotFound is returned by an S3Client for a key that does not exist
+var ErrObjectNotFound = errors.New("object not found")
+
+// S3Object is an object returned by a bucket listing
+type S3Object struct {
+	Key          string
+	LastModified time.Time
+}
+
+// S3Client is the part of the S3 API that S3Storage uses
+type S3Client interface {
+	PutObject(ctx context.Context, key string, body []byte) error
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	DeleteObject(ctx context.Context, key string) error
+	// ListObjectsV2 returns one page of the objects under prefix, starting at
+	// continuationToken, and the token of the next page or "" after the last
+	ListObjectsV2(ctx context.Context, prefix, continuationToken string) ([]S3Object, string, error)
+}
+
+// S3Storage implements Storage on an S3 bucket, storing the same JSON
+// documents as FileStorage as objects under a key prefix, so several agents
+// can share their projects
+type S3Storage struct {
+	client S3Client
+	prefix string
+}
+
+// NewS3Storage creates a storage keeping its objects under prefix
+func NewS3Storage(client S3Client, prefix string) *S3Storage {
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &S3Storage{client: client, prefix: prefix}
+}
+
+// key returns the object key of a path relative to the prefix
+func (s *S3Storage) key(elem ...string) string {
+	return s.prefix + path.Join(elem...)
+}
+
+// putJSON stores data as an indented JSON object
+func (s *S3Storage) putJSON(key string, data interface{}) error {
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %v", key, err)
+	}
+	if err := s.client.PutObject(context.Background(), key, encoded); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return nil
+}
+
+// getJSON reads a JSON object into result, returning ErrObjectNotFound when missing
+func (s *S3Storage) getJSON(key string, result interface{}) error {
+	data, err := s.client.GetObject(context.Background(), key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %v", key, err)
+	}
+	return nil
+}
+
+// list returns every object under a prefix relative to the storage prefix,
+// following continuation tokens across pages
+func (s *S3Storage) list(prefix string) ([]S3Object, error) {
+	var objects []S3Object
+	token := ""
+	for {
+		page, next, err := s.client.ListObjectsV2(context.Background(), s.prefix+prefix, token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", s.prefix+prefix, err)
+		}
+		objects = append(objects, page...)
+		if next == "" {
+			return objects, nil
+		}
+		token = next
+	}
+}
+
+// Store saves generic data to storage
+func (s *S3Storage) Store(key string, data interface{}) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	return s.putJSON(s.key("generic_data", key+".json"), data)
+}
+
+// Retrieve retrieves generic data from storage
+func (s *S3Storage) Retrieve(key string, result interface{}) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	err := s.getJSON(s.key("generic_data", key+".json"), result)
+	if errors.Is(err, ErrObjectNotFound) {
+		return fmt.Errorf("data not found for key: %s", key)
+	}
+	return err
+}
+
+// Delete deletes generic data from storage
+func (s *S3Storage) Delete(key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	objectKey := s.key("generic_data", key+".json")
+	if _, err := s.client.GetObject(context.Background(), objectKey); err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return fmt.Errorf("data not found for key: %s", key)
+		}
+		return err
+	}
+	return s.client.DeleteObject(context.Background(), objectKey)
+}
+
+// SaveProject saves project data to storage
+func (s *S3Storage) SaveProject(project *ProjectData) error {
+	return s.putJSON(s.key("projects", project.ID+".json"), project)
+}
+
+// GetProject retrieves project data from storage
+func (s *S3Storage) GetProject(id string) (*ProjectData, error) {
+	var project ProjectData
+	if err := s.getJSON(s.key("projects", id+".json"), &project); err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, fmt.Errorf("project not found: %s", id)
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+// ListProjects lists all projects in storage
+func (s *S3Storage) ListProjects() ([]*ProjectData, error) {
+	objects, err := s.list("projects/")
+	if err != nil {
+		return nil, err
+	}
+
+	projects := []*ProjectData{}
+	for _, object := range objects {
+		var project ProjectData
+		if !strings.HasSuffix(object.Key, ".json") || s.getJSON(object.Key, &project) != nil {
+			continue // Skip corrupted objects
+		}
+		projects = append(projects, &project)
+	}
+	return projects, nil
+}
+
+// UpdateProject updates existing project data
+func (s *S3Storage) UpdateProject(project *ProjectData) error {
+	return s.SaveProject(project)
+}
+
+// DeleteProject deletes a project with its analyses and suggestion statuses
+func (s *S3Storage) DeleteProject(id string) error {
+	if _, err := s.GetProject(id); err != nil {
+		return err
+	}
+
+	keys := []string{s.key("projects", id+".json"), s.key("suggestions", id+".json")}
+	analyses, err := s.list("analysis/" + id + "/")
+	if err != nil {
+		return err
+	}
+	for _, object := range analyses {
+		keys = append(keys, object.Key)
+	}
+
+	for _, key := range keys {
+		if err := s.client.DeleteObject(context.Background(), key); err != nil && !errors.Is(err, ErrObjectNotFound) {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// SaveAnalysis saves analysis data to storage
+func (s *S3Storage) SaveAnalysis(analysis *AnalysisData) error {
+	return s.putJSON(s.key("analysis", analysis.ProjectID, fmt.Sprintf("%d.json", analysis.Timestamp.Unix())), analysis)
+}
+
+// GetAnalysis retrieves analysis data for a project
+func (s *S3Storage) GetAnalysis(projectID string) ([]*AnalysisData, error) {
+	objects, err := s.list("analysis/" + projectID + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	analyses := []*AnalysisData{}
+	for _, object := range objects {
+		var analysis AnalysisData
+		if !strings.HasSuffix(object.Key, ".json") || s.getJSON(object.Key, &analysis) != nil {
+			continue // Skip corrupted objects
+		}
+		analyses = append(analyses, &analysis)
+	}
+	return analyses, nil
+}
+
+// GetSuggestionStatuses returns the persisted suggestion statuses for a project, keyed by suggestion ID
+func (s *S3Storage) GetSuggestionStatuses(projectID string) (map[string]string, error) {
+	statuses := make(map[string]string)
+	err := s.getJSON(s.key("suggestions", projectID+".json"), &statuses)
+	if err != nil && !errors.Is(err, ErrObjectNotFound) {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// UpdateSuggestionStatus sets the status of a suggestion from one of the project's analyses
+func (s *S3Storage) UpdateSuggestionStatus(projectID, suggestionID, status string) error {
+	if !IsValidSuggestionStatus(status) {
+		return fmt.Errorf("invalid suggestion status: %s", status)
+	}
+
+	analyses, err := s.GetAnalysis(projectID)
+	if err != nil {
+		return err
+	}
+	if !hasSuggestion(analyses, suggestionID) {
+		return fmt.Errorf("suggestion not found: %s", suggestionID)
+	}
+
+	statuses, err := s.GetSuggestionStatuses(projectID)
+	if err != nil {
+		return err
+	}
+	statuses[suggestionID] = status
+	return s.putJSON(s.key("suggestions", projectID+".json"), statuses)
+}
+
+// GetProjectStats calculates and returns project statistics. A non-empty language
+// restricts the popularity maps and averages to projects in that language.
+func (s *S3Storage) GetProjectStats(language string) (*ProjectStats, error) {
+	projects, err := s.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	return projectStats(projects, language), nil
+}
+
+// Cleanup removes projects and analyses last modified before olderThan ago
+func (s *S3Storage) Cleanup(olderThan time.Duration) error {
+	cutoffTime := time.Now().Add(-olderThan)
+
+	for _, prefix := range []string{"projects/", "analysis/"} {
+		objects, err := s.list(prefix)
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			if object.LastModified.Before(cutoffTime) {
+				if err := s.client.DeleteObject(context.Background(), object.Key); err != nil && !errors.Is(err, ErrObjectNotFound) {
+					return fmt.Errorf("failed to delete %s: %w", object.Key, err)
+				}
+			}
+		}
+	}
+	return nil
+}
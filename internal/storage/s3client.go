@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config locates a bucket and the credentials to access it
+type S3Config struct {
+	Bucket          string
+	Region          string
+	Endpoint        string // S3-compatible endpoint such as MinIO; empty for AWS
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // for temporary credentials
+}
+
+// HTTPS3Client is an S3Client speaking the S3 REST API with Signature Version 4
+type HTTPS3Client struct {
+	config     S3Config
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewHTTPS3Client creates a client for the bucket in config
+func NewHTTPS3Client(config S3Config) (*HTTPS3Client, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if config.Region == "" {
+		return nil, fmt.Errorf("S3 region is required")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 credentials are required")
+	}
+	return &HTTPS3Client{
+		config:     config,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		now:        time.Now,
+	}, nil
+}
+
+// objectURL returns the URL of a key, path-style on custom endpoints and
+// virtual-hosted style on AWS
+func (c *HTTPS3Client) objectURL(key string) string {
+	escaped := escapeS3Path(key)
+	if c.config.Endpoint != "" {
+		return strings.TrimSuffix(c.config.Endpoint, "/") + "/" + c.config.Bucket + escaped
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com%s", c.config.Bucket, c.config.Region, escaped)
+}
+
+// do signs and sends a request, returning the body of a 2xx response
+func (c *HTTPS3Client) do(ctx context.Context, method, rawURL string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	signS3Request(req, body, c.config, c.now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read S3 response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("S3 %s failed with status %d: %s", method, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// PutObject stores body under key
+func (c *HTTPS3Client) PutObject(ctx context.Context, key string, body []byte) error {
+	_, err := c.do(ctx, http.MethodPut, c.objectURL(key), body)
+	return err
+}
+
+// GetObject returns the contents of key
+func (c *HTTPS3Client) GetObject(ctx context.Context, key string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, c.objectURL(key), nil)
+}
+
+// DeleteObject deletes key; S3 reports success for keys that do not exist
+func (c *HTTPS3Client) DeleteObject(ctx context.Context, key string) error {
+	_, err := c.do(ctx, http.MethodDelete, c.objectURL(key), nil)
+	return err
+}
+
+// listBucketResult is the ListObjectsV2 response
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// ListObjectsV2 returns one page of the objects under prefix
+func (c *HTTPS3Client) ListObjectsV2(ctx context.Context, prefix, continuationToken string) ([]S3Object, string, error) {
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	if continuationToken != "" {
+		query.Set("continuation-token", continuationToken)
+	}
+	data, err := c.do(ctx, http.MethodGet, c.objectURL("")+"?"+canonicalQuery(query), nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var result listBucketResult
+	if err := xml.Unmarshal(data, &result); err != nil {
+		return nil, "", fmt.Errorf("failed to parse S3 listing: %w", err)
+	}
+	objects := make([]S3Object, 0, len(result.Contents))
+	for _, content := range result.Contents {
+		objects = append(objects, S3Object{Key: content.Key, LastModified: content.LastModified})
+	}
+	if !result.IsTruncated {
+		return objects, "", nil
+	}
+	return objects, result.NextContinuationToken, nil
+}
+
+// signS3Request adds AWS Signature Version 4 headers to a request, signing
+// the host and every header already set on it
+func signS3Request(req *http.Request, body []byte, config S3Config, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", config.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escapeS3Path(strings.TrimPrefix(req.URL.Path, "/")),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+config.SecretAccessKey), date)
+	key = hmacSHA256(key, config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// escapeS3Path URI-encodes each segment of a key as SigV4 requires, returning
+// it with a leading slash
+func escapeS3Path(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.QueryEscape(segment), "+", "%20")
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// canonicalQuery encodes a query with sorted keys and %20 for spaces
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
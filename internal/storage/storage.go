@@ -1,10 +1,13 @@
 package storage
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -54,6 +57,25 @@ type CodeQualityMetrics struct {
 	DuplicationRatio  float64 `json:"duplication_ratio"`
 	TechnicalDebt     string  `json:"technical_debt"`
 	Maintainability   string  `json:"maintainability"`
+	// ComplexHotspots are the most complex functions, most complex first
+	ComplexHotspots []FunctionComplexity `json:"complex_hotspots,omitempty"`
+	// Clones are the duplicated blocks found when computing DuplicationRatio
+	Clones []CodeClone `json:"clones,omitempty"`
+}
+
+// CodeClone is a block of code duplicating one at an earlier location. Files
+// are relative to the app.
+type CodeClone struct {
+	File         string `json:"file"`
+	Line         int    `json:"line"`
+	OriginalFile string `json:"original_file"`
+	OriginalLine int    `json:"original_line"`
+}
+
+// FunctionComplexity is the cyclomatic complexity of one function
+type FunctionComplexity struct {
+	Function   string `json:"function"` // file:function, with the file relative to the app
+	Complexity int    `json:"complexity"`
 }
 
 // PerformanceMetrics represents performance metrics
@@ -75,6 +97,8 @@ type SecurityMetrics struct {
 
 // ImprovementSuggestion represents a suggestion for improvement
 type ImprovementSuggestion struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"` // open, accepted, dismissed, done
 	Type        string `json:"type"` // performance, security, quality, functionality
 	Priority    string `json:"priority"` // high, medium, low
 	Description string `json:"description"`
@@ -83,6 +107,26 @@ type ImprovementSuggestion struct {
 	Code        string `json:"code,omitempty"`
 }
 
+// SuggestionID derives a stable ID from the suggestion's type and description
+// so the same finding keeps its ID across analyses
+func SuggestionID(suggestion ImprovementSuggestion) string {
+	sum := sha1.Sum([]byte(suggestion.Type + "\x00" + suggestion.Description))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// validSuggestionStatuses lists the statuses a suggestion can be moved to
+var validSuggestionStatuses = []string{"open", "accepted", "dismissed", "done"}
+
+// IsValidSuggestionStatus reports whether status is a known suggestion status
+func IsValidSuggestionStatus(status string) bool {
+	for _, valid := range validSuggestionStatuses {
+		if status == valid {
+			return true
+		}
+	}
+	return false
+}
+
 // Storage interface defines storage operations
 type Storage interface {
 	SaveProject(project *ProjectData) error
@@ -92,7 +136,9 @@ type Storage interface {
 	DeleteProject(id string) error
 	SaveAnalysis(analysis *AnalysisData) error
 	GetAnalysis(projectID string) ([]*AnalysisData, error)
-	GetProjectStats() (*ProjectStats, error)
+	GetSuggestionStatuses(projectID string) (map[string]string, error)
+	UpdateSuggestionStatus(projectID, suggestionID, status string) error
+	GetProjectStats(language string) (*ProjectStats, error)
 	Cleanup(olderThan time.Duration) error
 
 	// Methods for generic data storage
@@ -111,6 +157,7 @@ type ProjectStats struct {
 	PopularLanguages  map[string]int         `json:"popular_languages"`
 	PopularFrameworks map[string]int         `json:"popular_frameworks"`
 	RecentActivity    []ProjectData          `json:"recent_activity"`
+	Language          string                 `json:"language,omitempty"` // filter applied to popularity and averages
 }
 
 // FileStorage implements Storage interface using file system
@@ -130,6 +177,7 @@ func (fs *FileStorage) Initialize() error {
 	dirs := []string{
 		filepath.Join(fs.baseDir, "projects"),
 		filepath.Join(fs.baseDir, "analysis"),
+		filepath.Join(fs.baseDir, "suggestions"),
 		filepath.Join(fs.baseDir, "backups"),
 		filepath.Join(fs.baseDir, "generic_data"), // New directory for generic data
 	}
@@ -143,8 +191,20 @@ func (fs *FileStorage) Initialize() error {
 	return nil
 }
 
+// validateKey rejects generic data keys that are not a single file name,
+// which could otherwise read or write outside the data directory
+func validateKey(key string) error {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return fmt.Errorf("invalid key: %q", key)
+	}
+	return nil
+}
+
 // Store saves generic data to storage
 func (fs *FileStorage) Store(key string, data interface{}) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
 	if err := fs.Initialize(); err != nil {
 		return err
 	}
@@ -164,6 +224,9 @@ func (fs *FileStorage) Store(key string, data interface{}) error {
 
 // Retrieve retrieves generic data from storage
 func (fs *FileStorage) Retrieve(key string, result interface{}) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
 	filePath := filepath.Join(fs.baseDir, "generic_data", key+".json")
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -182,6 +245,9 @@ func (fs *FileStorage) Retrieve(key string, result interface{}) error {
 
 // Delete deletes generic data from storage
 func (fs *FileStorage) Delete(key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
 	filePath := filepath.Join(fs.baseDir, "generic_data", key+".json")
 	if err := os.Remove(filePath); err != nil {
 		if os.IsNotExist(err) {
@@ -278,6 +344,7 @@ func (fs *FileStorage) DeleteProject(id string) error {
 	if _, err := os.Stat(analysisDir); err == nil {
 		os.RemoveAll(analysisDir)
 	}
+	os.Remove(filepath.Join(fs.baseDir, "suggestions", id+".json"))
 
 	return nil
 }
@@ -341,12 +408,91 @@ func (fs *FileStorage) GetAnalysis(projectID string) ([]*AnalysisData, error) {
 	return analyses, nil
 }
 
-// GetProjectStats calculates and returns project statistics
-func (fs *FileStorage) GetProjectStats() (*ProjectStats, error) {
+// GetSuggestionStatuses returns the persisted suggestion statuses for a project, keyed by suggestion ID
+func (fs *FileStorage) GetSuggestionStatuses(projectID string) (map[string]string, error) {
+	statuses := make(map[string]string)
+
+	statusPath := filepath.Join(fs.baseDir, "suggestions", projectID+".json")
+	data, err := os.ReadFile(statusPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return statuses, nil
+		}
+		return nil, fmt.Errorf("failed to read suggestion statuses: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &statuses); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal suggestion statuses: %v", err)
+	}
+
+	return statuses, nil
+}
+
+// UpdateSuggestionStatus sets the status of a suggestion from one of the project's analyses
+func (fs *FileStorage) UpdateSuggestionStatus(projectID, suggestionID, status string) error {
+	if !IsValidSuggestionStatus(status) {
+		return fmt.Errorf("invalid suggestion status: %s", status)
+	}
+
+	analyses, err := fs.GetAnalysis(projectID)
+	if err != nil {
+		return err
+	}
+
+	if !hasSuggestion(analyses, suggestionID) {
+		return fmt.Errorf("suggestion not found: %s", suggestionID)
+	}
+
+	statuses, err := fs.GetSuggestionStatuses(projectID)
+	if err != nil {
+		return err
+	}
+	statuses[suggestionID] = status
+
+	if err := fs.Initialize(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal suggestion statuses: %v", err)
+	}
+
+	statusPath := filepath.Join(fs.baseDir, "suggestions", projectID+".json")
+	if err := os.WriteFile(statusPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write suggestion statuses: %v", err)
+	}
+
+	return nil
+}
+
+// hasSuggestion reports whether one of the analyses made the suggestion
+func hasSuggestion(analyses []*AnalysisData, suggestionID string) bool {
+	for _, analysis := range analyses {
+		for _, suggestion := range analysis.Suggestions {
+			if suggestion.ID == suggestionID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetProjectStats calculates and returns project statistics. A non-empty language
+// restricts the popularity maps and averages to projects in that language.
+func (fs *FileStorage) GetProjectStats(language string) (*ProjectStats, error) {
 	projects, err := fs.ListProjects()
 	if err != nil {
 		return nil, err
 	}
+	return projectStats(projects, language), nil
+}
+
+// projectStats aggregates statistics over projects, newest first in RecentActivity
+func projectStats(projects []*ProjectData, language string) *ProjectStats {
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].GeneratedAt.After(projects[j].GeneratedAt)
+	})
 
 	stats := &ProjectStats{
 		TotalProjects:     len(projects),
@@ -357,6 +503,7 @@ func (fs *FileStorage) GetProjectStats() (*ProjectStats, error) {
 		PopularLanguages:  make(map[string]int),
 		PopularFrameworks: make(map[string]int),
 		RecentActivity:    []ProjectData{},
+		Language:          language,
 	}
 
 	var totalCoverage float64
@@ -373,6 +520,15 @@ func (fs *FileStorage) GetProjectStats() (*ProjectStats, error) {
 			stats.FailedProjects++
 		}
 
+		// Recent activity (last 10 projects)
+		if len(stats.RecentActivity) < 10 {
+			stats.RecentActivity = append(stats.RecentActivity, *project)
+		}
+
+		if language != "" && (project.Requirements == nil || !strings.EqualFold(project.Requirements.Language, language)) {
+			continue
+		}
+
 		// Count languages and frameworks
 		if project.Requirements != nil {
 			if stats.PopularLanguages == nil {
@@ -395,11 +551,6 @@ func (fs *FileStorage) GetProjectStats() (*ProjectStats, error) {
 			totalBuildTime += project.TestResults.Duration.Seconds()
 			buildTimeCount++
 		}
-
-		// Recent activity (last 10 projects)
-		if len(stats.RecentActivity) < 10 {
-			stats.RecentActivity = append(stats.RecentActivity, *project)
-		}
 	}
 
 	// Calculate averages
@@ -410,7 +561,7 @@ func (fs *FileStorage) GetProjectStats() (*ProjectStats, error) {
 		stats.AvgBuildTime = totalBuildTime / float64(buildTimeCount)
 	}
 
-	return stats, nil
+	return stats
 }
 
 // Cleanup removes old data based on age
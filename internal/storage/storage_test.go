@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Every Storage implementation must provide the generic key-value API
+var (
+	_ Storage = (*FileStorage)(nil)
+	_ Storage = (*S3Storage)(nil)
+)
+
+func TestFileStorageGenericData(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStorage(dir)
+
+	if err := store.Store("test_item", map[string]interface{}{"test_key": "test_value"}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "generic_data", "test_item.json")); err != nil {
+		t.Errorf("Expected the data as a JSON file: %v", err)
+	}
+
+	var retrieved map[string]interface{}
+	if err := store.Retrieve("test_item", &retrieved); err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if retrieved["test_key"] != "test_value" {
+		t.Errorf("Expected the stored data, got %v", retrieved)
+	}
+
+	if err := store.Delete("test_item"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := store.Retrieve("test_item", &retrieved); err == nil || !strings.Contains(err.Error(), "data not found") {
+		t.Errorf("Expected data not found after delete, got %v", err)
+	}
+	if err := store.Delete("test_item"); err == nil {
+		t.Error("Expected deleting a missing key to fail")
+	}
+}
+
+func TestFileStorageRejectsInvalidKeys(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStorage(filepath.Join(dir, "data"))
+
+	for _, key := range []string{"", "..", "../escaped", `..\escaped`, "nested/key"} {
+		if err := store.Store(key, "value"); err == nil {
+			t.Errorf("Expected Store to reject key %q", key)
+		}
+		var value string
+		if err := store.Retrieve(key, &value); err == nil {
+			t.Errorf("Expected Retrieve to reject key %q", key)
+		}
+		if err := store.Delete(key); err == nil {
+			t.Errorf("Expected Delete to reject key %q", key)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data", "escaped.json")); !os.IsNotExist(err) {
+		t.Error("Expected nothing written outside generic_data")
+	}
+}
@@ -0,0 +1,90 @@
+package testing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RunLoadTest sends requests GETs to url from concurrency workers and reports
+// how many succeeded and how long they took
+func (tr *TestRunner) RunLoadTest(url string, requests int, concurrency int) LoadTestResult {
+	return tr.RunLoadTestContext(context.Background(), url, requests, concurrency)
+}
+
+// RunLoadTestContext is RunLoadTest stopping early when ctx is done; the
+// result then covers the requests sent so far. Responses with a status of 400
+// or above count as failures, and every completed request, failed or not,
+// counts towards the response times.
+func (tr *TestRunner) RunLoadTestContext(ctx context.Context, url string, requests int, concurrency int) LoadTestResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > requests {
+		concurrency = requests
+	}
+
+	var (
+		next   int64 // requests claimed by the workers
+		mutex  sync.Mutex
+		result LoadTestResult
+		total  time.Duration
+		wg     sync.WaitGroup
+	)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil && atomic.AddInt64(&next, 1) <= int64(requests) {
+				latency, ok := tr.loadTestRequest(ctx, url)
+				if ctx.Err() != nil {
+					return // cancelled mid-request, which says nothing about the server
+				}
+
+				mutex.Lock()
+				result.TotalRequests++
+				if ok {
+					result.SuccessfulReqs++
+				} else {
+					result.FailedRequests++
+				}
+				total += latency
+				if latency > result.MaxResponse {
+					result.MaxResponse = latency
+				}
+				if result.MinResponse == 0 || latency < result.MinResponse {
+					result.MinResponse = latency
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if result.TotalRequests > 0 {
+		result.AverageResponse = total / time.Duration(result.TotalRequests)
+	}
+	return result
+}
+
+// loadTestRequest sends one GET and returns how long the full response took
+// and whether it succeeded
+func (tr *TestRunner) loadTestRequest(ctx context.Context, url string) (time.Duration, bool) {
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return time.Since(start), false
+	}
+	resp, err := tr.httpClient.Do(req)
+	if err != nil {
+		return time.Since(start), false
+	}
+	defer resp.Body.Close()
+
+	// Reading the body lets the connection be reused by the next request
+	_, err = io.Copy(io.Discard, resp.Body)
+	return time.Since(start), err == nil && resp.StatusCode < http.StatusBadRequest
+}
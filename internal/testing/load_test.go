@@ -0,0 +1,84 @@
+package testing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	testingpkg "github.com/kevinpranata97/golang-ai-agent/internal/testing"
+)
+
+func TestRunLoadTest(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	var served, inFlight, maxInFlight int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			peak := atomic.LoadInt64(&maxInFlight)
+			if n <= peak || atomic.CompareAndSwapInt64(&maxInFlight, peak, n) {
+				break
+			}
+		}
+
+		time.Sleep(delay)
+		// Every fifth request fails
+		if atomic.AddInt64(&served, 1)%5 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	result := testingpkg.NewTestRunner().RunLoadTest(server.URL, 20, 4)
+	elapsed := time.Since(start)
+
+	if result.TotalRequests != 20 || result.SuccessfulReqs != 16 || result.FailedRequests != 4 {
+		t.Errorf("Expected 20 requests with 16 successes and 4 failures, got %+v", result)
+	}
+	if result.MinResponse < delay || result.AverageResponse < result.MinResponse || result.MaxResponse < result.AverageResponse {
+		t.Errorf("Expected delay <= min <= average <= max, got %+v", result)
+	}
+	if result.MaxResponse > 2*time.Second {
+		t.Errorf("Expected response times near %v, got max %v", delay, result.MaxResponse)
+	}
+	if atomic.LoadInt64(&maxInFlight) > 4 {
+		t.Errorf("Expected at most 4 concurrent requests, got %d", maxInFlight)
+	}
+	// 20 requests of 20ms over 4 workers take about 100ms, not 400ms
+	if elapsed > 20*delay {
+		t.Errorf("Expected requests to run concurrently, took %v", elapsed)
+	}
+}
+
+func TestRunLoadTestContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result := testingpkg.NewTestRunner().RunLoadTestContext(ctx, server.URL, 10000, 2)
+
+	if result.TotalRequests == 0 || result.TotalRequests >= 10000 {
+		t.Errorf("Expected the load test to stop early, got %d requests", result.TotalRequests)
+	}
+	if result.FailedRequests != 0 {
+		t.Errorf("Expected cancelled requests not to count as failures, got %d", result.FailedRequests)
+	}
+}
+
+func TestRunLoadTestUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	result := testingpkg.NewTestRunner().RunLoadTest(url, 3, 5)
+	if result.TotalRequests != 3 || result.FailedRequests != 3 {
+		t.Errorf("Expected 3 failed requests, got %+v", result)
+	}
+}
@@ -287,7 +287,7 @@ func (tr *TestRunner) runIntegrationTests(projectPath string) []TestDetail {
 		
 		// Run the integration test
 		if strings.HasSuffix(testFile, "_test.go") {
-			cmd := exec.Command("go", "test", "-v", testFile)
+			cmd := exec.Command("go", "test", "-tags", "integration", "-v", testFile)
 			cmd.Dir = filepath.Dir(testFile)
 			
 			output, err := cmd.CombinedOutput()
@@ -372,7 +372,7 @@ func (tr *TestRunner) analyzeGoCode(projectPath string, analysis *CodeAnalysis)
 			switch x := n.(type) {
 			case *ast.FuncDecl:
 				analysis.Functions++
-				complexity := tr.calculateCyclomaticComplexity(x)
+				complexity := CyclomaticComplexity(x)
 				analysis.Complexity += complexity
 				
 				if complexity > 10 {
@@ -405,7 +405,9 @@ func (tr *TestRunner) analyzeGoCode(projectPath string, analysis *CodeAnalysis)
 	})
 }
 
-func (tr *TestRunner) calculateCyclomaticComplexity(fn *ast.FuncDecl) int {
+// CyclomaticComplexity returns the cyclomatic complexity of a function: one
+// plus a point for every branch, loop, switch and case in it
+func CyclomaticComplexity(fn *ast.FuncDecl) int {
 	complexity := 1 // Base complexity
 	
 	ast.Inspect(fn, func(n ast.Node) bool {
@@ -593,16 +595,3 @@ func (tr *TestRunner) TestWebEndpoint(url string) TestDetail {
 		Output:   fmt.Sprintf("Status: %d %s", resp.StatusCode, resp.Status),
 	}
 }
-
-func (tr *TestRunner) RunLoadTest(url string, requests int, concurrency int) LoadTestResult {
-	// Simple load test implementation
-	result := LoadTestResult{
-		TotalRequests: requests,
-	}
-	
-	// This is a simplified implementation
-	// In a real scenario, you'd use a proper load testing tool
-	
-	return result
-}
-
@@ -1,20 +1,42 @@
 package workflow
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/buildsys"
+	"github.com/kevinpranata97/golang-ai-agent/internal/github"
+	"github.com/kevinpranata97/golang-ai-agent/internal/logging"
+	testingpkg "github.com/kevinpranata97/golang-ai-agent/internal/testing"
 )
 
+// DefaultQualityThreshold is the lowest quality score the analyze step of the
+// ci_cd workflow accepts unless SetQualityThreshold changes it
+const DefaultQualityThreshold = 60.0
+
 type Engine struct {
-	workflows   map[string]Workflow
-	activeJobs  int
-	totalJobs   int
-	mutex       sync.RWMutex
+	workflows     map[string]Workflow
+	activeJobs    int
+	totalJobs     int
+	maxConcurrent int
+	runStep       func(step Step, ctx Context) StepResult
+	logger        logging.Logger
+	mutex         sync.RWMutex
+
+	// github clones the repository in the clone step
+	github *github.Client
+
+	// testRunner analyzes the cloned repository in the analyze step, which
+	// fails below qualityThreshold
+	testRunner       *testingpkg.TestRunner
+	qualityThreshold float64
 }
 
 type Workflow struct {
@@ -22,12 +44,20 @@ type Workflow struct {
 	Steps []Step
 }
 
+// Step is a single command in a workflow. A step runs once every step named in
+// DependsOn has succeeded; when no step of a workflow declares dependencies,
+// each step depends on the one before it.
 type Step struct {
-	Name    string
-	Command string
-	Args    []string
-	WorkDir string
-	Timeout time.Duration
+	Name      string
+	Command   string
+	Args      []string
+	WorkDir   string
+	Timeout   time.Duration
+	DependsOn []string
+
+	// detect marks the built-in clone, analyze, build and test steps, which
+	// are run from the context and the cloned repository
+	detect bool
 }
 
 type Context struct {
@@ -56,15 +86,29 @@ type Result struct {
 type StepResult struct {
 	Name     string        `json:"name"`
 	Success  bool          `json:"success"`
+	Skipped  bool          `json:"skipped,omitempty"`
 	Output   string        `json:"output"`
 	Error    string        `json:"error,omitempty"`
 	Duration time.Duration `json:"duration"`
 }
 
-func NewEngine() *Engine {
+// NewEngine creates an engine with the built-in ci_cd workflow, cloning
+// repositories with githubClient so private ones work with its token. A nil
+// client clones without authentication.
+func NewEngine(githubClient *github.Client) *Engine {
+	if githubClient == nil {
+		githubClient = github.NewClient("")
+	}
 	engine := &Engine{
-		workflows: make(map[string]Workflow),
+		workflows:     make(map[string]Workflow),
+		maxConcurrent: 1,
+		logger:        logging.Default(),
+		github:        githubClient,
+
+		testRunner:       testingpkg.NewTestRunner(),
+		qualityThreshold: DefaultQualityThreshold,
 	}
+	engine.runStep = engine.executeStep
 	
 	// Register default workflows
 	engine.registerDefaultWorkflows()
@@ -79,33 +123,37 @@ func (e *Engine) registerDefaultWorkflows() {
 		Steps: []Step{
 			{
 				Name:    "clone",
-				Command: "git",
-				Args:    []string{"clone", "", ""},
 				Timeout: 5 * time.Minute,
+				detect:  true,
 			},
 			{
-				Name:    "analyze",
-				Command: "echo",
-				Args:    []string{"Analyzing repository structure..."},
-				Timeout: 1 * time.Minute,
+				Name:      "analyze",
+				Timeout:   15 * time.Minute,
+				DependsOn: []string{"clone"},
+				detect:    true,
 			},
 			{
-				Name:    "build",
-				Command: "echo",
-				Args:    []string{"Building application..."},
-				Timeout: 10 * time.Minute,
+				Name:      "build",
+				Command:   "echo",
+				Args:      []string{"Building application..."},
+				Timeout:   10 * time.Minute,
+				DependsOn: []string{"analyze"},
+				detect:    true,
 			},
 			{
-				Name:    "test",
-				Command: "echo",
-				Args:    []string{"Running tests..."},
-				Timeout: 15 * time.Minute,
+				Name:      "test",
+				Command:   "echo",
+				Args:      []string{"Running tests..."},
+				Timeout:   15 * time.Minute,
+				DependsOn: []string{"build"},
+				detect:    true,
 			},
 			{
-				Name:    "security_scan",
-				Command: "echo",
-				Args:    []string{"Running security scan..."},
-				Timeout: 5 * time.Minute,
+				Name:      "security_scan",
+				Command:   "echo",
+				Args:      []string{"Running security scan..."},
+				Timeout:   5 * time.Minute,
+				DependsOn: []string{"build"},
 			},
 		},
 	}
@@ -125,7 +173,9 @@ func (e *Engine) ExecuteWorkflow(name string, ctx Context) Result {
 		e.mutex.Unlock()
 	}()
 	
+	e.mutex.RLock()
 	workflow, exists := e.workflows[name]
+	e.mutex.RUnlock()
 	if !exists {
 		return Result{
 			Success: false,
@@ -134,7 +184,8 @@ func (e *Engine) ExecuteWorkflow(name string, ctx Context) Result {
 		}
 	}
 	
-	log.Printf("Executing workflow '%s' for repository '%s'", name, ctx.Repository)
+	logger := e.logger.With("workflow", name, "repository", ctx.Repository)
+	logger.Info("Executing workflow")
 	
 	startTime := time.Now()
 	result := Result{
@@ -156,26 +207,195 @@ func (e *Engine) ExecuteWorkflow(name string, ctx Context) Result {
 	
 	ctx.WorkDir = tempDir
 	
-	// Execute each step
-	for _, step := range workflow.Steps {
-		stepResult := e.executeStep(step, ctx)
-		result.Steps = append(result.Steps, stepResult)
-		
-		if !stepResult.Success {
+	// Execute the steps as a dependency graph
+	dependencies, err := stepDependencies(workflow.Steps)
+	if err != nil {
+		result.Success = false
+		result.Error = err.Error()
+		result.Duration = time.Since(startTime)
+		return result
+	}
+	result.Steps = e.executeGraph(workflow.Steps, dependencies, ctx)
+	for _, stepResult := range result.Steps {
+		if !stepResult.Success && !stepResult.Skipped {
 			result.Success = false
-			result.Error = fmt.Sprintf("step '%s' failed: %s", step.Name, stepResult.Error)
+			result.Error = fmt.Sprintf("step '%s' failed: %s", stepResult.Name, stepResult.Error)
 			break
 		}
 	}
 	
 	result.Duration = time.Since(startTime)
-	log.Printf("Workflow '%s' completed in %v, success: %v", name, result.Duration, result.Success)
+	if result.Success {
+		logger.Info("Workflow completed", "duration", result.Duration)
+	} else {
+		logger.Warn("Workflow failed", "duration", result.Duration)
+	}
 	
 	return result
 }
 
+// stepDependencies returns the indexes each step waits for, chaining steps in
+// declaration order when the workflow declares no dependencies. Unknown
+// dependencies and cycles are errors.
+func stepDependencies(steps []Step) ([][]int, error) {
+	index := make(map[string]int, len(steps))
+	declared := false
+	for i, step := range steps {
+		if _, exists := index[step.Name]; exists {
+			return nil, fmt.Errorf("duplicate step '%s'", step.Name)
+		}
+		index[step.Name] = i
+		if len(step.DependsOn) > 0 {
+			declared = true
+		}
+	}
+
+	dependencies := make([][]int, len(steps))
+	for i, step := range steps {
+		if !declared {
+			if i > 0 {
+				dependencies[i] = []int{i - 1}
+			}
+			continue
+		}
+		for _, name := range step.DependsOn {
+			dep, exists := index[name]
+			if !exists {
+				return nil, fmt.Errorf("step '%s' depends on unknown step '%s'", step.Name, name)
+			}
+			dependencies[i] = append(dependencies[i], dep)
+		}
+	}
+
+	// Detect cycles with a depth-first search
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(steps))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("dependency cycle through step '%s'", steps[i].Name)
+		case done:
+			return nil
+		}
+		state[i] = visiting
+		for _, dep := range dependencies[i] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[i] = done
+		return nil
+	}
+	for i := range steps {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return dependencies, nil
+}
+
+// executeGraph runs steps once their dependencies succeed, up to maxConcurrent
+// at a time. After a failure no further steps start, and steps that never ran
+// are reported as skipped. Results are returned in declaration order.
+func (e *Engine) executeGraph(steps []Step, dependencies [][]int, ctx Context) []StepResult {
+	type completion struct {
+		index  int
+		result StepResult
+	}
+
+	e.mutex.RLock()
+	workers := e.maxConcurrent
+	e.mutex.RUnlock()
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(steps) {
+		workers = len(steps)
+	}
+
+	pending := make([]int, len(steps))
+	dependents := make([][]int, len(steps))
+	for i, deps := range dependencies {
+		pending[i] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], i)
+		}
+	}
+
+	jobs := make(chan int, len(steps))
+	completions := make(chan completion, len(steps))
+	abort := make(chan struct{})
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				select {
+				case <-abort:
+					completions <- completion{i, StepResult{Name: steps[i].Name, Skipped: true, Error: "skipped after an earlier step failed"}}
+				default:
+					completions <- completion{i, e.runStep(steps[i], ctx)}
+				}
+			}
+		}()
+	}
+
+	results := make([]*StepResult, len(steps))
+	queued := 0
+	for i := range steps {
+		if pending[i] == 0 {
+			jobs <- i
+			queued++
+		}
+	}
+
+	failed := false
+	for queued > 0 {
+		done := <-completions
+		queued--
+		results[done.index] = &done.result
+		if !done.result.Success {
+			if !failed {
+				failed = true
+				close(abort)
+			}
+			continue
+		}
+		if failed {
+			continue
+		}
+		for _, next := range dependents[done.index] {
+			pending[next]--
+			if pending[next] == 0 {
+				jobs <- next
+				queued++
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	ordered := make([]StepResult, len(steps))
+	for i, step := range steps {
+		if results[i] != nil {
+			ordered[i] = *results[i]
+			continue
+		}
+		ordered[i] = StepResult{Name: step.Name, Skipped: true, Error: "skipped because a dependency failed"}
+	}
+	return ordered
+}
+
 func (e *Engine) executeStep(step Step, ctx Context) StepResult {
-	log.Printf("Executing step: %s", step.Name)
+	logger := e.logger.With("repository", ctx.Repository, "step", step.Name)
+	logger.Debug("Executing step")
 	
 	startTime := time.Now()
 	stepResult := StepResult{
@@ -189,64 +409,221 @@ func (e *Engine) executeStep(step Step, ctx Context) StepResult {
 	copy(args, step.Args)
 	
 	// Handle special cases
-	switch step.Name {
+	detect := step.Name
+	if !step.detect {
+		detect = ""
+	}
+	switch detect {
 	case "clone":
-		if len(args) >= 2 {
-			args[1] = ctx.CloneURL
-			args[2] = filepath.Join(ctx.WorkDir, "repo")
-		}
+		return e.cloneRepository(step, ctx, logger)
+	case "analyze":
+		return e.analyzeRepository(step, ctx, logger)
 	case "build":
 		// Detect project type and use appropriate build command
-		repoPath := filepath.Join(ctx.WorkDir, "repo")
-		if e.fileExists(filepath.Join(repoPath, "go.mod")) {
-			command = "go"
-			args = []string{"build", "./..."}
-		} else if e.fileExists(filepath.Join(repoPath, "package.json")) {
-			command = "npm"
-			args = []string{"install"}
-		} else if e.fileExists(filepath.Join(repoPath, "Makefile")) {
-			command = "make"
-			args = []string{}
+		if detected, detectedArgs := buildsys.DetectBuildCommand(filepath.Join(ctx.WorkDir, "repo")); detected != "" {
+			command, args = detected, detectedArgs
 		}
 	case "test":
 		// Detect test framework and run tests
-		repoPath := filepath.Join(ctx.WorkDir, "repo")
-		if e.fileExists(filepath.Join(repoPath, "go.mod")) {
-			command = "go"
-			args = []string{"test", "./..."}
-		} else if e.fileExists(filepath.Join(repoPath, "package.json")) {
-			command = "npm"
-			args = []string{"test"}
+		if detected, detectedArgs := buildsys.DetectTestCommand(filepath.Join(ctx.WorkDir, "repo")); detected != "" {
+			command, args = detected, detectedArgs
 		}
 	}
 	
-	// Set working directory
+	// Set working directory: steps run in the cloned repository when there
+	// is one, and relative step directories are resolved against it
 	workDir := ctx.WorkDir
-	if step.WorkDir != "" {
+	if repoPath := filepath.Join(ctx.WorkDir, "repo"); step.Name != "clone" && e.fileExists(repoPath) {
+		workDir = repoPath
+	}
+	if filepath.IsAbs(step.WorkDir) {
 		workDir = step.WorkDir
-	} else if step.Name != "clone" {
-		workDir = filepath.Join(ctx.WorkDir, "repo")
+	} else if step.WorkDir != "" {
+		workDir = filepath.Join(workDir, step.WorkDir)
+	}
+	
+	// Execute command, bounded by the step timeout
+	runCtx := context.Background()
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, step.Timeout)
+		defer cancel()
 	}
 	
-	// Execute command
-	cmd := exec.Command(command, args...)
+	var output bytes.Buffer
+	cmd := exec.CommandContext(runCtx, command, args...)
 	cmd.Dir = workDir
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	setProcessGroup(cmd)
 	
-	output, err := cmd.CombinedOutput()
-	stepResult.Output = string(output)
+	err := runCommand(runCtx, cmd)
+	stepResult.Output = output.String()
 	stepResult.Duration = time.Since(startTime)
 	
-	if err != nil {
+	if runCtx.Err() == context.DeadlineExceeded {
+		stepResult.Success = false
+		stepResult.Error = fmt.Sprintf("step timed out after %s", step.Timeout)
+		logger.Warn("Step timed out", "timeout", step.Timeout)
+	} else if err != nil {
 		stepResult.Success = false
 		stepResult.Error = err.Error()
-		log.Printf("Step '%s' failed: %v", step.Name, err)
+		logger.Warn("Step failed", "error", err)
 	} else {
-		log.Printf("Step '%s' completed successfully", step.Name)
+		logger.Debug("Step completed", "duration", stepResult.Duration)
 	}
 	
 	return stepResult
 }
 
+// cloneRepository clones the context's repository into the repo directory of
+// the workflow, checking out the pushed branch rather than the default one
+func (e *Engine) cloneRepository(step Step, ctx Context, logger logging.Logger) StepResult {
+	startTime := time.Now()
+	stepResult := StepResult{Name: step.Name, Success: true}
+
+	runCtx := context.Background()
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, step.Timeout)
+		defer cancel()
+	}
+
+	branch := strings.TrimPrefix(ctx.Ref, "refs/heads/")
+	if branch == ctx.Ref {
+		branch = "" // Tags and other refs are not branches
+	}
+
+	e.mutex.RLock()
+	client := e.github
+	e.mutex.RUnlock()
+
+	err := client.CloneBranch(runCtx, ctx.CloneURL, filepath.Join(ctx.WorkDir, "repo"), branch)
+	stepResult.Duration = time.Since(startTime)
+	switch {
+	case runCtx.Err() == context.DeadlineExceeded:
+		stepResult.Success = false
+		stepResult.Error = fmt.Sprintf("step timed out after %s", step.Timeout)
+		logger.Warn("Step timed out", "timeout", step.Timeout)
+	case err != nil:
+		stepResult.Success = false
+		stepResult.Error = err.Error()
+		logger.Warn("Step failed", "error", err)
+	default:
+		stepResult.Output = fmt.Sprintf("Cloned %s", ctx.CloneURL)
+		if branch != "" {
+			stepResult.Output += fmt.Sprintf(" (branch %s)", branch)
+		}
+		logger.Debug("Step completed", "duration", stepResult.Duration)
+	}
+	return stepResult
+}
+
+// analyzeRepository runs the test runner on the cloned repository and fails
+// when its quality score is below the threshold or the security scan finds
+// high-severity vulnerabilities. The metrics are reported as the step output.
+func (e *Engine) analyzeRepository(step Step, ctx Context, logger logging.Logger) StepResult {
+	startTime := time.Now()
+	stepResult := StepResult{Name: step.Name, Success: true}
+
+	e.mutex.RLock()
+	runner, threshold := e.testRunner, e.qualityThreshold
+	e.mutex.RUnlock()
+
+	// RunTests cannot be cancelled; on timeout it finishes in the background
+	// and its result is dropped
+	repoPath := filepath.Join(ctx.WorkDir, "repo")
+	done := make(chan testingpkg.TestResult, 1)
+	go func() {
+		done <- runner.RunTests(repoPath)
+	}()
+	var timeout <-chan time.Time
+	if step.Timeout > 0 {
+		timer := time.NewTimer(step.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var result testingpkg.TestResult
+	select {
+	case result = <-done:
+	case <-timeout:
+		stepResult.Success = false
+		stepResult.Error = fmt.Sprintf("step timed out after %s", step.Timeout)
+		stepResult.Duration = time.Since(startTime)
+		logger.Warn("Step timed out", "timeout", step.Timeout)
+		return stepResult
+	}
+
+	// Report files relative to the repository
+	relative := func(path string) string {
+		if rel, err := filepath.Rel(repoPath, path); err == nil {
+			return rel
+		}
+		return path
+	}
+
+	analysis := result.Analysis
+	var high []testingpkg.Vulnerability
+	for _, vulnerability := range result.SecurityScan.Vulnerabilities {
+		if vulnerability.Severity == "high" {
+			high = append(high, vulnerability)
+		}
+	}
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "quality_score: %.1f (threshold %.1f)\n", analysis.QualityScore, threshold)
+	fmt.Fprintf(&output, "complexity: %d across %d functions\n", analysis.Complexity, analysis.Functions)
+	fmt.Fprintf(&output, "lines_of_code: %d\n", analysis.LinesOfCode)
+	fmt.Fprintf(&output, "issues: %d\n", len(analysis.Issues))
+	for _, issue := range analysis.Issues {
+		fmt.Fprintf(&output, "  %s %s:%d: %s\n", issue.Severity, relative(issue.File), issue.Line, issue.Description)
+	}
+	fmt.Fprintf(&output, "high_severity_vulnerabilities: %d\n", len(high))
+	for _, vulnerability := range high {
+		fmt.Fprintf(&output, "  %s %s: %s\n", vulnerability.Type, relative(vulnerability.File), vulnerability.Description)
+	}
+	stepResult.Output = output.String()
+	stepResult.Duration = time.Since(startTime)
+
+	var failures []string
+	if analysis.QualityScore < threshold {
+		failures = append(failures, fmt.Sprintf("quality score %.1f is below the threshold of %.1f", analysis.QualityScore, threshold))
+	}
+	if len(high) > 0 {
+		failures = append(failures, fmt.Sprintf("%d high-severity vulnerabilities found", len(high)))
+	}
+	if len(failures) > 0 {
+		stepResult.Success = false
+		stepResult.Error = strings.Join(failures, "; ")
+		logger.Warn("Quality gate failed", "quality_score", analysis.QualityScore, "high_vulnerabilities", len(high))
+	} else {
+		logger.Debug("Step completed", "duration", stepResult.Duration, "quality_score", analysis.QualityScore)
+	}
+	return stepResult
+}
+
+// runCommand runs cmd and, when ctx expires first, kills its whole process group
+// so children holding the output pipes (npm spawning node) cannot keep it alive
+func runCommand(ctx context.Context, cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		killProcessGroup(cmd)
+		return <-done
+	}
+}
+
 func (e *Engine) fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
@@ -264,12 +641,42 @@ func (e *Engine) GetTotalJobs() int {
 	return e.totalJobs
 }
 
+// SetMaxConcurrent sets how many independent steps of a workflow may run at once
+func (e *Engine) SetMaxConcurrent(n int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.maxConcurrent = n
+}
+
+// SetQualityThreshold sets the lowest quality score, from 0 to 100, the
+// analyze step accepts; 0 only gates on high-severity vulnerabilities
+func (e *Engine) SetQualityThreshold(threshold float64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.qualityThreshold = threshold
+}
+
+// SetLogger sets the logger for workflow and step progress
+func (e *Engine) SetLogger(logger logging.Logger) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.logger = logger
+}
+
 func (e *Engine) RegisterWorkflow(workflow Workflow) {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 	e.workflows[workflow.Name] = workflow
 }
 
+// HasWorkflow reports whether a workflow is registered under name
+func (e *Engine) HasWorkflow(name string) bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	_, exists := e.workflows[name]
+	return exists
+}
+
 func (e *Engine) ListWorkflows() []string {
 	e.mutex.RLock()
 	defer e.mutex.RUnlock()
@@ -0,0 +1,393 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/github"
+	"github.com/kevinpranata97/golang-ai-agent/internal/logging"
+)
+
+// stepRecorder stands in for executeStep, recording when each step ran and
+// how many steps were running at once
+type stepRecorder struct {
+	mutex   sync.Mutex
+	starts  map[string]time.Time
+	ends    map[string]time.Time
+	running int
+	peak    int
+	fail    string
+}
+
+func newStepRecorder(fail string) *stepRecorder {
+	return &stepRecorder{starts: map[string]time.Time{}, ends: map[string]time.Time{}, fail: fail}
+}
+
+func (r *stepRecorder) run(step Step, ctx Context) StepResult {
+	r.mutex.Lock()
+	r.starts[step.Name] = time.Now()
+	r.running++
+	if r.running > r.peak {
+		r.peak = r.running
+	}
+	r.mutex.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	r.mutex.Lock()
+	r.running--
+	r.ends[step.Name] = time.Now()
+	r.mutex.Unlock()
+
+	if step.Name == r.fail {
+		return StepResult{Name: step.Name, Error: "boom"}
+	}
+	return StepResult{Name: step.Name, Success: true}
+}
+
+func diamondWorkflow() Workflow {
+	return Workflow{
+		Name: "diamond",
+		Steps: []Step{
+			{Name: "a"},
+			{Name: "b", DependsOn: []string{"a"}},
+			{Name: "c", DependsOn: []string{"a"}},
+			{Name: "d", DependsOn: []string{"b", "c"}},
+		},
+	}
+}
+
+func TestExecuteWorkflowDiamond(t *testing.T) {
+	for _, limit := range []int{1, 2, 4} {
+		t.Run(fmt.Sprintf("max_%d", limit), func(t *testing.T) {
+			recorder := newStepRecorder("")
+			engine := NewEngine(nil)
+			engine.runStep = recorder.run
+			engine.SetMaxConcurrent(limit)
+			engine.RegisterWorkflow(diamondWorkflow())
+
+			result := engine.ExecuteWorkflow("diamond", Context{})
+			if !result.Success {
+				t.Fatalf("workflow failed: %s", result.Error)
+			}
+
+			var names []string
+			for _, step := range result.Steps {
+				names = append(names, step.Name)
+			}
+			if fmt.Sprint(names) != "[a b c d]" {
+				t.Errorf("expected results in declaration order, got %v", names)
+			}
+
+			for _, edge := range [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}} {
+				if recorder.starts[edge[1]].Before(recorder.ends[edge[0]]) {
+					t.Errorf("step %s started before its dependency %s finished", edge[1], edge[0])
+				}
+			}
+
+			expectedPeak := limit
+			if expectedPeak > 2 {
+				expectedPeak = 2 // at most b and c can run together
+			}
+			if recorder.peak != expectedPeak {
+				t.Errorf("expected %d steps running at once, saw %d", expectedPeak, recorder.peak)
+			}
+		})
+	}
+}
+
+func TestExecuteWorkflowFailFast(t *testing.T) {
+	recorder := newStepRecorder("b")
+	engine := NewEngine(nil)
+	engine.runStep = recorder.run
+	engine.SetMaxConcurrent(2)
+	engine.RegisterWorkflow(diamondWorkflow())
+
+	result := engine.ExecuteWorkflow("diamond", Context{})
+	if result.Success {
+		t.Fatal("expected workflow to fail")
+	}
+	if result.Error != "step 'b' failed: boom" {
+		t.Errorf("unexpected error %q", result.Error)
+	}
+	if _, ran := recorder.starts["d"]; ran {
+		t.Error("step d ran although its dependency failed")
+	}
+	if d := result.Steps[3]; d.Name != "d" || !d.Skipped || d.Success {
+		t.Errorf("expected d to be reported as skipped, got %+v", d)
+	}
+}
+
+func TestExecuteWorkflowInvalidDependencies(t *testing.T) {
+	engine := NewEngine(nil)
+	engine.runStep = newStepRecorder("").run
+	engine.RegisterWorkflow(Workflow{Name: "unknown", Steps: []Step{
+		{Name: "a", DependsOn: []string{"missing"}},
+	}})
+	engine.RegisterWorkflow(Workflow{Name: "cycle", Steps: []Step{
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+	}})
+
+	for _, name := range []string{"unknown", "cycle"} {
+		result := engine.ExecuteWorkflow(name, Context{})
+		if result.Success || result.Error == "" || len(result.Steps) != 0 {
+			t.Errorf("%s: expected validation failure, got %+v", name, result)
+		}
+	}
+}
+
+func TestExecuteWorkflowSequentialByDefault(t *testing.T) {
+	recorder := newStepRecorder("")
+	engine := NewEngine(nil)
+	engine.runStep = recorder.run
+	engine.SetMaxConcurrent(4)
+	engine.RegisterWorkflow(Workflow{Name: "chain", Steps: []Step{{Name: "a"}, {Name: "b"}, {Name: "c"}}})
+
+	if result := engine.ExecuteWorkflow("chain", Context{}); !result.Success {
+		t.Fatalf("workflow failed: %s", result.Error)
+	}
+	if recorder.peak != 1 {
+		t.Errorf("steps without dependencies should run in sequence, saw %d at once", recorder.peak)
+	}
+}
+
+func TestExecuteStepTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+	}{
+		{"process", "sleep", []string{"10"}},
+		// The shell waits on a child that shares its output pipe, so only
+		// killing the process group ends the step
+		{"children", "sh", []string{"-c", "sleep 10; echo done"}},
+	}
+
+	engine := NewEngine(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step := Step{Name: tt.name, Command: tt.command, Args: tt.args, WorkDir: t.TempDir(), Timeout: time.Second}
+
+			start := time.Now()
+			result := engine.executeStep(step, Context{})
+			elapsed := time.Since(start)
+
+			if result.Success {
+				t.Fatal("expected step to fail")
+			}
+			if result.Error != "step timed out after 1s" {
+				t.Errorf("unexpected error %q", result.Error)
+			}
+			if elapsed > 2*time.Second {
+				t.Errorf("step took %s to time out", elapsed)
+			}
+		})
+	}
+}
+
+// recordingLogger captures the level and message of every record
+type recordingLogger struct {
+	mutex   sync.Mutex
+	records []string
+}
+
+func (l *recordingLogger) record(level, msg string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.records = append(l.records, level+" "+msg)
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{})   { l.record("DEBUG", msg) }
+func (l *recordingLogger) Info(msg string, args ...interface{})    { l.record("INFO", msg) }
+func (l *recordingLogger) Warn(msg string, args ...interface{})    { l.record("WARN", msg) }
+func (l *recordingLogger) Error(msg string, args ...interface{})   { l.record("ERROR", msg) }
+func (l *recordingLogger) With(args ...interface{}) logging.Logger { return l }
+
+func TestExecuteWorkflowLogsFailureAsWarning(t *testing.T) {
+	engine := NewEngine(nil)
+	logger := &recordingLogger{}
+	engine.SetLogger(logger)
+	engine.runStep = newStepRecorder("b").run
+	engine.RegisterWorkflow(diamondWorkflow())
+
+	engine.ExecuteWorkflow("diamond", Context{Repository: "owner/repo"})
+
+	expected := []string{"INFO Executing workflow", "WARN Workflow failed"}
+	if fmt.Sprint(logger.records) != fmt.Sprint(expected) {
+		t.Errorf("expected records %v, got %v", expected, logger.records)
+	}
+}
+
+// writeFixtureRepo writes a Go module with main.go as the cloned repository
+// of a workflow run in dir
+func writeFixtureRepo(t *testing.T, dir, main string) {
+	t.Helper()
+	repo := filepath.Join(dir, "repo")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"go.mod":  "module fixture\n\ngo 1.21\n",
+		"main.go": main,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// complexFunction returns a function with one branch per case, far above the
+// complexity the quality score tolerates
+func complexFunction(cases int) string {
+	var b strings.Builder
+	b.WriteString("package main\n\nfunc classify(n int) string {\n\tswitch n {\n")
+	for i := 0; i < cases; i++ {
+		fmt.Fprintf(&b, "\tcase %d:\n\t\tif n > 0 {\n\t\t\treturn \"%d\"\n\t\t}\n", i, i)
+	}
+	b.WriteString("\t}\n\treturn \"\"\n}\n\nfunc main() { println(classify(1)) }\n")
+	return b.String()
+}
+
+func TestAnalyzeStepQualityGate(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+
+	tests := []struct {
+		name      string
+		main      string
+		threshold float64
+		wantError string
+	}{
+		{"simple", "package main\n\nfunc main() { println(\"hello\") }\n", DefaultQualityThreshold, ""},
+		{"complex", complexFunction(30), DefaultQualityThreshold, "is below the threshold of 60.0"},
+		{"complex without threshold", complexFunction(30), 0, ""},
+		{"hardcoded secret", "package main\n\nfunc main() {\n\tpassword = \"hunter2\"\n\tprintln(password)\n}\n\nvar password string\n", 0, "1 high-severity vulnerabilities found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFixtureRepo(t, dir, tt.main)
+			engine := NewEngine(nil)
+			engine.SetQualityThreshold(tt.threshold)
+
+			var analyze Step
+			for _, step := range engine.workflows["ci_cd"].Steps {
+				if step.Name == "analyze" {
+					analyze = step
+				}
+			}
+			result := engine.executeStep(analyze, Context{WorkDir: dir})
+
+			if !strings.Contains(result.Output, "quality_score: ") || !strings.Contains(result.Output, "complexity: ") {
+				t.Errorf("expected the metrics in the output, got %q", result.Output)
+			}
+			if tt.wantError == "" {
+				if !result.Success {
+					t.Errorf("expected the gate to pass, got %q\n%s", result.Error, result.Output)
+				}
+				return
+			}
+			if result.Success || !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("expected the gate to trip with %q, got success=%v error=%q\n%s", tt.wantError, result.Success, result.Error, result.Output)
+			}
+		})
+	}
+}
+
+// fakeGit puts a git script first on PATH that records its arguments in
+// args.txt and creates the clone destination, or fails printing the URL when
+// fail is set
+func fakeGit(t *testing.T, fail bool) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake git is a shell script")
+	}
+	bin := t.TempDir()
+	record := filepath.Join(bin, "args.txt")
+	script := "#!/bin/sh\necho \"$@\" > " + record + "\n"
+	if fail {
+		script += "echo \"fatal: could not read from $2\" >&2\nexit 128\n"
+	} else {
+		script += "for arg; do dest=$arg; done\nmkdir -p \"$dest\"\n"
+	}
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return record
+}
+
+func cloneStep(t *testing.T, engine *Engine) Step {
+	t.Helper()
+	for _, step := range engine.workflows["ci_cd"].Steps {
+		if step.Name == "clone" {
+			return step
+		}
+	}
+	t.Fatal("ci_cd has no clone step")
+	return Step{}
+}
+
+func TestCloneStepUsesGitHubClient(t *testing.T) {
+	record := fakeGit(t, false)
+	engine := NewEngine(github.NewClient("secret-token"))
+	workDir := t.TempDir()
+
+	result := engine.executeStep(cloneStep(t, engine), Context{
+		CloneURL: "https://github.com/owner/private.git",
+		Ref:      "refs/heads/feature",
+		WorkDir:  workDir,
+	})
+	if !result.Success {
+		t.Fatalf("clone failed: %s", result.Error)
+	}
+	if strings.Contains(result.Output, "secret-token") {
+		t.Errorf("step output leaks the token: %q", result.Output)
+	}
+
+	args, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("git was not run: %v", err)
+	}
+	want := "clone --branch feature https://secret-token@github.com/owner/private.git " + filepath.Join(workDir, "repo")
+	if got := strings.TrimSpace(string(args)); got != want {
+		t.Errorf("git ran with %q, want %q", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "repo")); err != nil {
+		t.Errorf("repository was not cloned into the work directory: %v", err)
+	}
+}
+
+func TestCloneStepRedactsToken(t *testing.T) {
+	fakeGit(t, true)
+	engine := NewEngine(github.NewClient("secret-token"))
+
+	result := engine.executeStep(cloneStep(t, engine), Context{
+		CloneURL: "https://github.com/owner/private.git",
+		Ref:      "refs/tags/v1.0.0",
+		WorkDir:  t.TempDir(),
+	})
+	if result.Success {
+		t.Fatal("expected the clone to fail")
+	}
+	if strings.Contains(result.Error, "secret-token") {
+		t.Errorf("error leaks the token: %q", result.Error)
+	}
+	if !strings.Contains(result.Error, "https://***@github.com/owner/private.git") {
+		t.Errorf("expected git's output in the error, got %q", result.Error)
+	}
+}
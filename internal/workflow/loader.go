@@ -0,0 +1,132 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// workflowDefinition is a workflow as written in a YAML or JSON file
+type workflowDefinition struct {
+	Name  string           `json:"name" yaml:"name"`
+	Steps []stepDefinition `json:"steps" yaml:"steps"`
+}
+
+// stepDefinition is a step as written in a workflow file; the timeout is a
+// duration such as "90s" or "5m"
+type stepDefinition struct {
+	Name      string   `json:"name" yaml:"name"`
+	Command   string   `json:"command" yaml:"command"`
+	Args      []string `json:"args" yaml:"args"`
+	WorkDir   string   `json:"workdir" yaml:"workdir"`
+	Timeout   string   `json:"timeout" yaml:"timeout"`
+	DependsOn []string `json:"depends_on" yaml:"depends_on"`
+}
+
+// LoadWorkflowsFromDir registers the workflows defined by the .yaml, .yml and
+// .json files in dir. A workflow without a name is named after its file, and
+// a workflow with the name of a registered one replaces it. Nothing is
+// registered unless every file is valid.
+func (e *Engine) LoadWorkflowsFromDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read workflow directory: %w", err)
+	}
+
+	var workflows []Workflow
+	names := map[string]string{}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		workflow, err := loadWorkflowFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if other, exists := names[workflow.Name]; exists {
+			return fmt.Errorf("%s: workflow '%s' is already defined in %s", path, workflow.Name, other)
+		}
+		names[workflow.Name] = path
+		workflows = append(workflows, workflow)
+	}
+
+	for _, workflow := range workflows {
+		e.RegisterWorkflow(workflow)
+	}
+	return nil
+}
+
+// loadWorkflowFile parses and validates a single workflow file
+func loadWorkflowFile(path string) (Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Workflow{}, err
+	}
+
+	var definition workflowDefinition
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &definition)
+	} else {
+		err = yaml.Unmarshal(data, &definition)
+	}
+	if err != nil {
+		return Workflow{}, fmt.Errorf("failed to parse workflow: %w", err)
+	}
+
+	if definition.Name == "" {
+		definition.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return definition.workflow()
+}
+
+// workflow converts a definition into a Workflow, rejecting steps without a
+// name or command, unparsable timeouts and invalid dependencies
+func (definition workflowDefinition) workflow() (Workflow, error) {
+	if len(definition.Steps) == 0 {
+		return Workflow{}, fmt.Errorf("workflow '%s' has no steps", definition.Name)
+	}
+
+	workflow := Workflow{Name: definition.Name, Steps: make([]Step, 0, len(definition.Steps))}
+	for i, def := range definition.Steps {
+		if def.Name == "" {
+			return Workflow{}, fmt.Errorf("step %d has no name", i+1)
+		}
+		if strings.TrimSpace(def.Command) == "" {
+			return Workflow{}, fmt.Errorf("step '%s' has no command", def.Name)
+		}
+
+		var timeout time.Duration
+		if def.Timeout != "" {
+			parsed, err := time.ParseDuration(def.Timeout)
+			if err != nil {
+				return Workflow{}, fmt.Errorf("step '%s' has an invalid timeout: %w", def.Name, err)
+			}
+			if parsed < 0 {
+				return Workflow{}, fmt.Errorf("step '%s' has a negative timeout", def.Name)
+			}
+			timeout = parsed
+		}
+
+		workflow.Steps = append(workflow.Steps, Step{
+			Name:      def.Name,
+			Command:   def.Command,
+			Args:      def.Args,
+			WorkDir:   def.WorkDir,
+			Timeout:   timeout,
+			DependsOn: def.DependsOn,
+		})
+	}
+
+	if _, err := stepDependencies(workflow.Steps); err != nil {
+		return Workflow{}, err
+	}
+	return workflow, nil
+}
@@ -0,0 +1,120 @@
+package workflow
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeWorkflowFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+func TestLoadWorkflowsFromDir(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not available")
+	}
+
+	dir, outDir := t.TempDir(), t.TempDir()
+	writeWorkflowFile(t, dir, "release.yaml", `
+name: release
+steps:
+  - name: build
+    command: echo
+    args: ["building"]
+    timeout: 30s
+  - name: lint
+    command: echo
+    args: ["linting"]
+    depends_on: [build]
+  - name: publish
+    command: sh
+    args: ["-c", "echo published from $PWD"]
+    workdir: `+outDir+`
+    depends_on: [build, lint]
+`)
+	writeWorkflowFile(t, dir, "nightly.json", `{"steps": [{"name": "report", "command": "echo", "args": ["nightly"], "timeout": "1m"}]}`)
+	writeWorkflowFile(t, dir, "README.md", "not a workflow")
+
+	engine := NewEngine(nil)
+	if err := engine.LoadWorkflowsFromDir(dir); err != nil {
+		t.Fatalf("LoadWorkflowsFromDir failed: %v", err)
+	}
+	for _, name := range []string{"ci_cd", "release", "nightly"} {
+		if !engine.HasWorkflow(name) {
+			t.Errorf("expected workflow %s to be registered", name)
+		}
+	}
+
+	engine.mutex.RLock()
+	release := engine.workflows["release"]
+	engine.mutex.RUnlock()
+	if release.Steps[0].Timeout != 30*time.Second || release.Steps[2].WorkDir != outDir || len(release.Steps[2].DependsOn) != 2 {
+		t.Errorf("unexpected steps %+v", release.Steps)
+	}
+
+	result := engine.ExecuteWorkflow("release", Context{Repository: "owner/repo"})
+	if !result.Success {
+		t.Fatalf("workflow release failed: %s", result.Error)
+	}
+	if got := result.Steps[2].Output; !strings.Contains(got, outDir) {
+		t.Errorf("expected publish to run in %s, got %q", outDir, got)
+	}
+
+	result = engine.ExecuteWorkflow("nightly", Context{})
+	if !result.Success || strings.TrimSpace(result.Steps[0].Output) != "nightly" {
+		t.Errorf("expected nightly to echo, got %+v", result)
+	}
+}
+
+func TestExecuteStepRelativeWorkDir(t *testing.T) {
+	if _, err := exec.LookPath("pwd"); err != nil {
+		t.Skip("pwd not available")
+	}
+
+	workDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(workDir, "out"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	result := NewEngine(nil).executeStep(Step{Name: "where", Command: "pwd", WorkDir: "out"}, Context{WorkDir: workDir})
+	if !result.Success || !strings.Contains(result.Output, filepath.Join(workDir, "out")) {
+		t.Errorf("expected the step to run in out/, got %+v", result)
+	}
+}
+
+func TestLoadWorkflowsFromDirValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{"no steps", "empty.yaml", "name: empty\n", "has no steps"},
+		{"no command", "a.yaml", "steps:\n  - name: build\n", "has no command"},
+		{"bad timeout", "a.yaml", "steps:\n  - name: build\n    command: echo\n    timeout: soon\n", "invalid timeout"},
+		{"unknown dependency", "a.json", `{"steps": [{"name": "a", "command": "echo", "depends_on": ["b"]}]}`, "unknown step"},
+		{"invalid yaml", "a.yml", "steps: [", "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeWorkflowFile(t, dir, "valid.yaml", "steps:\n  - name: ok\n    command: echo\n")
+			writeWorkflowFile(t, dir, tt.file, tt.content)
+
+			engine := NewEngine(nil)
+			err := engine.LoadWorkflowsFromDir(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			if engine.HasWorkflow("valid") {
+				t.Error("expected no workflow registered when a file is invalid")
+			}
+		})
+	}
+}
@@ -1,397 +1,327 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
-	"path/filepath"
-	"strings"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/google/uuid"
-
+	"github.com/kevinpranata97/golang-ai-agent/internal/agent"
 	"github.com/kevinpranata97/golang-ai-agent/internal/apptesting"
 	"github.com/kevinpranata97/golang-ai-agent/internal/codegen"
 	"github.com/kevinpranata97/golang-ai-agent/internal/database"
 	"github.com/kevinpranata97/golang-ai-agent/internal/finetuning"
+	"github.com/kevinpranata97/golang-ai-agent/internal/github"
+	"github.com/kevinpranata97/golang-ai-agent/internal/logging"
 	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+	"github.com/kevinpranata97/golang-ai-agent/internal/storage"
+	testingpkg "github.com/kevinpranata97/golang-ai-agent/internal/testing"
+	"github.com/kevinpranata97/golang-ai-agent/internal/workflow"
 )
 
 func main() {
+	// Load configuration
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "config.json"
+	}
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Leveled logging, configured by debugging.log_level
+	logger := logging.New(os.Stderr, cfg.Debugging.LogLevel)
+	fatal := func(msg string, err error) {
+		logger.Error(msg, "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize requirement analyzer
-	geminiAPIKey := requirements.GetGeminiAPIKey()
-	reqAnalyzer := requirements.NewRequirementAnalyzer(geminiAPIKey)
+	llmProvider, err := requirements.NewProviderFromEnv()
+	if err != nil {
+		fatal("Failed to configure LLM provider", err)
+	}
+	reqAnalyzer := requirements.NewRequirementAnalyzer(llmProvider)
+	reqAnalyzer.SetLimits(requirements.RequirementLimits{
+		MaxEntities:  cfg.Generation.MaxEntities,
+		MaxEndpoints: cfg.Generation.MaxEndpoints,
+	})
+	reqAnalyzer.SetLogger(logger)
 	
 	// Initialize code generator
-	outputDir := "./generated_apps"
+	outputDir := cfg.Storage.OutputDir
 	codeGen := codegen.NewCodeGenerator(outputDir)
+	codeGen.SetLimits(cfg.Generation.MaxFiles, cfg.Generation.MaxBytes)
+	if err := codeGen.SetCollisionPolicy(cfg.Generation.CollisionPolicy); err != nil {
+		fatal("Invalid generation.collision_policy", err)
+	}
+	if cfg.Generation.TemplatesDir != "" {
+		if err := codeGen.LoadTemplates(cfg.Generation.TemplatesDir); err != nil {
+			fatal("Failed to load template overrides", err)
+		}
+	}
+	if cfg.Generation.LicenseHeader != "" {
+		codeGen.RegisterPostGenerateHook(codegen.LicenseHeaderHook(cfg.Generation.LicenseHeader))
+	}
 	
 	// Initialize application tester
 	appTester := apptesting.NewApplicationTester(outputDir)
+	appTester.SetCoverageThreshold(cfg.Testing.CoverageThreshold)
+	appTester.SetSmokeTest(cfg.Testing.SmokeTest)
+	appTester.SetAPITestPort(cfg.Testing.APIPort)
+	appTester.SetSecurityFailSeverity(cfg.Testing.SecurityFailSeverity)
+	appTester.SetTimeout(time.Duration(cfg.Testing.Timeout) * time.Second)
+	appTester.SetParallel(cfg.Testing.Parallel)
+	appTester.SetArtifactsDir(cfg.Testing.ArtifactsDir)
+	appTester.SetKeepArtifacts(cfg.Testing.KeepArtifacts)
+	commandTimeouts := make(map[string]time.Duration)
+	for language, seconds := range cfg.Testing.CommandTimeouts {
+		commandTimeouts[language] = time.Duration(seconds) * time.Second
+	}
+	appTester.SetCommandTimeouts(commandTimeouts)
 
 	// Initialize Local Database for Fine-tuning
-	dataDir := "./data"
-	db, err := database.NewDB(dataDir)
+	dataDir := cfg.Storage.Path
+	db, err := database.NewDBWithOptions(dataDir, database.Options{
+		JournalMode:  cfg.Database.JournalMode,
+		BusyTimeout:  time.Duration(cfg.Database.BusyTimeout) * time.Millisecond,
+		MaxOpenConns: cfg.Database.MaxOpenConns,
+	})
+	if err != nil {
+		fatal("Failed to initialize database", err)
+	}
+
+	// Initialize project and analysis storage
+	store, err := newStorage(cfg, dataDir)
+	if err != nil {
+		fatal("Failed to initialize storage", err)
+	}
+
+	// Initialize the GitHub client, which also clones repositories for workflows
+	githubClient, err := newGitHubClient(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		fatal("Failed to initialize GitHub client", err)
+	}
+	githubClient.SetBaseURL(cfg.GitHub.BaseURL)
+
+	// Initialize workflow engine
+	workflowEngine := workflow.NewEngine(githubClient)
+	workflowEngine.SetMaxConcurrent(cfg.Workflow.MaxConcurrent)
+	workflowEngine.SetLogger(logger)
+	workflowEngine.SetQualityThreshold(cfg.Workflow.QualityThreshold)
+	if cfg.Workflow.Dir != "" {
+		if err := workflowEngine.LoadWorkflowsFromDir(cfg.Workflow.Dir); err != nil {
+			fatal("Failed to load workflows", err)
+		}
 	}
-	defer db.Close()
+
+	// Initialize the agent that runs workflows for GitHub webhooks
+	aiAgent := agent.NewAgent(store, githubClient, testingpkg.NewTestRunner(), workflowEngine)
+	aiAgent.SetWebhookSecret(cfg.GitHub.WebhookSecret)
+	aiAgent.SetLogger(logger)
 
 	// Initialize Finetuner
 	finetuner := finetuning.NewFinetuner(db)
+	finetuner.SetLogger(logger)
+	reqAnalyzer.SetPromptHints(finetuner)
+
+	// SIGINT and SIGTERM start a graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Schedule periodic fine-tuning process
+	scheduler := finetuning.NewScheduler(finetuner, time.Duration(cfg.Finetuning.Interval)*time.Second)
+	scheduler.SetLogger(logger)
+	finetuningDone := make(chan struct{})
 	go func() {
-		for {
-			log.Println("Running scheduled fine-tuning process...")
-			if err := finetuner.ProcessLogs(); err != nil {
-				log.Printf("Error during scheduled fine-tuning: %v", err)
-			}
-			time.Sleep(5 * time.Minute) // Process every 5 minutes
-		}
+		defer close(finetuningDone)
+		scheduler.Run(ctx)
 	}()
 
 	// Setup HTTP routes
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
-	})
-
-	http.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"status": "running",
-			"agent":  "golang-ai-agent",
-			"features": []string{
-				"application_generation",
-				"code_testing",
-				"requirement_analysis",
-				"github_integration",
-				"fine_tuning",
-				"local_database_storage",
-			},
-		})
-	})
-
-	// New endpoint for generating applications
-	http.HandleFunc("/generate-app", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
+	srv := newServer(reqAnalyzer, codeGen, appTester, db, store, workflowEngine, finetuner, outputDir)
+	srv.logger = logger
+	srv.compileCheck = cfg.Generation.CompileCheck
 
-		var request struct {
-			Description string `json:"description"`
-		}
-
-		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			http.Error(w, "Invalid JSON", http.StatusBadRequest)
-			return
-		}
-
-		if request.Description == "" {
-			http.Error(w, "Description is required", http.StatusBadRequest)
-			return
-		}
+	// handle registers a route whose requests are counted in the metrics
+	handle := func(pattern string, handler http.HandlerFunc) {
+		http.HandleFunc(pattern, srv.instrument(pattern, handler))
+	}
 
-		interactionLog := database.InteractionLog{
-			ID:            uuid.New().String(),
-			Timestamp:     time.Now(),
-			Endpoint:      "/generate-app",
-			RequestPayload:  string(request.Description),
-			Status:        "success", // Default to success, update on error
-		}
+	handle("/health", srv.handleHealth)
 
-		// Analyze requirements
-		appReq, err := reqAnalyzer.AnalyzeRequirements(request.Description)
-		if err != nil {
-			log.Printf("Failed to analyze requirements: %v", err)
-			http.Error(w, fmt.Sprintf("Failed to analyze requirements: %v", err), http.StatusInternalServerError)
-			interactionLog.Status = "failure"
-			db.InsertInteractionLog(interactionLog)
-			return
-		}
+	handle("/status", srv.handleStatus)
 
-		// Validate requirements
-		if err := reqAnalyzer.ValidateRequirements(appReq); err != nil {
-			log.Printf("Invalid requirements: %v", err)
-			http.Error(w, fmt.Sprintf("Invalid requirements: %v", err), http.StatusBadRequest)
-			interactionLog.Status = "failure"
-			db.InsertInteractionLog(interactionLog)
-			return
-		}
+	// Endpoints that generate, test or analyze applications require the API
+	// token when one is configured
+	apiToken := cfg.Server.APIToken
 
-		// Generate application
-		if err := codeGen.GenerateApplication(appReq); err != nil {
-			log.Printf("Failed to generate application: %v", err)
-			http.Error(w, fmt.Sprintf("Failed to generate application: %v", err), http.StatusInternalServerError)
-			interactionLog.Status = "failure"
-			db.InsertInteractionLog(interactionLog)
-			return
-		}
+	// New endpoint for generating applications
+	handle("/generate-app", requireToken(apiToken, srv.handleGenerateApp))
 
-		// Return success response
-		w.Header().Set("Content-Type", "application/json")
-		jsonResponse, _ := json.Marshal(map[string]interface{}{
-			"success": true,
-			"message": "Application generated successfully",
-			"app": map[string]interface{}{
-				"name":        appReq.Name,
-				"type":        appReq.Type,
-				"language":    appReq.Language,
-				"framework":   appReq.Framework,
-				"entities":    len(appReq.Entities),
-				"endpoints":   len(appReq.Endpoints),
-				"output_dir":  filepath.Join(outputDir, strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-"))),
-			},
-		})
-		w.Write(jsonResponse)
+	// Generate an application from each of several descriptions
+	handle("/generate-batch", requireToken(apiToken, srv.handleGenerateBatch))
 
-		interactionLog.ResponsePayload = string(jsonResponse)
-		interactionLog.AppName = appReq.Name
-		interactionLog.AppPath = filepath.Join(outputDir, strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-")))
-		if err := db.InsertInteractionLog(interactionLog); err != nil {
-			log.Printf("Failed to log interaction: %v", err)
-		}
-	})
+	// Analyze and validate a description without generating anything
+	handle("/validate", requireToken(apiToken, srv.handleValidate))
 
 	// New endpoint for testing generated applications
-	http.HandleFunc("/test-app", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
+	handle("/test-app", requireToken(apiToken, srv.handleTestApp))
 
-		var request struct {
-			AppPath string `json:"app_path"`
-		}
+	// Static debugging analysis of a generated application
+	handle("/debug", requireToken(apiToken, srv.handleDebug))
 
-		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			http.Error(w, "Invalid JSON", http.StatusBadRequest)
-			return
-		}
+	// Regenerate one component of a generated Go application
+	handle("/regenerate", requireToken(apiToken, srv.handleRegenerate))
 
-		if request.AppPath == "" {
-			http.Error(w, "App path is required", http.StatusBadRequest)
-			return
-		}
-
-		interactionLog := database.InteractionLog{
-			ID:            uuid.New().String(),
-			Timestamp:     time.Now(),
-			Endpoint:      "/test-app",
-			RequestPayload:  string(request.AppPath),
-			AppPath:       request.AppPath,
-			Status:        "success", // Default to success, update on error
-		}
-
-		// Check if app path exists
-		if _, err := os.Stat(request.AppPath); os.IsNotExist(err) {
-			http.Error(w, "Application path does not exist", http.StatusNotFound)
-			interactionLog.Status = "failure"
-			db.InsertInteractionLog(interactionLog)
-			return
-		}
-
-		// Load application requirements (this would typically be saved during generation)
-		// For now, we'll create a basic requirement structure
-		appReq := &requirements.ApplicationRequirement{
-			Name:     filepath.Base(request.AppPath),
-			Type:     "api", // Default assumption
-			Language: "go",
-		}
-
-		// Run tests
-		testSuite, err := appTester.TestApplication(request.AppPath, appReq)
-		if err != nil {
-			log.Printf("Failed to test application: %v", err)
-			http.Error(w, fmt.Sprintf("Failed to test application: %v", err), http.StatusInternalServerError)
-			interactionLog.Status = "failure"
-			db.InsertInteractionLog(interactionLog)
-			return
-		}
-
-		// Save test results
-		resultsPath := filepath.Join(request.AppPath, "test_results.json")
-		if err := appTester.SaveTestResults(testSuite, resultsPath); err != nil {
-			log.Printf("Failed to save test results: %v", err)
-		}
-
-		// Return test results
-		w.Header().Set("Content-Type", "application/json")
-		jsonResponse, _ := json.Marshal(map[string]interface{}{
-			"success":      true,
-			"message":      "Application testing completed",
-			"test_suite":   testSuite,
-			"results_file": resultsPath,
-		})
-		w.Write(jsonResponse)
-
-		interactionLog.ResponsePayload = string(jsonResponse)
-		// Assuming testSuite has an OverallStatus field
-		if testSuite.OverallStatus == "failure" {
-			interactionLog.Status = "failure"
-		}
-		// Convert testSuite to JSON string for TestResultsJSON
-		testSuiteJSON, _ := json.Marshal(testSuite)
-		interactionLog.TestResultsJSON = string(testSuiteJSON)
-		if err := db.InsertInteractionLog(interactionLog); err != nil {
-			log.Printf("Failed to log interaction: %v", err)
-		}
-	})
+	// Run a registered workflow on demand
+	handle("/workflows/", requireToken(apiToken, srv.handleRunWorkflow))
 
 	// Combined endpoint for generating and testing applications
-	http.HandleFunc("/generate-and-test", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
+	handle("/generate-and-test", requireToken(apiToken, srv.handleGenerateAndTest))
+	handle("/generate-and-test/stream", requireToken(apiToken, srv.handleGenerateAndTestStream))
 
-		var request struct {
-			Description string `json:"description"`
-		}
+	// List generated projects and fetch their requirements and test results
+	handle("/projects", srv.handleListProjects)
+	handle("/projects/", srv.handleGetProject)
 
-		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			http.Error(w, "Invalid JSON", http.StatusBadRequest)
-			return
-		}
+	// Aggregate statistics over generated projects
+	handle("/stats", srv.handleStats)
 
-		if request.Description == "" {
-			http.Error(w, "Description is required", http.StatusBadRequest)
-			return
-		}
+	// Query interaction logs, e.g. to debug failed generations
+	handle("/logs", srv.handleListLogs)
 
-		interactionLog := database.InteractionLog{
-			ID:            uuid.New().String(),
-			Timestamp:     time.Now(),
-			Endpoint:      "/generate-and-test",
-			RequestPayload:  string(request.Description),
-			Status:        "success", // Default to success, update on error
-		}
+	// Update the status of an analysis suggestion
+	handle("/suggestions", requireTokenFor(apiToken, srv.handleSuggestionStatus, http.MethodPatch))
 
-		// Analyze requirements
-		appReq, err := reqAnalyzer.AnalyzeRequirements(request.Description)
-		if err != nil {
-			log.Printf("Failed to analyze requirements: %v", err)
-			http.Error(w, fmt.Sprintf("Failed to analyze requirements: %v", err), http.StatusInternalServerError)
-			interactionLog.Status = "failure"
-			db.InsertInteractionLog(interactionLog)
-			return
-		}
+	// GitHub webhook: runs the CI/CD workflow and reports back on commits and PRs
+	handle("/webhook", aiAgent.HandleWebhook)
 
-		// Validate requirements
-		if err := reqAnalyzer.ValidateRequirements(appReq); err != nil {
-			log.Printf("Invalid requirements: %v", err)
-			http.Error(w, fmt.Sprintf("Invalid requirements: %v", err), http.StatusBadRequest)
-			interactionLog.Status = "failure"
-			db.InsertInteractionLog(interactionLog)
-			return
-		}
+	// Prometheus metrics
+	http.HandleFunc("/metrics", srv.handleMetrics)
 
-		// Generate application
-		if err := codeGen.GenerateApplication(appReq); err != nil {
-			log.Printf("Failed to generate application: %v", err)
-			http.Error(w, fmt.Sprintf("Failed to generate application: %v", err), http.StatusInternalServerError)
-			interactionLog.Status = "failure"
-			db.InsertInteractionLog(interactionLog)
-			return
-		}
+	// Start server
+	addr := net.JoinHostPort(cfg.Server.Host, cfg.Server.Port)
+	httpServer := newHTTPServer(addr, nil, time.Duration(cfg.Server.ReadTimeout)*time.Second, time.Duration(cfg.Server.WriteTimeout)*time.Second)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		fatal("Server failed to start", err)
+	}
 
-		appPath := filepath.Join(outputDir, strings.ToLower(strings.ReplaceAll(appReq.Name, " ", "-")))
+	logger.Info("Server starting", "addr", addr, "auth", apiToken != "")
+	for _, endpoint := range [][2]string{
+		{"GET  /health", "Health check"},
+		{"GET  /status", "Agent and subsystem health"},
+		{"POST /generate-app", "Generate application from description"},
+		{"POST /generate-batch", "Generate applications from several descriptions"},
+		{"POST /validate", "Analyze requirements without generating"},
+		{"POST /test-app", "Test generated application"},
+		{"POST /debug", "Analyze application for issues"},
+		{"POST /generate-and-test", "Generate and test application"},
+		{"GET  /generate-and-test/stream", "Generate and test with progress events"},
+		{"POST /regenerate", "Regenerate one component of an application"},
+		{"POST /workflows/{name}/run", "Run a workflow"},
+		{"GET  /projects", "List generated projects"},
+		{"GET  /projects/{name}", "Project requirements and test results"},
+		{"GET  /stats", "Project statistics"},
+		{"GET  /logs", "Query interaction logs"},
+		{"GET  /metrics", "Prometheus metrics"},
+		{"PATCH /suggestions", "Update suggestion status"},
+		{"POST /webhook", "GitHub webhook"},
+	} {
+		logger.Info("Endpoint available", "endpoint", endpoint[0], "description", endpoint[1])
+	}
 
-		// Test the generated application
-		testSuite, err := appTester.TestApplication(appPath, appReq)
-		if err != nil {
-			log.Printf("Failed to test application: %v", err)
-			// Don't fail the entire request if testing fails
-		}
+	err = serve(ctx, httpServer, listener, time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	if err != nil {
+		logger.Error("Server stopped", "error", err)
+	} else {
+		logger.Info("Server stopped")
+	}
 
-		// Save test results if testing was successful
-		var resultsPath string
-		if testSuite != nil {
-			resultsPath = filepath.Join(appPath, "test_results.json")
-			if err := appTester.SaveTestResults(testSuite, resultsPath); err != nil {
-				log.Printf("Failed to save test results: %v", err)
-			}
-		}
+	stop()
+	<-finetuningDone
+	if err := db.Close(); err != nil {
+		logger.Error("Failed to close database", "error", err)
+	}
+}
 
-		// Return success response
-		w.Header().Set("Content-Type", "application/json")
-		responseMap := map[string]interface{}{
-			"success": true,
-			"message": "Application generated and tested successfully",
-			"app": map[string]interface{}{
-				"name":        appReq.Name,
-				"type":        appReq.Type,
-				"language":    appReq.Language,
-				"framework":   appReq.Framework,
-				"entities":    len(appReq.Entities),
-				"endpoints":   len(appReq.Endpoints),
-				"output_dir":  appPath,
-			},
-		}
+// newGitHubClient returns a client authenticated as the GitHub App
+// installation configured by github.app_id, or with the static token otherwise
+func newGitHubClient(cfg *Config) (*github.Client, error) {
+	if cfg.GitHub.AppID == 0 {
+		return github.NewClient(cfg.GitHub.Token), nil
+	}
+	privateKey, err := os.ReadFile(cfg.GitHub.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	return github.NewAppClient(cfg.GitHub.AppID, cfg.GitHub.InstallationID, privateKey)
+}
 
-		if testSuite != nil {
-			responseMap["test_results"] = map[string]interface{}{
-				"total_tests":    testSuite.TotalTests,
-				"passed_tests":   testSuite.PassedTests,
-				"failed_tests":   testSuite.FailedTests,
-				"skipped_tests":  testSuite.SkippedTests,
-				"coverage":       testSuite.Coverage,
-				"duration":       testSuite.Duration.String(),
-				"results_file":   resultsPath,
-				"summary":        testSuite.Summary,
-			}
-		}
-		jsonResponse, _ := json.Marshal(responseMap)
-		w.Write(jsonResponse)
-
-		interactionLog.ResponsePayload = string(jsonResponse)
-		interactionLog.AppName = appReq.Name
-		interactionLog.AppPath = appPath
-		if testSuite != nil {
-			// Convert testSuite to JSON string for TestResultsJSON
-			testSuiteJSON, _ := json.Marshal(testSuite)
-			interactionLog.TestResultsJSON = string(testSuiteJSON)
-			if testSuite.OverallStatus == "failure" {
-				interactionLog.Status = "failure"
-			}
-		}
-		if err := db.InsertInteractionLog(interactionLog); err != nil {
-			log.Printf("Failed to log interaction: %v", err)
+// newStorage returns the project storage selected by storage.type: local
+// files in dataDir, or an S3 bucket
+func newStorage(cfg *Config, dataDir string) (storage.Storage, error) {
+	switch cfg.Storage.Type {
+	case "", "file":
+		store := storage.NewFileStorage(dataDir)
+		if err := store.Initialize(); err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "s3":
+		client, err := storage.NewHTTPS3Client(storage.S3Config{
+			Bucket:          cfg.Storage.Bucket,
+			Region:          cfg.Storage.Region,
+			Endpoint:        cfg.Storage.Endpoint,
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		})
+		if err != nil {
+			return nil, err
 		}
-	})
+		return storage.NewS3Storage(client, cfg.Storage.Prefix), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage type: %s", cfg.Storage.Type)
+	}
+}
 
-	// Webhook endpoint (existing functionality)
-	http.HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
+// newHTTPServer returns a server for handler (the default mux when nil) whose
+// requests must be read and answered within the given timeouts
+func newHTTPServer(addr string, handler http.Handler, readTimeout, writeTimeout time.Duration) *http.Server {
+	return &http.Server{
+		Addr:         addr,
+		Handler:      handler,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
 
-		// Process webhook (existing logic)
-		log.Println("Webhook received")
-		w.WriteHeader(http.StatusOK)
-	})
+// serve serves on listener until ctx is done, then stops accepting connections
+// and waits up to drainTimeout for in-flight requests to complete
+func serve(ctx context.Context, httpServer *http.Server, listener net.Listener, drainTimeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
 
-	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
 	}
 
-	log.Printf("Server starting on port %s", port)
-	log.Printf("Available endpoints:")
-	log.Printf("  GET  /health - Health check")
-	log.Printf("  GET  /status - Agent status")
-	log.Printf("  POST /generate-app - Generate application from description")
-	log.Printf("  POST /test-app - Test generated application")
-	log.Printf("  POST /generate-and-test - Generate and test application")
-	log.Printf("  POST /webhook - GitHub webhook")
-	
-	if err := http.ListenAndServe("0.0.0.0:"+port, nil); err != nil {
-		log.Fatal("Server failed to start:", err)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to drain in-flight requests: %w", err)
 	}
+	return nil
 }
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
+	"io"
+	"net"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/kevinpranata97/golang-ai-agent/internal/agent"
 	"github.com/kevinpranata97/golang-ai-agent/internal/github"
@@ -30,7 +35,7 @@ func TestAgentInitialization(t *testing.T) {
 	}
 
 	// Test workflow engine initialization
-	workflowEngine := workflow.NewEngine()
+	workflowEngine := workflow.NewEngine(nil)
 	if workflowEngine == nil {
 		t.Fatal("Failed to initialize workflow engine")
 	}
@@ -43,7 +48,7 @@ func TestAgentInitialization(t *testing.T) {
 }
 
 func TestWorkflowEngine(t *testing.T) {
-	engine := workflow.NewEngine()
+	engine := workflow.NewEngine(nil)
 	
 	// Test workflow execution
 	ctx := workflow.Context{
@@ -87,4 +92,65 @@ func TestStorage(t *testing.T) {
 	storage.Delete("test_item")
 }
 
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
 
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	httpServer := newHTTPServer(listener.Addr().String(), handler, 5*time.Second, 5*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, httpServer, listener, 5*time.Second)
+	}()
+
+	type reply struct {
+		body string
+		err  error
+	}
+	replied := make(chan reply, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		if err != nil {
+			replied <- reply{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		replied <- reply{string(body), err}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-served:
+		t.Fatalf("serve returned before the in-flight request completed: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after the in-flight request completed")
+	}
+
+	r := <-replied
+	if r.err != nil || r.body != "done" {
+		t.Errorf("expected the in-flight request to complete, got %q, %v", r.body, r.err)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/apptesting"
+	"github.com/kevinpranata97/golang-ai-agent/internal/metrics"
+	"github.com/kevinpranata97/golang-ai-agent/internal/workflow"
+)
+
+// serverMetrics are the Prometheus metrics served at /metrics
+type serverMetrics struct {
+	registry *metrics.Registry
+	// requests counts requests by endpoint and outcome
+	requests *metrics.Counter
+	// stages times requirement analysis, generation and testing
+	stages *metrics.Histogram
+	// testPhases times each test phase and testResults counts their statuses
+	testPhases  *metrics.Histogram
+	testResults *metrics.Counter
+}
+
+// newServerMetrics registers the server's metrics, including a gauge of the
+// active jobs of engine, which may be nil
+func newServerMetrics(engine *workflow.Engine) *serverMetrics {
+	registry := metrics.NewRegistry()
+	m := &serverMetrics{
+		registry: registry,
+		requests: registry.NewCounter("agent_http_requests_total",
+			"HTTP requests by endpoint and outcome.", "endpoint", "outcome"),
+		stages: registry.NewHistogram("agent_stage_duration_seconds",
+			"Duration of requirement analysis, generation and testing.", metrics.DefaultBuckets, "stage"),
+		testPhases: registry.NewHistogram("agent_test_phase_duration_seconds",
+			"Duration of each phase of testing a generated application.", metrics.DefaultBuckets, "phase"),
+		testResults: registry.NewCounter("agent_test_phases_total",
+			"Finished test phases by phase and status.", "phase", "status"),
+	}
+	registry.NewGaugeFunc("agent_workflow_active_jobs", "Workflow jobs currently running.", func() float64 {
+		if engine == nil {
+			return 0
+		}
+		return float64(engine.GetActiveJobs())
+	})
+	return m
+}
+
+// observeStage records how long a stage of a request took
+func (m *serverMetrics) observeStage(stage string, start time.Time) {
+	m.stages.Observe(time.Since(start).Seconds(), stage)
+}
+
+// observePhase records a finished test phase; it is the phase observer of the
+// application tester
+func (m *serverMetrics) observePhase(kind string, result apptesting.TestResult) {
+	m.testPhases.Observe(result.Duration.Seconds(), kind)
+	m.testResults.Inc(kind, result.Status)
+}
+
+// handleMetrics serves the metrics in the Prometheus text format
+func (s *server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	s.metrics.registry.ServeHTTP(w, r)
+}
+
+// instrument counts the requests handler serves under endpoint, by outcome:
+// "success" below 400, "failure" otherwise
+func (s *server) instrument(endpoint string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler(recorder, r)
+		outcome := "success"
+		if recorder.status >= http.StatusBadRequest {
+			outcome = "failure"
+		}
+		s.metrics.requests.Inc(endpoint, outcome)
+	}
+}
+
+// statusRecorder remembers the status code written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush keeps streaming endpoints working behind the recorder
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	srv := newTestServer(t)
+	generate := srv.instrument("/generate-app", srv.handleGenerateApp)
+
+	postJSON(t, generate, "/generate-app", map[string]string{"description": "user management api"})
+	postJSON(t, generate, "/generate-app", map[string]string{"description": ""})
+
+	// An app without sources still runs every test phase
+	appPath := filepath.Join(srv.outputDir, "empty-app")
+	if err := os.MkdirAll(appPath, 0755); err != nil {
+		t.Fatalf("Failed to create app dir: %v", err)
+	}
+	postJSON(t, srv.handleTestApp, "/test-app", map[string]string{"app_path": appPath})
+
+	rec := httptest.NewRecorder()
+	srv.handleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("Expected a text/plain 200 response, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE agent_http_requests_total counter",
+		`agent_http_requests_total{endpoint="/generate-app",outcome="success"} 1`,
+		`agent_http_requests_total{endpoint="/generate-app",outcome="failure"} 1`,
+		"# TYPE agent_stage_duration_seconds histogram",
+		`agent_stage_duration_seconds_count{stage="analysis"} 1`,
+		`agent_stage_duration_seconds_count{stage="generation"} 1`,
+		`agent_stage_duration_seconds_count{stage="test"} 1`,
+		`agent_test_phase_duration_seconds_count{phase="build"} 1`,
+		`agent_test_phases_total{phase="build",status=`,
+		"# TYPE agent_workflow_active_jobs gauge",
+		"agent_workflow_active_jobs 0",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,1611 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/apptesting"
+	"github.com/kevinpranata97/golang-ai-agent/internal/codegen"
+	"github.com/kevinpranata97/golang-ai-agent/internal/database"
+	"github.com/kevinpranata97/golang-ai-agent/internal/debugging"
+	"github.com/kevinpranata97/golang-ai-agent/internal/finetuning"
+	"github.com/kevinpranata97/golang-ai-agent/internal/logging"
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+	"github.com/kevinpranata97/golang-ai-agent/internal/storage"
+	"github.com/kevinpranata97/golang-ai-agent/internal/workflow"
+)
+
+// statusCheckTimeout bounds how long /status waits on a single subsystem
+const statusCheckTimeout = 5 * time.Second
+
+// server holds the dependencies shared by the HTTP handlers
+type server struct {
+	reqAnalyzer *requirements.RequirementAnalyzer
+	codeGen     *codegen.CodeGenerator
+	appTester   *apptesting.ApplicationTester
+	db          *database.DB
+	store       storage.Storage
+	engine      *workflow.Engine
+	finetuner   *finetuning.Finetuner
+	logger      logging.Logger
+	outputDir   string
+	// compileCheck makes /generate-app build generated Go apps and report
+	// whether they compile
+	compileCheck bool
+	metrics      *serverMetrics
+}
+
+// newServer creates a new server instance
+func newServer(reqAnalyzer *requirements.RequirementAnalyzer, codeGen *codegen.CodeGenerator, appTester *apptesting.ApplicationTester, db *database.DB, store storage.Storage, engine *workflow.Engine, finetuner *finetuning.Finetuner, outputDir string) *server {
+	s := &server{
+		reqAnalyzer: reqAnalyzer,
+		codeGen:     codeGen,
+		appTester:   appTester,
+		db:          db,
+		store:       store,
+		engine:      engine,
+		finetuner:   finetuner,
+		logger:      logging.Default(),
+		outputDir:   outputDir,
+		metrics:     newServerMetrics(engine),
+	}
+	if appTester != nil {
+		appTester.SetPhaseObserver(s.metrics.observePhase)
+	}
+	return s
+}
+
+// handleHealth reports whether the server is up
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// subsystemStatus is the health of a single subsystem reported by /status
+type subsystemStatus struct {
+	Status  string                 `json:"status"` // ok, not_configured, down
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// handleStatus reports the agent features and the live health of its subsystems.
+// Any subsystem that is down marks the agent as degraded with a 503 response.
+func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), statusCheckTimeout)
+	defer cancel()
+
+	subsystems := map[string]subsystemStatus{
+		"database":   s.checkDatabase(ctx),
+		"llm":        s.checkLLM(ctx),
+		"storage":    s.checkStorage(),
+		"workflows":  s.checkWorkflows(),
+		"finetuning": s.checkFinetuning(),
+	}
+
+	status := "running"
+	code := http.StatusOK
+	for _, subsystem := range subsystems {
+		if subsystem.Status == "down" {
+			status = "degraded"
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     status,
+		"agent":      "golang-ai-agent",
+		"subsystems": subsystems,
+		"features": []string{
+			"application_generation",
+			"code_testing",
+			"requirement_analysis",
+			"github_integration",
+			"fine_tuning",
+			"local_database_storage",
+		},
+	})
+}
+
+// checkDatabase pings the interaction log database
+func (s *server) checkDatabase(ctx context.Context) subsystemStatus {
+	if err := s.db.PingContext(ctx); err != nil {
+		return subsystemStatus{Status: "down", Error: err.Error()}
+	}
+	return subsystemStatus{Status: "ok"}
+}
+
+// checkLLM reports whether the LLM provider is configured and reachable.
+// Without an API key the rule-based analyzer is used, which is not a failure.
+func (s *server) checkLLM(ctx context.Context) subsystemStatus {
+	err := s.reqAnalyzer.CheckLLM(ctx)
+	switch {
+	case errors.Is(err, requirements.ErrLLMNotConfigured):
+		return subsystemStatus{Status: "not_configured"}
+	case err != nil:
+		return subsystemStatus{Status: "down", Error: err.Error()}
+	}
+	return subsystemStatus{Status: "ok"}
+}
+
+// checkStorage verifies that the project storage accepts writes
+func (s *server) checkStorage() subsystemStatus {
+	const key = "status_check"
+	if err := s.store.Store(key, map[string]string{"checked_at": time.Now().Format(time.RFC3339)}); err != nil {
+		return subsystemStatus{Status: "down", Error: err.Error()}
+	}
+	if err := s.store.Delete(key); err != nil {
+		return subsystemStatus{Status: "down", Error: err.Error()}
+	}
+	return subsystemStatus{Status: "ok"}
+}
+
+// checkWorkflows reports the workflow engine job counters
+func (s *server) checkWorkflows() subsystemStatus {
+	if s.engine == nil {
+		return subsystemStatus{Status: "not_configured"}
+	}
+	return subsystemStatus{
+		Status: "ok",
+		Details: map[string]interface{}{
+			"active_jobs": s.engine.GetActiveJobs(),
+			"total_jobs":  s.engine.GetTotalJobs(),
+		},
+	}
+}
+
+// checkFinetuning reports when fine-tuning last ran and whether it failed
+func (s *server) checkFinetuning() subsystemStatus {
+	if s.finetuner == nil {
+		return subsystemStatus{Status: "not_configured"}
+	}
+	lastRun, err := s.finetuner.LastRun()
+	if lastRun.IsZero() {
+		return subsystemStatus{Status: "ok", Details: map[string]interface{}{"last_run": nil}}
+	}
+	status := subsystemStatus{Status: "ok", Details: map[string]interface{}{"last_run": lastRun.Format(time.RFC3339)}}
+	if err != nil {
+		status.Status = "down"
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// handleGenerateApp generates an application from a description
+func (s *server) handleGenerateApp(w http.ResponseWriter, r *http.Request) {
+	requestID := newRequestID(w)
+	logger := s.requestLogger(r, requestID)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Description string `json:"description"`
+		DryRun      bool   `json:"dry_run"`
+		requirements.RequirementOverrides
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "dry_run must be true or false", http.StatusBadRequest)
+			return
+		}
+		request.DryRun = request.DryRun || dryRun
+	}
+
+	if request.Description == "" {
+		http.Error(w, "Description is required", http.StatusBadRequest)
+		return
+	}
+
+	interactionLog := database.InteractionLog{
+		ID:             requestID,
+		Timestamp:      time.Now(),
+		Endpoint:       "/generate-app",
+		RequestPayload: string(request.Description),
+		Status:         "success", // Default to success, update on error
+	}
+
+	if request.DryRun {
+		appReq, err := s.analyzeDescription(r.Context(), request.Description, request.RequirementOverrides)
+		if err != nil {
+			s.failStage(logger, interactionLog, err)
+			http.Error(w, err.Error(), errorStatus(err))
+			return
+		}
+		s.planApplication(w, appReq, requestID, interactionLog, logger.With("app_name", appReq.Name))
+		return
+	}
+
+	appReq, project, appPath, err := s.generateOne(r.Context(), request.Description, request.RequirementOverrides, nil, interactionLog, logger)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+	logger = logger.With("app_name", appReq.Name)
+
+	response := map[string]interface{}{
+		"success":    true,
+		"message":    "Application generated successfully",
+		"request_id": requestID,
+		"app": map[string]interface{}{
+			"name":       appReq.Name,
+			"type":       appReq.Type,
+			"language":   appReq.Language,
+			"framework":  appReq.Framework,
+			"entities":   len(appReq.Entities),
+			"endpoints":  len(appReq.Endpoints),
+			"output_dir": appPath,
+		},
+	}
+
+	// Check that generated Go code compiles
+	if s.compileCheck && strings.EqualFold(appReq.Language, "go") {
+		build := s.appTester.CheckBuild(appPath, appReq)
+		response["compiles"] = build.Status == "pass"
+		if build.Status != "pass" {
+			logger.Warn("Generated application does not compile", "error", build.Error)
+			response["build_output"] = build.Output
+		}
+	}
+
+	// Return success response
+	w.Header().Set("Content-Type", "application/json")
+	jsonResponse, _ := json.Marshal(response)
+	w.Write(jsonResponse)
+
+	interactionLog.ResponsePayload = string(jsonResponse)
+	interactionLog.AppName = appReq.Name
+	interactionLog.AppPath = appPath
+	if err := s.db.InsertInteractionLog(interactionLog); err != nil {
+		logger.Error("Failed to log interaction", "error", err)
+	}
+
+	s.finishProject(project, "completed", nil, nil)
+}
+
+// batchWorkers is how many descriptions of a /generate-batch request are
+// processed at once
+const batchWorkers = 4
+
+// maxBatchSize is the most descriptions a /generate-batch request may hold
+const maxBatchSize = 50
+
+// batchResult is the outcome of one description of a /generate-batch request
+type batchResult struct {
+	Index       int                    `json:"index"`
+	Description string                 `json:"description"`
+	RequestID   string                 `json:"request_id"`
+	Success     bool                   `json:"success"`
+	Error       string                 `json:"error,omitempty"`
+	Status      int                    `json:"status,omitempty"`
+	App         map[string]interface{} `json:"app,omitempty"`
+}
+
+// handleGenerateBatch generates an application from each of several
+// descriptions. The descriptions are processed concurrently, each with its own
+// interaction log and project, and one failing does not stop the others.
+func (s *server) handleGenerateBatch(w http.ResponseWriter, r *http.Request) {
+	requestID := newRequestID(w)
+	logger := s.requestLogger(r, requestID)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Descriptions []string `json:"descriptions"`
+		requirements.RequirementOverrides
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if len(request.Descriptions) == 0 {
+		http.Error(w, "Descriptions are required", http.StatusBadRequest)
+		return
+	}
+	if len(request.Descriptions) > maxBatchSize {
+		http.Error(w, fmt.Sprintf("At most %d descriptions are allowed", maxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	results := make([]batchResult, len(request.Descriptions))
+	names := &appNames{claimed: map[string]bool{}}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < batchWorkers && i < len(request.Descriptions); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				results[index] = s.generateBatchItem(r.Context(), index, request.Descriptions[index], request.RequirementOverrides, names, logger)
+			}
+		}()
+	}
+	for i := range request.Descriptions {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+	logger.Info("Batch generated", "succeeded", succeeded, "failed", len(results)-succeeded)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    succeeded == len(results),
+		"request_id": requestID,
+		"succeeded":  succeeded,
+		"failed":     len(results) - succeeded,
+		"results":    results,
+	})
+}
+
+// generateBatchItem generates the application of one description of a batch
+// under a request ID of its own
+func (s *server) generateBatchItem(ctx context.Context, index int, description string, overrides requirements.RequirementOverrides, names *appNames, logger logging.Logger) batchResult {
+	result := batchResult{
+		Index:       index,
+		Description: description,
+		RequestID:   uuid.New().String(),
+	}
+	logger = logger.With("item_request_id", result.RequestID)
+
+	interactionLog := database.InteractionLog{
+		ID:             result.RequestID,
+		Timestamp:      time.Now(),
+		Endpoint:       "/generate-batch",
+		RequestPayload: description,
+		Status:         "success", // Default to success, update on error
+	}
+	if strings.TrimSpace(description) == "" {
+		result.Error = "Description is required"
+		result.Status = http.StatusBadRequest
+		interactionLog.Status = "failure"
+		s.db.InsertInteractionLog(interactionLog)
+		return result
+	}
+	appReq, project, appPath, err := s.generateOne(ctx, description, overrides, names, interactionLog, logger)
+	if err != nil {
+		result.Error = err.Error()
+		result.Status = errorStatus(err)
+		return result
+	}
+
+	result.Success = true
+	result.App = map[string]interface{}{
+		"name":       appReq.Name,
+		"type":       appReq.Type,
+		"language":   appReq.Language,
+		"framework":  appReq.Framework,
+		"entities":   len(appReq.Entities),
+		"endpoints":  len(appReq.Endpoints),
+		"output_dir": appPath,
+	}
+	response, _ := json.Marshal(result)
+	interactionLog.ResponsePayload = string(response)
+	interactionLog.AppName = appReq.Name
+	interactionLog.AppPath = appPath
+	if err := s.db.InsertInteractionLog(interactionLog); err != nil {
+		logger.Error("Failed to log interaction", "error", err)
+	}
+	s.finishProject(project, "completed", nil, nil)
+	return result
+}
+
+// appNames are the application names claimed by the items of a batch. Each
+// item is an application of its own, so an item whose name is taken is
+// numbered rather than generated over another item's directory.
+type appNames struct {
+	mutex   sync.Mutex
+	claimed map[string]bool
+}
+
+// claim claims appReq's name, numbering it "<name> 2", "<name> 3", ... when
+// an earlier item claimed it
+func (n *appNames) claim(appReq *requirements.ApplicationRequirement) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	name := appReq.Name
+	key, _ := requirements.SanitizeAppName(name)
+	for i := 2; n.claimed[key]; i++ {
+		name = fmt.Sprintf("%s %d", appReq.Name, i)
+		key, _ = requirements.SanitizeAppName(name)
+	}
+	n.claimed[key] = true
+	appReq.Name = name
+}
+
+// stageError is a failed step of a request, reported with the message of
+// that step
+type stageError struct {
+	message string
+	err     error
+}
+
+func (e *stageError) Error() string { return e.message + ": " + e.err.Error() }
+
+func (e *stageError) Unwrap() error { return e.err }
+
+// analyzeDescription analyzes a description, applies the stack overrides and
+// validates the result. Failures are returned as a *stageError.
+func (s *server) analyzeDescription(ctx context.Context, description string, overrides requirements.RequirementOverrides) (*requirements.ApplicationRequirement, error) {
+	start := time.Now()
+	appReq, err := s.reqAnalyzer.AnalyzeRequirementsContext(ctx, description)
+	s.metrics.observeStage("analysis", start)
+	if err != nil {
+		return nil, &stageError{"Failed to analyze requirements", err}
+	}
+	if err := s.reqAnalyzer.ApplyOverrides(appReq, overrides); err != nil {
+		return nil, &stageError{"Invalid overrides", err}
+	}
+	if err := s.reqAnalyzer.ValidateRequirements(appReq); err != nil {
+		return nil, &stageError{"Invalid requirements", err}
+	}
+	return appReq, nil
+}
+
+// failStage logs a failed step of a request and records the failure in its
+// interaction log
+func (s *server) failStage(logger logging.Logger, interactionLog database.InteractionLog, err error) {
+	message := "Request failed"
+	var stage *stageError
+	if errors.As(err, &stage) {
+		message, err = stage.message, stage.err
+	}
+	logger.Error(message, "error", err)
+	interactionLog.Status = "failure"
+	s.db.InsertInteractionLog(interactionLog)
+}
+
+// generateOne runs a description through analysis and generation, the
+// pipeline shared by /generate-app and /generate-batch, claiming the
+// application's name in names when set. Failures are logged,
+// recorded in interactionLog and returned as a *stageError; on success the
+// caller completes the interaction log and the project.
+func (s *server) generateOne(ctx context.Context, description string, overrides requirements.RequirementOverrides, names *appNames, interactionLog database.InteractionLog, logger logging.Logger) (*requirements.ApplicationRequirement, *storage.ProjectData, string, error) {
+	appReq, err := s.analyzeDescription(ctx, description, overrides)
+	if err != nil {
+		s.failStage(logger, interactionLog, err)
+		return nil, nil, "", err
+	}
+	if names != nil {
+		names.claim(appReq)
+	}
+	project, appPath, err := s.generateApplication(appReq, interactionLog, logger.With("app_name", appReq.Name))
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return appReq, project, appPath, nil
+}
+
+// generateApplication generates an application into the directory chosen by
+// the collision policy, recording it as a project. Failures are logged and
+// returned as a *stageError.
+func (s *server) generateApplication(appReq *requirements.ApplicationRequirement, interactionLog database.InteractionLog, logger logging.Logger) (*storage.ProjectData, string, error) {
+	fail := func(project *storage.ProjectData, err error) (*storage.ProjectData, string, error) {
+		logger.Error("Failed to generate application", "error", err)
+		interactionLog.Status = "failure"
+		s.db.InsertInteractionLog(interactionLog)
+		if project != nil {
+			s.finishProject(project, "failed", nil, err)
+		}
+		return nil, "", &stageError{"Failed to generate application", err}
+	}
+
+	// The project is started with the directory the generator resolved,
+	// under its lock, so it records the directory actually written and a
+	// rejected collision leaves the existing application's project untouched
+	var project *storage.ProjectData
+	start := time.Now()
+	appPath, err := s.codeGen.GenerateApplicationWith(appReq, func(appDir string) {
+		project = s.startProject(appReq, appDir)
+	})
+	s.metrics.observeStage("generation", start)
+	if err != nil {
+		return fail(project, err)
+	}
+	return project, appPath, nil
+}
+
+// errorStatus returns the HTTP status of an analysis, validation or generation
+// error: 400 for requirements that cannot be generated, 409 for an existing
+// app, 502 or 504 when the LLM fails and 500 for anything else
+func errorStatus(err error) int {
+	var validationErr *requirements.ValidationError
+	var llmErr *requirements.LLMError
+	switch {
+	case errors.As(err, &validationErr),
+		errors.Is(err, requirements.ErrInvalidAppName),
+		errors.Is(err, codegen.ErrGenerationLimit):
+		return http.StatusBadRequest
+	case errors.Is(err, codegen.ErrAppExists):
+		return http.StatusConflict
+	case errors.As(err, &llmErr) && errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.As(err, &llmErr):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// planApplication answers a dry run of /generate-app with the analyzed
+// requirements and the files generation would write, without writing them
+func (s *server) planApplication(w http.ResponseWriter, appReq *requirements.ApplicationRequirement, requestID string, interactionLog database.InteractionLog, logger logging.Logger) {
+	files, err := s.codeGen.PlanApplication(appReq)
+	if err != nil {
+		logger.Error("Failed to plan application", "error", err)
+		http.Error(w, fmt.Sprintf("Failed to plan application: %v", err), errorStatus(err))
+		interactionLog.Status = "failure"
+		s.db.InsertInteractionLog(interactionLog)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jsonResponse, _ := json.Marshal(map[string]interface{}{
+		"success":      true,
+		"message":      "Dry run: no files were written",
+		"request_id":   requestID,
+		"dry_run":      true,
+		"requirements": appReq,
+		"output_dir":   s.appPath(appReq),
+		"files":        files,
+	})
+	w.Write(jsonResponse)
+
+	interactionLog.ResponsePayload = string(jsonResponse)
+	interactionLog.AppName = appReq.Name
+	if err := s.db.InsertInteractionLog(interactionLog); err != nil {
+		logger.Error("Failed to log interaction", "error", err)
+	}
+}
+
+// handleValidate analyzes and validates a description without generating or
+// planning anything, so users can see how it is parsed and refine it
+func (s *server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	requestID := newRequestID(w)
+	logger := s.requestLogger(r, requestID)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Description string `json:"description"`
+		requirements.RequirementOverrides
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if request.Description == "" {
+		http.Error(w, "Description is required", http.StatusBadRequest)
+		return
+	}
+
+	interactionLog := database.InteractionLog{
+		ID:             requestID,
+		Timestamp:      time.Now(),
+		Endpoint:       "/validate",
+		RequestPayload: request.Description,
+		Status:         "success",
+	}
+
+	appReq, err := s.reqAnalyzer.AnalyzeRequirementsContext(r.Context(), request.Description)
+	if err != nil {
+		logger.Error("Failed to analyze requirements", "error", err)
+		http.Error(w, fmt.Sprintf("Failed to analyze requirements: %v", err), errorStatus(err))
+		interactionLog.Status = "failure"
+		s.db.InsertInteractionLog(interactionLog)
+		return
+	}
+	interactionLog.AppName = appReq.Name
+
+	if err := s.reqAnalyzer.ApplyOverrides(appReq, request.RequirementOverrides); err != nil {
+		logger.Error("Invalid overrides", "error", err)
+		http.Error(w, fmt.Sprintf("Invalid overrides: %v", err), errorStatus(err))
+		interactionLog.Status = "failure"
+		s.db.InsertInteractionLog(interactionLog)
+		return
+	}
+
+	status := http.StatusOK
+	response := map[string]interface{}{
+		"success":      true,
+		"valid":        true,
+		"request_id":   requestID,
+		"requirements": appReq,
+	}
+	if err := s.reqAnalyzer.ValidateRequirements(appReq); err != nil {
+		logger.Info("Invalid requirements", "app_name", appReq.Name, "error", err)
+		status = http.StatusBadRequest
+		response["success"] = false
+		response["valid"] = false
+		response["error"] = fmt.Sprintf("Invalid requirements: %v", err)
+		interactionLog.Status = "failure"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	jsonResponse, _ := json.Marshal(response)
+	w.Write(jsonResponse)
+
+	interactionLog.ResponsePayload = string(jsonResponse)
+	if err := s.db.InsertInteractionLog(interactionLog); err != nil {
+		logger.Error("Failed to log interaction", "error", err)
+	}
+}
+
+// handleTestApp tests a previously generated application
+func (s *server) handleTestApp(w http.ResponseWriter, r *http.Request) {
+	requestID := newRequestID(w)
+	logger := s.requestLogger(r, requestID)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		AppPath  string `json:"app_path"`
+		Language string `json:"language"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if request.AppPath == "" {
+		http.Error(w, "App path is required", http.StatusBadRequest)
+		return
+	}
+
+	interactionLog := database.InteractionLog{
+		ID:             requestID,
+		Timestamp:      time.Now(),
+		Endpoint:       "/test-app",
+		RequestPayload: string(request.AppPath),
+		AppPath:        request.AppPath,
+		Status:         "success", // Default to success, update on error
+	}
+
+	appPath, err := s.resolveAppPath(request.AppPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid app_path: %v", err), http.StatusBadRequest)
+		interactionLog.Status = "failure"
+		s.db.InsertInteractionLog(interactionLog)
+		return
+	}
+
+	// Check if app path exists
+	if _, err := os.Stat(appPath); os.IsNotExist(err) {
+		http.Error(w, "Application path does not exist", http.StatusNotFound)
+		interactionLog.Status = "failure"
+		s.db.InsertInteractionLog(interactionLog)
+		return
+	}
+
+	// Load the requirements saved during generation. Apps generated before they
+	// were saved fall back to a basic API requirement whose language is left
+	// empty unless requested so the tester detects it from the files on disk.
+	appReq, err := requirements.LoadFromFile(filepath.Join(appPath, requirements.RequirementsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		appReq = &requirements.ApplicationRequirement{
+			Name: filepath.Base(appPath),
+			Type: "api", // Default assumption
+		}
+	} else if err != nil {
+		logger.Error("Failed to load requirements", "error", err)
+		http.Error(w, fmt.Sprintf("Failed to load requirements: %v", err), http.StatusInternalServerError)
+		interactionLog.Status = "failure"
+		s.db.InsertInteractionLog(interactionLog)
+		return
+	}
+	if request.Language != "" {
+		appReq.Language = request.Language
+	}
+	logger = logger.With("app_name", appReq.Name)
+
+	// Run tests
+	start := time.Now()
+	testSuite, err := s.appTester.TestApplication(appPath, appReq)
+	s.metrics.observeStage("test", start)
+	if err != nil {
+		logger.Error("Failed to test application", "error", err)
+		http.Error(w, fmt.Sprintf("Failed to test application: %v", err), http.StatusInternalServerError)
+		interactionLog.Status = "failure"
+		s.db.InsertInteractionLog(interactionLog)
+		return
+	}
+
+	// Save test results
+	resultsPath := filepath.Join(appPath, apptesting.TestResultsFile)
+	if err := s.appTester.SaveTestResults(testSuite, resultsPath); err != nil {
+		logger.Error("Failed to save test results", "error", err)
+	}
+	junitPath := filepath.Join(appPath, apptesting.JUnitResultsFile)
+	if err := s.appTester.SaveTestResultsJUnit(testSuite, junitPath); err != nil {
+		logger.Error("Failed to save JUnit report", "error", err)
+	}
+
+	// Return test results
+	w.Header().Set("Content-Type", "application/json")
+	jsonResponse, _ := json.Marshal(map[string]interface{}{
+		"success":      true,
+		"message":      "Application testing completed",
+		"request_id":   requestID,
+		"test_suite":   testSuite,
+		"results_file": resultsPath,
+		"junit_file":   junitPath,
+	})
+	w.Write(jsonResponse)
+
+	interactionLog.ResponsePayload = string(jsonResponse)
+	// Assuming testSuite has an OverallStatus field
+	if testSuite.OverallStatus == "failure" {
+		interactionLog.Status = "failure"
+	}
+	// Convert testSuite to JSON string for TestResultsJSON
+	testSuiteJSON, _ := json.Marshal(testSuite)
+	interactionLog.TestResultsJSON = string(testSuiteJSON)
+	if err := s.db.InsertInteractionLog(interactionLog); err != nil {
+		logger.Error("Failed to log interaction", "error", err)
+	}
+}
+
+// handleDebug runs the debugger's static analysis over a generated application
+func (s *server) handleDebug(w http.ResponseWriter, r *http.Request) {
+	requestID := newRequestID(w)
+	logger := s.requestLogger(r, requestID)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		AppPath string `json:"app_path"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if request.AppPath == "" {
+		http.Error(w, "App path is required", http.StatusBadRequest)
+		return
+	}
+
+	interactionLog := database.InteractionLog{
+		ID:             requestID,
+		Timestamp:      time.Now(),
+		Endpoint:       "/debug",
+		RequestPayload: request.AppPath,
+		AppName:        filepath.Base(request.AppPath),
+		AppPath:        request.AppPath,
+		Status:         "success",
+	}
+
+	appPath, err := s.resolveAppPath(request.AppPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid app_path: %v", err), http.StatusBadRequest)
+		interactionLog.Status = "failure"
+		s.db.InsertInteractionLog(interactionLog)
+		return
+	}
+
+	// Check if app path exists
+	if info, err := os.Stat(appPath); err != nil || !info.IsDir() {
+		http.Error(w, "Application path does not exist", http.StatusNotFound)
+		interactionLog.Status = "failure"
+		s.db.InsertInteractionLog(interactionLog)
+		return
+	}
+
+	result := debugging.NewDebugger(appPath).AnalyzeProject()
+
+	w.Header().Set("Content-Type", "application/json")
+	jsonResponse, _ := json.Marshal(result)
+	w.Write(jsonResponse)
+
+	interactionLog.ResponsePayload = string(jsonResponse)
+	interactionLog.AnalysisResultsJSON = string(jsonResponse)
+	if !result.Success {
+		interactionLog.Status = "failure"
+	}
+	if err := s.db.InsertInteractionLog(interactionLog); err != nil {
+		logger.Error("Failed to log interaction", "error", err)
+	}
+}
+
+// handleRegenerate rewrites a single component of a generated Go application
+// from its saved requirements, keeping manual edits elsewhere
+func (s *server) handleRegenerate(w http.ResponseWriter, r *http.Request) {
+	requestID := newRequestID(w)
+	logger := s.requestLogger(r, requestID)
+
+	if r.Method != http.MethodPost {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var request struct {
+		AppPath   string `json:"app_path"`
+		Component string `json:"component"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if request.AppPath == "" || request.Component == "" {
+		writeJSONError(w, http.StatusBadRequest, "app_path and component are required")
+		return
+	}
+
+	interactionLog := database.InteractionLog{
+		ID:             requestID,
+		Timestamp:      time.Now(),
+		Endpoint:       "/regenerate",
+		RequestPayload: request.AppPath + " " + request.Component,
+		AppName:        filepath.Base(request.AppPath),
+		AppPath:        request.AppPath,
+		Status:         "success",
+	}
+	fail := func(status int, message string) {
+		writeJSONError(w, status, message)
+		interactionLog.Status = "failure"
+		s.db.InsertInteractionLog(interactionLog)
+	}
+
+	appPath, err := s.resolveAppPath(request.AppPath)
+	if err != nil {
+		fail(http.StatusBadRequest, fmt.Sprintf("Invalid app_path: %v", err))
+		return
+	}
+
+	if info, err := os.Stat(appPath); err != nil || !info.IsDir() {
+		fail(http.StatusNotFound, "Application path does not exist")
+		return
+	}
+
+	// Regeneration follows requirements.json, which may have been edited since
+	appReq, err := requirements.LoadFromFile(filepath.Join(appPath, requirements.RequirementsFile))
+	if err != nil {
+		fail(http.StatusBadRequest, fmt.Sprintf("Failed to load requirements: %v", err))
+		return
+	}
+	logger = logger.With("app_name", appReq.Name, "component", request.Component)
+
+	if err := s.codeGen.RegenerateComponent(appPath, appReq, request.Component); err != nil {
+		logger.Error("Failed to regenerate component", "error", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, codegen.ErrUnknownComponent) || errors.Is(err, codegen.ErrNotGoApplication) || errors.Is(err, codegen.ErrGenerationLimit) {
+			status = http.StatusBadRequest
+		}
+		fail(status, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jsonResponse, _ := json.Marshal(map[string]interface{}{
+		"success":    true,
+		"message":    fmt.Sprintf("Regenerated %s", request.Component),
+		"request_id": requestID,
+		"app_path":   request.AppPath,
+		"component":  request.Component,
+	})
+	w.Write(jsonResponse)
+
+	interactionLog.ResponsePayload = string(jsonResponse)
+	if err := s.db.InsertInteractionLog(interactionLog); err != nil {
+		logger.Error("Failed to log interaction", "error", err)
+	}
+}
+
+// handleRunWorkflow runs a registered workflow for POST /workflows/{name}/run
+// and answers with its result once it finishes. The optional body gives the
+// repository context the steps run against.
+func (s *server) handleRunWorkflow(w http.ResponseWriter, r *http.Request) {
+	requestID := newRequestID(w)
+	logger := s.requestLogger(r, requestID)
+
+	name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/workflows/"), "/run")
+	if !strings.HasSuffix(r.URL.Path, "/run") || name == "" || strings.Contains(name, "/") {
+		writeJSONError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var request struct {
+		Repository string `json:"repository"`
+		CloneURL   string `json:"clone_url"`
+		Ref        string `json:"ref"`
+		Commits    []struct {
+			ID      string `json:"id"`
+			Message string `json:"message"`
+			Author  string `json:"author"`
+		} `json:"commits"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		writeJSONError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if !s.engine.HasWorkflow(name) {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("workflow not found: %s", name))
+		return
+	}
+
+	ctx := workflow.Context{Repository: request.Repository, CloneURL: request.CloneURL, Ref: request.Ref}
+	for _, commit := range request.Commits {
+		ctx.Commits = append(ctx.Commits, workflow.Commit{ID: commit.ID, Message: commit.Message, Author: commit.Author})
+	}
+
+	logger = logger.With("workflow", name)
+	result := s.engine.ExecuteWorkflow(name, ctx)
+	if !result.Success {
+		logger.Warn("Workflow failed", "error", result.Error)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	jsonResponse, _ := json.Marshal(map[string]interface{}{
+		"success":    result.Success,
+		"request_id": requestID,
+		"workflow":   name,
+		"result":     result,
+	})
+	w.Write(jsonResponse)
+
+	interactionLog := database.InteractionLog{
+		ID:              requestID,
+		Timestamp:       time.Now(),
+		Endpoint:        "/workflows/run",
+		RequestPayload:  name,
+		ResponsePayload: string(jsonResponse),
+		Status:          "success",
+	}
+	if !result.Success {
+		interactionLog.Status = "failure"
+	}
+	if err := s.db.InsertInteractionLog(interactionLog); err != nil {
+		logger.Error("Failed to log interaction", "error", err)
+	}
+}
+
+// handleGenerateAndTest generates an application and immediately tests it
+func (s *server) handleGenerateAndTest(w http.ResponseWriter, r *http.Request) {
+	requestID := newRequestID(w)
+	logger := s.requestLogger(r, requestID)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Description string `json:"description"`
+		requirements.RequirementOverrides
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if request.Description == "" {
+		http.Error(w, "Description is required", http.StatusBadRequest)
+		return
+	}
+
+	response, err := s.generateAndTest(r.Context(), request.Description, request.RequirementOverrides, requestID, logger, nil)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
+
+// handleGenerateAndTestStream runs /generate-and-test for the description and
+// overrides in the query as a server-sent event stream: a progress event as
+// each step finishes, then a result event with the response or an error event
+func (s *server) handleGenerateAndTestStream(w http.ResponseWriter, r *http.Request) {
+	requestID := newRequestID(w)
+	logger := s.requestLogger(r, requestID)
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	description := query.Get("description")
+	if description == "" {
+		http.Error(w, "Description is required", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, data []byte) {
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		flusher.Flush()
+	}
+
+	overrides := requirements.RequirementOverrides{
+		Language:     query.Get("language"),
+		Framework:    query.Get("framework"),
+		Database:     query.Get("database"),
+		Type:         query.Get("type"),
+		AuthStrategy: query.Get("auth_strategy"),
+	}
+	response, err := s.generateAndTest(r.Context(), description, overrides, requestID, logger, func(event progressEvent) {
+		data, _ := json.Marshal(event)
+		send("progress", data)
+	})
+	if err != nil {
+		data, _ := json.Marshal(map[string]interface{}{"error": err.Error(), "status": errorStatus(err)})
+		send("error", data)
+		return
+	}
+	send("result", response)
+}
+
+// progressEvent is a finished step of generate-and-test: analyzing,
+// generating, building or testing:<phase> for each later test phase
+type progressEvent struct {
+	Phase    string `json:"phase"`
+	Status   string `json:"status"` // pass, fail, skip
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// testPhaseEvent returns the progress event of a finished test phase
+func testPhaseEvent(kind string, result apptesting.TestResult) progressEvent {
+	phase := "testing:" + kind
+	if kind == "build" {
+		phase = "building"
+	}
+	return progressEvent{Phase: phase, Status: result.Status, Duration: result.Duration.String(), Error: result.Error}
+}
+
+// generateAndTest analyzes a description, applies the overrides, generates
+// the application and tests it, passing each finished step to progress if set. It returns the JSON
+// response of /generate-and-test; a failed step is returned as a *stageError.
+func (s *server) generateAndTest(ctx context.Context, description string, overrides requirements.RequirementOverrides, requestID string, logger logging.Logger, progress func(progressEvent)) ([]byte, error) {
+	if progress == nil {
+		progress = func(progressEvent) {}
+	}
+
+	interactionLog := database.InteractionLog{
+		ID:             requestID,
+		Timestamp:      time.Now(),
+		Endpoint:       "/generate-and-test",
+		RequestPayload: description,
+		Status:         "success", // Default to success, update on error
+	}
+
+	// Analyze requirements
+	start := time.Now()
+	appReq, err := s.analyzeDescription(ctx, description, overrides)
+	if err != nil {
+		s.failStage(logger, interactionLog, err)
+		return nil, err
+	}
+	logger = logger.With("app_name", appReq.Name)
+	progress(progressEvent{Phase: "analyzing", Status: "pass", Duration: time.Since(start).String()})
+
+	// Generate application
+	start = time.Now()
+	project, appPath, err := s.generateApplication(appReq, interactionLog, logger)
+	if err != nil {
+		return nil, err
+	}
+	progress(progressEvent{Phase: "generating", Status: "pass", Duration: time.Since(start).String()})
+
+	// Test the generated application
+	project.Status = "testing"
+	s.updateProject(project)
+	start = time.Now()
+	testSuite, testErr := s.appTester.TestApplicationProgress(appPath, appReq, func(kind string, result apptesting.TestResult) {
+		progress(testPhaseEvent(kind, result))
+	})
+	s.metrics.observeStage("test", start)
+	if testErr != nil {
+		logger.Error("Failed to test application", "error", testErr)
+		// Don't fail the entire request if testing fails
+	}
+
+	// Save test results if testing was successful
+	var resultsPath, junitPath string
+	if testSuite != nil {
+		resultsPath = filepath.Join(appPath, apptesting.TestResultsFile)
+		if err := s.appTester.SaveTestResults(testSuite, resultsPath); err != nil {
+			logger.Error("Failed to save test results", "error", err)
+		}
+		junitPath = filepath.Join(appPath, apptesting.JUnitResultsFile)
+		if err := s.appTester.SaveTestResultsJUnit(testSuite, junitPath); err != nil {
+			logger.Error("Failed to save JUnit report", "error", err)
+		}
+	}
+
+	responseMap := map[string]interface{}{
+		"success":    true,
+		"message":    "Application generated and tested successfully",
+		"request_id": requestID,
+		"app": map[string]interface{}{
+			"name":       appReq.Name,
+			"type":       appReq.Type,
+			"language":   appReq.Language,
+			"framework":  appReq.Framework,
+			"entities":   len(appReq.Entities),
+			"endpoints":  len(appReq.Endpoints),
+			"output_dir": appPath,
+		},
+	}
+
+	if testSuite != nil {
+		responseMap["test_results"] = map[string]interface{}{
+			"total_tests":   testSuite.TotalTests,
+			"passed_tests":  testSuite.PassedTests,
+			"failed_tests":  testSuite.FailedTests,
+			"skipped_tests": testSuite.SkippedTests,
+			"coverage":      testSuite.Coverage,
+			"duration":      testSuite.Duration.String(),
+			"results_file":  resultsPath,
+			"junit_file":    junitPath,
+			"summary":       testSuite.Summary,
+		}
+	}
+	jsonResponse, _ := json.Marshal(responseMap)
+
+	interactionLog.ResponsePayload = string(jsonResponse)
+	interactionLog.AppName = appReq.Name
+	interactionLog.AppPath = appPath
+	projectStatus := "completed"
+	if testErr != nil || (testSuite != nil && testSuite.OverallStatus == "failure") {
+		projectStatus = "failed"
+	}
+	s.finishProject(project, projectStatus, testSuite, testErr)
+	if err := s.db.InsertInteractionLog(interactionLog); err != nil {
+		logger.Error("Failed to log interaction", "error", err)
+	}
+
+	// The test run is logged as a child of the generation
+	testLog := database.InteractionLog{
+		ID:             uuid.New().String(),
+		ParentID:       requestID,
+		Timestamp:      time.Now(),
+		Endpoint:       "/generate-and-test",
+		RequestPayload: appPath,
+		AppName:        appReq.Name,
+		AppPath:        appPath,
+		Status:         "success",
+	}
+	if testErr != nil {
+		testLog.Status = "failure"
+	}
+	if testSuite != nil {
+		// Convert testSuite to JSON string for TestResultsJSON
+		testSuiteJSON, _ := json.Marshal(testSuite)
+		testLog.TestResultsJSON = string(testSuiteJSON)
+		if testSuite.OverallStatus == "failure" {
+			testLog.Status = "failure"
+		}
+	}
+	if err := s.db.InsertInteractionLog(testLog); err != nil {
+		logger.Error("Failed to log interaction", "error", err)
+	}
+
+	return jsonResponse, nil
+}
+
+// handleSuggestionStatus updates the status of an analysis suggestion
+func (s *server) handleSuggestionStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		ProjectID    string `json:"project_id"`
+		SuggestionID string `json:"suggestion_id"`
+		Status       string `json:"status"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if request.ProjectID == "" || request.SuggestionID == "" {
+		http.Error(w, "project_id and suggestion_id are required", http.StatusBadRequest)
+		return
+	}
+
+	if !storage.IsValidSuggestionStatus(request.Status) {
+		http.Error(w, fmt.Sprintf("Invalid status: %s", request.Status), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.UpdateSuggestionStatus(request.ProjectID, request.SuggestionID, request.Status); err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		http.Error(w, fmt.Sprintf("Failed to update suggestion: %v", err), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":       true,
+		"project_id":    request.ProjectID,
+		"suggestion_id": request.SuggestionID,
+		"status":        request.Status,
+	})
+}
+
+// projectName returns the directory name an application is generated into.
+// Handlers validate the requirements, which rejects names that cannot be
+// sanitized, before asking for it.
+func projectName(appReq *requirements.ApplicationRequirement) string {
+	name, _ := requirements.SanitizeAppName(appReq.Name)
+	return name
+}
+
+// appPath returns where an application is generated
+func (s *server) appPath(appReq *requirements.ApplicationRequirement) string {
+	return filepath.Join(s.outputDir, projectName(appReq))
+}
+
+// resolveAppPath resolves an application path sent by a client, following
+// symlinks, and rejects paths outside the output directory so requests cannot
+// write elsewhere on disk
+func (s *server) resolveAppPath(appPath string) (string, error) {
+	outputDir, err := resolvePath(s.outputDir)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := resolvePath(appPath)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(outputDir, resolved)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%q is outside the output directory", appPath)
+	}
+	return resolved, nil
+}
+
+// resolvePath returns the absolute path of path with symlinks followed as far
+// as it exists
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved, nil
+	}
+	return abs, nil
+}
+
+// startProject records an application generating into appPath in project
+// storage, keyed by its directory name so regenerating an app replaces its record
+func (s *server) startProject(appReq *requirements.ApplicationRequirement, appPath string) *storage.ProjectData {
+	project := &storage.ProjectData{
+		ID:           filepath.Base(appPath),
+		Name:         appReq.Name,
+		Description:  appReq.Description,
+		Requirements: appReq,
+		GeneratedAt:  time.Now(),
+		AppPath:      appPath,
+		Status:       "generating",
+		Metadata:     map[string]interface{}{},
+	}
+	if err := s.store.SaveProject(project); err != nil {
+		s.logger.Error("Failed to save project", "app_name", project.Name, "error", err)
+	}
+	return project
+}
+
+// finishProject records the final status, test results and any error of a project
+func (s *server) finishProject(project *storage.ProjectData, status string, testSuite *apptesting.TestSuite, err error) {
+	project.Status = status
+	project.TestResults = testSuite
+	if err != nil {
+		project.Metadata["error"] = err.Error()
+	}
+	s.updateProject(project)
+}
+
+// updateProject saves a project's current state, logging failures
+func (s *server) updateProject(project *storage.ProjectData) {
+	if err := s.store.UpdateProject(project); err != nil {
+		s.logger.Error("Failed to update project", "app_name", project.Name, "error", err)
+	}
+}
+
+// requestLogger returns the server logger annotated with the request's
+// endpoint and ID
+func (s *server) requestLogger(r *http.Request, requestID string) logging.Logger {
+	return s.logger.With("endpoint", r.URL.Path, "request_id", requestID)
+}
+
+// newRequestID generates the ID of an incoming request, which is also the ID of
+// its interaction log, and returns it to the client in the X-Request-ID header
+func newRequestID(w http.ResponseWriter) string {
+	requestID := uuid.New().String()
+	w.Header().Set("X-Request-ID", requestID)
+	return requestID
+}
+
+// writeJSONError writes a JSON error body with the given status
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// defaultProjectsLimit is the page size of /projects when no limit is given
+const defaultProjectsLimit = 20
+
+// handleListProjects lists generated projects, newest first, paginated with ?limit=&offset=
+func (s *server) handleListProjects(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	limit, err := queryInt(r, "limit", defaultProjectsLimit)
+	if err != nil || limit < 1 {
+		writeJSONError(w, http.StatusBadRequest, "limit must be a positive integer")
+		return
+	}
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil || offset < 0 {
+		writeJSONError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+
+	projects, err := s.store.ListProjects()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to list projects: %v", err))
+		return
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].GeneratedAt.After(projects[j].GeneratedAt)
+	})
+
+	page := []map[string]interface{}{}
+	for i := offset; i < len(projects) && i < offset+limit; i++ {
+		project := projects[i]
+		summary := map[string]interface{}{
+			"name":         project.ID,
+			"path":         project.AppPath,
+			"status":       project.Status,
+			"generated_at": project.GeneratedAt,
+		}
+		if project.Requirements != nil {
+			summary["type"] = project.Requirements.Type
+			summary["language"] = project.Requirements.Language
+		}
+		page = append(page, summary)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"projects": page,
+		"total":    len(projects),
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// handleGetProject returns a project's saved requirements and its latest test results
+func (s *server) handleGetProject(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/projects/")
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("project not found: %s", name))
+		return
+	}
+
+	project, err := s.store.GetProject(name)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "not found") {
+			status = http.StatusNotFound
+		}
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	appReq, err := requirements.LoadFromFile(filepath.Join(project.AppPath, requirements.RequirementsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		appReq = project.Requirements
+	} else if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	testSuite, err := apptesting.LoadTestResults(filepath.Join(project.AppPath, apptesting.TestResultsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		testSuite = project.TestResults
+	} else if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"name":         project.ID,
+		"path":         project.AppPath,
+		"status":       project.Status,
+		"generated_at": project.GeneratedAt,
+		"requirements": appReq,
+		"test_results": testSuite,
+	})
+}
+
+// handleStats reports project statistics; ?language= restricts the popularity
+// maps and averages to projects in that language
+func (s *server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	stats, err := s.store.GetProjectStats(r.URL.Query().Get("language"))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to compute stats: %v", err))
+		return
+	}
+	for i, project := range stats.RecentActivity {
+		stats.RecentActivity[i] = compactProject(project)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// compactProject strips the bulky parts of a project for summaries: requirements
+// are reduced to the stack and test results lose per-test output and details
+func compactProject(project storage.ProjectData) storage.ProjectData {
+	if project.Requirements != nil {
+		project.Requirements = &requirements.ApplicationRequirement{
+			Name:      project.Requirements.Name,
+			Type:      project.Requirements.Type,
+			Language:  project.Requirements.Language,
+			Framework: project.Requirements.Framework,
+			Database:  project.Requirements.Database,
+		}
+	}
+	if project.TestResults != nil {
+		suite := *project.TestResults
+		suite.Results = make([]apptesting.TestResult, len(project.TestResults.Results))
+		for i, result := range project.TestResults.Results {
+			result.Output = ""
+			result.Details = nil
+			suite.Results[i] = result
+		}
+		project.TestResults = &suite
+	}
+	project.Iterations = nil
+	return project
+}
+
+// defaultLogsLimit is the page size of /logs when no limit is given
+const defaultLogsLimit = 50
+
+// handleListLogs returns interaction logs, newest first, filtered by
+// ?endpoint=, ?status= and an RFC 3339 ?since=/?until= window and paginated
+// with ?limit=&offset=
+func (s *server) handleListLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := database.LogFilter{
+		Endpoint: query.Get("endpoint"),
+		Status:   query.Get("status"),
+	}
+
+	var err error
+	filter.Limit, err = queryInt(r, "limit", defaultLogsLimit)
+	if err != nil || filter.Limit < 1 {
+		writeJSONError(w, http.StatusBadRequest, "limit must be a positive integer")
+		return
+	}
+	filter.Offset, err = queryInt(r, "offset", 0)
+	if err != nil || filter.Offset < 0 {
+		writeJSONError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+	for name, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		if *target, err = time.Parse(time.RFC3339, value); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("%s must be an RFC 3339 timestamp", name))
+			return
+		}
+	}
+
+	logs, err := s.db.QueryInteractionLogs(filter)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to query logs: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"logs":   logs,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// queryInt parses an integer query parameter, returning def when it is absent
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	return strconv.Atoi(value)
+}
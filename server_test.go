@@ -0,0 +1,1132 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevinpranata97/golang-ai-agent/internal/apptesting"
+	"github.com/kevinpranata97/golang-ai-agent/internal/codegen"
+	"github.com/kevinpranata97/golang-ai-agent/internal/database"
+	"github.com/kevinpranata97/golang-ai-agent/internal/finetuning"
+	"github.com/kevinpranata97/golang-ai-agent/internal/requirements"
+	"github.com/kevinpranata97/golang-ai-agent/internal/storage"
+	"github.com/kevinpranata97/golang-ai-agent/internal/workflow"
+)
+
+// newTestServer creates a server writing generated apps and data to temp dirs
+func newTestServer(t *testing.T) *server {
+	t.Helper()
+	outputDir := t.TempDir()
+	db, err := database.NewDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return newServer(
+		requirements.NewRequirementAnalyzer(nil),
+		codegen.NewCodeGenerator(outputDir),
+		apptesting.NewApplicationTester(outputDir),
+		db,
+		storage.NewFileStorage(t.TempDir()),
+		workflow.NewEngine(nil),
+		finetuning.NewFinetuner(db),
+		outputDir,
+	)
+}
+
+// postJSON sends a JSON POST request to handler and returns the recorder
+func postJSON(t *testing.T, handler http.HandlerFunc, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+// generatedApp decodes the "app" object from a generation response
+func generatedApp(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return response["app"].(map[string]interface{})
+}
+
+func TestGenerateAppOverrides(t *testing.T) {
+	srv := newTestServer(t)
+
+	// Without overrides the rule-based analyzer picks Go
+	app := generatedApp(t, postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+	}))
+	if app["language"] != "go" {
+		t.Errorf("Expected inferred language go, got %v", app["language"])
+	}
+
+	// An explicit language override changes the generated stack
+	app = generatedApp(t, postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+		"language":    "javascript",
+		"database":    "postgresql",
+	}))
+	if app["language"] != "javascript" || app["framework"] != "express" {
+		t.Errorf("Expected javascript/express, got %v/%v", app["language"], app["framework"])
+	}
+	outputDir := app["output_dir"].(string)
+	if _, err := os.Stat(filepath.Join(outputDir, "package.json")); err != nil {
+		t.Errorf("Expected a Node.js app with package.json: %v", err)
+	}
+
+	// Overriding a description that defaults to Go dispatches to the Python generator
+	app = generatedApp(t, postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+		"language":    "python",
+		"framework":   "flask",
+	}))
+	if app["language"] != "python" || app["framework"] != "flask" {
+		t.Errorf("Expected python/flask, got %v/%v", app["language"], app["framework"])
+	}
+	outputDir = app["output_dir"].(string)
+	for _, name := range []string{"app.py", "requirements.txt"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("Expected a Python app with %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "main.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected no Go sources in the Python app, got %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(outputDir, "requirements.txt")); err != nil || !strings.Contains(strings.ToLower(string(content)), "flask") {
+		t.Errorf("Expected flask in requirements.txt, got %q (%v)", content, err)
+	}
+
+	// /validate applies the same overrides to the analyzed requirements
+	rec := postJSON(t, srv.handleValidate, "/validate", map[string]string{
+		"description": "user management api",
+		"language":    "python",
+		"framework":   "flask",
+	})
+	var validation struct {
+		Valid        bool                                `json:"valid"`
+		Requirements requirements.ApplicationRequirement `json:"requirements"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &validation); err != nil {
+		t.Fatalf("Failed to decode validation: %v", err)
+	}
+	if !validation.Valid || validation.Requirements.Language != "python" || validation.Requirements.Framework != "flask" {
+		t.Errorf("Expected valid python/flask requirements, got %+v", validation)
+	}
+}
+
+func TestGenerateAppInvalidOverride(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+		"language":    "cobol",
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unsupported language, got %d", rec.Code)
+	}
+
+	rec = postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+		"framework":   "django",
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for framework not matching language, got %d", rec.Code)
+	}
+}
+
+func TestSuggestionStatus(t *testing.T) {
+	srv := newTestServer(t)
+
+	suggestion := storage.ImprovementSuggestion{Type: "quality", Description: "Add tests"}
+	suggestion.ID = storage.SuggestionID(suggestion)
+	if err := srv.store.SaveAnalysis(&storage.AnalysisData{
+		ProjectID:   "project-1",
+		Timestamp:   time.Now(),
+		Suggestions: []storage.ImprovementSuggestion{suggestion},
+	}); err != nil {
+		t.Fatalf("Failed to save analysis: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		suggestionID string
+		status       string
+		expected     int
+	}{
+		{"dismiss", suggestion.ID, "dismissed", http.StatusOK},
+		{"unknown status", suggestion.ID, "ignored", http.StatusBadRequest},
+		{"unknown suggestion", "missing", "done", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		body, _ := json.Marshal(map[string]string{
+			"project_id":    "project-1",
+			"suggestion_id": tt.suggestionID,
+			"status":        tt.status,
+		})
+		req := httptest.NewRequest(http.MethodPatch, "/suggestions", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		srv.handleSuggestionStatus(rec, req)
+
+		if rec.Code != tt.expected {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.expected, rec.Code, rec.Body.String())
+		}
+	}
+
+	statuses, err := srv.store.GetSuggestionStatuses("project-1")
+	if err != nil {
+		t.Fatalf("Failed to load statuses: %v", err)
+	}
+	if statuses[suggestion.ID] != "dismissed" {
+		t.Errorf("Expected suggestion to be dismissed, got %q", statuses[suggestion.ID])
+	}
+}
+
+func TestGenerateAppOverLimit(t *testing.T) {
+	srv := newTestServer(t)
+	srv.reqAnalyzer.SetLimits(requirements.RequirementLimits{MaxEntities: 1})
+
+	rec := postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]interface{}{
+		"description": "Create a user and product API",
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	entries, err := os.ReadDir(srv.outputDir)
+	if err != nil {
+		t.Fatalf("Failed to read output dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no files to be written, found %d entries", len(entries))
+	}
+}
+
+// getStatus calls /status and decodes the response
+func getStatus(t *testing.T, srv *server) (int, map[string]interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	srv.handleStatus(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return rec.Code, response
+}
+
+func TestStatusReportsSubsystems(t *testing.T) {
+	srv := newTestServer(t)
+
+	code, response := getStatus(t, srv)
+	if code != http.StatusOK || response["status"] != "running" {
+		t.Fatalf("Expected healthy status, got %d %v", code, response)
+	}
+	subsystems := response["subsystems"].(map[string]interface{})
+	for _, name := range []string{"database", "llm", "storage", "workflows", "finetuning"} {
+		if _, ok := subsystems[name]; !ok {
+			t.Errorf("Expected subsystem %s in status", name)
+		}
+	}
+	if llm := subsystems["llm"].(map[string]interface{}); llm["status"] != "not_configured" {
+		t.Errorf("Expected llm not_configured without an API key, got %v", llm["status"])
+	}
+}
+
+func TestStatusDegradedWhenDatabaseDown(t *testing.T) {
+	srv := newTestServer(t)
+	srv.db.Close()
+
+	code, response := getStatus(t, srv)
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", code)
+	}
+	if response["status"] != "degraded" {
+		t.Errorf("Expected degraded status, got %v", response["status"])
+	}
+	database := response["subsystems"].(map[string]interface{})["database"].(map[string]interface{})
+	if database["status"] != "down" || database["error"] == "" {
+		t.Errorf("Expected database down with an error, got %v", database)
+	}
+}
+
+// getJSON sends a GET request to handler and decodes the JSON response
+func getJSON(t *testing.T, handler http.HandlerFunc, path string) (int, map[string]interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, response
+}
+
+func TestProjectsEndpoints(t *testing.T) {
+	srv := newTestServer(t)
+
+	// An older project recorded directly, then one generated through the handler
+	older := &storage.ProjectData{
+		ID:           "older-app",
+		Name:         "Older App",
+		Requirements: &requirements.ApplicationRequirement{Type: "cli", Language: "go"},
+		GeneratedAt:  time.Now().Add(-time.Hour),
+		AppPath:      filepath.Join(srv.outputDir, "older-app"),
+		Status:       "completed",
+	}
+	if err := srv.store.SaveProject(older); err != nil {
+		t.Fatalf("Failed to save project: %v", err)
+	}
+	generatedApp(t, postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+	}))
+
+	code, response := getJSON(t, srv.handleListProjects, "/projects")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+	projects := response["projects"].([]interface{})
+	if response["total"] != float64(2) || len(projects) != 2 {
+		t.Fatalf("Expected both projects to be listed, got %v", response)
+	}
+	first := projects[0].(map[string]interface{})
+	for _, key := range []string{"name", "type", "language", "path", "generated_at"} {
+		if first[key] == nil || first[key] == "" {
+			t.Errorf("Expected project summary to include %s, got %v", key, first)
+		}
+	}
+
+	_, page := getJSON(t, srv.handleListProjects, "/projects?limit=1&offset=1")
+	if got := page["projects"].([]interface{}); len(got) != 1 || got[0].(map[string]interface{})["name"] != "older-app" {
+		t.Errorf("Expected the older project alone on the second page, got %v", got)
+	}
+	if code, _ := getJSON(t, srv.handleListProjects, "/projects?limit=abc"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid limit, got %d", code)
+	}
+
+	name := first["name"].(string)
+	code, project := getJSON(t, srv.handleGetProject, "/projects/"+name)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, project)
+	}
+	if appReq, ok := project["requirements"].(map[string]interface{}); !ok || appReq["language"] != first["language"] {
+		t.Errorf("Expected saved requirements, got %v", project["requirements"])
+	}
+
+	code, missing := getJSON(t, srv.handleGetProject, "/projects/does-not-exist")
+	if code != http.StatusNotFound || missing["error"] == nil {
+		t.Errorf("Expected 404 with a JSON error, got %d: %v", code, missing)
+	}
+}
+
+func TestGenerateAppCollisionPolicy(t *testing.T) {
+	srv := newTestServer(t)
+	body := map[string]string{"description": "user management api"}
+
+	if err := srv.codeGen.SetCollisionPolicy(codegen.CollisionSuffix); err != nil {
+		t.Fatal(err)
+	}
+	first := generatedApp(t, postJSON(t, srv.handleGenerateApp, "/generate-app", body))
+	second := generatedApp(t, postJSON(t, srv.handleGenerateApp, "/generate-app", body))
+	if second["output_dir"] != first["output_dir"].(string)+"-2" {
+		t.Fatalf("Expected the second app in %s-2, got %v", first["output_dir"], second["output_dir"])
+	}
+	project, err := srv.store.GetProject(filepath.Base(second["output_dir"].(string)))
+	if err != nil || project.AppPath != second["output_dir"] {
+		t.Errorf("Expected the suffixed app to be recorded as its own project, got %+v (%v)", project, err)
+	}
+
+	if err := srv.codeGen.SetCollisionPolicy(codegen.CollisionError); err != nil {
+		t.Fatal(err)
+	}
+	rec := postJSON(t, srv.handleGenerateApp, "/generate-app", body)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+	project, err = srv.store.GetProject(filepath.Base(first["output_dir"].(string)))
+	if err != nil || project.Status != "completed" {
+		t.Errorf("Expected the existing project to stay completed, got %+v (%v)", project, err)
+	}
+}
+
+func TestGenerateAppRecordsProjectStatus(t *testing.T) {
+	srv := newTestServer(t)
+
+	app := generatedApp(t, postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+	}))
+	project, err := srv.store.GetProject(filepath.Base(app["output_dir"].(string)))
+	if err != nil {
+		t.Fatalf("Expected project to be recorded: %v", err)
+	}
+	if project.Status != "completed" || project.Requirements == nil || project.AppPath != app["output_dir"] {
+		t.Errorf("Expected completed project with requirements and path, got %+v", project)
+	}
+
+	// A generation error leaves the project marked failed with the error recorded
+	srv.codeGen.SetLimits(1, 0)
+	rec := postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+	project, err = srv.store.GetProject(project.ID)
+	if err != nil {
+		t.Fatalf("Failed to load project: %v", err)
+	}
+	if project.Status != "failed" || project.Metadata["error"] == nil {
+		t.Errorf("Expected failed project with an error, got %+v", project)
+	}
+
+	stats, err := srv.store.GetProjectStats("")
+	if err != nil {
+		t.Fatalf("GetProjectStats failed: %v", err)
+	}
+	if stats.FailedProjects != 1 {
+		t.Errorf("Expected 1 failed project in stats, got %d", stats.FailedProjects)
+	}
+}
+
+func TestGenerateAppCompileCheck(t *testing.T) {
+	srv := newTestServer(t)
+	generate := func(body map[string]string) map[string]interface{} {
+		rec := postJSON(t, srv.handleGenerateApp, "/generate-app", body)
+		generatedApp(t, rec)
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		return response
+	}
+
+	// newServer leaves the check off
+	if response := generate(map[string]string{"description": "user management api"}); response["compiles"] != nil {
+		t.Errorf("Expected no compile result with the check off, got %v", response["compiles"])
+	}
+
+	srv.compileCheck = true
+	if response := generate(map[string]string{"description": "user management api", "language": "javascript"}); response["compiles"] != nil {
+		t.Errorf("Expected no compile result for a JavaScript app, got %v", response["compiles"])
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	// Whether the app compiles here depends on the modules available offline;
+	// the response must report either way
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOWORK", "off")
+	response := generate(map[string]string{"description": "user management api"})
+	compiles, ok := response["compiles"].(bool)
+	if !ok {
+		t.Fatalf("Expected a compiles flag for a Go app, got %v", response["compiles"])
+	}
+	if output, _ := response["build_output"].(string); !compiles && output == "" {
+		t.Error("Expected build output for an app that does not compile")
+	}
+}
+
+func TestStatsEndpoint(t *testing.T) {
+	srv := newTestServer(t)
+
+	projects := []*storage.ProjectData{
+		{
+			ID:           "go-app",
+			Requirements: &requirements.ApplicationRequirement{Language: "go", Framework: "gin", Entities: make([]requirements.Entity, 3)},
+			GeneratedAt:  time.Now(),
+			Status:       "completed",
+			TestResults: &apptesting.TestSuite{
+				Coverage: 80,
+				Results:  []apptesting.TestResult{{Name: "Unit Tests", Status: "pass", Output: strings.Repeat("ok\n", 1000)}},
+			},
+		},
+		{
+			ID:           "js-app",
+			Requirements: &requirements.ApplicationRequirement{Language: "javascript", Framework: "express"},
+			GeneratedAt:  time.Now().Add(-time.Minute),
+			Status:       "failed",
+			TestResults:  &apptesting.TestSuite{Coverage: 40},
+		},
+	}
+	for _, project := range projects {
+		if err := srv.store.SaveProject(project); err != nil {
+			t.Fatalf("Failed to save project: %v", err)
+		}
+	}
+
+	code, stats := getJSON(t, srv.handleStats, "/stats")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, stats)
+	}
+	if stats["total_projects"] != float64(2) || stats["completed_projects"] != float64(1) || stats["failed_projects"] != float64(1) {
+		t.Errorf("Unexpected counts: %v", stats)
+	}
+	if stats["avg_test_coverage"] != float64(60) {
+		t.Errorf("Expected average coverage 60, got %v", stats["avg_test_coverage"])
+	}
+
+	recent := stats["recent_activity"].([]interface{})
+	if len(recent) != 2 || recent[0].(map[string]interface{})["id"] != "go-app" {
+		t.Fatalf("Expected newest project first in recent activity, got %v", recent)
+	}
+	latest := recent[0].(map[string]interface{})
+	result := latest["test_results"].(map[string]interface{})["results"].([]interface{})[0].(map[string]interface{})
+	if result["output"] != "" || result["status"] != "pass" {
+		t.Errorf("Expected test output to be stripped but status kept, got %v", result)
+	}
+	if entities := latest["requirements"].(map[string]interface{})["entities"]; entities != nil {
+		t.Errorf("Expected requirements to be reduced to the stack, got entities %v", entities)
+	}
+
+	_, filtered := getJSON(t, srv.handleStats, "/stats?language=go")
+	languages := filtered["popular_languages"].(map[string]interface{})
+	if len(languages) != 1 || languages["go"] != float64(1) || filtered["avg_test_coverage"] != float64(80) {
+		t.Errorf("Expected popularity and averages restricted to go, got %v", filtered)
+	}
+	if filtered["total_projects"] != float64(2) {
+		t.Errorf("Expected totals to cover all projects, got %v", filtered["total_projects"])
+	}
+}
+
+func TestDebugEndpoint(t *testing.T) {
+	srv := newTestServer(t)
+
+	app := generatedApp(t, postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+	}))
+	appPath := app["output_dir"].(string)
+	// A log with errors, and a vendored file that must not be scanned
+	os.WriteFile(filepath.Join(appPath, "app.log"), []byte("ERROR: connection refused\n"), 0644)
+	os.MkdirAll(filepath.Join(appPath, "vendor", "dep"), 0755)
+	os.WriteFile(filepath.Join(appPath, "vendor", "dep", "dep.log"), []byte("ERROR: vendored\n"), 0644)
+
+	rec := postJSON(t, srv.handleDebug, "/debug", map[string]string{"app_path": appPath})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var result struct {
+		Success bool `json:"success"`
+		Issues  []struct {
+			Type string `json:"type"`
+			File string `json:"file"`
+		} `json:"issues"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(result.Issues) == 0 {
+		t.Fatal("Expected issues to be reported")
+	}
+	foundLog := false
+	for _, issue := range result.Issues {
+		if strings.Contains(issue.File, "vendor") {
+			t.Errorf("Vendored file was scanned: %s", issue.File)
+		}
+		if strings.HasSuffix(issue.File, "app.log") {
+			foundLog = true
+		}
+	}
+	if !foundLog {
+		t.Errorf("Expected an issue from app.log, got %+v", result.Issues)
+	}
+
+	logs, err := srv.db.GetUnprocessedLogs()
+	if err != nil {
+		t.Fatalf("GetUnprocessedLogs failed: %v", err)
+	}
+	logged := false
+	for _, entry := range logs {
+		if entry.Endpoint == "/debug" && entry.AppPath == appPath && strings.Contains(entry.AnalysisResultsJSON, `"issues"`) {
+			logged = true
+		}
+	}
+	if !logged {
+		t.Error("Expected the analysis to be logged as an interaction")
+	}
+
+	rec = postJSON(t, srv.handleDebug, "/debug", map[string]string{"app_path": filepath.Join(appPath, "missing")})
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a missing path, got %d", rec.Code)
+	}
+}
+
+func TestGenerateAppRequestID(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+	})
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	requestID := rec.Header().Get("X-Request-ID")
+	if requestID == "" || response["request_id"] != requestID {
+		t.Fatalf("Expected matching X-Request-ID header and body, got %q and %v", requestID, response["request_id"])
+	}
+
+	interaction, err := srv.db.GetInteractionLog(requestID)
+	if err != nil {
+		t.Fatalf("Expected the interaction to be logged under the request ID: %v", err)
+	}
+	if interaction.Endpoint != "/generate-app" || interaction.Status != "success" {
+		t.Errorf("Unexpected interaction log %+v", interaction)
+	}
+}
+
+func TestLogsEndpoint(t *testing.T) {
+	srv := newTestServer(t)
+
+	postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{"description": "user management api"})
+	postJSON(t, srv.handleTestApp, "/test-app", map[string]string{"app_path": filepath.Join(t.TempDir(), "missing")})
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.handleListLogs(rec, httptest.NewRequest(http.MethodGet, "/logs"+query, nil))
+		return rec
+	}
+
+	rec := get("?status=failure")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Logs  []database.InteractionLog `json:"logs"`
+		Limit int                       `json:"limit"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Logs) != 1 || response.Logs[0].Endpoint != "/test-app" || response.Limit != defaultLogsLimit {
+		t.Errorf("Expected the failed /test-app log with the default limit, got %+v", response)
+	}
+
+	for _, query := range []string{"?limit=0", "?offset=-1", "?since=yesterday"} {
+		if rec := get(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestGenerateAppDryRun(t *testing.T) {
+	srv := newTestServer(t)
+
+	for _, tc := range []struct {
+		path string
+		body map[string]interface{}
+	}{
+		{"/generate-app?dry_run=true", map[string]interface{}{"description": "user management api"}},
+		{"/generate-app", map[string]interface{}{"description": "user management api", "dry_run": true}},
+	} {
+		rec := postJSON(t, srv.handleGenerateApp, tc.path, tc.body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for %s, got %d: %s", tc.path, rec.Code, rec.Body.String())
+		}
+		var response struct {
+			DryRun       bool                                `json:"dry_run"`
+			Requirements requirements.ApplicationRequirement `json:"requirements"`
+			Files        []string                            `json:"files"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if !response.DryRun || response.Requirements.Language != "go" || len(response.Requirements.Entities) == 0 {
+			t.Errorf("Expected the analyzed requirements of a dry run, got %+v", response)
+		}
+		if !containsString(response.Files, "main.go") || !containsString(response.Files, "go.mod") {
+			t.Errorf("Expected main.go and go.mod in the planned files, got %v", response.Files)
+		}
+	}
+
+	if entries, err := os.ReadDir(srv.outputDir); err != nil || len(entries) != 0 {
+		t.Errorf("Expected no files in the output dir after dry runs, got %v (%v)", entries, err)
+	}
+	if projects, err := srv.store.ListProjects(); err != nil || len(projects) != 0 {
+		t.Errorf("Expected no projects recorded for dry runs, got %d (%v)", len(projects), err)
+	}
+
+	if rec := postJSON(t, srv.handleGenerateApp, "/generate-app?dry_run=maybe", map[string]string{"description": "user management api"}); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid dry_run, got %d", rec.Code)
+	}
+}
+
+// stubLLM answers every analysis prompt with a canned response
+type stubLLM struct {
+	response string
+}
+
+func (p stubLLM) AnalyzeRequirements(prompt string) (string, error) {
+	return p.response, nil
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := postJSON(t, srv.handleValidate, "/validate", map[string]string{"description": "user management api"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	type validation struct {
+		Valid        bool                                `json:"valid"`
+		Error        string                              `json:"error"`
+		Requirements requirements.ApplicationRequirement `json:"requirements"`
+	}
+	var response validation
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !response.Valid || response.Requirements.Language != "go" || len(response.Requirements.Entities) == 0 {
+		t.Errorf("Expected valid analyzed requirements, got %+v", response)
+	}
+	if entries, err := os.ReadDir(srv.outputDir); err != nil || len(entries) != 0 {
+		t.Errorf("Expected nothing written by /validate, got %v (%v)", entries, err)
+	}
+	interaction, err := srv.db.GetInteractionLog(rec.Header().Get("X-Request-ID"))
+	if err != nil || interaction.Endpoint != "/validate" || interaction.Status != "success" {
+		t.Errorf("Expected a successful /validate interaction log, got %+v (%v)", interaction, err)
+	}
+
+	// An entity without fields fails validation
+	srv.reqAnalyzer = requirements.NewRequirementAnalyzer(stubLLM{response: `{
+		"name": "Shop", "type": "api", "language": "go", "framework": "gin",
+		"entities": [{"name": "Product", "fields": []}]
+	}`})
+	rec = postJSON(t, srv.handleValidate, "/validate", map[string]string{"description": "a shop selling products"})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+	response = validation{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Valid || !strings.Contains(response.Error, "entity Product must have at least one field") {
+		t.Errorf("Expected the validation failure, got valid=%v error=%q", response.Valid, response.Error)
+	}
+	if response.Requirements.Name != "Shop" {
+		t.Errorf("Expected the analyzed requirements with the error, got %+v", response.Requirements)
+	}
+	interaction, err = srv.db.GetInteractionLog(rec.Header().Get("X-Request-ID"))
+	if err != nil || interaction.Status != "failure" {
+		t.Errorf("Expected a failed /validate interaction log, got %+v (%v)", interaction, err)
+	}
+}
+
+// blockingLLM holds every analysis until its context is done
+type blockingLLM struct{}
+
+func (blockingLLM) AnalyzeRequirements(prompt string) (string, error) {
+	return "", errors.New("blockingLLM needs a context")
+}
+
+func (blockingLLM) AnalyzeRequirementsContext(ctx context.Context, prompt string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestErrorStatus(t *testing.T) {
+	validationErr := requirements.NewRequirementAnalyzer(nil).ValidateRequirements(&requirements.ApplicationRequirement{
+		Name: "app", Type: "api", Language: "cobol",
+	})
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"validation", &requirements.ValidationError{Err: errors.New("application name is required")}, http.StatusBadRequest},
+		{"unsupported language", validationErr, http.StatusBadRequest},
+		{"invalid app name", &codegen.GenerationError{Err: fmt.Errorf("%w: %q", requirements.ErrInvalidAppName, "..")}, http.StatusBadRequest},
+		{"generation limit", &codegen.GenerationError{Err: codegen.ErrGenerationLimit}, http.StatusBadRequest},
+		{"app exists", &codegen.GenerationError{Err: codegen.ErrAppExists}, http.StatusConflict},
+		{"LLM failure", &requirements.LLMError{Err: context.Canceled}, http.StatusBadGateway},
+		{"LLM timeout", &requirements.LLMError{Err: context.DeadlineExceeded}, http.StatusGatewayTimeout},
+		{"filesystem", &codegen.GenerationError{Err: os.ErrPermission}, http.StatusInternalServerError},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := errorStatus(tt.err); got != tt.want {
+			t.Errorf("%s: expected status %d, got %d for %v", tt.name, tt.want, got, tt.err)
+		}
+	}
+
+	// An aborted LLM request surfaces as 502 instead of a rule-based result
+	srv := newTestServer(t)
+	srv.reqAnalyzer = requirements.NewRequirementAnalyzer(blockingLLM{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/generate-app", strings.NewReader(`{"description": "user management api"}`)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	srv.handleGenerateApp(rec, req)
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected status 502, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Unsupported overrides are validation failures
+	srv.reqAnalyzer = requirements.NewRequirementAnalyzer(nil)
+	rec = postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+		"language":    "cobol",
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func containsString(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRegenerateEndpoint(t *testing.T) {
+	srv := newTestServer(t)
+
+	app := generatedApp(t, postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+	}))
+	appPath := app["output_dir"].(string)
+	dockerfile := filepath.Join(appPath, "Dockerfile")
+	if err := os.WriteFile(dockerfile, []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatalf("Failed to edit Dockerfile: %v", err)
+	}
+
+	rec := postJSON(t, srv.handleRegenerate, "/regenerate", map[string]string{"app_path": appPath, "component": "dockerfile"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if content, _ := os.ReadFile(dockerfile); !strings.Contains(string(content), "golang") {
+		t.Errorf("Expected the Dockerfile to be regenerated, got:\n%s", content)
+	}
+
+	for _, tc := range []struct {
+		body   map[string]string
+		status int
+	}{
+		{map[string]string{"app_path": appPath, "component": "frontend"}, http.StatusBadRequest},
+		{map[string]string{"app_path": appPath}, http.StatusBadRequest},
+		{map[string]string{"app_path": filepath.Join(srv.outputDir, "missing"), "component": "models"}, http.StatusNotFound},
+		{map[string]string{"app_path": filepath.Join(appPath, "..", ".."), "component": "dockerfile"}, http.StatusBadRequest},
+		{map[string]string{"app_path": srv.outputDir, "component": "dockerfile"}, http.StatusBadRequest},
+	} {
+		if rec := postJSON(t, srv.handleRegenerate, "/regenerate", tc.body); rec.Code != tc.status {
+			t.Errorf("Expected status %d for %v, got %d: %s", tc.status, tc.body, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestRegenerateRejectsPathsOutsideOutputDir(t *testing.T) {
+	srv := newTestServer(t)
+
+	// A generated app copied out of the output directory, reached directly
+	// and through a symlink inside it
+	app := generatedApp(t, postJSON(t, srv.handleGenerateApp, "/generate-app", map[string]string{
+		"description": "user management api",
+	}))
+	outside := filepath.Join(t.TempDir(), "app")
+	if err := os.Rename(app["output_dir"].(string), outside); err != nil {
+		t.Fatalf("Failed to move app: %v", err)
+	}
+	dockerfile := filepath.Join(outside, "Dockerfile")
+	if err := os.WriteFile(dockerfile, []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatalf("Failed to edit Dockerfile: %v", err)
+	}
+	link := filepath.Join(srv.outputDir, "link")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	for _, path := range []string{outside, link} {
+		rec := postJSON(t, srv.handleRegenerate, "/regenerate", map[string]string{"app_path": path, "component": "dockerfile"})
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+	if content, _ := os.ReadFile(dockerfile); string(content) != "FROM scratch\n" {
+		t.Errorf("Expected the Dockerfile outside the output dir to be left alone, got:\n%s", content)
+	}
+}
+
+func TestAppPathsOutsideOutputDirRejected(t *testing.T) {
+	srv := newTestServer(t)
+
+	// A Go project elsewhere on the host that must not be built, tested or
+	// written to
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "go.mod"), []byte("module outside\n\ngo 1.18\n"), 0644)
+	os.WriteFile(filepath.Join(outside, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	relative, err := filepath.Rel(srv.outputDir, outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{
+		outside,
+		filepath.Join(srv.outputDir, relative),
+		filepath.Join(srv.outputDir, "..", filepath.Base(srv.outputDir)),
+		"../" + filepath.Base(outside),
+	} {
+		for _, endpoint := range []struct {
+			path    string
+			handler http.HandlerFunc
+		}{
+			{"/test-app", srv.handleTestApp},
+			{"/debug", srv.handleDebug},
+		} {
+			rec := postJSON(t, endpoint.handler, endpoint.path, map[string]string{"app_path": path})
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400 for %s, got %d: %s", endpoint.path, path, rec.Code, rec.Body.String())
+			}
+		}
+	}
+	for _, name := range []string{apptesting.TestResultsFile, apptesting.JUnitResultsFile} {
+		if _, err := os.Stat(filepath.Join(outside, name)); !os.IsNotExist(err) {
+			t.Errorf("expected no %s outside the output dir, got %v", name, err)
+		}
+	}
+}
+
+func TestRunWorkflowEndpoint(t *testing.T) {
+	srv := newTestServer(t)
+	dir := t.TempDir()
+	srv.engine.RegisterWorkflow(workflow.Workflow{Name: "greet", Steps: []workflow.Step{
+		{Name: "hello", Command: "echo", Args: []string{"hello"}, WorkDir: dir, Timeout: time.Minute},
+	}})
+
+	rec := postJSON(t, srv.handleRunWorkflow, "/workflows/greet/run", map[string]string{"repository": "owner/repo"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Success bool            `json:"success"`
+		Result  workflow.Result `json:"result"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !response.Success || len(response.Result.Steps) != 1 || strings.TrimSpace(response.Result.Steps[0].Output) != "hello" {
+		t.Errorf("Expected the hello step to succeed, got %+v", response)
+	}
+	if response.Result.Context.Repository != "owner/repo" {
+		t.Errorf("Expected the supplied context, got %+v", response.Result.Context)
+	}
+
+	for _, tc := range []struct {
+		path   string
+		status int
+	}{
+		{"/workflows/missing/run", http.StatusNotFound},
+		{"/workflows/greet", http.StatusNotFound},
+	} {
+		if rec := postJSON(t, srv.handleRunWorkflow, tc.path, map[string]string{}); rec.Code != tc.status {
+			t.Errorf("Expected status %d for %s, got %d", tc.status, tc.path, rec.Code)
+		}
+	}
+}
+
+func TestGenerateAndTestStream(t *testing.T) {
+	srv := newTestServer(t)
+	srv.appTester.SetParallel(false)
+	ts := httptest.NewServer(http.HandlerFunc(srv.handleGenerateAndTestStream))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "?description=" + url.QueryEscape("user management api"))
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	var phases []string
+	var event string
+	var result map[string]interface{}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data := []byte(strings.TrimPrefix(line, "data: "))
+			switch event {
+			case "progress":
+				var progress progressEvent
+				if err := json.Unmarshal(data, &progress); err != nil {
+					t.Fatalf("Failed to decode progress event: %v", err)
+				}
+				phases = append(phases, progress.Phase)
+			case "result":
+				if err := json.Unmarshal(data, &result); err != nil {
+					t.Fatalf("Failed to decode result event: %v", err)
+				}
+			default:
+				t.Fatalf("Unexpected %s event: %s", event, data)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+
+	want := "analyzing,generating,building,testing:static,testing:unit,testing:api,testing:security,testing:performance"
+	if got := strings.Join(phases, ","); got != want {
+		t.Errorf("Expected phases %s, got %s", want, got)
+	}
+	if result == nil || result["test_results"] == nil {
+		t.Errorf("Expected a final result with test results, got %v", result)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.handleGenerateAndTestStream(rec, httptest.NewRequest(http.MethodGet, "/generate-and-test/stream", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a description, got %d", rec.Code)
+	}
+
+	// Overrides come from the query; an invalid one ends the stream with an error event
+	rec = httptest.NewRecorder()
+	srv.handleGenerateAndTestStream(rec, httptest.NewRequest(http.MethodGet, "/generate-and-test/stream?description=todo+api&language=cobol", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "event: error\n") || !strings.Contains(body, `"status":400`) {
+		t.Errorf("Expected an error event with status 400, got %q", body)
+	}
+}
+
+func TestGenerateBatchDuplicateNames(t *testing.T) {
+	for _, policy := range []string{codegen.CollisionOverwrite, codegen.CollisionSuffix} {
+		t.Run(policy, func(t *testing.T) {
+			srv := newTestServer(t)
+			if err := srv.codeGen.SetCollisionPolicy(policy); err != nil {
+				t.Fatalf("Failed to set collision policy: %v", err)
+			}
+			// An application generated earlier takes the analyzed name's directory
+			existing := filepath.Join(srv.outputDir, "generated-application")
+			if err := os.MkdirAll(existing, 0755); err != nil {
+				t.Fatalf("Failed to create existing app: %v", err)
+			}
+
+			descriptions := make([]string, 2*batchWorkers)
+			for i := range descriptions {
+				descriptions[i] = "todo api"
+			}
+			rec := postJSON(t, srv.handleGenerateBatch, "/generate-batch", map[string]interface{}{"descriptions": descriptions})
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			var response struct {
+				Success bool          `json:"success"`
+				Results []batchResult `json:"results"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if !response.Success {
+				t.Fatalf("Expected every item to succeed, got %s", rec.Body.String())
+			}
+
+			seen := map[string]bool{}
+			for _, result := range response.Results {
+				outputDir, _ := result.App["output_dir"].(string)
+				if seen[outputDir] {
+					t.Errorf("Result %d: output dir %q reported twice", result.Index, outputDir)
+				}
+				seen[outputDir] = true
+
+				var saved requirements.ApplicationRequirement
+				data, err := os.ReadFile(filepath.Join(outputDir, requirements.RequirementsFile))
+				if err != nil || json.Unmarshal(data, &saved) != nil || saved.Name != result.App["name"] {
+					t.Errorf("Result %d: expected %q to hold app %v, got %q (%v)", result.Index, outputDir, result.App["name"], saved.Name, err)
+				}
+				project, err := srv.store.GetProject(filepath.Base(outputDir))
+				if err != nil || project.AppPath != outputDir || project.Status != "completed" {
+					t.Errorf("Result %d: expected a completed project at %q, got %+v (%v)", result.Index, outputDir, project, err)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateBatchPartialSuccess(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := postJSON(t, srv.handleGenerateBatch, "/generate-batch", map[string]interface{}{
+		"descriptions": []string{
+			"user management api",
+			"",
+			"graphql api for products in python",
+			"blog api with posts",
+		},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Success   bool          `json:"success"`
+		Succeeded int           `json:"succeeded"`
+		Failed    int           `json:"failed"`
+		Results   []batchResult `json:"results"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Success || response.Succeeded != 2 || response.Failed != 2 || len(response.Results) != 4 {
+		t.Fatalf("Expected 2 of 4 items to succeed, got %s", rec.Body.String())
+	}
+
+	for i, want := range []bool{true, false, false, true} {
+		result := response.Results[i]
+		if result.Index != i || result.Success != want {
+			t.Errorf("Result %d: expected success %v, got %+v", i, want, result)
+		}
+		if want {
+			outputDir, _ := result.App["output_dir"].(string)
+			if _, err := os.Stat(outputDir); err != nil {
+				t.Errorf("Result %d: output dir %q missing: %v", i, outputDir, err)
+			}
+		} else if result.Error == "" || result.Status != http.StatusBadRequest {
+			t.Errorf("Result %d: expected a 400 error, got %+v", i, result)
+		}
+
+		interaction, err := srv.db.GetInteractionLog(result.RequestID)
+		if err != nil {
+			t.Fatalf("Result %d: expected its own interaction log: %v", i, err)
+		}
+		wantStatus := "failure"
+		if want {
+			wantStatus = "success"
+		}
+		if interaction.Endpoint != "/generate-batch" || interaction.Status != wantStatus {
+			t.Errorf("Result %d: unexpected interaction log %+v", i, interaction)
+		}
+	}
+
+	if rec := postJSON(t, srv.handleGenerateBatch, "/generate-batch", map[string]interface{}{"descriptions": []string{}}); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an empty batch, got %d", rec.Code)
+	}
+}